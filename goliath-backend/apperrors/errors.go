@@ -0,0 +1,116 @@
+// Package apperrors defines the typed errors returned by services.
+// The error middleware turns them into RFC 7807 problem responses; any other
+// error is reported as an internal error without exposing its message.
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind classifies an error by how clients should react to it
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindUnauthenticated
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindPreconditionRequired
+)
+
+// FieldError describes an invalid field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a domain error with a stable code clients can switch on
+type Error struct {
+	Kind    Kind
+	Code    string       // Stable machine-readable code, e.g. "workout_not_found"
+	Message string       // Human-readable description, safe to show to clients
+	Fields  []FieldError // Invalid fields of validation errors
+	Err     error        // Underlying cause, logged but never sent to clients
+}
+
+// ErrVersionConflict is returned when an update expects a version the row no longer has
+var ErrVersionConflict = Conflict("version_conflict", "the resource was modified by another request")
+
+// ErrPreconditionRequired is returned when an update does not say which version it expects
+var ErrPreconditionRequired = New(KindPreconditionRequired, "precondition_required", "updates require the expected version as version or If-Match")
+
+// New creates an error of the given kind
+func New(kind Kind, code string, format string, args ...interface{}) *Error {
+	return &Error{Kind: kind, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Invalid creates a validation error
+func Invalid(code string, format string, args ...interface{}) *Error {
+	return New(KindValidation, code, format, args...)
+}
+
+// Unauthenticated creates an error for requests without a valid identity
+func Unauthenticated(code string, format string, args ...interface{}) *Error {
+	return New(KindUnauthenticated, code, format, args...)
+}
+
+// Forbidden creates an error for requests the user is not allowed to make
+func Forbidden(code string, format string, args ...interface{}) *Error {
+	return New(KindForbidden, code, format, args...)
+}
+
+// NotFound creates an error for a missing resource
+func NotFound(code string, format string, args ...interface{}) *Error {
+	return New(KindNotFound, code, format, args...)
+}
+
+// Conflict creates an error for requests conflicting with the current state
+func Conflict(code string, format string, args ...interface{}) *Error {
+	return New(KindConflict, code, format, args...)
+}
+
+// Error returns the message followed by the underlying cause, if any
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error with the same code,
+// so package-level errors can be compared with errors.Is after Wrap
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap returns a copy of the error with the given underlying cause
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// WithField returns a copy of the error describing one more invalid field
+func (e *Error) WithField(field string, format string, args ...interface{}) *Error {
+	withField := *e
+	withField.Fields = append(append([]FieldError{}, e.Fields...), FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	return &withField
+}
+
+// As returns the first *Error in the chain of err
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
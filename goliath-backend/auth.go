@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"goliath/config"
+	"goliath/middleware"
+
+	firebase "firebase.google.com/go/v4"
+	"google.golang.org/api/option"
+)
+
+// newTokenVerifier creates the verifier of bearer tokens for the configured auth provider.
+// Firebase is only initialized when it is the provider, so the other ones work offline.
+func newTokenVerifier(ctx context.Context, cfg *config.Config) (middleware.TokenVerifier, error) {
+	switch cfg.Auth.Provider {
+	case config.AuthProviderJWT:
+		verifier, err := middleware.NewLocalJWTVerifier(middleware.LocalJWTConfig{
+			Algorithm: cfg.Auth.JWTAlgorithm,
+			KeyFile:   cfg.Auth.JWTKeyFile,
+			Issuer:    cfg.Auth.JWTIssuer,
+			Audience:  cfg.Auth.JWTAudience,
+		})
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Verifying %s tokens signed with %s", cfg.Auth.JWTAlgorithm, cfg.Auth.JWTKeyFile)
+		return verifier, nil
+	case config.AuthProviderStatic:
+		log.Printf("Warning: static auth provider - every bearer token authenticates as %s (%s), do not use in production",
+			cfg.Auth.StaticUID, cfg.Auth.StaticEmail)
+		return middleware.NewStaticVerifier(cfg.Auth.StaticUID, cfg.Auth.StaticEmail), nil
+	}
+
+	// Initialize Firebase
+	opt := option.WithCredentialsFile(cfg.Firebase.CredentialsFile)
+	app, err := firebase.NewApp(ctx, nil, opt)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing Firebase app: %w", err)
+	}
+
+	// Get Firebase Auth client
+	authClient, err := app.Auth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting Firebase Auth client: %w", err)
+	}
+	log.Println("Firebase initialized successfully")
+	return middleware.NewFirebaseVerifier(authClient), nil
+}
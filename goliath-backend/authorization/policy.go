@@ -0,0 +1,175 @@
+// Package authorization decides whether the user of a request may perform an action on a
+// resource. Handlers describe the resource from the URL, with the parents it is nested in, or from
+// the body when it refers to one, and call Policy.Authorize before calling services, which do not
+// check access themselves.
+package authorization
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"goliath/apperrors"
+	"goliath/entities"
+	"goliath/middleware"
+)
+
+// Action is what a user wants to do with a resource
+type Action string
+
+const (
+	ActionRead   Action = "read"
+	ActionWrite  Action = "write"  // Change the resource, or add, change and remove its children
+	ActionDelete Action = "delete" // Delete the resource itself
+)
+
+// ResourceType names a type of resource that has a loader
+type ResourceType string
+
+// Resource identifies the target of a request by its type and ID, and the parent it is nested in
+// by the URL, e.g. the workout of a workout exercise
+type Resource struct {
+	Type   ResourceType
+	ID     int
+	Parent *Resource
+}
+
+// chain returns the resource and its parents, the root first
+func (r Resource) chain() []Resource {
+	if r.Parent == nil {
+		return []Resource{r}
+	}
+	return append(r.Parent.chain(), r)
+}
+
+// Record is what a loader reports about a stored resource: the user owning it for root
+// resources, and the ID of its parent for nested ones
+type Record struct {
+	OwnerID  int
+	ParentID int
+}
+
+// Loader loads a resource by ID. It returns sql.ErrNoRows when the resource does not exist.
+type Loader func(ctx context.Context, id int) (*Record, error)
+
+// Grant shares the resources of an owner with other users, e.g. with coaches. It reports whether
+// the user may perform the action on resources owned by ownerID.
+type Grant func(ctx context.Context, user *entities.User, ownerID int, action Action) (bool, error)
+
+// Kind describes how to load a type of resource and, for root resources, who besides the owner
+// may access it
+type Kind struct {
+	Load      Loader
+	NotFound  *apperrors.Error // Missing, or not a child of the parent in the URL
+	Forbidden *apperrors.Error // Root resources only; NotFound for private resources
+
+	// Overrides are the permissions that allow an action on every user's resources
+	Overrides map[Action]entities.Permission
+	Grants    []Grant
+}
+
+// Policy authorizes actions on resources of the registered kinds
+type Policy struct {
+	kinds map[ResourceType]Kind
+}
+
+// NewPolicy creates a new Policy for resources of the given kinds, e.g. of WorkoutKinds and
+// SessionKinds
+func NewPolicy(kinds ...map[ResourceType]Kind) *Policy {
+	merged := make(map[ResourceType]Kind)
+	for _, k := range kinds {
+		for resourceType, kind := range k {
+			merged[resourceType] = kind
+		}
+	}
+	return &Policy{
+		kinds: merged,
+	}
+}
+
+// privateKind describes a root resource only its owner may access. Other users get the NotFound
+// error, so they cannot tell which IDs exist.
+func privateKind(load Loader, notFound *apperrors.Error) Kind {
+	return Kind{
+		Load:      load,
+		NotFound:  notFound,
+		Forbidden: notFound,
+	}
+}
+
+// Authorize returns nil if the user of the request may perform the action on the resource. It
+// loads the resource and its parents, and fails with the NotFound error of the first one that
+// does not exist or does not belong to its parent, or with the Forbidden error of the root when
+// the user is neither its owner, nor holds an override permission, nor has a grant from the owner.
+// Access is decided on the root before nested resources are loaded, so users cannot probe the IDs
+// of resources they may not see.
+func (p *Policy) Authorize(ctx context.Context, action Action, resource Resource) error {
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return middleware.ErrAuthenticationRequired
+	}
+
+	chain := resource.chain()
+	root := chain[0]
+	kind, record, err := p.load(ctx, root)
+	if err != nil {
+		return err
+	}
+	allowed, err := p.allowed(ctx, user, action, kind, record.OwnerID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return kind.Forbidden
+	}
+
+	for i := 1; i < len(chain); i++ {
+		kind, record, err := p.load(ctx, chain[i])
+		if err != nil {
+			return err
+		}
+		if record.ParentID != chain[i-1].ID {
+			return kind.NotFound
+		}
+	}
+	return nil
+}
+
+// load loads a resource with the loader of its kind
+func (p *Policy) load(ctx context.Context, resource Resource) (Kind, *Record, error) {
+	kind, ok := p.kinds[resource.Type]
+	if !ok {
+		return Kind{}, nil, fmt.Errorf("no loader for resource type %q", resource.Type)
+	}
+
+	record, err := kind.Load(ctx, resource.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return kind, nil, kind.NotFound.Wrap(err)
+		}
+		return kind, nil, fmt.Errorf("failed to load %s: %w", resource.Type, err)
+	}
+	return kind, record, nil
+}
+
+// allowed reports whether the user may perform the action on a root resource owned by ownerID
+func (p *Policy) allowed(ctx context.Context, user *entities.User, action Action, kind Kind, ownerID int) (bool, error) {
+	if user.ID == ownerID {
+		return true, nil
+	}
+	if permission, ok := kind.Overrides[action]; ok && user.HasPermission(permission) {
+		return true, nil
+	}
+
+	for _, grant := range kind.Grants {
+		granted, err := grant(ctx, user, ownerID, action)
+		if err != nil {
+			return false, err
+		}
+		if granted {
+			return true, nil
+		}
+	}
+	return false, nil
+}
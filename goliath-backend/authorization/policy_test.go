@@ -0,0 +1,316 @@
+package authorization
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"goliath/apperrors"
+	"goliath/entities"
+	"goliath/middleware"
+)
+
+// Users of the tests
+var (
+	owner    = &entities.User{ID: 1}
+	coach    = &entities.User{ID: 2}
+	admin    = &entities.User{ID: 3, Permissions: []entities.Permission{entities.PermissionWorkoutRead, entities.PermissionWorkoutWrite}}
+	auditor  = &entities.User{ID: 4, Permissions: []entities.Permission{entities.PermissionWorkoutRead}}
+	stranger = &entities.User{ID: 5}
+)
+
+// Stored resources of the tests: the owner's workout with an exercise and a set, and the same
+// for the stranger, whom nobody coaches
+const (
+	ownerWorkout, ownerWorkoutExercise, ownerSet          = 10, 11, 12
+	strangerWorkout, strangerWorkoutExercise, strangerSet = 20, 21, 22
+	missing                                               = 99
+)
+
+// mapLoader loads resources from a map of ID to record
+func mapLoader(records map[int]Record) Loader {
+	return func(ctx context.Context, id int) (*Record, error) {
+		record, ok := records[id]
+		if !ok {
+			return nil, sql.ErrNoRows
+		}
+		return &record, nil
+	}
+}
+
+// newTestPolicy creates a policy over the workouts of the tests, where coach coaches owner
+func newTestPolicy() *Policy {
+	isCoaching := func(ctx context.Context, coachID int, athleteID int) (bool, error) {
+		return coachID == coach.ID && athleteID == owner.ID, nil
+	}
+	return NewPolicy(workoutKinds(
+		mapLoader(map[int]Record{ownerWorkout: {OwnerID: owner.ID}, strangerWorkout: {OwnerID: stranger.ID}}),
+		mapLoader(map[int]Record{ownerWorkoutExercise: {ParentID: ownerWorkout}, strangerWorkoutExercise: {ParentID: strangerWorkout}}),
+		mapLoader(map[int]Record{ownerSet: {ParentID: ownerWorkoutExercise}, strangerSet: {ParentID: strangerWorkoutExercise}}),
+		coachingGrant(isCoaching),
+	))
+}
+
+// withUser returns a context of a request by the user
+func withUser(user *entities.User) context.Context {
+	return context.WithValue(context.Background(), middleware.UserContextKey, user)
+}
+
+// errorCode returns the code of an application error, "" for nil
+func errorCode(t *testing.T, err error) string {
+	t.Helper()
+	if err == nil {
+		return ""
+	}
+	appErr, ok := apperrors.As(err)
+	if !ok {
+		t.Fatalf("expected an application error, got %v", err)
+	}
+	return appErr.Code
+}
+
+// access is an action on a resource built from the IDs of a request
+type access struct {
+	name     string
+	action   Action
+	resource func(workoutID, workoutExerciseID, setID int) Resource
+}
+
+// accesses are the actions handlers authorize on workouts, their exercises and sets. Which route
+// asks for which one is tested on the router of the main package.
+var accesses = []access{
+	{"read workout", ActionRead, workoutOf},
+	{"write workout", ActionWrite, workoutOf},
+	{"delete workout", ActionDelete, workoutOf},
+	{"read workout exercise", ActionRead, workoutExerciseOf},
+	{"write workout exercise", ActionWrite, workoutExerciseOf},
+	{"write set", ActionWrite, WorkoutExerciseSet},
+}
+
+// workoutOf builds the resource of accesses to a workout
+func workoutOf(workoutID, workoutExerciseID, setID int) Resource {
+	return Workout(workoutID)
+}
+
+// workoutExerciseOf builds the resource of accesses to an exercise of a workout
+func workoutExerciseOf(workoutID, workoutExerciseID, setID int) Resource {
+	return WorkoutExercise(workoutID, workoutExerciseID)
+}
+
+// TestAuthorizeAccess checks who may perform every action on the owner's workout
+func TestAuthorizeAccess(t *testing.T) {
+	const forbidden = "workout_forbidden"
+
+	// Expected error code per action and user; "" when allowed
+	access := map[Action]map[*entities.User]string{
+		ActionRead:   {owner: "", coach: "", admin: "", auditor: "", stranger: forbidden},
+		ActionWrite:  {owner: "", coach: "", admin: "", auditor: forbidden, stranger: forbidden},
+		ActionDelete: {owner: "", coach: forbidden, admin: "", auditor: forbidden, stranger: forbidden},
+	}
+	users := map[*entities.User]string{owner: "owner", coach: "coach", admin: "admin", auditor: "auditor", stranger: "stranger"}
+
+	policy := newTestPolicy()
+	for _, a := range accesses {
+		for user, want := range access[a.action] {
+			t.Run(a.name+" as "+users[user], func(t *testing.T) {
+				err := policy.Authorize(withUser(user), a.action, a.resource(ownerWorkout, ownerWorkoutExercise, ownerSet))
+				if got := errorCode(t, err); got != want {
+					t.Errorf("got %q, want %q", got, want)
+				}
+			})
+		}
+	}
+}
+
+// TestAuthorizeNesting checks that every access refuses resources missing or not belonging to the
+// parent in the URL, such as an exercise of another workout
+func TestAuthorizeNesting(t *testing.T) {
+	tests := []struct {
+		name                                string
+		user                                *entities.User
+		workoutID, workoutExerciseID, setID int
+		want                                map[ResourceType]string // Expected code by type of the resource of the access
+	}{
+		{
+			name:      "missing workout",
+			user:      owner,
+			workoutID: missing, workoutExerciseID: ownerWorkoutExercise, setID: ownerSet,
+			want: map[ResourceType]string{ResourceWorkout: "workout_not_found", ResourceWorkoutExercise: "workout_not_found", ResourceWorkoutExerciseSet: "workout_not_found"},
+		},
+		{
+			name:      "missing exercise",
+			user:      owner,
+			workoutID: ownerWorkout, workoutExerciseID: missing, setID: ownerSet,
+			want: map[ResourceType]string{ResourceWorkout: "", ResourceWorkoutExercise: "workout_exercise_not_found", ResourceWorkoutExerciseSet: "workout_exercise_not_found"},
+		},
+		{
+			name:      "missing set",
+			user:      owner,
+			workoutID: ownerWorkout, workoutExerciseID: ownerWorkoutExercise, setID: missing,
+			want: map[ResourceType]string{ResourceWorkout: "", ResourceWorkoutExercise: "", ResourceWorkoutExerciseSet: "set_not_found"},
+		},
+		{
+			name:      "exercise of another workout",
+			user:      owner,
+			workoutID: ownerWorkout, workoutExerciseID: strangerWorkoutExercise, setID: strangerSet,
+			want: map[ResourceType]string{ResourceWorkout: "", ResourceWorkoutExercise: "workout_exercise_not_found", ResourceWorkoutExerciseSet: "workout_exercise_not_found"},
+		},
+		{
+			name:      "set of another exercise",
+			user:      owner,
+			workoutID: ownerWorkout, workoutExerciseID: ownerWorkoutExercise, setID: strangerSet,
+			want: map[ResourceType]string{ResourceWorkout: "", ResourceWorkoutExercise: "", ResourceWorkoutExerciseSet: "set_not_found"},
+		},
+		{
+			name:      "own exercise under a foreign workout",
+			user:      owner,
+			workoutID: strangerWorkout, workoutExerciseID: ownerWorkoutExercise, setID: ownerSet,
+			want: map[ResourceType]string{ResourceWorkout: "workout_forbidden", ResourceWorkoutExercise: "workout_forbidden", ResourceWorkoutExerciseSet: "workout_forbidden"},
+		},
+		{
+			name:      "admin with an exercise of another workout",
+			user:      admin,
+			workoutID: strangerWorkout, workoutExerciseID: ownerWorkoutExercise, setID: ownerSet,
+			want: map[ResourceType]string{ResourceWorkout: "", ResourceWorkoutExercise: "workout_exercise_not_found", ResourceWorkoutExerciseSet: "workout_exercise_not_found"},
+		},
+	}
+
+	policy := newTestPolicy()
+	for _, tt := range tests {
+		for _, a := range accesses {
+			t.Run(a.name+" with "+tt.name, func(t *testing.T) {
+				resource := a.resource(tt.workoutID, tt.workoutExerciseID, tt.setID)
+				err := policy.Authorize(withUser(tt.user), a.action, resource)
+				want := tt.want[resource.Type]
+				if got := errorCode(t, err); got != want {
+					t.Errorf("got %q, want %q", got, want)
+				}
+			})
+		}
+	}
+}
+
+// Stored private resources of the tests: sessions with a set, programs with a week and a day,
+// enrollments and schedules of the owner and the stranger
+const (
+	ownerSession, ownerSessionSet, strangerSession, strangerSessionSet = 30, 31, 32, 33
+	ownerProgram, ownerProgramWeek, ownerProgramDay                    = 40, 41, 42
+	strangerProgram, strangerProgramWeek, strangerProgramDay           = 43, 44, 45
+	ownerEnrollment, strangerEnrollment                                = 46, 47
+	ownerSchedule, strangerSchedule                                    = 50, 51
+)
+
+// newPrivateTestPolicy creates a policy over the private resources of the tests
+func newPrivateTestPolicy() *Policy {
+	return NewPolicy(
+		sessionKinds(
+			mapLoader(map[int]Record{ownerSession: {OwnerID: owner.ID}, strangerSession: {OwnerID: stranger.ID}}),
+			mapLoader(map[int]Record{ownerSessionSet: {ParentID: ownerSession}, strangerSessionSet: {ParentID: strangerSession}}),
+		),
+		programKinds(
+			mapLoader(map[int]Record{ownerProgram: {OwnerID: owner.ID}, strangerProgram: {OwnerID: stranger.ID}}),
+			mapLoader(map[int]Record{ownerProgramWeek: {ParentID: ownerProgram}, strangerProgramWeek: {ParentID: strangerProgram}}),
+			mapLoader(map[int]Record{ownerProgramDay: {ParentID: ownerProgramWeek}, strangerProgramDay: {ParentID: strangerProgramWeek}}),
+			mapLoader(map[int]Record{ownerEnrollment: {OwnerID: owner.ID}, strangerEnrollment: {OwnerID: stranger.ID}}),
+		),
+		scheduleKinds(
+			mapLoader(map[int]Record{ownerSchedule: {OwnerID: owner.ID}, strangerSchedule: {OwnerID: stranger.ID}}),
+		),
+	)
+}
+
+// TestAuthorizePrivate checks that only the owner may access sessions, programs, enrollments and
+// schedules, and that everybody else, even holders of the workout permissions and coaches, gets
+// the error of a missing resource, so they cannot tell which IDs exist
+func TestAuthorizePrivate(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     *entities.User
+		resource Resource
+		want     string
+	}{
+		{"own session", owner, Session(ownerSession), ""},
+		{"own session set", owner, SessionSet(ownerSession, ownerSessionSet), ""},
+		{"foreign session", owner, Session(strangerSession), "session_not_found"},
+		{"session as coach", coach, Session(ownerSession), "session_not_found"},
+		{"session as admin", admin, Session(ownerSession), "session_not_found"},
+		{"missing session", owner, Session(missing), "session_not_found"},
+		{"own set under a foreign session", owner, SessionSet(strangerSession, ownerSessionSet), "session_not_found"},
+		{"set of another session", owner, SessionSet(ownerSession, strangerSessionSet), "set_not_found"},
+
+		{"own program", owner, Program(ownerProgram), ""},
+		{"own program day", owner, ProgramDay(ownerProgram, ownerProgramWeek, ownerProgramDay), ""},
+		{"foreign program", owner, Program(strangerProgram), "program_not_found"},
+		{"program as coach", coach, Program(ownerProgram), "program_not_found"},
+		{"week of another program", owner, ProgramWeek(ownerProgram, strangerProgramWeek), "program_week_not_found"},
+		{"day of another week", owner, ProgramDay(ownerProgram, ownerProgramWeek, strangerProgramDay), "program_day_not_found"},
+		{"own enrollment", owner, Enrollment(ownerEnrollment), ""},
+		{"foreign enrollment", owner, Enrollment(strangerEnrollment), "enrollment_not_found"},
+
+		{"own schedule", owner, Schedule(ownerSchedule), ""},
+		{"foreign schedule", owner, Schedule(strangerSchedule), "schedule_not_found"},
+		{"schedule as admin", admin, Schedule(ownerSchedule), "schedule_not_found"},
+	}
+
+	policy := newPrivateTestPolicy()
+	for _, tt := range tests {
+		for _, action := range []Action{ActionRead, ActionWrite, ActionDelete} {
+			t.Run(string(action)+" "+tt.name, func(t *testing.T) {
+				err := policy.Authorize(withUser(tt.user), action, tt.resource)
+				if got := errorCode(t, err); got != tt.want {
+					t.Errorf("got %q, want %q", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+// TestAuthorizeWithoutUser checks that every access requires a signed-in user
+func TestAuthorizeWithoutUser(t *testing.T) {
+	policy := newTestPolicy()
+	for _, a := range accesses {
+		t.Run(a.name, func(t *testing.T) {
+			err := policy.Authorize(context.Background(), a.action, a.resource(ownerWorkout, ownerWorkoutExercise, ownerSet))
+			if got := errorCode(t, err); got != "authentication_required" {
+				t.Errorf("got %q, want %q", got, "authentication_required")
+			}
+		})
+	}
+}
+
+// TestAuthorizeFailures checks that failing loaders and grants are not reported as missing or
+// forbidden resources
+func TestAuthorizeFailures(t *testing.T) {
+	failure := errors.New("database is locked")
+	failing := func(ctx context.Context, id int) (*Record, error) {
+		return nil, failure
+	}
+	found := mapLoader(map[int]Record{ownerWorkout: {OwnerID: owner.ID}, ownerWorkoutExercise: {ParentID: ownerWorkout}})
+	failingGrant := func(ctx context.Context, user *entities.User, ownerID int, action Action) (bool, error) {
+		return false, failure
+	}
+
+	tests := []struct {
+		name     string
+		policy   *Policy
+		user     *entities.User
+		resource Resource
+	}{
+		{"workout loader", NewPolicy(workoutKinds(failing, found, found)), owner, Workout(ownerWorkout)},
+		{"exercise loader", NewPolicy(workoutKinds(found, failing, found)), owner, WorkoutExercise(ownerWorkout, ownerWorkoutExercise)},
+		{"grant", NewPolicy(workoutKinds(found, found, found, failingGrant)), stranger, Workout(ownerWorkout)},
+		{"unknown resource type", NewPolicy(nil), owner, Workout(ownerWorkout)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Authorize(withUser(tt.user), ActionRead, tt.resource)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if _, ok := apperrors.As(err); ok {
+				t.Errorf("expected an internal error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package authorization
+
+import (
+	"context"
+
+	"goliath/repositories"
+	"goliath/services"
+)
+
+// Resource types of programs, what they contain and the enrollments following them
+const (
+	ResourceProgram     ResourceType = "program"
+	ResourceProgramWeek ResourceType = "program_week"
+	ResourceProgramDay  ResourceType = "program_day"
+	ResourceEnrollment  ResourceType = "enrollment"
+)
+
+// Program identifies a training program
+func Program(id int) Resource {
+	return Resource{Type: ResourceProgram, ID: id}
+}
+
+// ProgramWeek identifies a week of a program
+func ProgramWeek(programID int, id int) Resource {
+	program := Program(programID)
+	return Resource{Type: ResourceProgramWeek, ID: id, Parent: &program}
+}
+
+// ProgramDay identifies a day of a week of a program
+func ProgramDay(programID int, weekID int, id int) Resource {
+	week := ProgramWeek(programID, weekID)
+	return Resource{Type: ResourceProgramDay, ID: id, Parent: &week}
+}
+
+// Enrollment identifies a user's enrollment in a program
+func Enrollment(id int) Resource {
+	return Resource{Type: ResourceEnrollment, ID: id}
+}
+
+// ProgramKinds returns the kinds of programs, their weeks and days, and of enrollments. Programs
+// and enrollments are private to their owner.
+func ProgramKinds(programRepo *repositories.ProgramRepository, programWeekRepo *repositories.ProgramWeekRepository, programDayRepo *repositories.ProgramDayRepository, enrollmentRepo *repositories.ProgramEnrollmentRepository) map[ResourceType]Kind {
+	loadProgram := func(ctx context.Context, id int) (*Record, error) {
+		program, err := programRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &Record{OwnerID: program.UserID}, nil
+	}
+	loadProgramWeek := func(ctx context.Context, id int) (*Record, error) {
+		week, err := programWeekRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &Record{ParentID: week.ProgramID}, nil
+	}
+	loadProgramDay := func(ctx context.Context, id int) (*Record, error) {
+		day, err := programDayRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &Record{ParentID: day.ProgramWeekID}, nil
+	}
+	loadEnrollment := func(ctx context.Context, id int) (*Record, error) {
+		enrollment, err := enrollmentRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &Record{OwnerID: enrollment.UserID}, nil
+	}
+	return programKinds(loadProgram, loadProgramWeek, loadProgramDay, loadEnrollment)
+}
+
+// programKinds returns the kinds of programs, their weeks and days, and of enrollments with the
+// given loaders
+func programKinds(loadProgram Loader, loadProgramWeek Loader, loadProgramDay Loader, loadEnrollment Loader) map[ResourceType]Kind {
+	return map[ResourceType]Kind{
+		ResourceProgram: privateKind(loadProgram, services.ErrProgramNotFound),
+		ResourceProgramWeek: {
+			Load:     loadProgramWeek,
+			NotFound: services.ErrProgramWeekNotFound,
+		},
+		ResourceProgramDay: {
+			Load:     loadProgramDay,
+			NotFound: services.ErrProgramDayNotFound,
+		},
+		ResourceEnrollment: privateKind(loadEnrollment, services.ErrEnrollmentNotFound),
+	}
+}
@@ -0,0 +1,35 @@
+package authorization
+
+import (
+	"context"
+
+	"goliath/repositories"
+	"goliath/services"
+)
+
+// ResourceSchedule is the resource type of workout schedules
+const ResourceSchedule ResourceType = "schedule"
+
+// Schedule identifies a workout schedule
+func Schedule(id int) Resource {
+	return Resource{Type: ResourceSchedule, ID: id}
+}
+
+// ScheduleKinds returns the kind of workout schedules. Schedules are private to their owner.
+func ScheduleKinds(scheduleRepo *repositories.ScheduleRepository) map[ResourceType]Kind {
+	loadSchedule := func(ctx context.Context, id int) (*Record, error) {
+		schedule, err := scheduleRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &Record{OwnerID: schedule.UserID}, nil
+	}
+	return scheduleKinds(loadSchedule)
+}
+
+// scheduleKinds returns the kind of workout schedules with the given loader
+func scheduleKinds(loadSchedule Loader) map[ResourceType]Kind {
+	return map[ResourceType]Kind{
+		ResourceSchedule: privateKind(loadSchedule, services.ErrScheduleNotFound),
+	}
+}
@@ -0,0 +1,55 @@
+package authorization
+
+import (
+	"context"
+
+	"goliath/repositories"
+	"goliath/services"
+)
+
+// Resource types of performed sessions and their sets
+const (
+	ResourceSession    ResourceType = "session"
+	ResourceSessionSet ResourceType = "session_set"
+)
+
+// Session identifies a performed workout session
+func Session(id int) Resource {
+	return Resource{Type: ResourceSession, ID: id}
+}
+
+// SessionSet identifies a performed set of a session
+func SessionSet(sessionID int, id int) Resource {
+	session := Session(sessionID)
+	return Resource{Type: ResourceSessionSet, ID: id, Parent: &session}
+}
+
+// SessionKinds returns the kinds of sessions and their sets. Sessions are private to their owner.
+func SessionKinds(sessionRepo *repositories.SessionRepository, sessionSetRepo *repositories.SessionSetRepository) map[ResourceType]Kind {
+	loadSession := func(ctx context.Context, id int) (*Record, error) {
+		session, err := sessionRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &Record{OwnerID: session.UserID}, nil
+	}
+	loadSessionSet := func(ctx context.Context, id int) (*Record, error) {
+		set, err := sessionSetRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &Record{ParentID: set.SessionID}, nil
+	}
+	return sessionKinds(loadSession, loadSessionSet)
+}
+
+// sessionKinds returns the kinds of sessions and their sets with the given loaders
+func sessionKinds(loadSession Loader, loadSessionSet Loader) map[ResourceType]Kind {
+	return map[ResourceType]Kind{
+		ResourceSession: privateKind(loadSession, services.ErrSessionNotFound),
+		ResourceSessionSet: {
+			Load:     loadSessionSet,
+			NotFound: services.ErrSetNotFound,
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package authorization
+
+import (
+	"context"
+
+	"goliath/entities"
+	"goliath/repositories"
+	"goliath/services"
+)
+
+// Resource types of workouts and what they contain
+const (
+	ResourceWorkout            ResourceType = "workout"
+	ResourceWorkoutExercise    ResourceType = "workout_exercise"
+	ResourceWorkoutExerciseSet ResourceType = "workout_exercise_set"
+)
+
+// Workout identifies a workout
+func Workout(id int) Resource {
+	return Resource{Type: ResourceWorkout, ID: id}
+}
+
+// WorkoutExercise identifies an exercise of a workout
+func WorkoutExercise(workoutID int, id int) Resource {
+	workout := Workout(workoutID)
+	return Resource{Type: ResourceWorkoutExercise, ID: id, Parent: &workout}
+}
+
+// WorkoutExerciseSet identifies a set of an exercise of a workout
+func WorkoutExerciseSet(workoutID int, workoutExerciseID int, id int) Resource {
+	workoutExercise := WorkoutExercise(workoutID, workoutExerciseID)
+	return Resource{Type: ResourceWorkoutExerciseSet, ID: id, Parent: &workoutExercise}
+}
+
+// WorkoutKinds returns the kinds of workouts, their exercises and sets. Holders of workout:read
+// and workout:write may read and change every user's workouts; grants share them with others.
+func WorkoutKinds(workoutRepo *repositories.WorkoutRepository, workoutExerciseRepo *repositories.WorkoutExerciseRepository, workoutExerciseSetRepo *repositories.WorkoutExerciseSetRepository, grants ...Grant) map[ResourceType]Kind {
+	loadWorkout := func(ctx context.Context, id int) (*Record, error) {
+		workout, err := workoutRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &Record{OwnerID: workout.UserID}, nil
+	}
+	loadWorkoutExercise := func(ctx context.Context, id int) (*Record, error) {
+		workoutExercise, err := workoutExerciseRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &Record{ParentID: workoutExercise.WorkoutID}, nil
+	}
+	loadWorkoutExerciseSet := func(ctx context.Context, id int) (*Record, error) {
+		set, err := workoutExerciseSetRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return &Record{ParentID: set.WorkoutExerciseID}, nil
+	}
+	return workoutKinds(loadWorkout, loadWorkoutExercise, loadWorkoutExerciseSet, grants...)
+}
+
+// workoutKinds returns the kinds of workouts, their exercises and sets with the given loaders
+func workoutKinds(loadWorkout Loader, loadWorkoutExercise Loader, loadWorkoutExerciseSet Loader, grants ...Grant) map[ResourceType]Kind {
+	return map[ResourceType]Kind{
+		ResourceWorkout: {
+			Load:      loadWorkout,
+			NotFound:  services.ErrWorkoutNotFound,
+			Forbidden: services.ErrWorkoutForbidden,
+			Overrides: map[Action]entities.Permission{
+				ActionRead:   entities.PermissionWorkoutRead,
+				ActionWrite:  entities.PermissionWorkoutWrite,
+				ActionDelete: entities.PermissionWorkoutWrite,
+			},
+			Grants: grants,
+		},
+		ResourceWorkoutExercise: {
+			Load:     loadWorkoutExercise,
+			NotFound: services.ErrWorkoutExerciseNotFound,
+		},
+		ResourceWorkoutExerciseSet: {
+			Load:     loadWorkoutExerciseSet,
+			NotFound: services.ErrSetNotFound,
+		},
+	}
+}
+
+// CoachingGrant lets coaches read and change, but not delete, the workouts of athletes who
+// accepted their invite, as long as their role grants athlete:coach
+func CoachingGrant(coachingRepo *repositories.CoachingRepository) Grant {
+	return coachingGrant(coachingRepo.IsCoaching)
+}
+
+// coachingGrant builds the coaching grant on a check whether a user coaches an athlete
+func coachingGrant(isCoaching func(ctx context.Context, coachID int, athleteID int) (bool, error)) Grant {
+	return func(ctx context.Context, user *entities.User, ownerID int, action Action) (bool, error) {
+		if action != ActionRead && action != ActionWrite {
+			return false, nil
+		}
+		return isCoaching(ctx, user.ID, ownerID)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"goliath/config"
+)
+
+// commandUsage lists the maintenance commands run instead of the server
+const commandUsage = `usage: goliath-backend [flags] [command]
+
+Without a command the server is started. Flags override the config file and environment
+variables, see -h. Commands:
+  migrate status      Show the applied and pending migrations
+  migrate up          Apply all pending migrations
+  migrate down        Revert the last applied migration
+  migrate to <N>      Apply or revert migrations until the database is at version N
+  vacuum              Rebuild the database file to reclaim unused space
+  backup              Take a snapshot of the database
+  backup list         List the snapshots, newest first
+  backup verify <S>   Check that snapshot S is an intact Goliath database
+  restore <S>         Replace the database with snapshot S, saving the current one first
+  config print        Print the effective configuration and where each setting came from`
+
+// runCommand runs a maintenance command given on the command line
+func runCommand(cfg *config.Config, args []string) error {
+	switch args[0] {
+	case "migrate":
+		return runMigrate(cfg.Database, args[1:])
+	case "vacuum":
+		if len(args) != 1 {
+			return fmt.Errorf("vacuum takes no arguments\n\n%s", commandUsage)
+		}
+		db, err := openDB(cfg.Database)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		return vacuumDB(db)
+	case "backup":
+		return runBackup(cfg, args[1:])
+	case "restore":
+		if len(args) != 2 {
+			return fmt.Errorf("restore takes a snapshot name\n\n%s", commandUsage)
+		}
+		result, err := newBackupService(cfg).Restore(context.Background(), args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Restored %s (schema version %d); the previous database was saved as %s\n", result.Restored.Name, result.Restored.SchemaVersion, result.PreRestore.Name)
+		return nil
+	case "config":
+		if len(args) != 2 || args[1] != "print" {
+			return fmt.Errorf("invalid config arguments\n\n%s", commandUsage)
+		}
+		if cfg.File() != "" {
+			fmt.Printf("# Loaded from %s\n", cfg.File())
+		}
+		return cfg.Print(os.Stdout)
+	case "help":
+		fmt.Println(commandUsage)
+		return nil
+	}
+	return fmt.Errorf("unknown command %q\n\n%s", args[0], commandUsage)
+}
+
+// runMigrate runs a migrate subcommand
+func runMigrate(cfg config.DatabaseConfig, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate subcommand\n\n%s", commandUsage)
+	}
+	if (args[0] == "to") != (len(args) == 2) || len(args) > 2 {
+		return fmt.Errorf("invalid migrate arguments\n\n%s", commandUsage)
+	}
+
+	migrations, err := GetMigrations()
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+	latest := 0
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].Version
+	}
+
+	if args[0] != "up" && args[0] != "to" && !fileExists(cfg.Path) {
+		return fmt.Errorf("database %s does not exist", cfg.Path)
+	}
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "status":
+		return printMigrationStatus(db, migrations)
+	case "up":
+		return migrateTo(db, migrations, latest)
+	case "down":
+		currentVersion, err := getUserVersion(db)
+		if err != nil {
+			return err
+		}
+		if currentVersion == 0 {
+			return fmt.Errorf("no migration to revert")
+		}
+		return migrateTo(db, migrations, previousVersion(migrations, currentVersion))
+	case "to":
+		target, err := strconv.Atoi(args[1])
+		if err != nil || !isMigrationVersion(migrations, target) {
+			return fmt.Errorf("invalid version %q: must be 0 or the version of a migration (1-%d)", args[1], latest)
+		}
+		return migrateTo(db, migrations, target)
+	}
+	return fmt.Errorf("unknown migrate subcommand %q\n\n%s", args[0], commandUsage)
+}
+
+// isMigrationVersion reports whether version is 0 or the version of a migration
+func isMigrationVersion(migrations []Migration, version int) bool {
+	if version == 0 {
+		return true
+	}
+	for _, migration := range migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// printMigrationStatus writes the state of every migration as a table
+func printMigrationStatus(db *sql.DB, migrations []Migration) error {
+	currentVersion, statuses, err := getMigrationStatus(db, migrations)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Database version: %d\n\n", currentVersion)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED\tDOWN")
+	downs := make(map[int]bool, len(migrations))
+	for _, migration := range migrations {
+		downs[migration.Version] = migration.DownSQL != ""
+	}
+	for _, status := range statuses {
+		down := "no"
+		if downs[status.Version] {
+			down = "yes"
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\t%s\n", status.Version, status.Name, status.State, status.AppliedWhen, down)
+	}
+	return w.Flush()
+}
+
+// runBackup runs a backup subcommand
+func runBackup(cfg *config.Config, args []string) error {
+	backupService := newBackupService(cfg)
+	ctx := context.Background()
+
+	switch {
+	case len(args) == 0:
+		if !fileExists(cfg.Database.Path) {
+			return fmt.Errorf("database %s does not exist", cfg.Database.Path)
+		}
+		snapshot, err := backupService.Create(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s (%d bytes)\n", snapshot.Name, snapshot.SizeBytes)
+		return nil
+	case len(args) == 1 && args[0] == "list":
+		snapshots, err := backupService.List()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tCREATED\tSIZE")
+		for _, snapshot := range snapshots {
+			fmt.Fprintf(w, "%s\t%s\t%d\n", snapshot.Name, snapshot.CreatedWhen.Format(time.RFC3339), snapshot.SizeBytes)
+		}
+		return w.Flush()
+	case len(args) == 2 && args[0] == "verify":
+		verification, err := backupService.Verify(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s is intact (schema version %d)\n", verification.Name, verification.SchemaVersion)
+		return nil
+	}
+	return fmt.Errorf("invalid backup arguments\n\n%s", commandUsage)
+}
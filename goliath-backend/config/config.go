@@ -0,0 +1,223 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Log levels
+const (
+	LogLevelDebug = "debug" // Request log and gin debug output, including the registered routes
+	LogLevelInfo  = "info"  // Request log
+	LogLevelWarn  = "warn"  // No request log
+	LogLevelError = "error" // No request log
+)
+
+// Authentication providers
+const (
+	AuthProviderFirebase = "firebase" // Firebase ID tokens
+	AuthProviderJWT      = "jwt"      // Tokens signed with a local key
+	AuthProviderStatic   = "static"   // Development only: any bearer token is the static user
+)
+
+// Config holds the settings of the server and the maintenance commands
+type Config struct {
+	Server   ServerConfig   `yaml:"server"`
+	Database DatabaseConfig `yaml:"database"`
+	Backup   BackupConfig   `yaml:"backup"`
+	Auth     AuthConfig     `yaml:"auth"`
+	Firebase FirebaseConfig `yaml:"firebase"`
+	Log      LogConfig      `yaml:"log"`
+	Features FeatureConfig  `yaml:"features"`
+
+	file    string            // Config file the settings were loaded from, if any
+	sources map[string]string // Where each setting not left at its default came from, by key
+}
+
+// ServerConfig configures the HTTP server
+type ServerConfig struct {
+	Host               string   `yaml:"host"` // Address to listen on, empty for all interfaces
+	Port               int      `yaml:"port"`
+	CORSAllowedOrigins []string `yaml:"cors_allowed_origins"` // "*" allows any origin
+}
+
+// DatabaseConfig configures the SQLite database and its connection pool
+type DatabaseConfig struct {
+	Path            string        `yaml:"path"`
+	JournalMode     string        `yaml:"journal_mode"`
+	Synchronous     string        `yaml:"synchronous"`
+	BusyTimeout     time.Duration `yaml:"busy_timeout"` // How long a connection waits for a lock held by another one
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+}
+
+// BackupConfig configures database snapshots
+type BackupConfig struct {
+	Dir            string        `yaml:"dir"`
+	Keep           int           `yaml:"keep"`             // 0 keeps all snapshots
+	KeepPreRestore int           `yaml:"keep_pre_restore"` // Snapshots taken before restores, kept apart from the others; 0 keeps all
+	Interval       time.Duration `yaml:"interval"`         // 0 disables scheduled snapshots
+}
+
+// AuthConfig configures how bearer tokens are verified
+type AuthConfig struct {
+	Provider     string `yaml:"provider"`
+	JWTAlgorithm string `yaml:"jwt_algorithm"` // HS256 or RS256
+	JWTKeyFile   string `yaml:"jwt_key_file"`  // HS256 secret, or RS256 public key in PEM
+	JWTIssuer    string `yaml:"jwt_issuer"`    // Required iss claim, if set
+	JWTAudience  string `yaml:"jwt_audience"`  // Required aud claim, if set
+	StaticUID    string `yaml:"static_uid"`
+	StaticEmail  string `yaml:"static_email"`
+	StaticRemote bool   `yaml:"static_remote"` // Allow the static provider on a server.host other than loopback
+}
+
+// FirebaseConfig configures Firebase authentication
+type FirebaseConfig struct {
+	CredentialsFile string `yaml:"credentials_file"`
+}
+
+// LogConfig configures logging
+type LogConfig struct {
+	Level string `yaml:"level"`
+}
+
+// FeatureConfig turns optional features on or off
+type FeatureConfig struct {
+	CalendarFeeds bool `yaml:"calendar_feeds"` // Public iCalendar feeds of user schedules
+	Imports       bool `yaml:"imports"`        // Workout archive and exercise catalog imports
+}
+
+// Default returns the settings used when nothing else is configured
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:               8080,
+			CORSAllowedOrigins: []string{"*"},
+		},
+		Database: DatabaseConfig{
+			Path:            "./goliath.db",
+			JournalMode:     "WAL",
+			Synchronous:     "FULL",
+			BusyTimeout:     5 * time.Second,
+			MaxOpenConns:    1, // SQLite: one writer at a time
+			MaxIdleConns:    1,
+			ConnMaxLifetime: time.Hour,
+		},
+		Backup: BackupConfig{
+			Dir:            "./backups",
+			Keep:           7,
+			KeepPreRestore: 3,
+			Interval:       24 * time.Hour,
+		},
+		Auth: AuthConfig{
+			Provider:     AuthProviderFirebase,
+			JWTAlgorithm: "HS256",
+			StaticUID:    "dev-user",
+			StaticEmail:  "dev@localhost",
+		},
+		Firebase: FirebaseConfig{
+			CredentialsFile: "./goliath-firebase.json",
+		},
+		Log: LogConfig{
+			Level: LogLevelInfo,
+		},
+		Features: FeatureConfig{
+			CalendarFeeds: true,
+			Imports:       true,
+		},
+		sources: make(map[string]string),
+	}
+}
+
+// File returns the config file the settings were loaded from, empty when there was none
+func (c *Config) File() string {
+	return c.file
+}
+
+// Source describes where a setting came from, empty when it has its default value
+func (c *Config) Source(key string) string {
+	return c.sources[key]
+}
+
+// Validate checks every setting and reports all invalid ones at once
+func (c *Config) Validate() error {
+	return problemsError(c.problems())
+}
+
+// problems lists the invalid settings
+func (c *Config) problems() []string {
+	var problems []string
+	check := func(ok bool, key, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port", "must be between 1 and 65535")
+	check(len(c.Server.CORSAllowedOrigins) > 0, "server.cors_allowed_origins", "must list at least one origin, or * for any")
+	for _, origin := range c.Server.CORSAllowedOrigins {
+		check(origin == "*" || strings.HasPrefix(origin, "http://") || strings.HasPrefix(origin, "https://"),
+			"server.cors_allowed_origins", "%q must be * or start with http:// or https://", origin)
+		check(!strings.HasSuffix(origin, "/"), "server.cors_allowed_origins", "%q must not end with a slash", origin)
+	}
+
+	check(c.Database.Path != "", "database.path", "must not be empty")
+	check(oneOf(c.Database.JournalMode, "WAL", "DELETE", "TRUNCATE", "PERSIST"), "database.journal_mode", "must be WAL, DELETE, TRUNCATE or PERSIST")
+	check(oneOf(c.Database.Synchronous, "OFF", "NORMAL", "FULL", "EXTRA"), "database.synchronous", "must be OFF, NORMAL, FULL or EXTRA")
+	check(c.Database.BusyTimeout >= 0, "database.busy_timeout", "must not be negative")
+	check(c.Database.MaxOpenConns > 0, "database.max_open_conns", "must be at least 1")
+	check(c.Database.MaxIdleConns >= 0 && c.Database.MaxIdleConns <= c.Database.MaxOpenConns, "database.max_idle_conns", "must be between 0 and database.max_open_conns")
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime", "must not be negative, 0 keeps connections forever")
+
+	check(c.Backup.Dir != "", "backup.dir", "must not be empty")
+	check(c.Backup.Keep >= 0, "backup.keep", "must not be negative, 0 keeps all snapshots")
+	check(c.Backup.KeepPreRestore >= 0, "backup.keep_pre_restore", "must not be negative, 0 keeps all pre-restore snapshots")
+	check(c.Backup.Interval >= 0, "backup.interval", "must not be negative, 0 disables scheduled snapshots")
+
+	check(oneOf(c.Auth.Provider, AuthProviderFirebase, AuthProviderJWT, AuthProviderStatic), "auth.provider", "must be firebase, jwt or static")
+	switch c.Auth.Provider {
+	case AuthProviderFirebase:
+		check(c.Firebase.CredentialsFile != "", "firebase.credentials_file", "must not be empty with the firebase auth provider")
+	case AuthProviderJWT:
+		check(oneOf(c.Auth.JWTAlgorithm, "HS256", "RS256"), "auth.jwt_algorithm", "must be HS256 or RS256")
+		check(c.Auth.JWTKeyFile != "", "auth.jwt_key_file", "must not be empty with the jwt auth provider")
+	case AuthProviderStatic:
+		check(c.Auth.StaticUID != "", "auth.static_uid", "must not be empty with the static auth provider")
+		check(isLoopback(c.Server.Host) || c.Auth.StaticRemote, "auth.provider",
+			"static requires server.host to be a loopback address, or auth.static_remote to be true")
+	}
+
+	check(oneOf(c.Log.Level, LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError), "log.level", "must be debug, info, warn or error")
+
+	return problems
+}
+
+// problemsError reports invalid settings as one error, nil when there are none
+func problemsError(problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+}
+
+// isLoopback reports whether host only accepts connections from the local machine
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// oneOf reports whether value is one of the allowed values
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv names the environment variable with the path of the config file
+const ConfigFileEnv = "CONFIG_FILE"
+
+// setting is a single configuration value that can be set from an environment variable or a flag.
+// Its key is the dotted path in the config file and also the name of its flag.
+type setting struct {
+	key   string
+	env   string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+// settings lists every setting; PORT and BACKUP_* keep the names they had before the config file
+var settings = []setting{
+	stringSetting("server.host", "SERVER_HOST", "Address to listen on, empty for all interfaces", func(c *Config) *string { return &c.Server.Host }),
+	intSetting("server.port", "PORT", "Server port", func(c *Config) *int { return &c.Server.Port }),
+	listSetting("server.cors_allowed_origins", "CORS_ALLOWED_ORIGINS", "Comma-separated origins allowed to call the API, * for any", func(c *Config) *[]string { return &c.Server.CORSAllowedOrigins }),
+	stringSetting("database.path", "DB_PATH", "SQLite database file", func(c *Config) *string { return &c.Database.Path }),
+	stringSetting("database.journal_mode", "DB_JOURNAL_MODE", "SQLite journal mode (WAL, DELETE, TRUNCATE or PERSIST)", func(c *Config) *string { return &c.Database.JournalMode }),
+	stringSetting("database.synchronous", "DB_SYNCHRONOUS", "SQLite synchronous setting (OFF, NORMAL, FULL or EXTRA)", func(c *Config) *string { return &c.Database.Synchronous }),
+	durationSetting("database.busy_timeout", "DB_BUSY_TIMEOUT", "How long to wait for a database lock, e.g. 5s", func(c *Config) *time.Duration { return &c.Database.BusyTimeout }),
+	intSetting("database.max_open_conns", "DB_MAX_OPEN_CONNS", "Maximum open database connections", func(c *Config) *int { return &c.Database.MaxOpenConns }),
+	intSetting("database.max_idle_conns", "DB_MAX_IDLE_CONNS", "Maximum idle database connections", func(c *Config) *int { return &c.Database.MaxIdleConns }),
+	durationSetting("database.conn_max_lifetime", "DB_CONN_MAX_LIFETIME", "How long a database connection is reused, 0 forever", func(c *Config) *time.Duration { return &c.Database.ConnMaxLifetime }),
+	stringSetting("backup.dir", "BACKUP_DIR", "Directory database snapshots are written to", func(c *Config) *string { return &c.Backup.Dir }),
+	intSetting("backup.keep", "BACKUP_KEEP", "Number of scheduled and manual snapshots kept, 0 keeps all", func(c *Config) *int { return &c.Backup.Keep }),
+	intSetting("backup.keep_pre_restore", "BACKUP_KEEP_PRE_RESTORE", "Number of snapshots taken before restores kept, 0 keeps all", func(c *Config) *int { return &c.Backup.KeepPreRestore }),
+	durationSetting("backup.interval", "BACKUP_INTERVAL", "Interval of scheduled snapshots, 0 disables them", func(c *Config) *time.Duration { return &c.Backup.Interval }),
+	stringSetting("auth.provider", "AUTH_PROVIDER", "How bearer tokens are verified (firebase, jwt or static)", func(c *Config) *string { return &c.Auth.Provider }),
+	stringSetting("auth.jwt_algorithm", "AUTH_JWT_ALGORITHM", "Algorithm of locally signed tokens (HS256 or RS256)", func(c *Config) *string { return &c.Auth.JWTAlgorithm }),
+	stringSetting("auth.jwt_key_file", "AUTH_JWT_KEY_FILE", "HS256 secret or RS256 public key (PEM) of locally signed tokens", func(c *Config) *string { return &c.Auth.JWTKeyFile }),
+	stringSetting("auth.jwt_issuer", "AUTH_JWT_ISSUER", "Issuer locally signed tokens must have, if set", func(c *Config) *string { return &c.Auth.JWTIssuer }),
+	stringSetting("auth.jwt_audience", "AUTH_JWT_AUDIENCE", "Audience locally signed tokens must have, if set", func(c *Config) *string { return &c.Auth.JWTAudience }),
+	stringSetting("auth.static_uid", "AUTH_STATIC_UID", "UID of the static development user", func(c *Config) *string { return &c.Auth.StaticUID }),
+	stringSetting("auth.static_email", "AUTH_STATIC_EMAIL", "Email of the static development user", func(c *Config) *string { return &c.Auth.StaticEmail }),
+	boolSetting("auth.static_remote", "AUTH_STATIC_REMOTE", "Allow the static provider on a non-loopback server.host (true or false)", func(c *Config) *bool { return &c.Auth.StaticRemote }),
+	stringSetting("firebase.credentials_file", "FIREBASE_CREDENTIALS", "Firebase service account credentials file", func(c *Config) *string { return &c.Firebase.CredentialsFile }),
+	stringSetting("log.level", "LOG_LEVEL", "Log level (debug, info, warn or error)", func(c *Config) *string { return &c.Log.Level }),
+	boolSetting("features.calendar_feeds", "FEATURE_CALENDAR_FEEDS", "Serve public iCalendar feeds (true or false)", func(c *Config) *bool { return &c.Features.CalendarFeeds }),
+	boolSetting("features.imports", "FEATURE_IMPORTS", "Accept archive and catalog imports (true or false)", func(c *Config) *bool { return &c.Features.Imports }),
+}
+
+func stringSetting(key, env, usage string, field func(c *Config) *string) setting {
+	return setting{key, env, usage, func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}}
+}
+
+func intSetting(key, env, usage string, field func(c *Config) *int) setting {
+	return setting{key, env, usage, func(c *Config, value string) error {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		*field(c) = parsed
+		return nil
+	}}
+}
+
+func boolSetting(key, env, usage string, field func(c *Config) *bool) setting {
+	return setting{key, env, usage, func(c *Config, value string) error {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		*field(c) = parsed
+		return nil
+	}}
+}
+
+func durationSetting(key, env, usage string, field func(c *Config) *time.Duration) setting {
+	return setting{key, env, usage, func(c *Config, value string) error {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration like 30s or 24h", value)
+		}
+		*field(c) = parsed
+		return nil
+	}}
+}
+
+func listSetting(key, env, usage string, field func(c *Config) *[]string) setting {
+	return setting{key, env, usage, func(c *Config, value string) error {
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*field(c) = items
+		return nil
+	}}
+}
+
+// Load builds the configuration from the defaults, then the config file, then environment
+// variables, then the flags in args, each overriding the ones before. It validates the result
+// and returns the arguments left after the flags, which name a maintenance command.
+func Load(args []string) (*Config, []string, error) {
+	fs := flag.NewFlagSet("goliath-backend", flag.ContinueOnError)
+	file := fs.String("config", "", "YAML config file (env "+ConfigFileEnv+")")
+	values := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		values[s.key] = fs.String(s.key, "", s.usage+" (env "+s.env+")")
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	c := Default()
+
+	if *file == "" {
+		*file = os.Getenv(ConfigFileEnv)
+	}
+	if *file != "" {
+		if err := c.loadFile(*file); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var problems []string
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env); ok && value != "" {
+			if err := s.set(c, value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: env %s: %v", s.key, s.env, err))
+				continue
+			}
+			c.sources[s.key] = "env " + s.env
+		}
+	}
+	for _, s := range settings {
+		if !isFlagSet(fs, s.key) {
+			continue
+		}
+		if err := s.set(c, *values[s.key]); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: flag -%s: %v", s.key, s.key, err))
+			continue
+		}
+		c.sources[s.key] = "flag -" + s.key
+	}
+
+	c.normalize()
+	if err := problemsError(append(problems, c.problems()...)); err != nil {
+		return nil, nil, err
+	}
+	return c, fs.Args(), nil
+}
+
+// isFlagSet reports whether a flag was given on the command line
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	found := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return found
+}
+
+// loadFile reads a YAML config file over the current settings. Unknown keys are rejected so a
+// misspelled setting does not silently keep its default.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	c.file = path
+	for _, key := range fileKeys(&root, "") {
+		c.sources[key] = "file " + path
+	}
+	return nil
+}
+
+// fileKeys lists the dotted keys of the settings present in a parsed config file
+func fileKeys(node *yaml.Node, prefix string) []string {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		return fileKeys(node.Content[0], prefix)
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var keys []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := prefix + node.Content[i].Value
+		if value := node.Content[i+1]; value.Kind == yaml.MappingNode {
+			keys = append(keys, fileKeys(value, key+".")...)
+		} else {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// normalize accepts the enumerated settings in any case
+func (c *Config) normalize() {
+	c.Database.JournalMode = strings.ToUpper(c.Database.JournalMode)
+	c.Database.Synchronous = strings.ToUpper(c.Database.Synchronous)
+	c.Auth.Provider = strings.ToLower(c.Auth.Provider)
+	c.Auth.JWTAlgorithm = strings.ToUpper(c.Auth.JWTAlgorithm)
+	c.Log.Level = strings.ToLower(c.Log.Level)
+}
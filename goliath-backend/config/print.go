@@ -0,0 +1,38 @@
+package config
+
+import (
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Print writes the effective configuration as a config file, each setting that is not at its
+// default annotated with where it came from
+func (c *Config) Print(w io.Writer) error {
+	var root yaml.Node
+	if err := root.Encode(c); err != nil {
+		return err
+	}
+	c.annotate(&root, "")
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// annotate adds the source of each setting as a line comment
+func (c *Config) annotate(node *yaml.Node, prefix string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if value.Kind == yaml.MappingNode {
+			c.annotate(value, prefix+key.Value+".")
+			continue
+		}
+		if source := c.Source(prefix + key.Value); source != "" {
+			key.LineComment = "from " + source
+		}
+	}
+}
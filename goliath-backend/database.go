@@ -1,12 +1,19 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"goliath/config"
+	"goliath/services"
+
 	_ "modernc.org/sqlite"
 )
 
@@ -14,22 +21,40 @@ import (
 const applicationID = 0x476F6C69
 
 // InitDB opens the database, configures it, runs migrations, and returns the connection pool
-func InitDB(dbPath string) (*sql.DB, error) {
+func InitDB(cfg config.DatabaseConfig) (*sql.DB, error) {
+	db, err := openDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run migrations
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	log.Println("Database initialized successfully")
+	return db, nil
+}
+
+// openDB opens and configures the database without migrating it
+func openDB(cfg config.DatabaseConfig) (*sql.DB, error) {
 	// Check if database file exists
-	isNewDB := !fileExists(dbPath)
+	isNewDB := !fileExists(cfg.Path)
 
-	db, err := sql.Open("sqlite", dbPath)
+	db, err := sql.Open("sqlite", databaseDSN(cfg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
 	// Configure connection pool
-	db.SetMaxOpenConns(1)            // SQLite: one writer at a time
-	db.SetMaxIdleConns(1)            // Keep one connection idle
-	db.SetConnMaxLifetime(time.Hour) // Recycle connections periodically
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime) // Recycle connections periodically
 
 	// Test connection
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
@@ -39,25 +64,12 @@ func InitDB(dbPath string) (*sql.DB, error) {
 		return nil, err
 	}
 
-	// Configure SQLite pragmas
-	if err := configurePragmas(db); err != nil {
+	// Verify SQLite pragmas
+	if err := verifyPragmas(db); err != nil {
 		db.Close()
 		return nil, err
 	}
 
-	// Run migrations
-	if err := migrate(db); err != nil {
-		db.Close()
-		return nil, err
-	}
-
-	// Vacuum database to optimize storage
-	if err := vacuumDB(db); err != nil {
-		db.Close()
-		return nil, err
-	}
-
-	log.Println("Database initialized successfully")
 	return db, nil
 }
 
@@ -96,20 +108,20 @@ func validateApplicationID(db *sql.DB, isNewDB bool) error {
 	return nil
 }
 
-// configurePragmas sets SQLite pragma flags for optimal performance and reliability
-func configurePragmas(db *sql.DB) error {
-	pragmas := map[string]string{
-		"journal_mode": "WAL",            // Write-Ahead Logging for better concurrency
-		"foreign_keys": "ON",             // Enable foreign key constraints
-	}
-
-	for pragma, value := range pragmas {
-		query := fmt.Sprintf("PRAGMA %s = %s", pragma, value)
-		if _, err := db.Exec(query); err != nil {
-			return fmt.Errorf("failed to set pragma %s: %w", pragma, err)
-		}
+// databaseDSN builds the connection string of the database. Pragmas are part of it rather than
+// executed once, so every connection of the pool gets them.
+func databaseDSN(cfg config.DatabaseConfig) string {
+	pragmas := []string{
+		"foreign_keys(1)", // Enable foreign key constraints
+		"journal_mode(" + cfg.JournalMode + ")",
+		"synchronous(" + cfg.Synchronous + ")",
+		"busy_timeout(" + strconv.FormatInt(cfg.BusyTimeout.Milliseconds(), 10) + ")",
 	}
+	return "file:" + cfg.Path + "?_pragma=" + strings.Join(pragmas, "&_pragma=")
+}
 
+// verifyPragmas checks that the pragmas of the connection string took effect
+func verifyPragmas(db *sql.DB) error {
 	// Verify critical pragmas
 	var journalMode string
 	if err := db.QueryRow("PRAGMA journal_mode").Scan(&journalMode); err != nil {
@@ -126,16 +138,8 @@ func configurePragmas(db *sql.DB) error {
 	return nil
 }
 
-// migrate runs all database migrations using user_version pragma
+// migrate runs all pending database migrations
 func migrate(db *sql.DB) error {
-	// Get current database version
-	var currentVersion int
-	if err := db.QueryRow("PRAGMA user_version").Scan(&currentVersion); err != nil {
-		return fmt.Errorf("failed to get user_version: %w", err)
-	}
-	log.Printf("Current database version: %d", currentVersion)
-
-	// Load migrations
 	migrations, err := GetMigrations()
 	if err != nil {
 		return fmt.Errorf("failed to load migrations: %w", err)
@@ -146,18 +150,241 @@ func migrate(db *sql.DB) error {
 		return nil
 	}
 
-	// Apply migrations
+	return migrateTo(db, migrations, migrations[len(migrations)-1].Version)
+}
+
+// migrateTo applies or reverts migrations until the database is at the target version.
+// The applied migrations are verified first, and reverting only starts when every
+// migration on the way has a down migration.
+func migrateTo(db *sql.DB, migrations []Migration, target int) error {
+	currentVersion, err := verifyMigrations(db, migrations)
+	if err != nil {
+		return err
+	}
+	log.Printf("Current database version: %d", currentVersion)
+
+	if target >= currentVersion {
+		for _, migration := range migrations {
+			if migration.Version <= currentVersion || migration.Version > target {
+				continue
+			}
+			if err := applyMigration(db, migration); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var reverts []Migration
+	for i := len(migrations) - 1; i >= 0; i-- {
+		migration := migrations[i]
+		if migration.Version > currentVersion || migration.Version <= target {
+			continue
+		}
+		if migration.DownSQL == "" {
+			return fmt.Errorf("migration %d_%s has no down migration and cannot be reverted", migration.Version, migration.Name)
+		}
+		reverts = append(reverts, migration)
+	}
+	for _, migration := range reverts {
+		if err := revertMigration(db, migration, previousVersion(migrations, migration.Version)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// previousVersion returns the version of the migration before the given one, 0 for the first
+func previousVersion(migrations []Migration, version int) int {
+	previous := 0
 	for _, migration := range migrations {
-		if migration.Version <= currentVersion {
-			log.Printf("Skipping migration %d_%s (already applied)", migration.Version, migration.Name)
+		if migration.Version >= version {
+			break
+		}
+		previous = migration.Version
+	}
+	return previous
+}
+
+// getUserVersion returns the version of the last applied migration
+func getUserVersion(db *sql.DB) (int, error) {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to get user_version: %w", err)
+	}
+	return version, nil
+}
+
+// ensureMigrationTable creates the table recording the checksum of every applied migration
+func ensureMigrationTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migration (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_when TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migration table: %w", err)
+	}
+	return nil
+}
+
+// AppliedMigration represents a migration recorded in the schema_migration table
+type AppliedMigration struct {
+	Version     int
+	Name        string
+	Checksum    string
+	AppliedWhen string
+}
+
+// getAppliedMigrations reads the schema_migration table by version
+func getAppliedMigrations(db *sql.DB) (map[int]AppliedMigration, error) {
+	rows, err := db.Query("SELECT version, name, checksum, applied_when FROM schema_migration")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migration: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]AppliedMigration)
+	for rows.Next() {
+		var m AppliedMigration
+		if err := rows.Scan(&m.Version, &m.Name, &m.Checksum, &m.AppliedWhen); err != nil {
+			return nil, fmt.Errorf("failed to read schema_migration: %w", err)
+		}
+		applied[m.Version] = m
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read schema_migration: %w", err)
+	}
+
+	return applied, nil
+}
+
+// verifyMigrations checks that every migration up to user_version is still embedded unchanged
+// and returns user_version. Migrations applied before checksums were recorded are recorded
+// with their current checksum.
+func verifyMigrations(db *sql.DB, migrations []Migration) (int, error) {
+	currentVersion, err := getUserVersion(db)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := ensureMigrationTable(db); err != nil {
+		return 0, err
+	}
+	applied, err := getAppliedMigrations(db)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(migrations) > 0 && currentVersion > migrations[len(migrations)-1].Version {
+		return 0, fmt.Errorf("database version %d is newer than the latest migration %d of this binary", currentVersion, migrations[len(migrations)-1].Version)
+	}
+
+	var modified []string
+	for _, migration := range migrations {
+		if migration.Version > currentVersion {
+			break
+		}
+
+		record, ok := applied[migration.Version]
+		if !ok {
+			if err := recordMigration(db, migration); err != nil {
+				return 0, err
+			}
+			log.Printf("Recorded checksum of migration %d_%s applied before checksums were tracked", migration.Version, migration.Name)
 			continue
 		}
+		if record.Checksum != migration.Checksum {
+			modified = append(modified, fmt.Sprintf("%d_%s", migration.Version, migration.Name))
+		}
+	}
+	if len(modified) > 0 {
+		return 0, fmt.Errorf("applied migrations were modified: %s - restore their original SQL and add a new migration instead", strings.Join(modified, ", "))
+	}
 
-		if err := applyMigration(db, migration); err != nil {
-			return err
+	return currentVersion, nil
+}
+
+// States of a migration reported by `migrate status`
+const (
+	MigrationApplied  = "applied"
+	MigrationPending  = "pending"
+	MigrationModified = "modified" // Applied, but its SQL changed since
+	MigrationMissing  = "missing"  // Applied, but no longer embedded in the binary
+)
+
+// MigrationStatus describes the state of one migration
+type MigrationStatus struct {
+	Version     int
+	Name        string
+	State       string
+	AppliedWhen string // Empty for pending migrations
+}
+
+// getMigrationStatus compares the embedded migrations with the ones recorded in the database
+// without changing anything, and returns user_version with the state of every migration
+func getMigrationStatus(db *sql.DB, migrations []Migration) (int, []MigrationStatus, error) {
+	currentVersion, err := getUserVersion(db)
+	if err != nil {
+		return 0, nil, err
+	}
+	applied := map[int]AppliedMigration{}
+	var exists int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migration'").Scan(&exists); err != nil {
+		return 0, nil, fmt.Errorf("failed to look up schema_migration: %w", err)
+	}
+	if exists > 0 {
+		if applied, err = getAppliedMigrations(db); err != nil {
+			return 0, nil, err
 		}
 	}
 
+	embedded := make(map[int]bool, len(migrations))
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		embedded[migration.Version] = true
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name, State: MigrationPending}
+		if migration.Version <= currentVersion {
+			status.State = MigrationApplied
+			if record, ok := applied[migration.Version]; ok {
+				status.AppliedWhen = record.AppliedWhen
+				if record.Checksum != migration.Checksum {
+					status.State = MigrationModified
+				}
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	for version, record := range applied {
+		if !embedded[version] {
+			statuses = append(statuses, MigrationStatus{Version: version, Name: record.Name, State: MigrationMissing, AppliedWhen: record.AppliedWhen})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return currentVersion, statuses, nil
+}
+
+// sqlExecer is implemented by both *sql.DB and *sql.Tx
+type sqlExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// recordMigration stores the checksum of an applied migration
+func recordMigration(exec sqlExecer, migration Migration) error {
+	_, err := exec.Exec(`
+		INSERT OR REPLACE INTO schema_migration (version, name, checksum, applied_when)
+		VALUES (?, ?, ?, ?)
+	`, migration.Version, migration.Name, migration.Checksum, time.Now().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+	}
 	return nil
 }
 
@@ -177,7 +404,10 @@ func applyMigration(db *sql.DB, migration Migration) error {
 		return fmt.Errorf("failed to execute migration %d_%s: %w", migration.Version, migration.Name, err)
 	}
 
-	// Update user_version
+	// Record checksum and update user_version
+	if err := recordMigration(tx, migration); err != nil {
+		return err
+	}
 	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", migration.Version)); err != nil {
 		return fmt.Errorf("failed to update user_version for migration %d: %w", migration.Version, err)
 	}
@@ -191,6 +421,77 @@ func applyMigration(db *sql.DB, migration Migration) error {
 	return nil
 }
 
+// revertMigration runs the down migration of a single migration in a transaction and sets
+// user_version to the previous version
+func revertMigration(db *sql.DB, migration Migration, previousVersion int) error {
+	log.Printf("Reverting migration %d_%s...", migration.Version, migration.Name)
+
+	// Pragmas apply per connection, so the whole revert runs on one
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection for migration %d: %w", migration.Version, err)
+	}
+	defer conn.Close()
+
+	// foreign_keys cannot be changed inside a transaction
+	if migration.DownForeignKeysOff {
+		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+			return fmt.Errorf("failed to disable foreign keys for migration %d: %w", migration.Version, err)
+		}
+		defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for migration %d: %w", migration.Version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(migration.DownSQL); err != nil {
+		return fmt.Errorf("failed to revert migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if migration.DownForeignKeysOff {
+		if err := checkForeignKeys(tx); err != nil {
+			return fmt.Errorf("failed to revert migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM schema_migration WHERE version = ?", migration.Version); err != nil {
+		return fmt.Errorf("failed to remove record of migration %d: %w", migration.Version, err)
+	}
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", previousVersion)); err != nil {
+		return fmt.Errorf("failed to update user_version for migration %d: %w", migration.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit revert of migration %d: %w", migration.Version, err)
+	}
+
+	log.Printf("Successfully reverted migration %d_%s", migration.Version, migration.Name)
+	return nil
+}
+
+// checkForeignKeys fails when a row references a missing row, which enforcement would have prevented
+func checkForeignKeys(tx *sql.Tx) error {
+	rows, err := tx.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		return fmt.Errorf("failed to check foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var table, parent string
+		var rowID sql.NullInt64
+		var index int
+		if err := rows.Scan(&table, &rowID, &parent, &index); err != nil {
+			return fmt.Errorf("failed to check foreign keys: %w", err)
+		}
+		return fmt.Errorf("row %d of %s references a missing row of %s", rowID.Int64, table, parent)
+	}
+	return rows.Err()
+}
+
 // vacuumDB rebuilds the database file, repacking it into minimal disk space
 func vacuumDB(db *sql.DB) error {
 	log.Println("Running VACUUM to optimize database...")
@@ -210,3 +511,15 @@ func pragmaSelect(db *sql.DB, pragmaName string) (interface{}, error) {
 	}
 	return value, nil
 }
+
+// newBackupService configures snapshots of the database
+func newBackupService(cfg *config.Config) *services.BackupService {
+	return services.NewBackupService(services.BackupConfig{
+		DatabasePath:   cfg.Database.Path,
+		Dir:            cfg.Backup.Dir,
+		Keep:           cfg.Backup.Keep,
+		KeepPreRestore: cfg.Backup.KeepPreRestore,
+		ApplicationID:  applicationID,
+		Migrate:        migrate,
+	})
+}
@@ -2,6 +2,7 @@ package entities
 
 import (
 	"database/sql"
+	"strings"
 	"time"
 )
 
@@ -32,9 +33,32 @@ type MuscleGroup struct {
 // ExerciseArea represents a type of exercise movement
 type ExerciseArea struct {
 	BaseEntity
-	Name string `json:"name" db:"name"`
+	Name      string             `json:"name" db:"name"`
+	Plane     *MovementPlane     `json:"plane" db:"plane"`         // Set for push and pull areas, used by balance reports
+	Direction *MovementDirection `json:"direction" db:"direction"` // Set together with Plane
 }
 
+// MovementPlane represents the plane of a push or pull movement
+type MovementPlane string
+
+const (
+	MovementPlaneHorizontal MovementPlane = "Horizontal"
+	MovementPlaneVertical   MovementPlane = "Vertical"
+	MovementPlaneLateral    MovementPlane = "Lateral"
+	MovementPlaneLegs       MovementPlane = "Legs"
+)
+
+// MovementPlanes lists the planes in the order balance reports show them
+var MovementPlanes = []MovementPlane{MovementPlaneHorizontal, MovementPlaneVertical, MovementPlaneLateral, MovementPlaneLegs}
+
+// MovementDirection represents whether a movement pushes or pulls
+type MovementDirection string
+
+const (
+	MovementDirectionPush MovementDirection = "Push"
+	MovementDirectionPull MovementDirection = "Pull"
+)
+
 // Muscle represents a specific muscle in the body
 type Muscle struct {
 	BaseEntity
@@ -67,6 +91,7 @@ type Exercise struct {
 	BaseEntity
 	Name          string                 `json:"name" db:"name"`
 	Type          ExerciseType           `json:"type" db:"type"`
+	ArchivedWhen  *time.Time             `json:"archived_when,omitempty" db:"archived_when"` // Set when soft-deleted
 	Muscles       []ExerciseMuscle       `json:"muscles,omitempty"`        // For many-to-many relationship with percentages
 	ExerciseAreas []ExerciseAreaSummary  `json:"exercise_areas,omitempty"` // Grouped exercise areas
 }
@@ -88,39 +113,452 @@ type ExerciseMuscle struct {
 	CreatedBy   *string   `json:"created_by" db:"created_by"`
 }
 
+
 // User represents a user in the system
 type User struct {
-	ID           int     `json:"id"`
-	Version      int     `json:"version"`
-	CreatedWhen  string  `json:"created_when"`
-	CreatedBy    *string `json:"created_by"`
-	ModifiedWhen string  `json:"modified_when"`
-	ModifiedBy   *string `json:"modified_by"`
-	Email        string  `json:"email"`
-	Role         string  `json:"role"`
-	FirebaseUID  *string `json:"firebase_uid,omitempty"`
+	ID           int          `json:"id"`
+	Version      int          `json:"version"`
+	CreatedWhen  string       `json:"created_when"`
+	CreatedBy    *string      `json:"created_by"`
+	ModifiedWhen string       `json:"modified_when"`
+	ModifiedBy   *string      `json:"modified_by"`
+	Email        string       `json:"email"`
+	Role         string       `json:"role"`
+	FirebaseUID  *string      `json:"firebase_uid,omitempty"`
+	Status       UserStatus   `json:"status"`
+	StatusReason *string      `json:"status_reason,omitempty"` // Why the user was disabled or banned
+	Permissions  []Permission `json:"permissions,omitempty"`   // Granted by the role; only loaded for the current user
+	UserProfile
+}
+
+// HasPermission reports whether the role of the user grants a permission
+func (u *User) HasPermission(permission Permission) bool {
+	for _, granted := range u.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Built-in roles, which cannot be deleted
+const (
+	RoleUser  = "USER"  // Assigned to new users
+	RoleAdmin = "ADMIN" // Holds every permission
+)
+
+// Permission names an action a role may perform, e.g. exercise:write
+type Permission string
+
+const (
+	PermissionExerciseWrite     Permission = "exercise:write"
+	PermissionCatalogRead       Permission = "catalog:read"
+	PermissionMuscleWrite       Permission = "muscle:write"
+	PermissionUserRead          Permission = "user:read"
+	PermissionUserStatus        Permission = "user:status"
+	PermissionUserRole          Permission = "user:role"
+	PermissionRegistrationRead  Permission = "registration:read"
+	PermissionRegistrationWrite Permission = "registration:write"
+	PermissionBackupRead        Permission = "backup:read"
+	PermissionBackupWrite       Permission = "backup:write"
+	PermissionRoleRead          Permission = "role:read"
+	PermissionRoleWrite         Permission = "role:write"
+	PermissionAthleteCoach      Permission = "athlete:coach"
+	PermissionWorkoutRead       Permission = "workout:read"
+	PermissionWorkoutWrite      Permission = "workout:write"
+)
+
+// PermissionDefinition describes a permission that roles can grant
+type PermissionDefinition struct {
+	Name        Permission `json:"name"`
+	Description string     `json:"description"`
+}
+
+// Role represents a named set of permissions assigned to users
+type Role struct {
+	BaseEntity
+	Name        string       `json:"name"` // e.g. CATALOG_EDITOR
+	Description *string      `json:"description,omitempty"`
+	BuiltIn     bool         `json:"built_in"` // USER and ADMIN cannot be deleted
+	Permissions []Permission `json:"permissions"`
+}
+
+// UserStatus is whether a user may use the API
+type UserStatus string
+
+const (
+	UserStatusActive   UserStatus = "Active"
+	UserStatusDisabled UserStatus = "Disabled" // Suspended, expected to be re-enabled
+	UserStatusBanned   UserStatus = "Banned"   // Removed for good
+)
+
+// RegistrationPolicy decides who may sign up
+type RegistrationPolicy struct {
+	Version             int      `json:"version"`
+	ModifiedWhen        string   `json:"modified_when"`
+	ModifiedBy          *string  `json:"modified_by"`
+	InviteOnly          bool     `json:"invite_only"`           // New users need an invite or an allowed email domain
+	AllowedEmailDomains []string `json:"allowed_email_domains"` // Verified emails of these domains need no invite
+}
+
+// AllowsEmail reports whether an email belongs to one of the allowed email domains
+func (p RegistrationPolicy) AllowsEmail(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range p.AllowedEmailDomains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// UserInvite represents a single-use invite code for invite-only registration
+type UserInvite struct {
+	BaseEntity
+	Code         string     `json:"code,omitempty"`  // Only returned when the invite is created
+	Email        *string    `json:"email,omitempty"` // Only this email may redeem the invite, if set
+	Note         *string    `json:"note,omitempty"`
+	ExpiresWhen  *time.Time `json:"expires_when,omitempty"`
+	UsedWhen     *time.Time `json:"used_when,omitempty"`
+	UsedByUserID *int       `json:"used_by_user_id,omitempty"`
+}
+
+// Coaching lets a coach manage the workouts of an athlete once the athlete accepted the invite
+type Coaching struct {
+	BaseEntity
+	CoachUserID   int            `json:"coach_user_id"`
+	CoachEmail    string         `json:"coach_email"`
+	AthleteUserID int            `json:"athlete_user_id"`
+	AthleteEmail  string         `json:"athlete_email"`
+	Status        CoachingStatus `json:"status"`
+	AcceptedWhen  *time.Time     `json:"accepted_when,omitempty"`
+	EndedWhen     *time.Time     `json:"ended_when,omitempty"`
+}
+
+// CoachingStatus is the stage of a coaching
+type CoachingStatus string
+
+const (
+	CoachingStatusPending CoachingStatus = "Pending" // Invited, waiting for the athlete
+	CoachingStatusActive  CoachingStatus = "Active"  // Accepted; the coach may manage the athlete's workouts
+	CoachingStatusEnded   CoachingStatus = "Ended"   // Declined, revoked or ended by the coach
+)
+
+// Units is the unit system a user prefers for weights and distances
+type Units string
+
+const (
+	UnitsMetric   Units = "Metric"   // kg
+	UnitsImperial Units = "Imperial" // lb
+)
+
+// Sex is the sex of a user, used for analytics
+type Sex string
+
+const (
+	SexMale   Sex = "Male"
+	SexFemale Sex = "Female"
+)
+
+// UserProfile represents the part of a user maintained by the user themselves
+type UserProfile struct {
+	DisplayName *string `json:"display_name"`
+	Units       Units   `json:"units"`
+	TimeZone    string  `json:"time_zone"`     // IANA name, e.g. Europe/Berlin
+	DateOfBirth *string `json:"date_of_birth"` // YYYY-MM-DD
+	Sex         *Sex    `json:"sex"`
 }
 
 // Workout represents a workout belonging to a user
 type Workout struct {
 	BaseEntity
-	Name   string `json:"name" db:"name"`
-	UserID int    `json:"user_id" db:"user_id"`
+	Name            string `json:"name" db:"name"`
+	UserID          int    `json:"user_id" db:"user_id"`
+	Shareable       bool   `json:"shareable" db:"shareable"`                           // Listed in the template library
+	SourceWorkoutID *int   `json:"source_workout_id,omitempty" db:"source_workout_id"` // Workout this one was cloned from
 }
 
+// WorkoutChange is an entry of the audit trail of a workout: who created, updated or deleted the
+// workout, one of its exercises or one of their sets
+type WorkoutChange struct {
+	ID          int                 `json:"id"`
+	ChangedWhen time.Time           `json:"changed_when"`
+	WorkoutID   int                 `json:"workout_id"`
+	UserID      *int                `json:"user_id"`              // Nil when the user was deleted
+	UserEmail   *string             `json:"user_email,omitempty"` // For JOIN queries
+	EntityType  WorkoutChangeEntity `json:"entity_type"`
+	EntityID    int                 `json:"entity_id"`
+	Action      WorkoutChangeAction `json:"action"`
+}
+
+// WorkoutChangeEntity is the kind of row a workout change affected
+type WorkoutChangeEntity string
+
+const (
+	WorkoutChangeEntityWorkout            WorkoutChangeEntity = "Workout"
+	WorkoutChangeEntityWorkoutExercise    WorkoutChangeEntity = "WorkoutExercise"
+	WorkoutChangeEntityWorkoutExerciseSet WorkoutChangeEntity = "WorkoutExerciseSet"
+)
+
+// WorkoutChangeAction is what a workout change did
+type WorkoutChangeAction string
+
+const (
+	WorkoutChangeCreated WorkoutChangeAction = "Created"
+	WorkoutChangeUpdated WorkoutChangeAction = "Updated"
+	WorkoutChangeDeleted WorkoutChangeAction = "Deleted"
+)
+
 // WorkoutExercise represents an exercise within a workout with configuration
 type WorkoutExercise struct {
 	BaseEntity
-	WorkoutID    int     `json:"workout_id" db:"workout_id"`
-	ExerciseID   int     `json:"exercise_id" db:"exercise_id"`
-	ExerciseName string  `json:"exercise_name,omitempty" db:"exercise_name"` // For JOIN queries
-	ExerciseType string  `json:"exercise_type,omitempty" db:"exercise_type"` // For JOIN queries
-	Position     int     `json:"position" db:"position"`
-	Sets         *int    `json:"sets,omitempty" db:"sets"`
-	Reps         *int    `json:"reps,omitempty" db:"reps"`
-	TimeSeconds  *int    `json:"time_seconds,omitempty" db:"time_seconds"`
-	Weight       *float64 `json:"weight,omitempty" db:"weight"`
-	Notes        *string `json:"notes,omitempty" db:"notes"`
+	WorkoutID    int                  `json:"workout_id" db:"workout_id"`
+	ExerciseID   int                  `json:"exercise_id" db:"exercise_id"`
+	ExerciseName string               `json:"exercise_name,omitempty" db:"exercise_name"` // For JOIN queries
+	ExerciseType string               `json:"exercise_type,omitempty" db:"exercise_type"` // For JOIN queries
+	Position     int                  `json:"position" db:"position"`
+	Sets         *int                 `json:"sets,omitempty" db:"sets"`
+	Reps         *int                 `json:"reps,omitempty" db:"reps"`
+	TimeSeconds  *int                 `json:"time_seconds,omitempty" db:"time_seconds"`
+	Weight       *float64             `json:"weight,omitempty" db:"weight"`
+	Notes        *string              `json:"notes,omitempty" db:"notes"`
+	SetDetails   []WorkoutExerciseSet `json:"set_details,omitempty"` // Individual sets; scalar fields summarize them when present
+}
+
+// SetType represents the kind of a planned set
+type SetType string
+
+const (
+	SetTypeWarmUp  SetType = "WarmUp"
+	SetTypeWorking SetType = "Working"
+	SetTypeDrop    SetType = "Drop"
+	SetTypeFailure SetType = "Failure"
+)
+
+// WorkoutExerciseSet represents a single planned set of a workout exercise
+type WorkoutExerciseSet struct {
+	BaseEntity
+	WorkoutExerciseID int      `json:"workout_exercise_id" db:"workout_exercise_id"`
+	Position          int      `json:"position" db:"position"`
+	SetType           SetType  `json:"set_type" db:"set_type"`
+	Reps              *int     `json:"reps,omitempty" db:"reps"`
+	TimeSeconds       *int     `json:"time_seconds,omitempty" db:"time_seconds"`
+	Weight            *float64 `json:"weight,omitempty" db:"weight"`
+	RPE               *float64 `json:"rpe,omitempty" db:"rpe"`
+}
+
+// WorkoutSession represents a workout actually performed by a user
+type WorkoutSession struct {
+	BaseEntity
+	UserID       int                 `json:"user_id" db:"user_id"`
+	WorkoutID    *int                `json:"workout_id,omitempty" db:"workout_id"` // Template the session was started from
+	Name         string              `json:"name" db:"name"`
+	StartedWhen  time.Time           `json:"started_when" db:"started_when"`
+	FinishedWhen *time.Time          `json:"finished_when,omitempty" db:"finished_when"`
+	Notes        *string             `json:"notes,omitempty" db:"notes"`
+	Sets         []WorkoutSessionSet `json:"sets,omitempty"` // Performed sets
+}
+
+// WorkoutSessionSet represents the actual result of a single set performed in a session
+type WorkoutSessionSet struct {
+	BaseEntity
+	SessionID         int      `json:"session_id" db:"session_id"`
+	WorkoutExerciseID *int     `json:"workout_exercise_id,omitempty" db:"workout_exercise_id"` // Planned exercise this set fulfils
+	ExerciseID        int      `json:"exercise_id" db:"exercise_id"`
+	ExerciseName      string   `json:"exercise_name,omitempty" db:"exercise_name"` // For JOIN queries
+	ExerciseType      string   `json:"exercise_type,omitempty" db:"exercise_type"` // For JOIN queries
+	SetNumber         int      `json:"set_number" db:"set_number"`
+	Reps              *int     `json:"reps,omitempty" db:"reps"`
+	TimeSeconds       *int     `json:"time_seconds,omitempty" db:"time_seconds"`
+	Weight            *float64 `json:"weight,omitempty" db:"weight"`
+	Notes             *string  `json:"notes,omitempty" db:"notes"`
+}
+
+// PersonalRecordType represents the kind of a personal record
+type PersonalRecordType string
+
+const (
+	PersonalRecordMaxWeight        PersonalRecordType = "MaxWeight"        // Heaviest weight lifted (Reps)
+	PersonalRecordMaxRepsAtWeight  PersonalRecordType = "MaxRepsAtWeight"  // Most reps at a given weight (Reps)
+	PersonalRecordEstimated1RM     PersonalRecordType = "Estimated1RM"     // Estimated one-rep max (Reps)
+	PersonalRecordLongestHold      PersonalRecordType = "LongestHold"      // Longest hold (Isometric)
+	PersonalRecordLongestEccentric PersonalRecordType = "LongestEccentric" // Longest eccentric phase (Eccentric)
+)
+
+// PersonalRecord represents the best result of a user for an exercise
+type PersonalRecord struct {
+	BaseEntity
+	UserID       int                `json:"user_id" db:"user_id"`
+	ExerciseID   int                `json:"exercise_id" db:"exercise_id"`
+	ExerciseName string             `json:"exercise_name,omitempty" db:"exercise_name"` // For JOIN queries
+	RecordType   PersonalRecordType `json:"record_type" db:"record_type"`
+	Value        float64            `json:"value" db:"value"` // Weight, reps or seconds depending on the record type
+	Reps         *int               `json:"reps,omitempty" db:"reps"`
+	TimeSeconds  *int               `json:"time_seconds,omitempty" db:"time_seconds"`
+	Weight       *float64           `json:"weight,omitempty" db:"weight"`
+	SessionSetID *int               `json:"session_set_id,omitempty" db:"session_set_id"` // Set that achieved the record
+	AchievedWhen time.Time          `json:"achieved_when" db:"achieved_when"`
+}
+
+// Program represents a multi-week training plan of a user composed of workouts.
+// Each week adds the increments to the planned weights and reps; every DeloadEvery-th week
+// is a deload week that scales the weights down to DeloadPercentage instead.
+type Program struct {
+	BaseEntity
+	UserID           int           `json:"user_id" db:"user_id"`
+	Name             string        `json:"name" db:"name"`
+	Description      *string       `json:"description,omitempty" db:"description"`
+	WeightIncrement  float64       `json:"weight_increment" db:"weight_increment"`   // Weight added every week
+	RepsIncrement    int           `json:"reps_increment" db:"reps_increment"`       // Reps added every week
+	DeloadEvery      int           `json:"deload_every" db:"deload_every"`           // 0 for no deload weeks
+	DeloadPercentage float64       `json:"deload_percentage" db:"deload_percentage"` // Share of the weights kept on deload weeks
+	Weeks            []ProgramWeek `json:"weeks,omitempty"`
+}
+
+// ProgramWeek represents one week of a program
+type ProgramWeek struct {
+	BaseEntity
+	ProgramID  int          `json:"program_id" db:"program_id"`
+	WeekNumber int          `json:"week_number" db:"week_number"`
+	Notes      *string      `json:"notes,omitempty" db:"notes"`
+	Days       []ProgramDay `json:"days"`
+}
+
+// ProgramDay represents the workout planned for one day of a program week
+type ProgramDay struct {
+	BaseEntity
+	ProgramWeekID int    `json:"program_week_id" db:"program_week_id"`
+	DayNumber     int    `json:"day_number" db:"day_number"`
+	WorkoutID     int    `json:"workout_id" db:"workout_id"`
+	WorkoutName   string `json:"workout_name,omitempty" db:"workout_name"` // For JOIN queries
+}
+
+// ProgramEnrollment represents a user following a program and the next day to train
+type ProgramEnrollment struct {
+	BaseEntity
+	UserID        int        `json:"user_id" db:"user_id"`
+	ProgramID     int        `json:"program_id" db:"program_id"`
+	ProgramName   string     `json:"program_name,omitempty" db:"program_name"` // For JOIN queries
+	StartedWhen   time.Time  `json:"started_when" db:"started_when"`
+	CurrentWeek   int        `json:"current_week" db:"current_week"`
+	CurrentDay    int        `json:"current_day" db:"current_day"`
+	CompletedWhen *time.Time `json:"completed_when,omitempty" db:"completed_when"` // Set once the last day is done
+}
+
+// ProgramPrescription represents the concrete workout of one day of a program
+// with the progression of its week applied to the planned weights and reps
+type ProgramPrescription struct {
+	ProgramID       int               `json:"program_id"`
+	WeekNumber      int               `json:"week_number"`
+	DayNumber       int               `json:"day_number"`
+	WorkoutID       int               `json:"workout_id"`
+	WorkoutName     string            `json:"workout_name"`
+	Deload          bool              `json:"deload"`
+	WeightIncrement float64           `json:"weight_increment"` // Weight added to the planned weights, before the deload
+	RepsIncrement   int               `json:"reps_increment"`   // Reps added to the planned reps
+	Exercises       []WorkoutExercise `json:"exercises"`
+}
+
+// WorkoutSchedule represents a workout planned on a date or on recurring weekdays.
+// Without weekdays the workout is planned once on StartDate; with weekdays it repeats
+// every IntervalWeeks weeks from StartDate until EndDate.
+type WorkoutSchedule struct {
+	BaseEntity
+	UserID        int      `json:"user_id" db:"user_id"`
+	WorkoutID     int      `json:"workout_id" db:"workout_id"`
+	WorkoutName   string   `json:"workout_name,omitempty" db:"workout_name"` // For JOIN queries
+	StartDate     string   `json:"start_date" db:"start_date"`               // YYYY-MM-DD
+	EndDate       *string  `json:"end_date,omitempty" db:"end_date"`         // YYYY-MM-DD, inclusive; open-ended when empty
+	Weekdays      []string `json:"weekdays,omitempty" db:"weekdays"`         // Mon, Tue, ... Sun
+	IntervalWeeks int      `json:"interval_weeks" db:"interval_weeks"`
+	Notes         *string  `json:"notes,omitempty" db:"notes"`
+}
+
+// CalendarEntryStatus represents how a calendar entry compares to the plan
+type CalendarEntryStatus string
+
+const (
+	CalendarEntryPlanned   CalendarEntryStatus = "planned"   // Planned for today or later
+	CalendarEntryCompleted CalendarEntryStatus = "completed" // Planned and done in a finished session
+	CalendarEntryMissed    CalendarEntryStatus = "missed"    // Planned for a past day and not done
+	CalendarEntryUnplanned CalendarEntryStatus = "unplanned" // Done in a finished session without being planned
+)
+
+// CalendarEntry represents a planned or performed workout on one day
+type CalendarEntry struct {
+	Status     CalendarEntryStatus `json:"status"`
+	ScheduleID *int                `json:"schedule_id,omitempty"`
+	WorkoutID  *int                `json:"workout_id,omitempty"`
+	Name       string              `json:"name"`                 // Workout or session name
+	SessionID  *int                `json:"session_id,omitempty"` // Finished session that completed the entry
+}
+
+// CalendarDay represents the planned and performed workouts of one day
+type CalendarDay struct {
+	Date    string          `json:"date"` // YYYY-MM-DD
+	Entries []CalendarEntry `json:"entries"`
+}
+
+// TrainingVolume represents the training volume attributed to a muscle, muscle group or region.
+// Values are weighted by the muscle's percentage in each exercise, so sets are effective sets.
+type TrainingVolume struct {
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	Sets        float64 `json:"sets"`
+	Reps        float64 `json:"reps"`
+	Load        float64 `json:"load"` // Reps multiplied by weight
+	TimeSeconds float64 `json:"time_seconds"`
+}
+
+// TrainingVolumePeriod represents the training volume of one period (day, week or month)
+type TrainingVolumePeriod struct {
+	PeriodStart  time.Time        `json:"period_start"`
+	Muscles      []TrainingVolume `json:"muscles"`
+	MuscleGroups []TrainingVolume `json:"muscle_groups"`
+	Regions      []TrainingVolume `json:"regions"`
+}
+
+// AreaBalance represents how much of a workout or week targets one exercise area
+type AreaBalance struct {
+	ExerciseAreaID   int     `json:"exercise_area_id"`
+	ExerciseAreaName string  `json:"exercise_area_name"`
+	Score            float64 `json:"score"` // Sum of area percentages weighted by sets
+	Share            float64 `json:"share"` // Percentage of the total score
+}
+
+// PushPullRatio compares push and pull scores within one movement plane
+type PushPullRatio struct {
+	Plane string   `json:"plane"` // Horizontal, Vertical, Lateral, Legs or Total
+	Push  float64  `json:"push"`
+	Pull  float64  `json:"pull"`
+	Ratio *float64 `json:"ratio"` // Push divided by pull, null when nothing is pulled
+}
+
+// BalanceReport represents the movement pattern balance of a workout or week
+type BalanceReport struct {
+	Areas          []AreaBalance   `json:"areas"`
+	PushPull       []PushPullRatio `json:"push_pull"`
+	UntouchedAreas []ExerciseArea  `json:"untouched_areas"`
+}
+
+// FacetCount represents the number of matching items for one value of a search facet
+type FacetCount struct {
+	ID    int    `json:"id,omitempty"` // Empty for value-only facets such as exercise type
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// ExerciseFacets represents per-facet counts of an exercise search
+type ExerciseFacets struct {
+	Types         []FacetCount `json:"types"`
+	Muscles       []FacetCount `json:"muscles"`
+	MuscleGroups  []FacetCount `json:"muscle_groups"`
+	Regions       []FacetCount `json:"regions"`
+	ExerciseAreas []FacetCount `json:"exercise_areas"`
 }
 
 // ScanBaseEntity is a helper to scan common fields from database rows
@@ -221,6 +659,8 @@ func ScanExerciseArea(rows *sql.Rows) (*ExerciseArea, error) {
 		&modifiedWhen,
 		&ea.ModifiedBy,
 		&ea.Name,
+		&ea.Plane,
+		&ea.Direction,
 	)
 	if err != nil {
 		return nil, err
@@ -278,6 +718,7 @@ func ScanExercise(rows *sql.Rows) (*Exercise, error) {
 	var e Exercise
 	var createdWhen, modifiedWhen string
 	var exerciseType string
+	var archivedWhen *string
 	err := rows.Scan(
 		&e.ID,
 		&e.Version,
@@ -287,6 +728,7 @@ func ScanExercise(rows *sql.Rows) (*Exercise, error) {
 		&e.ModifiedBy,
 		&e.Name,
 		&exerciseType,
+		&archivedWhen,
 	)
 	if err != nil {
 		return nil, err
@@ -295,6 +737,10 @@ func ScanExercise(rows *sql.Rows) (*Exercise, error) {
 	e.CreatedWhen, _ = time.Parse("2006-01-02 15:04:05", createdWhen)
 	e.ModifiedWhen, _ = time.Parse("2006-01-02 15:04:05", modifiedWhen)
 	e.Type = ExerciseType(exerciseType)
+	if archivedWhen != nil {
+		archived, _ := time.Parse("2006-01-02 15:04:05", *archivedWhen)
+		e.ArchivedWhen = &archived
+	}
 	e.Muscles = []ExerciseMuscle{}
 	return &e, nil
 }
@@ -312,6 +758,8 @@ func ScanWorkout(rows *sql.Rows) (*Workout, error) {
 		&w.ModifiedBy,
 		&w.Name,
 		&w.UserID,
+		&w.Shareable,
+		&w.SourceWorkoutID,
 	)
 	if err != nil {
 		return nil, err
@@ -352,3 +800,449 @@ func ScanWorkoutExercise(rows *sql.Rows) (*WorkoutExercise, error) {
 	we.ModifiedWhen, _ = time.Parse("2006-01-02 15:04:05", modifiedWhen)
 	return &we, nil
 }
+
+// ScanWorkoutSession scans a WorkoutSession from a database row
+func ScanWorkoutSession(row interface {
+	Scan(dest ...interface{}) error
+}) (*WorkoutSession, error) {
+	var s WorkoutSession
+	var createdWhen, modifiedWhen, startedWhen string
+	var finishedWhen *string
+	err := row.Scan(
+		&s.ID,
+		&s.Version,
+		&createdWhen,
+		&s.CreatedBy,
+		&modifiedWhen,
+		&s.ModifiedBy,
+		&s.UserID,
+		&s.WorkoutID,
+		&s.Name,
+		&startedWhen,
+		&finishedWhen,
+		&s.Notes,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.CreatedWhen, _ = time.Parse("2006-01-02 15:04:05", createdWhen)
+	s.ModifiedWhen, _ = time.Parse("2006-01-02 15:04:05", modifiedWhen)
+	s.StartedWhen, _ = time.Parse("2006-01-02 15:04:05", startedWhen)
+	if finishedWhen != nil {
+		finished, _ := time.Parse("2006-01-02 15:04:05", *finishedWhen)
+		s.FinishedWhen = &finished
+	}
+	s.Sets = []WorkoutSessionSet{}
+	return &s, nil
+}
+
+// ScanWorkoutSessionSet scans a WorkoutSessionSet from a database row with exercise details
+func ScanWorkoutSessionSet(row interface {
+	Scan(dest ...interface{}) error
+}) (*WorkoutSessionSet, error) {
+	var ss WorkoutSessionSet
+	var createdWhen, modifiedWhen string
+	err := row.Scan(
+		&ss.ID,
+		&ss.Version,
+		&createdWhen,
+		&ss.CreatedBy,
+		&modifiedWhen,
+		&ss.ModifiedBy,
+		&ss.SessionID,
+		&ss.WorkoutExerciseID,
+		&ss.ExerciseID,
+		&ss.SetNumber,
+		&ss.Reps,
+		&ss.TimeSeconds,
+		&ss.Weight,
+		&ss.Notes,
+		&ss.ExerciseName,
+		&ss.ExerciseType,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ss.CreatedWhen, _ = time.Parse("2006-01-02 15:04:05", createdWhen)
+	ss.ModifiedWhen, _ = time.Parse("2006-01-02 15:04:05", modifiedWhen)
+	return &ss, nil
+}
+
+// ScanWorkoutExerciseSet scans a WorkoutExerciseSet from a database row
+func ScanWorkoutExerciseSet(row interface {
+	Scan(dest ...interface{}) error
+}) (*WorkoutExerciseSet, error) {
+	var ws WorkoutExerciseSet
+	var createdWhen, modifiedWhen string
+	var setType string
+	err := row.Scan(
+		&ws.ID,
+		&ws.Version,
+		&createdWhen,
+		&ws.CreatedBy,
+		&modifiedWhen,
+		&ws.ModifiedBy,
+		&ws.WorkoutExerciseID,
+		&ws.Position,
+		&setType,
+		&ws.Reps,
+		&ws.TimeSeconds,
+		&ws.Weight,
+		&ws.RPE,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.CreatedWhen, _ = time.Parse("2006-01-02 15:04:05", createdWhen)
+	ws.ModifiedWhen, _ = time.Parse("2006-01-02 15:04:05", modifiedWhen)
+	ws.SetType = SetType(setType)
+	return &ws, nil
+}
+
+// ScanPersonalRecord scans a PersonalRecord from a database row with exercise details
+func ScanPersonalRecord(row interface {
+	Scan(dest ...interface{}) error
+}) (*PersonalRecord, error) {
+	var pr PersonalRecord
+	var createdWhen, modifiedWhen, achievedWhen string
+	var recordType string
+	err := row.Scan(
+		&pr.ID,
+		&pr.Version,
+		&createdWhen,
+		&pr.CreatedBy,
+		&modifiedWhen,
+		&pr.ModifiedBy,
+		&pr.UserID,
+		&pr.ExerciseID,
+		&recordType,
+		&pr.Value,
+		&pr.Reps,
+		&pr.TimeSeconds,
+		&pr.Weight,
+		&pr.SessionSetID,
+		&achievedWhen,
+		&pr.ExerciseName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	pr.CreatedWhen, _ = time.Parse("2006-01-02 15:04:05", createdWhen)
+	pr.ModifiedWhen, _ = time.Parse("2006-01-02 15:04:05", modifiedWhen)
+	pr.AchievedWhen, _ = time.Parse("2006-01-02 15:04:05", achievedWhen)
+	pr.RecordType = PersonalRecordType(recordType)
+	return &pr, nil
+}
+
+// ScanProgram scans a Program from a database row
+func ScanProgram(row interface {
+	Scan(dest ...interface{}) error
+}) (*Program, error) {
+	var p Program
+	var createdWhen, modifiedWhen string
+	err := row.Scan(
+		&p.ID,
+		&p.Version,
+		&createdWhen,
+		&p.CreatedBy,
+		&modifiedWhen,
+		&p.ModifiedBy,
+		&p.UserID,
+		&p.Name,
+		&p.Description,
+		&p.WeightIncrement,
+		&p.RepsIncrement,
+		&p.DeloadEvery,
+		&p.DeloadPercentage,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.CreatedWhen, _ = time.Parse("2006-01-02 15:04:05", createdWhen)
+	p.ModifiedWhen, _ = time.Parse("2006-01-02 15:04:05", modifiedWhen)
+	return &p, nil
+}
+
+// ScanProgramWeek scans a ProgramWeek from a database row
+func ScanProgramWeek(row interface {
+	Scan(dest ...interface{}) error
+}) (*ProgramWeek, error) {
+	var w ProgramWeek
+	var createdWhen, modifiedWhen string
+	err := row.Scan(
+		&w.ID,
+		&w.Version,
+		&createdWhen,
+		&w.CreatedBy,
+		&modifiedWhen,
+		&w.ModifiedBy,
+		&w.ProgramID,
+		&w.WeekNumber,
+		&w.Notes,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	w.CreatedWhen, _ = time.Parse("2006-01-02 15:04:05", createdWhen)
+	w.ModifiedWhen, _ = time.Parse("2006-01-02 15:04:05", modifiedWhen)
+	w.Days = []ProgramDay{}
+	return &w, nil
+}
+
+// ScanProgramDay scans a ProgramDay from a database row with workout details
+func ScanProgramDay(row interface {
+	Scan(dest ...interface{}) error
+}) (*ProgramDay, error) {
+	var d ProgramDay
+	var createdWhen, modifiedWhen string
+	err := row.Scan(
+		&d.ID,
+		&d.Version,
+		&createdWhen,
+		&d.CreatedBy,
+		&modifiedWhen,
+		&d.ModifiedBy,
+		&d.ProgramWeekID,
+		&d.DayNumber,
+		&d.WorkoutID,
+		&d.WorkoutName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	d.CreatedWhen, _ = time.Parse("2006-01-02 15:04:05", createdWhen)
+	d.ModifiedWhen, _ = time.Parse("2006-01-02 15:04:05", modifiedWhen)
+	return &d, nil
+}
+
+// ScanProgramEnrollment scans a ProgramEnrollment from a database row with program details
+func ScanProgramEnrollment(row interface {
+	Scan(dest ...interface{}) error
+}) (*ProgramEnrollment, error) {
+	var e ProgramEnrollment
+	var createdWhen, modifiedWhen, startedWhen string
+	var completedWhen *string
+	err := row.Scan(
+		&e.ID,
+		&e.Version,
+		&createdWhen,
+		&e.CreatedBy,
+		&modifiedWhen,
+		&e.ModifiedBy,
+		&e.UserID,
+		&e.ProgramID,
+		&startedWhen,
+		&e.CurrentWeek,
+		&e.CurrentDay,
+		&completedWhen,
+		&e.ProgramName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	e.CreatedWhen, _ = time.Parse("2006-01-02 15:04:05", createdWhen)
+	e.ModifiedWhen, _ = time.Parse("2006-01-02 15:04:05", modifiedWhen)
+	e.StartedWhen, _ = time.Parse("2006-01-02 15:04:05", startedWhen)
+	if completedWhen != nil {
+		completed, _ := time.Parse("2006-01-02 15:04:05", *completedWhen)
+		e.CompletedWhen = &completed
+	}
+	return &e, nil
+}
+
+// ScanWorkoutSchedule scans a WorkoutSchedule from a database row with workout details
+func ScanWorkoutSchedule(row interface {
+	Scan(dest ...interface{}) error
+}) (*WorkoutSchedule, error) {
+	var ws WorkoutSchedule
+	var createdWhen, modifiedWhen string
+	var weekdays *string
+	err := row.Scan(
+		&ws.ID,
+		&ws.Version,
+		&createdWhen,
+		&ws.CreatedBy,
+		&modifiedWhen,
+		&ws.ModifiedBy,
+		&ws.UserID,
+		&ws.WorkoutID,
+		&ws.StartDate,
+		&ws.EndDate,
+		&weekdays,
+		&ws.IntervalWeeks,
+		&ws.Notes,
+		&ws.WorkoutName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.CreatedWhen, _ = time.Parse("2006-01-02 15:04:05", createdWhen)
+	ws.ModifiedWhen, _ = time.Parse("2006-01-02 15:04:05", modifiedWhen)
+	if weekdays != nil && *weekdays != "" {
+		ws.Weekdays = strings.Split(*weekdays, ",")
+	}
+	return &ws, nil
+}
+
+// ScanUser scans a User with its profile from a database row
+func ScanUser(row interface {
+	Scan(dest ...interface{}) error
+}) (*User, error) {
+	var user User
+	err := row.Scan(
+		&user.ID,
+		&user.Version,
+		&user.CreatedWhen,
+		&user.CreatedBy,
+		&user.ModifiedWhen,
+		&user.ModifiedBy,
+		&user.Email,
+		&user.Role,
+		&user.FirebaseUID,
+		&user.Status,
+		&user.StatusReason,
+		&user.DisplayName,
+		&user.Units,
+		&user.TimeZone,
+		&user.DateOfBirth,
+		&user.Sex,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ScanUserInvite scans a UserInvite from a database row
+func ScanUserInvite(row interface {
+	Scan(dest ...interface{}) error
+}) (*UserInvite, error) {
+	var invite UserInvite
+	var createdWhen, modifiedWhen string
+	var expiresWhen, usedWhen *string
+	err := row.Scan(
+		&invite.ID,
+		&invite.Version,
+		&createdWhen,
+		&invite.CreatedBy,
+		&modifiedWhen,
+		&invite.ModifiedBy,
+		&invite.Email,
+		&invite.Note,
+		&expiresWhen,
+		&usedWhen,
+		&invite.UsedByUserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	invite.CreatedWhen, _ = time.Parse("2006-01-02 15:04:05", createdWhen)
+	invite.ModifiedWhen, _ = time.Parse("2006-01-02 15:04:05", modifiedWhen)
+	if expiresWhen != nil {
+		expires, _ := time.Parse("2006-01-02 15:04:05", *expiresWhen)
+		invite.ExpiresWhen = &expires
+	}
+	if usedWhen != nil {
+		used, _ := time.Parse("2006-01-02 15:04:05", *usedWhen)
+		invite.UsedWhen = &used
+	}
+	return &invite, nil
+}
+
+// ScanRole scans a Role without its permissions from a database row
+func ScanRole(row interface {
+	Scan(dest ...interface{}) error
+}) (*Role, error) {
+	var role Role
+	var createdWhen, modifiedWhen string
+	err := row.Scan(
+		&role.ID,
+		&role.Version,
+		&createdWhen,
+		&role.CreatedBy,
+		&modifiedWhen,
+		&role.ModifiedBy,
+		&role.Name,
+		&role.Description,
+		&role.BuiltIn,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	role.CreatedWhen, _ = time.Parse("2006-01-02 15:04:05", createdWhen)
+	role.ModifiedWhen, _ = time.Parse("2006-01-02 15:04:05", modifiedWhen)
+	role.Permissions = []Permission{}
+	return &role, nil
+}
+
+// ScanCoaching scans a Coaching from a database row
+func ScanCoaching(row interface {
+	Scan(dest ...interface{}) error
+}) (*Coaching, error) {
+	var coaching Coaching
+	var createdWhen, modifiedWhen string
+	var acceptedWhen, endedWhen *string
+	err := row.Scan(
+		&coaching.ID,
+		&coaching.Version,
+		&createdWhen,
+		&coaching.CreatedBy,
+		&modifiedWhen,
+		&coaching.ModifiedBy,
+		&coaching.CoachUserID,
+		&coaching.CoachEmail,
+		&coaching.AthleteUserID,
+		&coaching.AthleteEmail,
+		&coaching.Status,
+		&acceptedWhen,
+		&endedWhen,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	coaching.CreatedWhen, _ = time.Parse("2006-01-02 15:04:05", createdWhen)
+	coaching.ModifiedWhen, _ = time.Parse("2006-01-02 15:04:05", modifiedWhen)
+	if acceptedWhen != nil {
+		accepted, _ := time.Parse("2006-01-02 15:04:05", *acceptedWhen)
+		coaching.AcceptedWhen = &accepted
+	}
+	if endedWhen != nil {
+		ended, _ := time.Parse("2006-01-02 15:04:05", *endedWhen)
+		coaching.EndedWhen = &ended
+	}
+	return &coaching, nil
+}
+
+// ScanWorkoutChange scans a WorkoutChange from a database row
+func ScanWorkoutChange(rows *sql.Rows) (*WorkoutChange, error) {
+	var change WorkoutChange
+	var changedWhen string
+	err := rows.Scan(
+		&change.ID,
+		&changedWhen,
+		&change.WorkoutID,
+		&change.UserID,
+		&change.UserEmail,
+		&change.EntityType,
+		&change.EntityID,
+		&change.Action,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	change.ChangedWhen, _ = time.Parse("2006-01-02 15:04:05", changedWhen)
+	return &change, nil
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"goliath/apperrors"
+	"goliath/authorization"
+	"goliath/middleware"
+	"goliath/services"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AnalyticsHandlers handles HTTP requests for training analytics endpoints
+type AnalyticsHandlers struct {
+	analyticsService *services.AnalyticsService
+	policy           Authorizer
+}
+
+// NewAnalyticsHandlers creates a new AnalyticsHandlers
+func NewAnalyticsHandlers(analyticsService *services.AnalyticsService, policy Authorizer) *AnalyticsHandlers {
+	return &AnalyticsHandlers{
+		analyticsService: analyticsService,
+		policy:           policy,
+	}
+}
+
+// parseDateQuery parses an optional YYYY-MM-DD query parameter into date, leaving it unchanged when absent
+func parseDateQuery(c *gin.Context, param string, date *time.Time) bool {
+	value := c.Query(param)
+	if value == "" {
+		return true
+	}
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		respondError(c, apperrors.Invalid("invalid_date", "Invalid %s date, expected YYYY-MM-DD", param).WithField(param, "must be a date like 2006-01-02"))
+		return false
+	}
+	*date = parsed
+	return true
+}
+
+// parseDateRange parses the from/to query parameters (YYYY-MM-DD, to inclusive).
+// Defaults to the twelve weeks up to and including today.
+func parseDateRange(c *gin.Context) (time.Time, time.Time, bool) {
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if !parseDateQuery(c, "to", &to) {
+		return time.Time{}, time.Time{}, false
+	}
+	to = to.AddDate(0, 0, 1)
+
+	from := to.AddDate(0, 0, -7*12)
+	if !parseDateQuery(c, "from", &from) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, to, true
+}
+
+// GetMuscleVolume handles GET /me/analytics/muscle-volume?from=&to=&granularity=week
+func (h *AnalyticsHandlers) GetMuscleVolume(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	granularity := c.DefaultQuery("granularity", services.GranularityWeek)
+
+	report, err := h.analyticsService.GetMuscleVolume(ctx, user.ID, from, to, granularity)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, report)
+}
+
+// GetWorkoutBalance handles GET /workouts/:id/balance
+func (h *AnalyticsHandlers) GetWorkoutBalance(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	workoutID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("workout"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionRead, authorization.Workout(workoutID)) {
+		return
+	}
+
+	report, err := h.analyticsService.GetWorkoutBalance(ctx, workoutID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, report)
+}
+
+// GetWeeklyBalance handles GET /me/analytics/balance?week=YYYY-MM-DD (any day of the week, defaults to this week)
+func (h *AnalyticsHandlers) GetWeeklyBalance(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	day := time.Now().UTC()
+	if weekStr := c.Query("week"); weekStr != "" {
+		parsed, err := time.Parse("2006-01-02", weekStr)
+		if err != nil {
+			respondError(c, apperrors.Invalid("invalid_date", "Invalid week date, expected YYYY-MM-DD").WithField("week", "must be a date like 2006-01-02"))
+			return
+		}
+		day = parsed
+	}
+
+	report, err := h.analyticsService.GetWeeklyBalance(ctx, user.ID, day)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"week_start":      services.PeriodStart(day, services.GranularityWeek),
+		"areas":           report.Areas,
+		"push_pull":       report.PushPull,
+		"untouched_areas": report.UntouchedAreas,
+	})
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"goliath/apperrors"
+	"goliath/middleware"
+	"goliath/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxImportBytes is the largest file accepted by POST /me/import and POST /catalog/import
+const MaxImportBytes = 10 << 20
+
+// ArchiveHandlers handles HTTP requests for exporting and importing training data
+type ArchiveHandlers struct {
+	archiveService *services.ArchiveService
+}
+
+// NewArchiveHandlers creates a new ArchiveHandlers
+func NewArchiveHandlers(archiveService *services.ArchiveService) *ArchiveHandlers {
+	return &ArchiveHandlers{
+		archiveService: archiveService,
+	}
+}
+
+// Export handles GET /me/export?format=json|csv - returns all workouts and sessions of the user
+func (h *ArchiveHandlers) Export(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	format := c.DefaultQuery("format", services.ArchiveFormatJSON)
+	if format != services.ArchiveFormatJSON && format != services.ArchiveFormatCSV {
+		respondError(c, apperrors.Invalid("invalid_query", "Invalid export format").WithField("format", "must be json or csv"))
+		return
+	}
+
+	archive, err := h.archiveService.Export(ctx, user.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="goliath-export.`+format+`"`)
+	if format == services.ArchiveFormatJSON {
+		c.JSON(200, archive)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := services.WriteArchiveCSV(&buf, archive); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Data(200, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// Import handles POST /me/import?format=&dry_run=true - imports a Goliath JSON or CSV archive or a
+// Strong or Hevy CSV export sent as the request body. The format is detected when omitted.
+func (h *ArchiveHandlers) Import(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	dryRun := false
+	if !bindDryRun(c, &dryRun) {
+		return
+	}
+	data, ok := readImportBody(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.archiveService.Import(ctx, user.ID, c.Query("format"), data, dryRun)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	status := 201
+	if dryRun {
+		status = 200
+	}
+	c.JSON(status, report)
+}
+
+// bindDryRun reads the optional dry_run query flag into dryRun, writing the error response
+// when it is invalid
+func bindDryRun(c *gin.Context, dryRun *bool) bool {
+	dryRunStr := c.Query("dry_run")
+	if dryRunStr == "" {
+		return true
+	}
+	parsed, err := strconv.ParseBool(dryRunStr)
+	if err != nil {
+		respondError(c, apperrors.Invalid("invalid_query", "Invalid dry_run flag").WithField("dry_run", "must be true or false"))
+		return false
+	}
+	*dryRun = parsed
+	return true
+}
+
+// readImportBody reads an import file sent as the request body, writing the error response
+// when it is too large or empty
+func readImportBody(c *gin.Context) ([]byte, bool) {
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, MaxImportBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(c, apperrors.Invalid("import_too_large", "import file exceeds %d bytes", MaxImportBytes))
+			return nil, false
+		}
+		respondError(c, errInvalidBody.Wrap(err))
+		return nil, false
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		respondError(c, apperrors.Invalid("invalid_import", "import file is empty"))
+		return nil, false
+	}
+	return data, true
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+
+	"goliath/apperrors"
+	"goliath/authorization"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authorizer decides whether the user of a request may perform an action on a resource, see
+// authorization.Policy
+type Authorizer interface {
+	Authorize(ctx context.Context, action authorization.Action, resource authorization.Resource) error
+}
+
+// authorize checks that the user of the request may perform the action on the resource.
+// Otherwise it writes the error response and returns false.
+func authorize(c *gin.Context, policy Authorizer, action authorization.Action, resource authorization.Resource) bool {
+	if err := policy.Authorize(c.Request.Context(), action, resource); err != nil {
+		respondError(c, err)
+		return false
+	}
+	return true
+}
+
+// authorizeReference checks that the user of the request may read a resource the body refers to
+// in field, e.g. the workout of a schedule. Unlike a resource in the URL, a missing one is an
+// invalid field.
+func authorizeReference(c *gin.Context, policy Authorizer, resource authorization.Resource, field string) bool {
+	err := policy.Authorize(c.Request.Context(), authorization.ActionRead, resource)
+	if appErr, ok := apperrors.As(err); ok && appErr.Kind == apperrors.KindNotFound {
+		err = apperrors.Invalid("unknown_"+string(resource.Type), "%s %d not found", resource.Type, resource.ID).
+			WithField(field, "no %s with this ID", resource.Type).Wrap(err)
+	}
+	if err != nil {
+		respondError(c, err)
+		return false
+	}
+	return true
+}
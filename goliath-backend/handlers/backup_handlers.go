@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"goliath/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BackupHandlers handles HTTP requests for database snapshots
+type BackupHandlers struct {
+	backupService *services.BackupService
+}
+
+// NewBackupHandlers creates a new BackupHandlers
+func NewBackupHandlers(backupService *services.BackupService) *BackupHandlers {
+	return &BackupHandlers{
+		backupService: backupService,
+	}
+}
+
+// GetBackups handles GET /backups - lists the snapshots, newest first (backup:read permission)
+func (h *BackupHandlers) GetBackups(c *gin.Context) {
+	snapshots, err := h.backupService.List()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"backups": snapshots,
+		"count":   len(snapshots),
+	})
+}
+
+// CreateBackup handles POST /backups - takes a snapshot of the database now (backup:write permission)
+func (h *BackupHandlers) CreateBackup(c *gin.Context) {
+	snapshot, err := h.backupService.Create(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, snapshot)
+}
+
+// VerifyBackup handles POST /backups/:name/verify - checks that a snapshot is an intact
+// Goliath database (backup:read permission)
+func (h *BackupHandlers) VerifyBackup(c *gin.Context) {
+	verification, err := h.backupService.Verify(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, verification)
+}
+
+// RestoreBackup handles POST /backups/:name/restore - replaces the database with a snapshot
+// after saving the current one as a pre-restore snapshot (backup:write permission)
+func (h *BackupHandlers) RestoreBackup(c *gin.Context) {
+	result, err := h.backupService.Restore(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, result)
+}
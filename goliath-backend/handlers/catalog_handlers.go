@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"bytes"
+
+	"goliath/apperrors"
+	"goliath/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CatalogHandlers handles HTTP requests for exporting and bulk importing the exercise catalog
+type CatalogHandlers struct {
+	catalogService *services.CatalogService
+}
+
+// NewCatalogHandlers creates a new CatalogHandlers
+func NewCatalogHandlers(catalogService *services.CatalogService) *CatalogHandlers {
+	return &CatalogHandlers{
+		catalogService: catalogService,
+	}
+}
+
+// ExportCatalog handles GET /catalog/export?format=json|yaml|csv - returns all exercises with
+// their muscle percentages and exercise areas (catalog:read permission)
+func (h *CatalogHandlers) ExportCatalog(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	format := c.DefaultQuery("format", services.CatalogFormatJSON)
+	if format != services.CatalogFormatJSON && format != services.CatalogFormatYAML && format != services.CatalogFormatCSV {
+		respondError(c, apperrors.Invalid("invalid_query", "Invalid export format").WithField("format", "must be json, yaml or csv"))
+		return
+	}
+
+	catalog, err := h.catalogService.Export(ctx)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="goliath-catalog.`+format+`"`)
+	var buf bytes.Buffer
+	switch format {
+	case services.CatalogFormatJSON:
+		c.IndentedJSON(200, catalog)
+		return
+	case services.CatalogFormatYAML:
+		err = services.WriteCatalogYAML(&buf, catalog)
+	default:
+		err = services.WriteCatalogCSV(&buf, catalog)
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == services.CatalogFormatYAML {
+		contentType = "application/yaml; charset=utf-8"
+	}
+	c.Data(200, contentType, buf.Bytes())
+}
+
+// ImportCatalog handles POST /catalog/import?format=&dry_run=true - upserts the exercises of a
+// JSON, YAML or CSV catalog sent as the request body by name (exercise:write permission). The format is
+// detected when omitted. A dry run only reports the changes.
+func (h *CatalogHandlers) ImportCatalog(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	dryRun := false
+	if !bindDryRun(c, &dryRun) {
+		return
+	}
+	data, ok := readImportBody(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.catalogService.Import(ctx, c.Query("format"), data, dryRun)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, report)
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"strconv"
+
+	"goliath/middleware"
+	"goliath/repositories"
+	"goliath/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CoachingHandlers handles HTTP requests for coaches and their athletes
+type CoachingHandlers struct {
+	coachingService *services.CoachingService
+}
+
+// NewCoachingHandlers creates a new CoachingHandlers
+func NewCoachingHandlers(coachingService *services.CoachingService) *CoachingHandlers {
+	return &CoachingHandlers{
+		coachingService: coachingService,
+	}
+}
+
+// GetAthletes handles GET /me/athletes (athlete:coach permission)
+func (h *CoachingHandlers) GetAthletes(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	athletes, err := h.coachingService.GetAthletes(ctx, user.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"athletes": athletes,
+		"count":    len(athletes),
+	})
+}
+
+// InviteAthlete handles POST /me/athletes - invites a user to be coached (athlete:coach permission)
+func (h *CoachingHandlers) InviteAthlete(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	var input services.CoachingInviteInput
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	coachingID, err := h.coachingService.InviteAthlete(ctx, user.ID, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"id":      coachingID,
+		"message": "Athlete invited successfully",
+	})
+}
+
+// EndAthlete handles DELETE /me/athletes/:id - stops coaching an athlete or withdraws the invite
+// (athlete:coach permission)
+func (h *CoachingHandlers) EndAthlete(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("coaching"))
+		return
+	}
+
+	if err := h.coachingService.EndAthlete(ctx, user.ID, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Coaching ended successfully",
+	})
+}
+
+// GetAthleteWorkouts handles GET /me/athletes/:id/workouts (athlete:coach permission)
+func (h *CoachingHandlers) GetAthleteWorkouts(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("coaching"))
+		return
+	}
+
+	params, ok := bindListParams(c, repositories.WorkoutListSpec)
+	if !ok {
+		return
+	}
+
+	workouts, nextCursor, err := h.coachingService.GetAthleteWorkouts(ctx, user.ID, id, params)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondList(c, "workouts", workouts, len(workouts), nextCursor)
+}
+
+// CreateAthleteWorkout handles POST /me/athletes/:id/workouts - creates a workout owned by the
+// athlete (athlete:coach permission)
+func (h *CoachingHandlers) CreateAthleteWorkout(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("coaching"))
+		return
+	}
+
+	var input services.CreateWorkoutInput
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	workoutID, err := h.coachingService.CreateAthleteWorkout(ctx, user.ID, id, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"id":      workoutID,
+		"message": "Workout created successfully",
+	})
+}
+
+// GetCoaches handles GET /me/coaches
+func (h *CoachingHandlers) GetCoaches(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	coaches, err := h.coachingService.GetCoaches(ctx, user.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"coaches": coaches,
+		"count":   len(coaches),
+	})
+}
+
+// AcceptCoach handles POST /me/coaches/:id/accept - grants the coach access to the user's workouts
+func (h *CoachingHandlers) AcceptCoach(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("coaching"))
+		return
+	}
+
+	if err := h.coachingService.AcceptCoach(ctx, user.ID, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Coaching accepted successfully",
+	})
+}
+
+// RevokeCoach handles DELETE /me/coaches/:id - declines the invite or revokes the coach's access
+func (h *CoachingHandlers) RevokeCoach(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("coaching"))
+		return
+	}
+
+	if err := h.coachingService.RevokeCoach(ctx, user.ID, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Coaching ended successfully",
+	})
+}
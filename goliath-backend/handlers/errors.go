@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"goliath/apperrors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// errInvalidBody is returned when a request body cannot be bound to its input
+var errInvalidBody = apperrors.Invalid("invalid_body", "request body is invalid")
+
+func init() {
+	// Report invalid fields by their JSON names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// respondError hands err to the error middleware, which writes the problem response
+func respondError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// invalidID returns the error of a malformed ID in the URL
+func invalidID(label string) error {
+	return apperrors.Invalid("invalid_id", "Invalid %s ID", label)
+}
+
+// bindJSON binds the request body to input and responds with the invalid fields on failure
+func bindJSON(c *gin.Context, input interface{}) bool {
+	if err := c.ShouldBindJSON(input); err != nil {
+		respondError(c, bindError(err))
+		return false
+	}
+	return true
+}
+
+// bindError describes a binding failure as a validation error with field details
+func bindError(err error) error {
+	var validationErrors validator.ValidationErrors
+	var typeError *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &validationErrors):
+		invalid := errInvalidBody
+		for _, fe := range validationErrors {
+			invalid = invalid.WithField(fieldPath(fe), "%s", fieldMessage(fe))
+		}
+		return invalid
+	case errors.As(err, &typeError) && typeError.Field != "":
+		return errInvalidBody.WithField(typeError.Field, "must be of type %s", typeError.Type.Kind())
+	default:
+		return errInvalidBody.Wrap(err)
+	}
+}
+
+// fieldPath returns the JSON path of an invalid field without the input struct name
+func fieldPath(fe validator.FieldError) string {
+	path := fe.Namespace()
+	if i := strings.Index(path, "."); i >= 0 {
+		path = path[i+1:]
+	}
+	return path
+}
+
+// fieldMessage describes a failed validation rule
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", fe.Param())
+	default:
+		return fmt.Sprintf("failed the %s rule", fe.Tag())
+	}
+}
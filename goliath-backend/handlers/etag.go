@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"goliath/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// versionETag returns the entity tag of a resource at the given version
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// parseETag returns the version of an entity tag produced by versionETag.
+// Weak tags are accepted since the version identifies the representation either way.
+func parseETag(tag string) (int, bool) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		return 0, false
+	}
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// notModified sets the ETag of a resource and answers 304 when it matches If-None-Match.
+// It returns true when the response has been written.
+func notModified(c *gin.Context, version int) bool {
+	etag := versionETag(version)
+	c.Header("ETag", etag)
+
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if v, ok := parseETag(tag); tag == "*" || (ok && v == version) {
+			c.Status(304)
+			return true
+		}
+	}
+	return false
+}
+
+// bindIfMatch replaces the expected version of an update with the one of the If-Match header,
+// if sent. "*" only requires the resource to exist. It responds 400 to a malformed header, and
+// 428 when neither the header nor the body says which version the update expects.
+// A stale If-Match version is answered with 412 by the error middleware.
+func bindIfMatch(c *gin.Context, version *int) bool {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		if *version == 0 {
+			respondError(c, apperrors.ErrPreconditionRequired.WithField("version", "is required unless If-Match is sent"))
+			return false
+		}
+		return true
+	}
+
+	if header == "*" {
+		*version = 0
+		return true
+	}
+	v, ok := parseETag(header)
+	if !ok {
+		respondError(c, apperrors.Invalid("invalid_if_match", "Invalid If-Match header: expected a single ETag").WithField("If-Match", "must be a single ETag or *"))
+		return false
+	}
+	*version = v
+	return true
+}
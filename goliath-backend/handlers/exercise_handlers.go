@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"goliath/apperrors"
+	"goliath/repositories"
 	"goliath/services"
 	"log"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -24,16 +27,86 @@ func NewExerciseHandlers(exerciseService *services.ExerciseService) *ExerciseHan
 func (h *ExerciseHandlers) GetExercises(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	exercises, err := h.exerciseService.GetAllExercises(ctx)
+	params, ok := bindListParams(c, repositories.ExerciseListSpec)
+	if !ok {
+		return
+	}
+
+	includeArchived := c.Query("include_archived") == "true"
+
+	exercises, nextCursor, err := h.exerciseService.GetAllExercises(ctx, params, includeArchived)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(200, gin.H{
-		"exercises": exercises,
-		"count":     len(exercises),
-	})
+	respondList(c, "exercises", exercises, len(exercises), nextCursor)
+}
+
+// SearchExercises handles GET /exercises/search
+func (h *ExerciseHandlers) SearchExercises(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	input := services.SearchExercisesInput{
+		Query:  c.Query("q"),
+		Sort:   c.Query("sort"),
+		Cursor: c.Query("cursor"),
+	}
+
+	if typesStr := c.Query("type"); typesStr != "" {
+		for _, t := range strings.Split(typesStr, ",") {
+			input.Types = append(input.Types, strings.TrimSpace(t))
+		}
+	}
+
+	// Parse optional facet filters
+	facetFilters := []struct {
+		param string
+		dest  **int
+	}{
+		{"muscle_id", &input.MuscleID},
+		{"muscle_group_id", &input.MuscleGroupID},
+		{"region_id", &input.RegionID},
+		{"exercise_area_id", &input.ExerciseAreaID},
+	}
+	for _, filter := range facetFilters {
+		valueStr := c.Query(filter.param)
+		if valueStr == "" {
+			continue
+		}
+		value, err := strconv.Atoi(valueStr)
+		if err != nil {
+			respondError(c, apperrors.Invalid("invalid_query", "Invalid %s", filter.param).WithField(filter.param, "must be an integer"))
+			return
+		}
+		*filter.dest = &value
+	}
+
+	if minStr := c.Query("min_percentage"); minStr != "" {
+		minPercentage, err := strconv.ParseFloat(minStr, 64)
+		if err != nil {
+			respondError(c, apperrors.Invalid("invalid_query", "Invalid min_percentage").WithField("min_percentage", "must be a number"))
+			return
+		}
+		input.MinPercentage = minPercentage
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			respondError(c, apperrors.Invalid("invalid_query", "Invalid limit").WithField("limit", "must be an integer"))
+			return
+		}
+		input.Limit = limit
+	}
+
+	result, err := h.exerciseService.SearchExercises(ctx, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, result)
 }
 
 // GetExerciseTypes handles GET /exercise-types
@@ -50,8 +123,7 @@ func (h *ExerciseHandlers) CreateExercise(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	var input services.CreateExerciseInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+	if !bindJSON(c, &input) {
 		return
 	}
 
@@ -59,17 +131,7 @@ func (h *ExerciseHandlers) CreateExercise(c *gin.Context) {
 	exerciseID, err := h.exerciseService.CreateExercise(ctx, input)
 	log.Printf("3POST excersise create %s", c.Request.Method)
 	if err != nil {
-		// Check if it's a business logic error (duplicate name, invalid type)
-		if err.Error() == "exercise with name '"+input.Name+"' already exists" {
-			c.JSON(409, gin.H{"error": err.Error()})
-			return
-		}
-		if err.Error() == "invalid exercise type: "+input.Type {
-			c.JSON(400, gin.H{"error": err.Error()})
-			return
-		}
-		// Database or other errors
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -87,13 +149,17 @@ func (h *ExerciseHandlers) GetExercise(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(400, gin.H{"error": "Invalid exercise ID"})
+		respondError(c, invalidID("exercise"))
 		return
 	}
 
 	exercise, err := h.exerciseService.GetExerciseByID(ctx, id)
 	if err != nil {
-		c.JSON(404, gin.H{"error": "Exercise not found"})
+		respondError(c, err)
+		return
+	}
+
+	if notModified(c, exercise.Version) {
 		return
 	}
 
@@ -108,29 +174,21 @@ func (h *ExerciseHandlers) UpdateExercise(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(400, gin.H{"error": "Invalid exercise ID"})
+		respondError(c, invalidID("exercise"))
 		return
 	}
 
 	var input services.UpdateExerciseInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
 		return
 	}
 
 	err = h.exerciseService.UpdateExercise(ctx, id, input)
 	if err != nil {
-		// Check if it's a business logic error
-		if err.Error() == "exercise with name '"+input.Name+"' already exists" {
-			c.JSON(409, gin.H{"error": err.Error()})
-			return
-		}
-		if err.Error() == "invalid exercise type: "+input.Type {
-			c.JSON(400, gin.H{"error": err.Error()})
-			return
-		}
-		// Database or other errors
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -138,3 +196,69 @@ func (h *ExerciseHandlers) UpdateExercise(c *gin.Context) {
 		"message": "Exercise updated successfully",
 	})
 }
+
+// ArchiveExercise handles DELETE /exercises/:id - archives (soft-deletes) the exercise
+func (h *ExerciseHandlers) ArchiveExercise(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("exercise"))
+		return
+	}
+
+	if err := h.exerciseService.ArchiveExercise(ctx, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Exercise archived successfully",
+	})
+}
+
+// RestoreExercise handles POST /exercises/:id/restore
+func (h *ExerciseHandlers) RestoreExercise(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("exercise"))
+		return
+	}
+
+	if err := h.exerciseService.RestoreExercise(ctx, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Exercise restored successfully",
+	})
+}
+
+// MergeExercise handles POST /exercises/:id/merge - merges the exercise into target_exercise_id
+func (h *ExerciseHandlers) MergeExercise(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("exercise"))
+		return
+	}
+
+	var input services.MergeExercisesInput
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	if err := h.exerciseService.MergeExercises(ctx, id, input); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"id":      input.TargetExerciseID,
+		"message": "Exercises merged successfully",
+	})
+}
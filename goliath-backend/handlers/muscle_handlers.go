@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"goliath/repositories"
 	"goliath/services"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,63 +24,394 @@ func NewMuscleHandlers(muscleService *services.MuscleService) *MuscleHandlers {
 func (h *MuscleHandlers) GetMuscles(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	muscles, err := h.muscleService.GetAllMuscles(ctx)
+	params, ok := bindListParams(c, repositories.MuscleListSpec)
+	if !ok {
+		return
+	}
+
+	muscles, nextCursor, err := h.muscleService.GetAllMuscles(ctx, params)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(200, gin.H{
-		"muscles": muscles,
-		"count":   len(muscles),
-	})
+	respondList(c, "muscles", muscles, len(muscles), nextCursor)
 }
 
 // GetMuscleGroups handles GET /muscle-groups
 func (h *MuscleHandlers) GetMuscleGroups(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	muscleGroups, err := h.muscleService.GetAllMuscleGroups(ctx)
+	params, ok := bindListParams(c, repositories.MuscleGroupListSpec)
+	if !ok {
+		return
+	}
+
+	muscleGroups, nextCursor, err := h.muscleService.GetAllMuscleGroups(ctx, params)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(200, gin.H{
-		"muscle_groups": muscleGroups,
-		"count":         len(muscleGroups),
-	})
+	respondList(c, "muscle_groups", muscleGroups, len(muscleGroups), nextCursor)
 }
 
 // GetRegions handles GET /regions
 func (h *MuscleHandlers) GetRegions(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	regions, err := h.muscleService.GetAllRegions(ctx)
+	params, ok := bindListParams(c, repositories.RegionListSpec)
+	if !ok {
+		return
+	}
+
+	regions, nextCursor, err := h.muscleService.GetAllRegions(ctx, params)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(200, gin.H{
-		"regions": regions,
-		"count":   len(regions),
-	})
+	respondList(c, "regions", regions, len(regions), nextCursor)
 }
 
 // GetExerciseAreas handles GET /exercise-areas
 func (h *MuscleHandlers) GetExerciseAreas(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	exerciseAreas, err := h.muscleService.GetAllExerciseAreas(ctx)
+	params, ok := bindListParams(c, repositories.ExerciseAreaListSpec)
+	if !ok {
+		return
+	}
+
+	exerciseAreas, nextCursor, err := h.muscleService.GetAllExerciseAreas(ctx, params)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondList(c, "exercise_areas", exerciseAreas, len(exerciseAreas), nextCursor)
+}
+
+// parseTaxonomyID parses a taxonomy entity ID from the URL
+func parseTaxonomyID(c *gin.Context, param string, label string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(param))
+	if err != nil {
+		respondError(c, invalidID(label))
+		return 0, false
+	}
+	return id, true
+}
+
+// CreateRegion handles POST /regions
+func (h *MuscleHandlers) CreateRegion(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input services.SaveRegionInput
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	id, err := h.muscleService.CreateRegion(ctx, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"id":      id,
+		"message": "Region created successfully",
+	})
+}
+
+// UpdateRegion handles PUT /regions/:id
+func (h *MuscleHandlers) UpdateRegion(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseTaxonomyID(c, "id", "region")
+	if !ok {
+		return
+	}
+
+	var input services.SaveRegionInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
+		return
+	}
+
+	if err := h.muscleService.UpdateRegion(ctx, id, input); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Region updated successfully",
+	})
+}
+
+// DeleteRegion handles DELETE /regions/:id
+func (h *MuscleHandlers) DeleteRegion(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseTaxonomyID(c, "id", "region")
+	if !ok {
+		return
+	}
+
+	if err := h.muscleService.DeleteRegion(ctx, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Region deleted successfully",
+	})
+}
+
+// CreateMuscleGroup handles POST /muscle-groups
+func (h *MuscleHandlers) CreateMuscleGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input services.SaveMuscleGroupInput
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	id, err := h.muscleService.CreateMuscleGroup(ctx, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"id":      id,
+		"message": "Muscle group created successfully",
+	})
+}
+
+// UpdateMuscleGroup handles PUT /muscle-groups/:id
+func (h *MuscleHandlers) UpdateMuscleGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseTaxonomyID(c, "id", "muscle group")
+	if !ok {
+		return
+	}
+
+	var input services.SaveMuscleGroupInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
+		return
+	}
+
+	if err := h.muscleService.UpdateMuscleGroup(ctx, id, input); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Muscle group updated successfully",
+	})
+}
+
+// DeleteMuscleGroup handles DELETE /muscle-groups/:id
+func (h *MuscleHandlers) DeleteMuscleGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseTaxonomyID(c, "id", "muscle group")
+	if !ok {
+		return
+	}
+
+	if err := h.muscleService.DeleteMuscleGroup(ctx, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Muscle group deleted successfully",
+	})
+}
+
+// CreateMuscle handles POST /muscles
+func (h *MuscleHandlers) CreateMuscle(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input services.SaveMuscleInput
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	id, err := h.muscleService.CreateMuscle(ctx, input)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"id":      id,
+		"message": "Muscle created successfully",
+	})
+}
+
+// UpdateMuscle handles PUT /muscles/:id
+func (h *MuscleHandlers) UpdateMuscle(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseTaxonomyID(c, "id", "muscle")
+	if !ok {
+		return
+	}
+
+	var input services.SaveMuscleInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
+		return
+	}
+
+	if err := h.muscleService.UpdateMuscle(ctx, id, input); err != nil {
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(200, gin.H{
-		"exercise_areas": exerciseAreas,
-		"count":          len(exerciseAreas),
+		"message": "Muscle updated successfully",
 	})
 }
 
+// DeleteMuscle handles DELETE /muscles/:id
+func (h *MuscleHandlers) DeleteMuscle(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseTaxonomyID(c, "id", "muscle")
+	if !ok {
+		return
+	}
+
+	if err := h.muscleService.DeleteMuscle(ctx, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Muscle deleted successfully",
+	})
+}
+
+// CreateExerciseArea handles POST /exercise-areas
+func (h *MuscleHandlers) CreateExerciseArea(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input services.SaveExerciseAreaInput
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	id, err := h.muscleService.CreateExerciseArea(ctx, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"id":      id,
+		"message": "Exercise area created successfully",
+	})
+}
+
+// UpdateExerciseArea handles PUT /exercise-areas/:id
+func (h *MuscleHandlers) UpdateExerciseArea(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseTaxonomyID(c, "id", "exercise area")
+	if !ok {
+		return
+	}
+
+	var input services.SaveExerciseAreaInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
+		return
+	}
+
+	if err := h.muscleService.UpdateExerciseArea(ctx, id, input); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Exercise area updated successfully",
+	})
+}
+
+// DeleteExerciseArea handles DELETE /exercise-areas/:id
+func (h *MuscleHandlers) DeleteExerciseArea(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := parseTaxonomyID(c, "id", "exercise area")
+	if !ok {
+		return
+	}
+
+	if err := h.muscleService.DeleteExerciseArea(ctx, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Exercise area deleted successfully",
+	})
+}
+
+// LinkMuscleExerciseArea handles POST /muscles/:id/exercise-areas/:area_id
+func (h *MuscleHandlers) LinkMuscleExerciseArea(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	muscleID, ok := parseTaxonomyID(c, "id", "muscle")
+	if !ok {
+		return
+	}
+	exerciseAreaID, ok := parseTaxonomyID(c, "area_id", "exercise area")
+	if !ok {
+		return
+	}
+
+	if err := h.muscleService.LinkMuscleExerciseArea(ctx, muscleID, exerciseAreaID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"message": "Exercise area linked successfully",
+	})
+}
+
+// UnlinkMuscleExerciseArea handles DELETE /muscles/:id/exercise-areas/:area_id
+func (h *MuscleHandlers) UnlinkMuscleExerciseArea(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	muscleID, ok := parseTaxonomyID(c, "id", "muscle")
+	if !ok {
+		return
+	}
+	exerciseAreaID, ok := parseTaxonomyID(c, "area_id", "exercise area")
+	if !ok {
+		return
+	}
+
+	if err := h.muscleService.UnlinkMuscleExerciseArea(ctx, muscleID, exerciseAreaID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Exercise area unlinked successfully",
+	})
+}
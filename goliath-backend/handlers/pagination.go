@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"strconv"
+
+	"goliath/apperrors"
+	"goliath/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseListParams reads limit, sort, cursor and the filters allowed by spec from the query string
+func parseListParams(c *gin.Context, spec repositories.ListSpec) (repositories.ListParams, error) {
+	params := repositories.ListParams{
+		Limit:   repositories.DefaultListLimit,
+		Sort:    c.Query("sort"),
+		Filters: map[string]string{},
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > repositories.MaxListLimit {
+			return params, apperrors.Invalid("invalid_limit", "limit must be between 1 and %d", repositories.MaxListLimit).WithField("limit", "must be between 1 and %d", repositories.MaxListLimit)
+		}
+		params.Limit = limit
+	}
+
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		var cursor repositories.ListCursor
+		if err := repositories.DecodeCursor(cursorStr, &cursor); err != nil {
+			return params, err
+		}
+		params.Cursor = &cursor
+	}
+
+	// Only whitelisted filters are read, other query parameters are ignored
+	for name := range spec.Filters {
+		if value, ok := c.GetQuery(name); ok {
+			params.Filters[name] = value
+		}
+	}
+
+	if err := spec.Validate(params); err != nil {
+		return params, err
+	}
+
+	return params, nil
+}
+
+// bindListParams parses the list parameters and responds with a validation problem when they are invalid
+func bindListParams(c *gin.Context, spec repositories.ListSpec) (repositories.ListParams, bool) {
+	params, err := parseListParams(c, spec)
+	if err != nil {
+		respondError(c, err)
+		return params, false
+	}
+	return params, true
+}
+
+// respondList writes a page of items in the common list envelope.
+// An empty nextCursor marks the last page and is returned as null.
+func respondList(c *gin.Context, key string, items interface{}, count int, nextCursor string) {
+	var next interface{}
+	if nextCursor != "" {
+		next = nextCursor
+	}
+
+	c.JSON(200, gin.H{
+		key:           items,
+		"count":       count,
+		"next_cursor": next,
+	})
+}
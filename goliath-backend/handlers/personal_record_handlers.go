@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"goliath/middleware"
+	"goliath/repositories"
+	"goliath/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PersonalRecordHandlers handles HTTP requests for personal record endpoints
+type PersonalRecordHandlers struct {
+	recordService *services.PersonalRecordService
+}
+
+// NewPersonalRecordHandlers creates a new PersonalRecordHandlers
+func NewPersonalRecordHandlers(recordService *services.PersonalRecordService) *PersonalRecordHandlers {
+	return &PersonalRecordHandlers{
+		recordService: recordService,
+	}
+}
+
+// GetMyRecords handles GET /me/records - returns personal records of the authenticated user
+func (h *PersonalRecordHandlers) GetMyRecords(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	params, ok := bindListParams(c, repositories.PersonalRecordListSpec)
+	if !ok {
+		return
+	}
+
+	records, nextCursor, err := h.recordService.GetUserRecords(ctx, user.ID, params)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondList(c, "records", records, len(records), nextCursor)
+}
+
+// GetExerciseRecords handles GET /exercises/:id/records - returns the authenticated user's records for an exercise
+func (h *PersonalRecordHandlers) GetExerciseRecords(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	// Parse ID from URL
+	exerciseID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("exercise"))
+		return
+	}
+
+	records, err := h.recordService.GetUserExerciseRecords(ctx, user.ID, exerciseID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondList(c, "records", records, len(records), "")
+}
@@ -0,0 +1,600 @@
+package handlers
+
+import (
+	"strconv"
+
+	"goliath/apperrors"
+	"goliath/authorization"
+	"goliath/middleware"
+	"goliath/repositories"
+	"goliath/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProgramHandlers handles HTTP requests for training programs and enrollments
+type ProgramHandlers struct {
+	programService *services.ProgramService
+	policy         Authorizer
+}
+
+// NewProgramHandlers creates a new ProgramHandlers
+func NewProgramHandlers(programService *services.ProgramService, policy Authorizer) *ProgramHandlers {
+	return &ProgramHandlers{
+		programService: programService,
+		policy:         policy,
+	}
+}
+
+// parseProgramWeekParams parses the program and week IDs from the URL
+func parseProgramWeekParams(c *gin.Context) (int, int, bool) {
+	programID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("program"))
+		return 0, 0, false
+	}
+	weekID, err := strconv.Atoi(c.Param("week_id"))
+	if err != nil {
+		respondError(c, invalidID("program week"))
+		return 0, 0, false
+	}
+	return programID, weekID, true
+}
+
+// GetPrograms handles GET /programs - returns programs for authenticated user
+func (h *ProgramHandlers) GetPrograms(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	params, ok := bindListParams(c, repositories.ProgramListSpec)
+	if !ok {
+		return
+	}
+
+	programs, nextCursor, err := h.programService.GetUserPrograms(ctx, user.ID, params)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondList(c, "programs", programs, len(programs), nextCursor)
+}
+
+// GetProgram handles GET /programs/:id - returns a program with its weeks and days
+func (h *ProgramHandlers) GetProgram(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("program"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionRead, authorization.Program(id)) {
+		return
+	}
+
+	program, err := h.programService.GetProgramByID(ctx, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if notModified(c, program.Version) {
+		return
+	}
+
+	c.JSON(200, program)
+}
+
+// CreateProgram handles POST /programs
+func (h *ProgramHandlers) CreateProgram(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	var input services.ProgramInput
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	programID, err := h.programService.CreateProgram(ctx, user.ID, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"id":      programID,
+		"message": "Program created successfully",
+	})
+}
+
+// UpdateProgram handles PUT /programs/:id
+func (h *ProgramHandlers) UpdateProgram(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("program"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.Program(id)) {
+		return
+	}
+
+	var input services.ProgramInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
+		return
+	}
+
+	if err := h.programService.UpdateProgram(ctx, id, input); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Program updated successfully",
+	})
+}
+
+// DeleteProgram handles DELETE /programs/:id
+func (h *ProgramHandlers) DeleteProgram(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("program"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionDelete, authorization.Program(id)) {
+		return
+	}
+
+	if err := h.programService.DeleteProgram(ctx, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Program deleted successfully",
+	})
+}
+
+// AddWeekToProgram handles POST /programs/:id/weeks
+func (h *ProgramHandlers) AddWeekToProgram(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	programID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("program"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.Program(programID)) {
+		return
+	}
+
+	var input services.ProgramWeekInput
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	weekID, err := h.programService.AddWeekToProgram(ctx, programID, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"id":      weekID,
+		"message": "Week added to program successfully",
+	})
+}
+
+// UpdateProgramWeek handles PUT /programs/:id/weeks/:week_id
+func (h *ProgramHandlers) UpdateProgramWeek(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	programID, weekID, ok := parseProgramWeekParams(c)
+	if !ok {
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.ProgramWeek(programID, weekID)) {
+		return
+	}
+
+	var input services.ProgramWeekInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
+		return
+	}
+
+	if err := h.programService.UpdateProgramWeek(ctx, programID, weekID, input); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Program week updated successfully",
+	})
+}
+
+// RemoveWeekFromProgram handles DELETE /programs/:id/weeks/:week_id
+func (h *ProgramHandlers) RemoveWeekFromProgram(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	programID, weekID, ok := parseProgramWeekParams(c)
+	if !ok {
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.ProgramWeek(programID, weekID)) {
+		return
+	}
+
+	if err := h.programService.RemoveWeekFromProgram(ctx, weekID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Week removed from program successfully",
+	})
+}
+
+// AddDayToProgramWeek handles POST /programs/:id/weeks/:week_id/days
+func (h *ProgramHandlers) AddDayToProgramWeek(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	programID, weekID, ok := parseProgramWeekParams(c)
+	if !ok {
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.ProgramWeek(programID, weekID)) {
+		return
+	}
+
+	var input services.ProgramDayInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !authorizeReference(c, h.policy, authorization.Workout(input.WorkoutID), "workout_id") {
+		return
+	}
+
+	dayID, err := h.programService.AddDayToProgramWeek(ctx, weekID, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"id":      dayID,
+		"message": "Day added to program week successfully",
+	})
+}
+
+// UpdateProgramDay handles PUT /programs/:id/weeks/:week_id/days/:day_id
+func (h *ProgramHandlers) UpdateProgramDay(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	programID, weekID, ok := parseProgramWeekParams(c)
+	if !ok {
+		return
+	}
+	dayID, err := strconv.Atoi(c.Param("day_id"))
+	if err != nil {
+		respondError(c, invalidID("program day"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.ProgramDay(programID, weekID, dayID)) {
+		return
+	}
+
+	var input services.ProgramDayInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
+		return
+	}
+	if !authorizeReference(c, h.policy, authorization.Workout(input.WorkoutID), "workout_id") {
+		return
+	}
+
+	if err := h.programService.UpdateProgramDay(ctx, weekID, dayID, input); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Program day updated successfully",
+	})
+}
+
+// RemoveDayFromProgramWeek handles DELETE /programs/:id/weeks/:week_id/days/:day_id
+func (h *ProgramHandlers) RemoveDayFromProgramWeek(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	programID, weekID, ok := parseProgramWeekParams(c)
+	if !ok {
+		return
+	}
+	dayID, err := strconv.Atoi(c.Param("day_id"))
+	if err != nil {
+		respondError(c, invalidID("program day"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.ProgramDay(programID, weekID, dayID)) {
+		return
+	}
+
+	if err := h.programService.RemoveDayFromProgramWeek(ctx, dayID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Day removed from program week successfully",
+	})
+}
+
+// GetPrescription handles GET /programs/:id/prescription?week=3&day=2 - returns the
+// workout of that day with the progression of its week applied
+func (h *ProgramHandlers) GetPrescription(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	programID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("program"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionRead, authorization.Program(programID)) {
+		return
+	}
+
+	week, err := strconv.Atoi(c.Query("week"))
+	if err != nil || week < 1 {
+		respondError(c, apperrors.Invalid("invalid_query", "Invalid week").WithField("week", "must be a positive integer"))
+		return
+	}
+	day, err := strconv.Atoi(c.Query("day"))
+	if err != nil || day < 1 {
+		respondError(c, apperrors.Invalid("invalid_query", "Invalid day").WithField("day", "must be a positive integer"))
+		return
+	}
+
+	prescription, err := h.programService.GetPrescription(ctx, programID, week, day)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, prescription)
+}
+
+// EnrollInProgram handles POST /programs/:id/enroll
+func (h *ProgramHandlers) EnrollInProgram(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	// Parse ID from URL
+	programID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("program"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionRead, authorization.Program(programID)) {
+		return
+	}
+
+	// Body is optional
+	var input services.EnrollInput
+	if c.Request.ContentLength > 0 {
+		if !bindJSON(c, &input) {
+			return
+		}
+	}
+
+	enrollmentID, err := h.programService.EnrollInProgram(ctx, programID, user.ID, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"id":      enrollmentID,
+		"message": "Enrolled in program successfully",
+	})
+}
+
+// GetMyEnrollments handles GET /me/enrollments - returns the programs the user follows or followed
+func (h *ProgramHandlers) GetMyEnrollments(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	params, ok := bindListParams(c, repositories.EnrollmentListSpec)
+	if !ok {
+		return
+	}
+
+	enrollments, nextCursor, err := h.programService.GetUserEnrollments(ctx, user.ID, params)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondList(c, "enrollments", enrollments, len(enrollments), nextCursor)
+}
+
+// GetMyEnrollment handles GET /me/enrollments/:id
+func (h *ProgramHandlers) GetMyEnrollment(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("enrollment"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionRead, authorization.Enrollment(id)) {
+		return
+	}
+
+	enrollment, err := h.programService.GetEnrollmentByID(ctx, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if notModified(c, enrollment.Version) {
+		return
+	}
+
+	c.JSON(200, enrollment)
+}
+
+// GetMyEnrollmentPrescription handles GET /me/enrollments/:id/prescription - returns the workout of the next day
+func (h *ProgramHandlers) GetMyEnrollmentPrescription(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("enrollment"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionRead, authorization.Enrollment(id)) {
+		return
+	}
+
+	prescription, err := h.programService.GetEnrollmentPrescription(ctx, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, prescription)
+}
+
+// AdvanceMyEnrollment handles POST /me/enrollments/:id/advance - marks the current day as done
+func (h *ProgramHandlers) AdvanceMyEnrollment(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("enrollment"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.Enrollment(id)) {
+		return
+	}
+
+	// Body is optional
+	var input services.AdvanceEnrollmentInput
+	if c.Request.ContentLength > 0 {
+		if !bindJSON(c, &input) {
+			return
+		}
+	}
+	if !bindIfMatch(c, &input.Version) {
+		return
+	}
+
+	if err := h.programService.AdvanceEnrollment(ctx, id, input); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Enrollment advanced successfully",
+	})
+}
+
+// UpdateMyEnrollment handles PUT /me/enrollments/:id - moves the enrollment to another day
+func (h *ProgramHandlers) UpdateMyEnrollment(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("enrollment"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.Enrollment(id)) {
+		return
+	}
+
+	var input services.UpdateEnrollmentInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
+		return
+	}
+
+	if err := h.programService.UpdateEnrollment(ctx, id, input); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Enrollment updated successfully",
+	})
+}
+
+// DeleteMyEnrollment handles DELETE /me/enrollments/:id - stops following the program
+func (h *ProgramHandlers) DeleteMyEnrollment(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("enrollment"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionDelete, authorization.Enrollment(id)) {
+		return
+	}
+
+	if err := h.programService.DeleteEnrollment(ctx, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Enrollment deleted successfully",
+	})
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"strconv"
+
+	"goliath/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegistrationHandlers handles HTTP requests for the registration policy and invites
+type RegistrationHandlers struct {
+	registrationService *services.RegistrationService
+}
+
+// NewRegistrationHandlers creates a new RegistrationHandlers
+func NewRegistrationHandlers(registrationService *services.RegistrationService) *RegistrationHandlers {
+	return &RegistrationHandlers{
+		registrationService: registrationService,
+	}
+}
+
+// GetRegistrationPolicy handles GET /registration (registration:read permission)
+func (h *RegistrationHandlers) GetRegistrationPolicy(c *gin.Context) {
+	policy, err := h.registrationService.GetPolicy(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if notModified(c, policy.Version) {
+		return
+	}
+
+	c.JSON(200, policy)
+}
+
+// UpdateRegistrationPolicy handles PUT /registration - switches invite-only registration on or
+// off (registration:write permission)
+func (h *RegistrationHandlers) UpdateRegistrationPolicy(c *gin.Context) {
+	var input services.RegistrationPolicyInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
+		return
+	}
+
+	if err := h.registrationService.UpdatePolicy(c.Request.Context(), input); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Registration policy updated successfully",
+	})
+}
+
+// GetInvites handles GET /invites (registration:read permission)
+func (h *RegistrationHandlers) GetInvites(c *gin.Context) {
+	invites, err := h.registrationService.GetInvites(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"invites": invites,
+		"count":   len(invites),
+	})
+}
+
+// CreateInvite handles POST /invites - the code is only returned in this response (registration:write permission)
+func (h *RegistrationHandlers) CreateInvite(c *gin.Context) {
+	var input services.InviteInput
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	invite, err := h.registrationService.CreateInvite(c.Request.Context(), input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, invite)
+}
+
+// DeleteInvite handles DELETE /invites/:id - revokes an unused invite (registration:write permission)
+func (h *RegistrationHandlers) DeleteInvite(c *gin.Context) {
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("invite"))
+		return
+	}
+
+	if err := h.registrationService.DeleteInvite(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Invite deleted successfully",
+	})
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"goliath/middleware"
+	"goliath/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandlers handles HTTP requests for roles and permissions
+type RoleHandlers struct {
+	roleService *services.RoleService
+}
+
+// NewRoleHandlers creates a new RoleHandlers
+func NewRoleHandlers(roleService *services.RoleService) *RoleHandlers {
+	return &RoleHandlers{
+		roleService: roleService,
+	}
+}
+
+// GetPermissions handles GET /permissions - lists the permissions roles can grant (role:read permission)
+func (h *RoleHandlers) GetPermissions(c *gin.Context) {
+	permissions, err := h.roleService.GetPermissions(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"permissions": permissions,
+		"count":       len(permissions),
+	})
+}
+
+// GetRoles handles GET /roles (role:read permission)
+func (h *RoleHandlers) GetRoles(c *gin.Context) {
+	roles, err := h.roleService.GetRoles(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"roles": roles,
+		"count": len(roles),
+	})
+}
+
+// GetRole handles GET /roles/:name (role:read permission)
+func (h *RoleHandlers) GetRole(c *gin.Context) {
+	role, err := h.roleService.GetRole(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if notModified(c, role.Version) {
+		return
+	}
+
+	c.JSON(200, role)
+}
+
+// CreateRole handles POST /roles (role:write permission)
+func (h *RoleHandlers) CreateRole(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	var input services.CreateRoleInput
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	id, err := h.roleService.CreateRole(ctx, user, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"id":      id,
+		"message": "Role created successfully",
+	})
+}
+
+// UpdateRole handles PUT /roles/:name - replaces the description and permissions of a role
+// (role:write permission)
+func (h *RoleHandlers) UpdateRole(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	var input services.UpdateRoleInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
+		return
+	}
+
+	if err := h.roleService.UpdateRole(ctx, user, c.Param("name"), input); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Role updated successfully",
+	})
+}
+
+// DeleteRole handles DELETE /roles/:name (role:write permission)
+func (h *RoleHandlers) DeleteRole(c *gin.Context) {
+	if err := h.roleService.DeleteRole(c.Request.Context(), c.Param("name")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Role deleted successfully",
+	})
+}
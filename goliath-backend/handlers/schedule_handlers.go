@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"goliath/authorization"
+	"goliath/middleware"
+	"goliath/repositories"
+	"goliath/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduleHandlers handles HTTP requests for workout schedules, the calendar and its iCalendar feed
+type ScheduleHandlers struct {
+	scheduleService *services.ScheduleService
+	policy          Authorizer
+}
+
+// NewScheduleHandlers creates a new ScheduleHandlers
+func NewScheduleHandlers(scheduleService *services.ScheduleService, policy Authorizer) *ScheduleHandlers {
+	return &ScheduleHandlers{
+		scheduleService: scheduleService,
+		policy:          policy,
+	}
+}
+
+// GetSchedules handles GET /schedules - returns workout schedules for authenticated user
+func (h *ScheduleHandlers) GetSchedules(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	params, ok := bindListParams(c, repositories.ScheduleListSpec)
+	if !ok {
+		return
+	}
+
+	schedules, nextCursor, err := h.scheduleService.GetUserSchedules(ctx, user.ID, params)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondList(c, "schedules", schedules, len(schedules), nextCursor)
+}
+
+// GetSchedule handles GET /schedules/:id
+func (h *ScheduleHandlers) GetSchedule(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("schedule"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionRead, authorization.Schedule(id)) {
+		return
+	}
+
+	schedule, err := h.scheduleService.GetScheduleByID(ctx, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if notModified(c, schedule.Version) {
+		return
+	}
+
+	c.JSON(200, schedule)
+}
+
+// CreateSchedule handles POST /schedules
+func (h *ScheduleHandlers) CreateSchedule(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	var input services.ScheduleInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !authorizeReference(c, h.policy, authorization.Workout(input.WorkoutID), "workout_id") {
+		return
+	}
+
+	scheduleID, err := h.scheduleService.CreateSchedule(ctx, user.ID, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"id":      scheduleID,
+		"message": "Schedule created successfully",
+	})
+}
+
+// UpdateSchedule handles PUT /schedules/:id
+func (h *ScheduleHandlers) UpdateSchedule(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("schedule"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.Schedule(id)) {
+		return
+	}
+
+	var input services.ScheduleInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
+		return
+	}
+	if !authorizeReference(c, h.policy, authorization.Workout(input.WorkoutID), "workout_id") {
+		return
+	}
+
+	if err := h.scheduleService.UpdateSchedule(ctx, id, input); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Schedule updated successfully",
+	})
+}
+
+// DeleteSchedule handles DELETE /schedules/:id
+func (h *ScheduleHandlers) DeleteSchedule(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("schedule"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionDelete, authorization.Schedule(id)) {
+		return
+	}
+
+	if err := h.scheduleService.DeleteSchedule(ctx, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Schedule deleted successfully",
+	})
+}
+
+// GetCalendar handles GET /me/calendar?from=&to= (YYYY-MM-DD, to inclusive).
+// Defaults to the four weeks starting today.
+func (h *ScheduleHandlers) GetCalendar(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if !parseDateQuery(c, "from", &from) {
+		return
+	}
+	to := from.AddDate(0, 0, 7*4-1)
+	if !parseDateQuery(c, "to", &to) {
+		return
+	}
+
+	calendar, err := h.scheduleService.GetCalendar(ctx, user.ID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, calendar)
+}
+
+// RotateCalendarFeed handles POST /me/calendar/feed - issues a new feed token, revoking the previous one
+func (h *ScheduleHandlers) RotateCalendarFeed(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	token, err := h.scheduleService.RotateCalendarFeed(ctx, user.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"token":   token,
+		"path":    "/calendar/feeds/" + token + ".ics",
+		"message": "Calendar feed created successfully",
+	})
+}
+
+// RevokeCalendarFeed handles DELETE /me/calendar/feed
+func (h *ScheduleHandlers) RevokeCalendarFeed(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	if err := h.scheduleService.RevokeCalendarFeed(ctx, user.ID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Calendar feed revoked successfully",
+	})
+}
+
+// GetCalendarFeed handles GET /calendar/feeds/:token - returns the schedules of the token's user
+// as an iCalendar document. The token authenticates the request so calendar apps can subscribe.
+func (h *ScheduleHandlers) GetCalendarFeed(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	feed, err := h.scheduleService.GetCalendarFeed(ctx, token)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="goliath.ics"`)
+	c.Data(200, "text/calendar; charset=utf-8", []byte(feed))
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"goliath/authorization"
+	"goliath/middleware"
+	"goliath/repositories"
+	"goliath/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHandlers handles HTTP requests for workout session endpoints
+type SessionHandlers struct {
+	sessionService *services.SessionService
+	policy         Authorizer
+}
+
+// NewSessionHandlers creates a new SessionHandlers
+func NewSessionHandlers(sessionService *services.SessionService, policy Authorizer) *SessionHandlers {
+	return &SessionHandlers{
+		sessionService: sessionService,
+		policy:         policy,
+	}
+}
+
+// GetSessions handles GET /sessions - returns sessions for authenticated user
+func (h *SessionHandlers) GetSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	params, ok := bindListParams(c, repositories.SessionListSpec)
+	if !ok {
+		return
+	}
+
+	sessions, nextCursor, err := h.sessionService.GetUserSessions(ctx, user.ID, params)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondList(c, "sessions", sessions, len(sessions), nextCursor)
+}
+
+// GetSession handles GET /sessions/:id
+func (h *SessionHandlers) GetSession(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("session"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionRead, authorization.Session(id)) {
+		return
+	}
+
+	session, err := h.sessionService.GetSessionByID(ctx, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, session)
+}
+
+// StartSession handles POST /sessions
+func (h *SessionHandlers) StartSession(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	var input services.StartSessionInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if input.WorkoutID != nil && !authorize(c, h.policy, authorization.ActionRead, authorization.Workout(*input.WorkoutID)) {
+		return
+	}
+
+	sessionID, err := h.sessionService.StartSession(ctx, user.ID, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"id":      sessionID,
+		"message": "Session started successfully",
+	})
+}
+
+// FinishSession handles POST /sessions/:id/finish
+func (h *SessionHandlers) FinishSession(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("session"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.Session(id)) {
+		return
+	}
+
+	// Body is optional
+	var input services.FinishSessionInput
+	if c.Request.ContentLength > 0 {
+		if !bindJSON(c, &input) {
+			return
+		}
+	}
+
+	if err := h.sessionService.FinishSession(ctx, id, input); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Session finished successfully",
+	})
+}
+
+// DeleteSession handles DELETE /sessions/:id
+func (h *SessionHandlers) DeleteSession(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("session"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionDelete, authorization.Session(id)) {
+		return
+	}
+
+	if err := h.sessionService.DeleteSession(ctx, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Session deleted successfully",
+	})
+}
+
+// RecordSet handles POST /sessions/:id/sets
+func (h *SessionHandlers) RecordSet(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse session ID from URL
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("session"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.Session(sessionID)) {
+		return
+	}
+
+	var input services.RecordSetInput
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	setID, newRecords, err := h.sessionService.RecordSet(ctx, sessionID, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"id":                 setID,
+		"message":            "Set recorded successfully",
+		"is_personal_record": len(newRecords) > 0,
+		"new_records":        newRecords,
+	})
+}
+
+// UpdateSet handles PUT /sessions/:id/sets/:set_id
+func (h *SessionHandlers) UpdateSet(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse IDs from URL
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("session"))
+		return
+	}
+	setID, err := strconv.Atoi(c.Param("set_id"))
+	if err != nil {
+		respondError(c, invalidID("set"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.SessionSet(sessionID, setID)) {
+		return
+	}
+
+	var input services.UpdateSetInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
+		return
+	}
+
+	newRecords, err := h.sessionService.UpdateSet(ctx, sessionID, setID, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message":            "Set updated successfully",
+		"is_personal_record": len(newRecords) > 0,
+		"new_records":        newRecords,
+	})
+}
+
+// DeleteSet handles DELETE /sessions/:id/sets/:set_id
+func (h *SessionHandlers) DeleteSet(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse IDs from URL
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("session"))
+		return
+	}
+	setID, err := strconv.Atoi(c.Param("set_id"))
+	if err != nil {
+		respondError(c, invalidID("set"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.SessionSet(sessionID, setID)) {
+		return
+	}
+
+	if err := h.sessionService.DeleteSet(ctx, sessionID, setID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Set deleted successfully",
+	})
+}
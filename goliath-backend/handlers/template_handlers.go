@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"strconv"
+
+	"goliath/middleware"
+	"goliath/repositories"
+	"goliath/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TemplateHandlers handles HTTP requests for the template library of shared workouts
+type TemplateHandlers struct {
+	workoutService *services.WorkoutService
+}
+
+// NewTemplateHandlers creates a new TemplateHandlers
+func NewTemplateHandlers(workoutService *services.WorkoutService) *TemplateHandlers {
+	return &TemplateHandlers{
+		workoutService: workoutService,
+	}
+}
+
+// GetTemplates handles GET /templates - returns the workouts shared by all users
+func (h *TemplateHandlers) GetTemplates(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	params, ok := bindListParams(c, repositories.TemplateListSpec)
+	if !ok {
+		return
+	}
+
+	templates, nextCursor, err := h.workoutService.GetTemplates(ctx, params)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondList(c, "templates", templates, len(templates), nextCursor)
+}
+
+// GetTemplate handles GET /templates/:id
+func (h *TemplateHandlers) GetTemplate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("template"))
+		return
+	}
+
+	template, err := h.workoutService.GetTemplate(ctx, id, user.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if notModified(c, template.Version) {
+		return
+	}
+
+	c.JSON(200, template)
+}
+
+// GetTemplateExercises handles GET /templates/:id/exercises
+func (h *TemplateHandlers) GetTemplateExercises(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("template"))
+		return
+	}
+
+	exercises, err := h.workoutService.GetTemplateExercises(ctx, id, user.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Exercises of a template are bounded by the template and never paginated
+	respondList(c, "exercises", exercises, len(exercises), "")
+}
+
+// CloneTemplate handles POST /templates/:id/clone - copies a template into the user's workouts
+func (h *TemplateHandlers) CloneTemplate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("template"))
+		return
+	}
+
+	// Body is optional
+	var input services.CloneWorkoutInput
+	if c.Request.ContentLength > 0 {
+		if !bindJSON(c, &input) {
+			return
+		}
+	}
+
+	workoutID, err := h.workoutService.CloneTemplate(ctx, id, user.ID, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"id":      workoutID,
+		"message": "Template cloned successfully",
+	})
+}
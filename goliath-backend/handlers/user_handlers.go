@@ -1,6 +1,10 @@
 package handlers
 
 import (
+	"strconv"
+
+	"goliath/middleware"
+	"goliath/repositories"
 	"goliath/services"
 
 	"github.com/gin-gonic/gin"
@@ -18,19 +22,169 @@ func NewUserHandlers(userService *services.UserService) *UserHandlers {
 	}
 }
 
-// GetUsers handles GET /users
+// GetUsers handles GET /users (user:read permission)
 func (h *UserHandlers) GetUsers(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	users, err := h.userService.GetAllUsers(ctx)
+	params, ok := bindListParams(c, repositories.UserListSpec)
+	if !ok {
+		return
+	}
+
+	users, nextCursor, err := h.userService.GetAllUsers(ctx, params)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	respondList(c, "users", users, len(users), nextCursor)
+}
+
+// GetMe handles GET /me
+func (h *UserHandlers) GetMe(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	me, err := h.userService.GetProfile(ctx, user.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if notModified(c, me.Version) {
+		return
+	}
+
+	c.JSON(200, me)
+}
+
+// UpdateMe handles PUT /me
+func (h *UserHandlers) UpdateMe(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	var input services.ProfileInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
+		return
+	}
+
+	if err := h.userService.UpdateProfile(ctx, user.ID, input); err != nil {
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(200, gin.H{
-		"users": users,
-		"count": len(users),
+		"message": "Profile updated successfully",
 	})
 }
 
+// GetUser handles GET /users/:id (user:read permission)
+func (h *UserHandlers) GetUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("user"))
+		return
+	}
+
+	user, err := h.userService.GetUser(ctx, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if notModified(c, user.Version) {
+		return
+	}
+
+	c.JSON(200, user)
+}
+
+// UpdateUserRole handles PUT /users/:id/role - assigns a role to a user (user:role permission)
+func (h *UserHandlers) UpdateUserRole(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("user"))
+		return
+	}
+
+	var input services.UserRoleInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
+		return
+	}
+
+	if err := h.userService.ChangeRole(ctx, user, id, input); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "User role updated successfully",
+	})
+}
+
+// UpdateUserStatus handles PUT /users/:id/status - enables, disables or bans a user (user:status permission)
+func (h *UserHandlers) UpdateUserStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		respondError(c, middleware.ErrAuthenticationRequired)
+		return
+	}
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("user"))
+		return
+	}
+
+	var input services.StatusInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
+		return
+	}
+
+	if err := h.userService.ChangeStatus(ctx, user, id, input); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "User status updated successfully",
+	})
+}
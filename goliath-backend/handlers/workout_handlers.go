@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"goliath/authorization"
 	"goliath/middleware"
+	"goliath/repositories"
 	"goliath/services"
 	"log"
 	"strconv"
@@ -12,12 +14,14 @@ import (
 // WorkoutHandlers handles HTTP requests for workout-related endpoints
 type WorkoutHandlers struct {
 	workoutService *services.WorkoutService
+	policy         Authorizer
 }
 
 // NewWorkoutHandlers creates a new WorkoutHandlers
-func NewWorkoutHandlers(workoutService *services.WorkoutService) *WorkoutHandlers {
+func NewWorkoutHandlers(workoutService *services.WorkoutService, policy Authorizer) *WorkoutHandlers {
 	return &WorkoutHandlers{
 		workoutService: workoutService,
+		policy:         policy,
 	}
 }
 
@@ -28,48 +32,46 @@ func (h *WorkoutHandlers) GetWorkouts(c *gin.Context) {
 	// Get user from context (set by authentication middleware)
 	user, hasUser := middleware.GetUserFromContext(ctx)
 	if !hasUser {
-		c.JSON(401, gin.H{"error": "Authentication required"})
+		respondError(c, middleware.ErrAuthenticationRequired)
 		return
 	}
 
-	workouts, err := h.workoutService.GetUserWorkouts(ctx, user.ID)
+	params, ok := bindListParams(c, repositories.WorkoutListSpec)
+	if !ok {
+		return
+	}
+
+	workouts, nextCursor, err := h.workoutService.GetUserWorkouts(ctx, user.ID, params)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(200, gin.H{
-		"workouts": workouts,
-		"count":    len(workouts),
-	})
+	respondList(c, "workouts", workouts, len(workouts), nextCursor)
 }
 
 // GetWorkout handles GET /workouts/:id
 func (h *WorkoutHandlers) GetWorkout(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	// Get user from context
-	user, hasUser := middleware.GetUserFromContext(ctx)
-	if !hasUser {
-		c.JSON(401, gin.H{"error": "Authentication required"})
-		return
-	}
-
 	// Parse ID from URL
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(400, gin.H{"error": "Invalid workout ID"})
+		respondError(c, invalidID("workout"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionRead, authorization.Workout(id)) {
 		return
 	}
 
-	workout, err := h.workoutService.GetWorkoutByID(ctx, id, user.ID)
+	workout, err := h.workoutService.GetWorkoutByID(ctx, id)
 	if err != nil {
-		if err.Error() == "unauthorized: workout does not belong to user" {
-			c.JSON(403, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(404, gin.H{"error": "Workout not found"})
+		respondError(c, err)
+		return
+	}
+
+	if notModified(c, workout.Version) {
 		return
 	}
 
@@ -84,19 +86,18 @@ func (h *WorkoutHandlers) CreateWorkout(c *gin.Context) {
 	// Get user from context
 	user, hasUser := middleware.GetUserFromContext(ctx)
 	if !hasUser {
-		c.JSON(401, gin.H{"error": "Authentication required"})
+		respondError(c, middleware.ErrAuthenticationRequired)
 		return
 	}
 
 	var input services.CreateWorkoutInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+	if !bindJSON(c, &input) {
 		return
 	}
 
 	workoutID, err := h.workoutService.CreateWorkout(ctx, user.ID, input)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -110,34 +111,28 @@ func (h *WorkoutHandlers) CreateWorkout(c *gin.Context) {
 func (h *WorkoutHandlers) UpdateWorkout(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	// Get user from context
-	user, hasUser := middleware.GetUserFromContext(ctx)
-	if !hasUser {
-		c.JSON(401, gin.H{"error": "Authentication required"})
-		return
-	}
-
 	// Parse ID from URL
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(400, gin.H{"error": "Invalid workout ID"})
+		respondError(c, invalidID("workout"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.Workout(id)) {
 		return
 	}
 
 	var input services.UpdateWorkoutInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
 		return
 	}
 
-	err = h.workoutService.UpdateWorkout(ctx, id, user.ID, input)
+	err = h.workoutService.UpdateWorkout(ctx, id, input)
 	if err != nil {
-		if err.Error() == "unauthorized: workout does not belong to user" {
-			c.JSON(403, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -150,28 +145,20 @@ func (h *WorkoutHandlers) UpdateWorkout(c *gin.Context) {
 func (h *WorkoutHandlers) DeleteWorkout(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	// Get user from context
-	user, hasUser := middleware.GetUserFromContext(ctx)
-	if !hasUser {
-		c.JSON(401, gin.H{"error": "Authentication required"})
-		return
-	}
-
 	// Parse ID from URL
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(400, gin.H{"error": "Invalid workout ID"})
+		respondError(c, invalidID("workout"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionDelete, authorization.Workout(id)) {
 		return
 	}
 
-	err = h.workoutService.DeleteWorkout(ctx, id, user.ID)
+	err = h.workoutService.DeleteWorkout(ctx, id)
 	if err != nil {
-		if err.Error() == "unauthorized: workout does not belong to user" {
-			c.JSON(403, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -180,73 +167,113 @@ func (h *WorkoutHandlers) DeleteWorkout(c *gin.Context) {
 	})
 }
 
-// GetWorkoutExercises handles GET /workouts/:id/exercises
-func (h *WorkoutHandlers) GetWorkoutExercises(c *gin.Context) {
+// DuplicateWorkout handles POST /workouts/:id/duplicate
+func (h *WorkoutHandlers) DuplicateWorkout(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	// Get user from context
-	user, hasUser := middleware.GetUserFromContext(ctx)
-	if !hasUser {
-		c.JSON(401, gin.H{"error": "Authentication required"})
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("workout"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.Workout(id)) {
+		return
+	}
+
+	// Body is optional
+	var input services.CloneWorkoutInput
+	if c.Request.ContentLength > 0 {
+		if !bindJSON(c, &input) {
+			return
+		}
+	}
+
+	workoutID, err := h.workoutService.DuplicateWorkout(ctx, id, input)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
+	c.JSON(201, gin.H{
+		"id":      workoutID,
+		"message": "Workout duplicated successfully",
+	})
+}
+
+// GetWorkoutExercises handles GET /workouts/:id/exercises
+func (h *WorkoutHandlers) GetWorkoutExercises(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	// Parse workout ID from URL
 	idStr := c.Param("id")
 	workoutID, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(400, gin.H{"error": "Invalid workout ID"})
+		respondError(c, invalidID("workout"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionRead, authorization.Workout(workoutID)) {
 		return
 	}
 
-	exercises, err := h.workoutService.GetWorkoutExercises(ctx, workoutID, user.ID)
+	exercises, err := h.workoutService.GetWorkoutExercises(ctx, workoutID)
 	if err != nil {
-		if err.Error() == "unauthorized: workout does not belong to user" {
-			c.JSON(403, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(404, gin.H{"error": "Workout not found"})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(200, gin.H{
-		"exercises": exercises,
-		"count":     len(exercises),
-	})
+	// Exercises of a workout are bounded by the workout and never paginated
+	respondList(c, "exercises", exercises, len(exercises), "")
 }
 
-// AddExerciseToWorkout handles POST /workouts/:id/exercises
-func (h *WorkoutHandlers) AddExerciseToWorkout(c *gin.Context) {
+// GetWorkoutExercise handles GET /workouts/:id/exercises/:exercise_id
+func (h *WorkoutHandlers) GetWorkoutExercise(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	// Get user from context
-	user, hasUser := middleware.GetUserFromContext(ctx)
-	if !hasUser {
-		c.JSON(401, gin.H{"error": "Authentication required"})
+	workoutID, workoutExerciseID, ok := parseWorkoutExerciseParams(c)
+	if !ok {
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionRead, authorization.WorkoutExercise(workoutID, workoutExerciseID)) {
 		return
 	}
 
+	workoutExercise, err := h.workoutService.GetWorkoutExercise(ctx, workoutExerciseID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if notModified(c, workoutExercise.Version) {
+		return
+	}
+
+	c.JSON(200, workoutExercise)
+}
+
+// AddExerciseToWorkout handles POST /workouts/:id/exercises
+func (h *WorkoutHandlers) AddExerciseToWorkout(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	// Parse workout ID from URL
 	idStr := c.Param("id")
 	workoutID, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(400, gin.H{"error": "Invalid workout ID"})
+		respondError(c, invalidID("workout"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.Workout(workoutID)) {
 		return
 	}
 
 	var input services.AddExerciseToWorkoutInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+	if !bindJSON(c, &input) {
 		return
 	}
 
-	exerciseID, err := h.workoutService.AddExerciseToWorkout(ctx, workoutID, user.ID, input)
+	exerciseID, err := h.workoutService.AddExerciseToWorkout(ctx, workoutID, input)
 	if err != nil {
-		if err.Error() == "unauthorized: workout does not belong to user" {
-			c.JSON(403, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -260,34 +287,25 @@ func (h *WorkoutHandlers) AddExerciseToWorkout(c *gin.Context) {
 func (h *WorkoutHandlers) UpdateWorkoutExercise(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	// Get user from context
-	user, hasUser := middleware.GetUserFromContext(ctx)
-	if !hasUser {
-		c.JSON(401, gin.H{"error": "Authentication required"})
+	workoutID, workoutExerciseID, ok := parseWorkoutExerciseParams(c)
+	if !ok {
 		return
 	}
-
-	// Parse workout exercise ID from URL
-	exerciseIDStr := c.Param("exercise_id")
-	workoutExerciseID, err := strconv.Atoi(exerciseIDStr)
-	if err != nil {
-		c.JSON(400, gin.H{"error": "Invalid workout exercise ID"})
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.WorkoutExercise(workoutID, workoutExerciseID)) {
 		return
 	}
 
 	var input services.UpdateWorkoutExerciseInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
 		return
 	}
 
-	err = h.workoutService.UpdateWorkoutExercise(ctx, workoutExerciseID, user.ID, input)
+	err := h.workoutService.UpdateWorkoutExercise(ctx, workoutID, workoutExerciseID, input)
 	if err != nil {
-		if err.Error() == "unauthorized: workout does not belong to user" {
-			c.JSON(403, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -300,33 +318,185 @@ func (h *WorkoutHandlers) UpdateWorkoutExercise(c *gin.Context) {
 func (h *WorkoutHandlers) RemoveExerciseFromWorkout(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	// Get user from context
-	user, hasUser := middleware.GetUserFromContext(ctx)
-	if !hasUser {
-		c.JSON(401, gin.H{"error": "Authentication required"})
+	workoutID, workoutExerciseID, ok := parseWorkoutExerciseParams(c)
+	if !ok {
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.WorkoutExercise(workoutID, workoutExerciseID)) {
 		return
 	}
 
-	// Parse workout exercise ID from URL
-	exerciseIDStr := c.Param("exercise_id")
-	workoutExerciseID, err := strconv.Atoi(exerciseIDStr)
+	err := h.workoutService.RemoveExerciseFromWorkout(ctx, workoutID, workoutExerciseID)
 	if err != nil {
-		c.JSON(400, gin.H{"error": "Invalid workout exercise ID"})
+		respondError(c, err)
 		return
 	}
 
-	err = h.workoutService.RemoveExerciseFromWorkout(ctx, workoutExerciseID, user.ID)
+	c.JSON(200, gin.H{
+		"message": "Exercise removed from workout successfully",
+	})
+}
+
+// parseWorkoutExerciseParams parses the workout and workout exercise IDs from the URL
+func parseWorkoutExerciseParams(c *gin.Context) (int, int, bool) {
+	workoutID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		if err.Error() == "unauthorized: workout does not belong to user" {
-			c.JSON(403, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondError(c, invalidID("workout"))
+		return 0, 0, false
+	}
+	workoutExerciseID, err := strconv.Atoi(c.Param("exercise_id"))
+	if err != nil {
+		respondError(c, invalidID("workout exercise"))
+		return 0, 0, false
+	}
+	return workoutID, workoutExerciseID, true
+}
+
+// GetSetTypes handles GET /set-types
+func (h *WorkoutHandlers) GetSetTypes(c *gin.Context) {
+	types := h.workoutService.GetSetTypes()
+	c.JSON(200, gin.H{
+		"types": types,
+	})
+}
+
+// GetWorkoutExerciseSets handles GET /workouts/:id/exercises/:exercise_id/sets
+func (h *WorkoutHandlers) GetWorkoutExerciseSets(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	workoutID, workoutExerciseID, ok := parseWorkoutExerciseParams(c)
+	if !ok {
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionRead, authorization.WorkoutExercise(workoutID, workoutExerciseID)) {
+		return
+	}
+
+	sets, err := h.workoutService.GetWorkoutExerciseSets(ctx, workoutExerciseID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondList(c, "sets", sets, len(sets), "")
+}
+
+// AddSetToWorkoutExercise handles POST /workouts/:id/exercises/:exercise_id/sets
+func (h *WorkoutHandlers) AddSetToWorkoutExercise(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	workoutID, workoutExerciseID, ok := parseWorkoutExerciseParams(c)
+	if !ok {
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.WorkoutExercise(workoutID, workoutExerciseID)) {
+		return
+	}
+
+	var input services.WorkoutExerciseSetInput
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	setID, err := h.workoutService.AddSetToWorkoutExercise(ctx, workoutID, workoutExerciseID, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"id":      setID,
+		"message": "Set added to workout exercise successfully",
+	})
+}
+
+// UpdateWorkoutExerciseSet handles PUT /workouts/:id/exercises/:exercise_id/sets/:set_id
+func (h *WorkoutHandlers) UpdateWorkoutExerciseSet(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	workoutID, workoutExerciseID, ok := parseWorkoutExerciseParams(c)
+	if !ok {
+		return
+	}
+	setID, err := strconv.Atoi(c.Param("set_id"))
+	if err != nil {
+		respondError(c, invalidID("set"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.WorkoutExerciseSet(workoutID, workoutExerciseID, setID)) {
+		return
+	}
+
+	var input services.WorkoutExerciseSetInput
+	if !bindJSON(c, &input) {
+		return
+	}
+	if !bindIfMatch(c, &input.Version) {
+		return
+	}
+
+	if err := h.workoutService.UpdateWorkoutExerciseSet(ctx, workoutID, setID, input); err != nil {
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(200, gin.H{
-		"message": "Exercise removed from workout successfully",
+		"message": "Set updated successfully",
 	})
 }
 
+// RemoveSetFromWorkoutExercise handles DELETE /workouts/:id/exercises/:exercise_id/sets/:set_id
+func (h *WorkoutHandlers) RemoveSetFromWorkoutExercise(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	workoutID, workoutExerciseID, ok := parseWorkoutExerciseParams(c)
+	if !ok {
+		return
+	}
+	setID, err := strconv.Atoi(c.Param("set_id"))
+	if err != nil {
+		respondError(c, invalidID("set"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionWrite, authorization.WorkoutExerciseSet(workoutID, workoutExerciseID, setID)) {
+		return
+	}
+
+	if err := h.workoutService.RemoveSetFromWorkoutExercise(ctx, workoutID, setID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "Set removed from workout exercise successfully",
+	})
+}
+
+// GetWorkoutChanges handles GET /workouts/:id/changes - the audit trail of who created, updated
+// or deleted the workout, its exercises and sets
+func (h *WorkoutHandlers) GetWorkoutChanges(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Parse ID from URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("workout"))
+		return
+	}
+	if !authorize(c, h.policy, authorization.ActionRead, authorization.Workout(id)) {
+		return
+	}
+
+	params, ok := bindListParams(c, repositories.WorkoutChangeListSpec)
+	if !ok {
+		return
+	}
+
+	changes, nextCursor, err := h.workoutService.GetWorkoutChanges(ctx, id, params)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondList(c, "changes", changes, len(changes), nextCursor)
+}
@@ -2,143 +2,64 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net"
 	"os"
+	"strconv"
 
-	"goliath/handlers"
-	"goliath/middleware"
-	"goliath/repositories"
-	"goliath/services"
-
-	firebase "firebase.google.com/go/v4"
-	"github.com/gin-gonic/gin"
-	"google.golang.org/api/option"
+	"goliath/config"
 )
 
 func main() {
-	// Initialize database
-	db, err := InitDB("./goliath.db")
+	// Load configuration from the config file, environment variables and flags
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Println(commandUsage)
+		return
+	}
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		log.Fatal(err)
 	}
-	defer db.Close()
 
-	// Initialize Firebase
-	opt := option.WithCredentialsFile("./goliath-firebase.json")
-	app, err := firebase.NewApp(context.Background(), nil, opt)
-	if err != nil {
-		log.Fatalf("Error initializing Firebase app: %v", err)
+	// Maintenance commands run instead of the server
+	if len(args) > 0 {
+		if err := runCommand(cfg, args); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
 
-	// Get Firebase Auth client
-	authClient, err := app.Auth(context.Background())
+	// Initialize database
+	db, err := InitDB(cfg.Database)
 	if err != nil {
-		log.Fatalf("Error getting Firebase Auth client: %v", err)
+		log.Fatalf("Failed to initialize database: %v", err)
 	}
-	log.Println("Firebase initialized successfully")
-
-	// Initialize repositories
-	regionRepo := repositories.NewRegionRepository(db)
-	muscleGroupRepo := repositories.NewMuscleGroupRepository(db)
-	exerciseAreaRepo := repositories.NewExerciseAreaRepository(db)
-	muscleRepo := repositories.NewMuscleRepository(db)
-	exerciseRepo := repositories.NewExerciseRepository(db)
-	userRepo := repositories.NewUserRepository(db)
-	workoutRepo := repositories.NewWorkoutRepository(db)
-	workoutExerciseRepo := repositories.NewWorkoutExerciseRepository(db)
-
-	// Initialize services
-	muscleService := services.NewMuscleService(muscleRepo, muscleGroupRepo, regionRepo, exerciseAreaRepo)
-	exerciseService := services.NewExerciseService(exerciseRepo)
-	userService := services.NewUserService(userRepo)
-	workoutService := services.NewWorkoutService(workoutRepo, workoutExerciseRepo)
-
-	// Initialize handlers
-	muscleHandlers := handlers.NewMuscleHandlers(muscleService)
-	exerciseHandlers := handlers.NewExerciseHandlers(exerciseService)
-	userHandlers := handlers.NewUserHandlers(userService)
-	workoutHandlers := handlers.NewWorkoutHandlers(workoutService)
-
-	// Setup router
-	r := gin.Default()
-
-	// Apply global middleware in order:
-	// 1. CORS - handle cross-origin requests
-	r.Use(middleware.CORS())
-	
-	// 2. Firebase JWT (optional) - extract user info from token if present
-	r.Use(middleware.JWT(middleware.JWTConfig{
-		AuthClient: authClient,
-		Required:   false, // Allow requests without JWT
-	}))
-	
-	// 3. User Loader - load full user details if JWT was present
-	r.Use(middleware.UserLoader(db))
-	
-	// 4. Transaction - wrap ALL requests in database transaction
-	// Required because all repository operations now require a transaction
-	r.Use(middleware.Transaction(db))
-
-	// Health check endpoint (public, no auth required)
-	r.GET("/hello", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"message": "hello",
-		})
-	})
-
-	// Public routes - no authentication required
-	public := r.Group("/")
-	{
-		// Muscle-related routes
-		public.GET("/regions", muscleHandlers.GetRegions)
-		public.GET("/muscle-groups", muscleHandlers.GetMuscleGroups)
-		public.GET("/exercise-areas", muscleHandlers.GetExerciseAreas)
-		public.GET("/muscles", muscleHandlers.GetMuscles)
-
-		// Exercise-related routes
-		public.GET("/exercises", exerciseHandlers.GetExercises)
-		public.GET("/exercises/:id", exerciseHandlers.GetExercise)
-		public.GET("/exercise-types", exerciseHandlers.GetExerciseTypes)
+	defer db.Close()
 
-		// User-related routes
-		public.GET("/users", userHandlers.GetUsers)
+	// Initialize backups
+	backupService := newBackupService(cfg)
+	if cfg.Backup.Interval > 0 {
+		backupService.Schedule(context.Background(), cfg.Backup.Interval)
 	}
 
-	// Authenticated user routes - requires authentication but not admin
-	auth := r.Group("/")
-	auth.Use(middleware.RequireAuth())
-	{
-		// Workout routes - users can only access their own workouts
-		auth.GET("/workouts", workoutHandlers.GetWorkouts)
-		auth.GET("/workouts/:id", workoutHandlers.GetWorkout)
-		auth.POST("/workouts", workoutHandlers.CreateWorkout)
-		auth.PUT("/workouts/:id", workoutHandlers.UpdateWorkout)
-		auth.DELETE("/workouts/:id", workoutHandlers.DeleteWorkout)
-		
-		// Workout exercise routes - manage exercises within workouts
-		auth.GET("/workouts/:id/exercises", workoutHandlers.GetWorkoutExercises)
-		auth.POST("/workouts/:id/exercises", workoutHandlers.AddExerciseToWorkout)
-		auth.PUT("/workouts/:id/exercises/:exercise_id", workoutHandlers.UpdateWorkoutExercise)
-		auth.DELETE("/workouts/:id/exercises/:exercise_id", workoutHandlers.RemoveExerciseFromWorkout)
+	// Initialize authentication
+	tokenVerifier, err := newTokenVerifier(context.Background(), cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize authentication: %v", err)
 	}
 
-	// Admin-only routes
-	admin := r.Group("/")
-	admin.Use(middleware.RequireAdmin())
-	{
-		// Create and update exercise requires admin role (transaction is already global)
-		admin.POST("/exercises", exerciseHandlers.CreateExercise)
-		admin.PUT("/exercises/:id", exerciseHandlers.UpdateExercise)
-	}
+	// Initialize the authorization policy - who may access which workouts, their exercises and sets
+	policy := newPolicy(db)
 
-	// Get port from environment or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
+	// Initialize the router with every route of the API
+	r := newRouter(cfg, db, tokenVerifier, backupService, policy)
 
-	log.Printf("Server starting on :%s...\n", port)
-	if err := r.Run(":" + port); err != nil {
+	addr := net.JoinHostPort(cfg.Server.Host, strconv.Itoa(cfg.Server.Port))
+	log.Printf("Server starting on %s...\n", addr)
+	if err := r.Run(addr); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"goliath/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Problem is an RFC 7807 problem details response
+type Problem struct {
+	Type     string                 `json:"type"`
+	Title    string                 `json:"title"`
+	Status   int                    `json:"status"`
+	Detail   string                 `json:"detail"`
+	Instance string                 `json:"instance"`
+	Code     string                 `json:"code"` // Stable error code clients can switch on
+	Errors   []apperrors.FieldError `json:"errors,omitempty"`
+}
+
+// ErrAuthenticationRequired is returned when a route needs a signed-in user
+var ErrAuthenticationRequired = apperrors.Unauthenticated("authentication_required", "Authentication required")
+
+// ErrInsufficientPermissions is returned when the role of the user does not grant a permission
+var ErrInsufficientPermissions = apperrors.Forbidden("insufficient_permissions", "Insufficient permissions")
+
+// statusOfKind maps error kinds to HTTP status codes
+var statusOfKind = map[apperrors.Kind]int{
+	apperrors.KindValidation:           http.StatusBadRequest,
+	apperrors.KindUnauthenticated:      http.StatusUnauthorized,
+	apperrors.KindForbidden:            http.StatusForbidden,
+	apperrors.KindNotFound:             http.StatusNotFound,
+	apperrors.KindConflict:             http.StatusConflict,
+	apperrors.KindPreconditionRequired: http.StatusPreconditionRequired,
+}
+
+// Errors middleware writes the last error a handler recorded with c.Error as a problem response.
+// It must run inside Transaction so the transaction sees the error status and rolls back.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		WriteProblem(c, c.Errors.Last().Err)
+	}
+}
+
+// WriteProblem writes err as a problem response and aborts the request.
+// Errors that are not *apperrors.Error are logged and reported without their message.
+func WriteProblem(c *gin.Context, err error) {
+	problem := Problem{
+		Type:     "about:blank",
+		Instance: c.Request.URL.Path,
+	}
+
+	status := http.StatusInternalServerError
+	if appErr, ok := apperrors.As(err); ok && appErr.Kind != apperrors.KindInternal {
+		status = statusOfKind[appErr.Kind]
+		problem.Detail = appErr.Message
+		problem.Code = appErr.Code
+		problem.Errors = appErr.Fields
+	} else {
+		log.Printf("Internal error on %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		problem.Detail = "An unexpected error occurred"
+		problem.Code = "internal_error"
+	}
+
+	// A stale version sent as If-Match fails the precondition rather than conflicting
+	if errors.Is(err, apperrors.ErrVersionConflict) && c.GetHeader("If-Match") != "" {
+		status = http.StatusPreconditionFailed
+	}
+
+	problem.Status = status
+	problem.Title = http.StatusText(status)
+
+	c.Header("Content-Type", "application/problem+json")
+	c.AbortWithStatusJSON(status, problem)
+}
@@ -4,7 +4,8 @@ import (
 	"context"
 	"strings"
 
-	"firebase.google.com/go/v4/auth"
+	"goliath/apperrors"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,17 +17,19 @@ const (
 	UserIDKey ContextKey = "userID"
 	// UserEmailKey is the context key for user email
 	UserEmailKey ContextKey = "userEmail"
-	// FirebaseUIDKey is the context key for Firebase UID
+	// EmailVerifiedKey is the context key for whether the identity provider verified the email
+	EmailVerifiedKey ContextKey = "emailVerified"
+	// FirebaseUIDKey is the context key for Firebase UID, the subject of the token whatever verified it
 	FirebaseUIDKey ContextKey = "firebaseUID"
 )
 
-// JWTConfig holds JWT configuration for Firebase
+// JWTConfig holds JWT configuration
 type JWTConfig struct {
-	AuthClient *auth.Client
-	Required   bool // If true, request fails without valid JWT
+	Verifier TokenVerifier // Firebase, locally signed tokens, or a static development user
+	Required bool          // If true, request fails without valid JWT
 }
 
-// JWT middleware validates Firebase JWT tokens and adds user info to context
+// JWT middleware validates bearer tokens with the configured verifier and adds user info to context
 func JWT(config JWTConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get token from Authorization header
@@ -35,8 +38,7 @@ func JWT(config JWTConfig) gin.HandlerFunc {
 		if authHeader == "" {
 			// No token provided
 			if config.Required {
-				c.JSON(401, gin.H{"error": "Authorization header required"})
-				c.Abort()
+				WriteProblem(c, apperrors.Unauthenticated("authorization_required", "Authorization header required"))
 				return
 			}
 			// Token not required, continue without user
@@ -48,8 +50,7 @@ func JWT(config JWTConfig) gin.HandlerFunc {
 		tokenParts := strings.Split(authHeader, " ")
 		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
 			if config.Required {
-				c.JSON(401, gin.H{"error": "Invalid authorization header format. Expected: Bearer <token>"})
-				c.Abort()
+				WriteProblem(c, apperrors.Unauthenticated("invalid_authorization_header", "Invalid authorization header format. Expected: Bearer <token>"))
 				return
 			}
 			c.Next()
@@ -58,12 +59,11 @@ func JWT(config JWTConfig) gin.HandlerFunc {
 
 		tokenString := tokenParts[1]
 
-		// Verify Firebase token
-		token, err := config.AuthClient.VerifyIDToken(c.Request.Context(), tokenString)
+		// Verify token
+		token, err := config.Verifier.VerifyToken(c.Request.Context(), tokenString)
 		if err != nil {
 			if config.Required {
-				c.JSON(401, gin.H{"error": "Invalid or expired Firebase token"})
-				c.Abort()
+				WriteProblem(c, apperrors.Unauthenticated("invalid_token", "Invalid or expired token"))
 				return
 			}
 			c.Next()
@@ -75,8 +75,14 @@ func JWT(config JWTConfig) gin.HandlerFunc {
 		c.Request = c.Request.WithContext(ctx)
 
 		// Add email to context if available
-		if email, ok := token.Claims["email"].(string); ok {
-			ctx = context.WithValue(c.Request.Context(), UserEmailKey, email)
+		if token.Email != "" {
+			ctx = context.WithValue(c.Request.Context(), UserEmailKey, token.Email)
+			c.Request = c.Request.WithContext(ctx)
+		}
+
+		// Add whether the email was verified, which allowlisted email domains rely on
+		if verified, ok := token.Claims["email_verified"].(bool); ok {
+			ctx = context.WithValue(c.Request.Context(), EmailVerifiedKey, verified)
 			c.Request = c.Request.WithContext(ctx)
 		}
 
@@ -102,6 +108,12 @@ func GetUserEmailFromContext(ctx context.Context) (string, bool) {
 	return email, ok
 }
 
+// GetEmailVerifiedFromContext retrieves whether the email in the context was verified
+func GetEmailVerifiedFromContext(ctx context.Context) (bool, bool) {
+	verified, ok := ctx.Value(EmailVerifiedKey).(bool)
+	return verified, ok
+}
+
 // GetFirebaseUIDFromContext retrieves Firebase UID from context
 func GetFirebaseUIDFromContext(ctx context.Context) (string, bool) {
 	uid, ok := ctx.Value(FirebaseUIDKey).(string)
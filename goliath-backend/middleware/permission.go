@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+
+	"goliath/entities"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loadRolePermissions loads the permissions granted by a role
+func loadRolePermissions(ctx context.Context, db *sql.DB, role string) ([]entities.Permission, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT rp.permission
+		FROM role_permission rp
+		JOIN role r ON r.id = rp.role_id
+		WHERE r.name = ?
+		ORDER BY rp.permission
+	`, role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var permissions []entities.Permission
+	for rows.Next() {
+		var permission entities.Permission
+		if err := rows.Scan(&permission); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, rows.Err()
+}
+
+// RequirePermission middleware ensures the role of the user grants a permission
+func RequirePermission(permission entities.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, hasUser := GetUserFromContext(c.Request.Context())
+		if !hasUser {
+			WriteProblem(c, getAuthProblem(c.Request.Context()))
+			return
+		}
+		if err := accountStatusError(user.Status); err != nil {
+			WriteProblem(c, err)
+			return
+		}
+
+		if !user.HasPermission(permission) {
+			WriteProblem(c, ErrInsufficientPermissions)
+			return
+		}
+
+		c.Next()
+	}
+}
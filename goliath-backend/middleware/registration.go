@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"goliath/apperrors"
+	"goliath/entities"
+)
+
+// InviteCodeHeader carries the invite code of a user signing up while registration is invite-only
+const InviteCodeHeader = "X-Invite-Code"
+
+// AuthProblemKey is the context key for the reason the holder of a valid token was refused
+const AuthProblemKey ContextKey = "authProblem"
+
+// Errors for token holders refused by UserLoader
+var (
+	ErrAccountDisabled = apperrors.Forbidden("account_disabled", "This account is disabled")
+	ErrAccountBanned   = apperrors.Forbidden("account_banned", "This account is banned")
+	ErrInviteRequired  = apperrors.Forbidden("invite_required", "Registration is invite-only, sign up with an invite code in the %s header", InviteCodeHeader)
+	ErrInvalidInvite   = apperrors.Forbidden("invalid_invite", "The invite code is invalid, expired, already used, or for another or an unverified email")
+)
+
+// HashInviteCode returns the hash under which an invite code is stored
+func HashInviteCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// accountStatusError returns the error refusing a user with the given status, nil for active users
+func accountStatusError(status entities.UserStatus) error {
+	switch status {
+	case entities.UserStatusActive:
+		return nil
+	case entities.UserStatusBanned:
+		return ErrAccountBanned
+	}
+	return ErrAccountDisabled
+}
+
+// getAuthProblem returns why the token holder of a request has no user, ErrAuthenticationRequired
+// when there was no token or it was invalid
+func getAuthProblem(ctx context.Context) error {
+	if err, ok := ctx.Value(AuthProblemKey).(error); ok {
+		return err
+	}
+	return ErrAuthenticationRequired
+}
+
+// loadRegistrationPolicy loads the policy deciding who may sign up
+func loadRegistrationPolicy(ctx context.Context, db *sql.DB) (*entities.RegistrationPolicy, error) {
+	var policy entities.RegistrationPolicy
+	var domains *string
+	err := db.QueryRowContext(ctx, `
+		SELECT version, modified_when, modified_by, invite_only, allowed_email_domains
+		FROM registration_policy
+		WHERE id = 1
+	`).Scan(&policy.Version, &policy.ModifiedWhen, &policy.ModifiedBy, &policy.InviteOnly, &domains)
+	if err != nil {
+		return nil, err
+	}
+	if domains != nil && *domains != "" {
+		policy.AllowedEmailDomains = strings.Split(*domains, ",")
+	}
+	return &policy, nil
+}
+
+// registerUser creates the user of a verified token if the registration policy lets them sign up.
+// While registration is invite-only, a verified email of an allowed domain or an invite code is
+// needed; the invite is redeemed in the same transaction the user is created in. An invite for an
+// email is only redeemed with a token proving that email is verified.
+func registerUser(ctx context.Context, db *sql.DB, firebaseUID, email string, emailVerified bool, inviteCode string) (*entities.User, error) {
+	policy, err := loadRegistrationPolicy(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.InviteOnly || (emailVerified && policy.AllowsEmail(email)) {
+		if err := createUserFromFirebase(ctx, db, firebaseUID, email); err != nil {
+			return nil, err
+		}
+		return loadUserByFirebaseUID(ctx, db, firebaseUID)
+	}
+	if inviteCode == "" {
+		return nil, ErrInviteRequired
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Claim the invite before creating the user, so it cannot be redeemed twice
+	now := time.Now().UTC().Format("2006-01-02 15:04:05")
+	codeHash := HashInviteCode(inviteCode)
+	result, err := tx.ExecContext(ctx, `
+		UPDATE user_invite
+		SET used_when = ?, modified_when = ?, modified_by = ?, version = version + 1
+		WHERE code_hash = ? AND used_when IS NULL
+		  AND (expires_when IS NULL OR expires_when > ?)
+		  AND (email IS NULL OR (? AND lower(email) = lower(?)))
+	`, now, now, firebaseUID, codeHash, now, emailVerified, email)
+	if err != nil {
+		return nil, err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if affected == 0 {
+		return nil, ErrInvalidInvite
+	}
+
+	if err := createUserFromFirebase(ctx, tx, firebaseUID, email); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE user_invite SET used_by_user_id = (SELECT id FROM user WHERE firebase_uid = ?) WHERE code_hash = ?
+	`, firebaseUID, codeHash); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return loadUserByFirebaseUID(ctx, db, firebaseUID)
+}
@@ -18,8 +18,7 @@ func Transaction(db *sql.DB) gin.HandlerFunc {
 		tx, err := db.BeginTx(c.Request.Context(), nil)
 		if err != nil {
 			log.Printf("Failed to start transaction: %v", err)
-			c.JSON(500, gin.H{"error": "Failed to start database transaction"})
-			c.Abort()
+			WriteProblem(c, err)
 			return
 		}
 
@@ -51,7 +50,7 @@ func Transaction(db *sql.DB) gin.HandlerFunc {
 					log.Printf("Failed to commit transaction: %v", err)
 					// If we failed to commit and haven't sent a response, send error
 					if !c.Writer.Written() {
-						c.JSON(500, gin.H{"error": "Failed to commit database transaction"})
+						WriteProblem(c, err)
 					}
 				}
 			}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"log"
 
+	"goliath/apperrors"
 	"goliath/entities"
 
 	"github.com/gin-gonic/gin"
@@ -14,7 +15,9 @@ import (
 const UserContextKey ContextKey = "user"
 
 // UserLoader middleware loads user details from database based on Firebase UID
-// If the user doesn't exist, it creates them automatically
+// If the user doesn't exist, it creates them if the registration policy allows it.
+// Users refused for registration or for a disabled account are left out of the context with
+// the reason, which RequireAuth responds with.
 func UserLoader(db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Try to get Firebase UID from context (set by JWT middleware)
@@ -45,8 +48,14 @@ func UserLoader(db *sql.DB) gin.HandlerFunc {
 		if user == nil {
 			// User not found in database - create them
 			log.Printf("User not found for Firebase UID: %s, creating new user with email: %s", firebaseUID, email)
-			user, err = createUserFromFirebase(c.Request.Context(), db, firebaseUID, email)
+			emailVerified, _ := GetEmailVerifiedFromContext(c.Request.Context())
+			user, err = registerUser(c.Request.Context(), db, firebaseUID, email, emailVerified, c.GetHeader(InviteCodeHeader))
 			if err != nil {
+				if _, ok := apperrors.As(err); ok {
+					log.Printf("Refused registration of Firebase UID %s: %v", firebaseUID, err)
+					refuseUser(c, err)
+					return
+				}
 				log.Printf("Failed to create user: %v", err)
 				c.Next()
 				return
@@ -54,6 +63,13 @@ func UserLoader(db *sql.DB) gin.HandlerFunc {
 			log.Printf("Created new user: ID=%d, Email=%s, Firebase UID=%s", user.ID, user.Email, firebaseUID)
 		}
 
+		// Disabled and banned users are not signed in
+		if err := accountStatusError(user.Status); err != nil {
+			log.Printf("Refused %s user: ID=%d, Email=%s", user.Status, user.ID, user.Email)
+			refuseUser(c, err)
+			return
+		}
+
 		// Add full user object to context
 		ctx := context.WithValue(c.Request.Context(), UserContextKey, user)
 		c.Request = c.Request.WithContext(ctx)
@@ -64,22 +80,12 @@ func UserLoader(db *sql.DB) gin.HandlerFunc {
 
 // loadUserByFirebaseUID loads a user from the database by Firebase UID
 func loadUserByFirebaseUID(ctx context.Context, db *sql.DB, firebaseUID string) (*entities.User, error) {
-	var user entities.User
-	err := db.QueryRowContext(ctx, `
-		SELECT id, version, created_when, created_by, modified_when, modified_by, email, role, firebase_uid
+	user, err := entities.ScanUser(db.QueryRowContext(ctx, `
+		SELECT id, version, created_when, created_by, modified_when, modified_by, email, role, firebase_uid,
+		       status, status_reason, display_name, units, time_zone, date_of_birth, sex
 		FROM user 
 		WHERE firebase_uid = ?
-	`, firebaseUID).Scan(
-		&user.ID,
-		&user.Version,
-		&user.CreatedWhen,
-		&user.CreatedBy,
-		&user.ModifiedWhen,
-		&user.ModifiedBy,
-		&user.Email,
-		&user.Role,
-		&user.FirebaseUID,
-	)
+	`, firebaseUID))
 	
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -87,25 +93,31 @@ func loadUserByFirebaseUID(ctx context.Context, db *sql.DB, firebaseUID string)
 		}
 		return nil, err
 	}
+
+	user.Permissions, err = loadRolePermissions(ctx, db, user.Role)
+	if err != nil {
+		return nil, err
+	}
 	
 	log.Printf("Loaded user: ID=%d, Email=%s, Role=%s, Firebase UID=%s", user.ID, user.Email, user.Role, firebaseUID)
-	return &user, nil
+	return user, nil
 }
 
 // createUserFromFirebase creates a new user in the database from Firebase auth info
-func createUserFromFirebase(ctx context.Context, db *sql.DB, firebaseUID, email string) (*entities.User, error) {
+func createUserFromFirebase(ctx context.Context, executor DBExecutor, firebaseUID, email string) error {
 	// Insert new user with default USER role
-	_, err := db.ExecContext(ctx, `
+	_, err := executor.ExecContext(ctx, `
 		INSERT INTO user (email, role, firebase_uid, created_by, modified_by)
 		VALUES (?, 'USER', ?, 'system', 'system')
 	`, email, firebaseUID)
-	
-	if err != nil {
-		return nil, err
-	}
-	
-	// Load and return the newly created user
-	return loadUserByFirebaseUID(ctx, db, firebaseUID)
+	return err
+}
+
+// refuseUser continues the request without a user, recording why for RequireAuth
+func refuseUser(c *gin.Context, err error) {
+	ctx := context.WithValue(c.Request.Context(), AuthProblemKey, err)
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
 }
 
 // GetUserFromContext retrieves the user from context
@@ -114,51 +126,18 @@ func GetUserFromContext(ctx context.Context) (*entities.User, bool) {
 	return user, ok
 }
 
-// RequireAuth middleware ensures a user is authenticated
+// RequireAuth middleware ensures a user is authenticated and their account is active
 func RequireAuth() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		_, hasUser := GetUserFromContext(c.Request.Context())
-		if !hasUser {
-			c.JSON(401, gin.H{"error": "Authentication required"})
-			c.Abort()
-			return
-		}
-		c.Next()
-	}
-}
-
-// RequireRole middleware ensures a user has a specific role
-func RequireRole(role string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user, hasUser := GetUserFromContext(c.Request.Context())
 		if !hasUser {
-			c.JSON(401, gin.H{"error": "Authentication required"})
-			c.Abort()
+			WriteProblem(c, getAuthProblem(c.Request.Context()))
 			return
 		}
-		
-		if user.Role != role {
-			c.JSON(403, gin.H{"error": "Insufficient permissions"})
-			c.Abort()
+		if err := accountStatusError(user.Status); err != nil {
+			WriteProblem(c, err)
 			return
 		}
-		
 		c.Next()
 	}
 }
-
-// RequireAdmin middleware ensures a user has admin role
-func RequireAdmin() gin.HandlerFunc {
-	return RequireRole("ADMIN")
-}
-
-// IsAdmin checks if a user has admin role
-func IsAdmin(user *entities.User) bool {
-	return user != nil && user.Role == "ADMIN"
-}
-
-// IsUser checks if a user has user role
-func IsUser(user *entities.User) bool {
-	return user != nil && user.Role == "USER"
-}
-
@@ -1,48 +1,62 @@
 package main
 
 import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
 	"fmt"
+	"io/fs"
 	"log"
-	"os"
-	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
+	"strings"
 )
 
-const migrationsPath = "./migrations"
+// migrationFiles holds the SQL migrations compiled into the binary, so it no longer
+// depends on a migrations directory next to it
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
 
-// Migration represents a database migration loaded from a file
+const migrationsPath = "migrations"
+
+// Migration represents a database migration embedded in the binary
 type Migration struct {
-	Version int
-	Name    string
-	SQL     string
+	Version            int
+	Name               string
+	SQL                string
+	DownSQL            string // Reverts SQL; empty when the migration cannot be reverted
+	DownForeignKeysOff bool   // DownSQL rebuilds a referenced table and runs without foreign keys
+	Checksum           string // SHA-256 of SQL, recorded when the migration is applied
 }
 
-// GetMigrations loads all migration files from the migrations directory
-func GetMigrations() ([]Migration, error) {
-	// Ensure migrations directory exists
-	if err := os.MkdirAll(migrationsPath, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create migrations directory: %w", err)
-	}
+// foreignKeysOffDirective marks a down migration that rebuilds a table other tables reference.
+// SQLite cannot drop a column with a foreign key, and dropping the old table while foreign keys
+// are enforced would delete the rows referencing it, so such migrations run with enforcement off
+// and the references are checked before they are committed.
+const foreignKeysOffDirective = "-- migrate: foreign_keys off"
+
+// migrationPattern matches <version>_<name>.sql and <version>_<name>.down.sql
+// (e.g., 001_create_users_table.sql and 001_create_users_table.down.sql)
+var migrationPattern = regexp.MustCompile(`^(\d+)_(.+?)(\.down)?\.sql$`)
 
-	// Read all files in migrations directory
-	entries, err := os.ReadDir(migrationsPath)
+// GetMigrations loads all migrations embedded in the binary, sorted by version
+func GetMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, migrationsPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
 	}
 
-	var migrations []Migration
-	// Pattern: <version>_<name>.sql (e.g., 001_create_users_table.sql)
-	pattern := regexp.MustCompile(`^(\d+)_(.+)\.sql$`)
-
+	byVersion := make(map[int]*Migration)
+	downs := make(map[int]string)
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
 
 		filename := entry.Name()
-		matches := pattern.FindStringSubmatch(filename)
+		matches := migrationPattern.FindStringSubmatch(filename)
 		if matches == nil {
 			log.Printf("Warning: Skipping file with invalid name format: %s", filename)
 			continue
@@ -50,24 +64,42 @@ func GetMigrations() ([]Migration, error) {
 
 		version, err := strconv.Atoi(matches[1])
 		if err != nil {
-			log.Printf("Warning: Invalid version number in file %s: %v", filename, err)
-			continue
+			return nil, fmt.Errorf("invalid version number in migration %s: %w", filename, err)
 		}
 
-		name := matches[2]
-		filePath := filepath.Join(migrationsPath, filename)
-
 		// Read migration SQL
-		sqlBytes, err := os.ReadFile(filePath)
+		sqlBytes, err := fs.ReadFile(migrationFiles, migrationsPath+"/"+filename)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read migration file %s: %w", filename, err)
 		}
 
-		migrations = append(migrations, Migration{
-			Version: version,
-			Name:    name,
-			SQL:     string(sqlBytes),
-		})
+		if matches[3] != "" {
+			downs[version] = string(sqlBytes)
+			continue
+		}
+		if existing, ok := byVersion[version]; ok {
+			return nil, fmt.Errorf("migrations %d_%s and %s have the same version", version, existing.Name, filename)
+		}
+		checksum := sha256.Sum256(sqlBytes)
+		byVersion[version] = &Migration{
+			Version:  version,
+			Name:     matches[2],
+			SQL:      string(sqlBytes),
+			Checksum: hex.EncodeToString(checksum[:]),
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for version, downSQL := range downs {
+		migration, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("down migration %d has no matching migration", version)
+		}
+		migration.DownSQL = downSQL
+		migration.DownForeignKeysOff = strings.Contains(downSQL, foreignKeysOffDirective)
+	}
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
 	}
 
 	// Sort migrations by version
@@ -75,6 +107,5 @@ func GetMigrations() ([]Migration, error) {
 		return migrations[i].Version < migrations[j].Version
 	})
 
-	log.Printf("Loaded %d migration(s) from %s", len(migrations), migrationsPath)
 	return migrations, nil
 }
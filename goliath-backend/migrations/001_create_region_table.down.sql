@@ -0,0 +1,3 @@
+-- Drop Region table
+DROP INDEX IF EXISTS idx_region_name;
+DROP TABLE IF EXISTS region;
@@ -0,0 +1,2 @@
+-- Remove initial regions
+DELETE FROM region WHERE created_by = 'migration';
@@ -0,0 +1,4 @@
+-- Drop Muscle Group table
+DROP INDEX IF EXISTS idx_muscle_group_region;
+DROP INDEX IF EXISTS idx_muscle_group_name;
+DROP TABLE IF EXISTS muscle_group;
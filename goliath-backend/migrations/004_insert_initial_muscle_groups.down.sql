@@ -0,0 +1,2 @@
+-- Remove initial muscle groups
+DELETE FROM muscle_group WHERE created_by = 'migration';
@@ -0,0 +1,3 @@
+-- Drop Exercise Area table
+DROP INDEX IF EXISTS idx_exercise_area_name;
+DROP TABLE IF EXISTS exercise_area;
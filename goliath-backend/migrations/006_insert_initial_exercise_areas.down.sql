@@ -0,0 +1,2 @@
+-- Remove initial exercise areas
+DELETE FROM exercise_area WHERE created_by = 'migration';
@@ -0,0 +1,4 @@
+-- Drop Muscle table
+DROP INDEX IF EXISTS idx_muscle_muscle_group;
+DROP INDEX IF EXISTS idx_muscle_name;
+DROP TABLE IF EXISTS muscle;
@@ -0,0 +1,4 @@
+-- Drop junction table between Muscle and Exercise Area
+DROP INDEX IF EXISTS idx_muscle_exercise_area_exercise;
+DROP INDEX IF EXISTS idx_muscle_exercise_area_muscle;
+DROP TABLE IF EXISTS muscle_exercise_area;
@@ -0,0 +1,2 @@
+-- Remove initial muscles
+DELETE FROM muscle WHERE created_by = 'migration';
@@ -0,0 +1,2 @@
+-- Remove initial muscle to exercise area relationships
+DELETE FROM muscle_exercise_area WHERE created_by = 'migration';
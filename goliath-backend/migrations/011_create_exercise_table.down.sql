@@ -0,0 +1,4 @@
+-- Drop Exercise table
+DROP INDEX IF EXISTS idx_exercise_type;
+DROP INDEX IF EXISTS idx_exercise_name;
+DROP TABLE IF EXISTS exercise;
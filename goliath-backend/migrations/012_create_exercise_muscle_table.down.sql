@@ -0,0 +1,4 @@
+-- Drop junction table between Exercise and Muscle
+DROP INDEX IF EXISTS idx_exercise_muscle_muscle;
+DROP INDEX IF EXISTS idx_exercise_muscle_exercise;
+DROP TABLE IF EXISTS exercise_muscle;
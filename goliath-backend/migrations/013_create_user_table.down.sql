@@ -0,0 +1,5 @@
+-- Drop user table
+DROP INDEX IF EXISTS idx_user_firebase_uid;
+DROP INDEX IF EXISTS idx_user_role;
+DROP INDEX IF EXISTS idx_user_email;
+DROP TABLE IF EXISTS user;
@@ -0,0 +1,4 @@
+-- Drop Workout table
+DROP INDEX IF EXISTS idx_workout_name;
+DROP INDEX IF EXISTS idx_workout_user_id;
+DROP TABLE IF EXISTS workout;
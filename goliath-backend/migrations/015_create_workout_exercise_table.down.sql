@@ -0,0 +1,5 @@
+-- Drop Workout Exercise table
+DROP INDEX IF EXISTS idx_workout_exercise_workout_position;
+DROP INDEX IF EXISTS idx_workout_exercise_exercise_id;
+DROP INDEX IF EXISTS idx_workout_exercise_workout_id;
+DROP TABLE IF EXISTS workout_exercise;
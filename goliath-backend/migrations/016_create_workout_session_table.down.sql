@@ -0,0 +1,4 @@
+-- Drop Workout Session table
+DROP INDEX IF EXISTS idx_workout_session_workout_id;
+DROP INDEX IF EXISTS idx_workout_session_user_started;
+DROP TABLE IF EXISTS workout_session;
@@ -0,0 +1,23 @@
+-- Create Workout Session table (a workout actually performed by a user)
+CREATE TABLE IF NOT EXISTS workout_session (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT NOT NULL,
+    modified_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    modified_by TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    workout_id INTEGER,
+    name TEXT NOT NULL,
+    started_when TEXT NOT NULL,
+    finished_when TEXT,
+    notes TEXT,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE,
+    FOREIGN KEY (workout_id) REFERENCES workout(id) ON DELETE SET NULL
+);
+
+-- Create compound index for a user's session history ordered by start time
+CREATE INDEX IF NOT EXISTS idx_workout_session_user_started ON workout_session(user_id, started_when);
+
+-- Create index on workout_id for faster lookups of sessions of a workout
+CREATE INDEX IF NOT EXISTS idx_workout_session_workout_id ON workout_session(workout_id);
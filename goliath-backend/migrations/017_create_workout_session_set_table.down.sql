@@ -0,0 +1,4 @@
+-- Drop Workout Session Set table
+DROP INDEX IF EXISTS idx_workout_session_set_exercise_id;
+DROP INDEX IF EXISTS idx_workout_session_set_session;
+DROP TABLE IF EXISTS workout_session_set;
@@ -0,0 +1,26 @@
+-- Create Workout Session Set table (actual result of a single performed set)
+CREATE TABLE IF NOT EXISTS workout_session_set (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT NOT NULL,
+    modified_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    modified_by TEXT NOT NULL,
+    session_id INTEGER NOT NULL,
+    workout_exercise_id INTEGER,
+    exercise_id INTEGER NOT NULL,
+    set_number INTEGER NOT NULL DEFAULT 1,
+    reps INTEGER,
+    time_seconds INTEGER,
+    weight REAL,
+    notes TEXT,
+    FOREIGN KEY (session_id) REFERENCES workout_session(id) ON DELETE CASCADE,
+    FOREIGN KEY (workout_exercise_id) REFERENCES workout_exercise(id) ON DELETE SET NULL,
+    FOREIGN KEY (exercise_id) REFERENCES exercise(id) ON DELETE RESTRICT
+);
+
+-- Create compound index for session sets ordered by set number
+CREATE INDEX IF NOT EXISTS idx_workout_session_set_session ON workout_session_set(session_id, set_number);
+
+-- Create index on exercise_id for exercise history lookups
+CREATE INDEX IF NOT EXISTS idx_workout_session_set_exercise_id ON workout_session_set(exercise_id);
@@ -0,0 +1,3 @@
+-- Drop Workout Exercise Set table
+DROP INDEX IF EXISTS idx_workout_exercise_set_position;
+DROP TABLE IF EXISTS workout_exercise_set;
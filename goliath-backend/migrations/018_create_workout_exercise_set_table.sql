@@ -0,0 +1,20 @@
+-- Create Workout Exercise Set table (individual planned sets of a workout exercise)
+CREATE TABLE IF NOT EXISTS workout_exercise_set (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT NOT NULL,
+    modified_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    modified_by TEXT NOT NULL,
+    workout_exercise_id INTEGER NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    set_type TEXT NOT NULL DEFAULT 'Working' CHECK(set_type IN ('WarmUp', 'Working', 'Drop', 'Failure')),
+    reps INTEGER,
+    time_seconds INTEGER,
+    weight REAL,
+    rpe REAL CHECK(rpe IS NULL OR (rpe >= 1 AND rpe <= 10)),
+    FOREIGN KEY (workout_exercise_id) REFERENCES workout_exercise(id) ON DELETE CASCADE
+);
+
+-- Create compound index for sets ordered by position
+CREATE INDEX IF NOT EXISTS idx_workout_exercise_set_position ON workout_exercise_set(workout_exercise_id, position);
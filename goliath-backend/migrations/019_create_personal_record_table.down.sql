@@ -0,0 +1,3 @@
+-- Drop Personal Record table
+DROP INDEX IF EXISTS idx_personal_record_user_exercise;
+DROP TABLE IF EXISTS personal_record;
@@ -0,0 +1,24 @@
+-- Create Personal Record table (best results of a user per exercise, derived from performed session sets)
+CREATE TABLE IF NOT EXISTS personal_record (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT NOT NULL,
+    modified_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    modified_by TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    exercise_id INTEGER NOT NULL,
+    record_type TEXT NOT NULL CHECK(record_type IN ('MaxWeight', 'MaxRepsAtWeight', 'Estimated1RM', 'LongestHold', 'LongestEccentric')),
+    value REAL NOT NULL,
+    reps INTEGER,
+    time_seconds INTEGER,
+    weight REAL,
+    session_set_id INTEGER,
+    achieved_when TEXT NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE,
+    FOREIGN KEY (exercise_id) REFERENCES exercise(id) ON DELETE CASCADE,
+    FOREIGN KEY (session_set_id) REFERENCES workout_session_set(id) ON DELETE SET NULL
+);
+
+-- Create compound index for a user's records of an exercise
+CREATE INDEX IF NOT EXISTS idx_personal_record_user_exercise ON personal_record(user_id, exercise_id);
@@ -0,0 +1,5 @@
+-- Drop full-text search index over exercises
+DROP TRIGGER IF EXISTS exercise_fts_after_delete;
+DROP TRIGGER IF EXISTS exercise_fts_after_update;
+DROP TRIGGER IF EXISTS exercise_fts_after_insert;
+DROP TABLE IF EXISTS exercise_fts;
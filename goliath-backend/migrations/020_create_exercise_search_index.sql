@@ -0,0 +1,20 @@
+-- Create full-text search index over exercises (FTS5)
+-- The index is kept in sync with the exercise table by triggers; add columns here
+-- (e.g. description) when exercises gain more searchable text
+CREATE VIRTUAL TABLE IF NOT EXISTS exercise_fts USING fts5(name, tokenize = 'unicode61 remove_diacritics 2');
+
+-- Index existing exercises
+INSERT INTO exercise_fts (rowid, name) SELECT id, name FROM exercise;
+
+-- Keep the index in sync with the exercise table
+CREATE TRIGGER IF NOT EXISTS exercise_fts_after_insert AFTER INSERT ON exercise BEGIN
+    INSERT INTO exercise_fts (rowid, name) VALUES (new.id, new.name);
+END;
+
+CREATE TRIGGER IF NOT EXISTS exercise_fts_after_update AFTER UPDATE OF name ON exercise BEGIN
+    UPDATE exercise_fts SET name = new.name WHERE rowid = new.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS exercise_fts_after_delete AFTER DELETE ON exercise BEGIN
+    DELETE FROM exercise_fts WHERE rowid = old.id;
+END;
@@ -0,0 +1,4 @@
+-- Remove soft-delete support from exercises
+-- Archived exercises become active again
+DROP INDEX IF EXISTS idx_exercise_archived_when;
+ALTER TABLE exercise DROP COLUMN archived_when;
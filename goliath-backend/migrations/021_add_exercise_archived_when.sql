@@ -0,0 +1,6 @@
+-- Add soft-delete support to exercises
+-- Archived exercises are hidden from the catalog and search but remain in existing workouts
+ALTER TABLE exercise ADD COLUMN archived_when TEXT;
+
+-- Create index on archived_when for filtering the active catalog
+CREATE INDEX IF NOT EXISTS idx_exercise_archived_when ON exercise(archived_when);
@@ -0,0 +1,26 @@
+-- migrate: foreign_keys off
+-- Remove template sharing from workouts
+-- SQLite cannot drop a column with a foreign key, so the workout table is rebuilt without it
+DROP INDEX IF EXISTS idx_workout_source_workout_id;
+DROP INDEX IF EXISTS idx_workout_shareable;
+
+CREATE TABLE workout_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT NOT NULL,
+    modified_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    modified_by TEXT NOT NULL,
+    name TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+INSERT INTO workout_new (id, version, created_when, created_by, modified_when, modified_by, name, user_id)
+SELECT id, version, created_when, created_by, modified_when, modified_by, name, user_id FROM workout;
+
+DROP TABLE workout;
+ALTER TABLE workout_new RENAME TO workout;
+
+CREATE INDEX IF NOT EXISTS idx_workout_user_id ON workout(user_id);
+CREATE INDEX IF NOT EXISTS idx_workout_name ON workout(name);
@@ -0,0 +1,12 @@
+-- Add template sharing to workouts
+-- Shareable workouts are listed in the template library and can be cloned by other users
+ALTER TABLE workout ADD COLUMN shareable INTEGER NOT NULL DEFAULT 0;
+
+-- Workout a clone was copied from; cleared when the source is deleted
+ALTER TABLE workout ADD COLUMN source_workout_id INTEGER REFERENCES workout(id) ON DELETE SET NULL;
+
+-- Create index on shareable for browsing the template library
+CREATE INDEX IF NOT EXISTS idx_workout_shareable ON workout(shareable);
+
+-- Create index on source_workout_id for finding clones of a template
+CREATE INDEX IF NOT EXISTS idx_workout_source_workout_id ON workout(source_workout_id);
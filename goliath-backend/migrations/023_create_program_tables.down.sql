@@ -0,0 +1,9 @@
+-- Drop program tables, enrollments and days first as they reference the others
+DROP INDEX IF EXISTS idx_program_enrollment_program_id;
+DROP INDEX IF EXISTS idx_program_enrollment_active;
+DROP TABLE IF EXISTS program_enrollment;
+DROP INDEX IF EXISTS idx_program_day_workout_id;
+DROP TABLE IF EXISTS program_day;
+DROP TABLE IF EXISTS program_week;
+DROP INDEX IF EXISTS idx_program_user_id;
+DROP TABLE IF EXISTS program;
@@ -0,0 +1,80 @@
+-- Create Program table (a multi-week training plan composed of workouts)
+-- Every week the weights and reps of the planned workouts grow by the increments,
+-- except on deload weeks (every deload_every-th week) which lighten the weights instead
+CREATE TABLE IF NOT EXISTS program (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT NOT NULL,
+    modified_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    modified_by TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT,
+    weight_increment REAL NOT NULL DEFAULT 0,
+    reps_increment INTEGER NOT NULL DEFAULT 0,
+    deload_every INTEGER NOT NULL DEFAULT 0 CHECK(deload_every >= 0),
+    deload_percentage REAL NOT NULL DEFAULT 60 CHECK(deload_percentage > 0 AND deload_percentage <= 100),
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+-- Create index on user_id for faster lookups of user's programs
+CREATE INDEX IF NOT EXISTS idx_program_user_id ON program(user_id);
+
+-- Create Program Week table
+CREATE TABLE IF NOT EXISTS program_week (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT NOT NULL,
+    modified_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    modified_by TEXT NOT NULL,
+    program_id INTEGER NOT NULL,
+    week_number INTEGER NOT NULL CHECK(week_number > 0),
+    notes TEXT,
+    FOREIGN KEY (program_id) REFERENCES program(id) ON DELETE CASCADE,
+    UNIQUE(program_id, week_number)
+);
+
+-- Create Program Day table (the workout planned for one day of a week)
+CREATE TABLE IF NOT EXISTS program_day (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT NOT NULL,
+    modified_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    modified_by TEXT NOT NULL,
+    program_week_id INTEGER NOT NULL,
+    day_number INTEGER NOT NULL CHECK(day_number > 0),
+    workout_id INTEGER NOT NULL,
+    FOREIGN KEY (program_week_id) REFERENCES program_week(id) ON DELETE CASCADE,
+    FOREIGN KEY (workout_id) REFERENCES workout(id) ON DELETE CASCADE,
+    UNIQUE(program_week_id, day_number)
+);
+
+-- Create index on workout_id for finding the programs using a workout
+CREATE INDEX IF NOT EXISTS idx_program_day_workout_id ON program_day(workout_id);
+
+-- Create Program Enrollment table (a user following a program and their position in it)
+CREATE TABLE IF NOT EXISTS program_enrollment (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT NOT NULL,
+    modified_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    modified_by TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    program_id INTEGER NOT NULL,
+    started_when TEXT NOT NULL,
+    current_week INTEGER NOT NULL DEFAULT 1,
+    current_day INTEGER NOT NULL DEFAULT 1,
+    completed_when TEXT,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE,
+    FOREIGN KEY (program_id) REFERENCES program(id) ON DELETE CASCADE
+);
+
+-- A user follows a program at most once at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_program_enrollment_active ON program_enrollment(user_id, program_id) WHERE completed_when IS NULL;
+
+-- Create index on program_id for faster lookups of enrollments of a program
+CREATE INDEX IF NOT EXISTS idx_program_enrollment_program_id ON program_enrollment(program_id);
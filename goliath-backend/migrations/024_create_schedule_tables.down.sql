@@ -0,0 +1,5 @@
+-- Drop schedule tables
+DROP TABLE IF EXISTS calendar_feed;
+DROP INDEX IF EXISTS idx_workout_schedule_workout_id;
+DROP INDEX IF EXISTS idx_workout_schedule_user_dates;
+DROP TABLE IF EXISTS workout_schedule;
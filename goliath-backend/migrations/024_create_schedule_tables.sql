@@ -0,0 +1,40 @@
+-- Create Workout Schedule table (a workout planned on a date or on recurring weekdays)
+-- Without weekdays the workout is planned once on start_date; with weekdays it repeats
+-- every interval_weeks weeks from start_date until end_date (inclusive, open-ended when NULL)
+CREATE TABLE IF NOT EXISTS workout_schedule (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT NOT NULL,
+    modified_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    modified_by TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    workout_id INTEGER NOT NULL,
+    start_date TEXT NOT NULL,
+    end_date TEXT,
+    weekdays TEXT,
+    interval_weeks INTEGER NOT NULL DEFAULT 1 CHECK(interval_weeks > 0),
+    notes TEXT,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE,
+    FOREIGN KEY (workout_id) REFERENCES workout(id) ON DELETE CASCADE
+);
+
+-- Create compound index for the schedules of a user overlapping a date range
+CREATE INDEX IF NOT EXISTS idx_workout_schedule_user_dates ON workout_schedule(user_id, start_date, end_date);
+
+-- Create index on workout_id for faster lookups of schedules of a workout
+CREATE INDEX IF NOT EXISTS idx_workout_schedule_workout_id ON workout_schedule(workout_id);
+
+-- Create Calendar Feed table (the secret token of a user's iCalendar feed)
+-- Only a SHA-256 hash of the token is stored; deleting the row revokes the feed
+CREATE TABLE IF NOT EXISTS calendar_feed (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT NOT NULL,
+    modified_when TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    modified_by TEXT NOT NULL,
+    user_id INTEGER NOT NULL UNIQUE,
+    token_hash TEXT NOT NULL UNIQUE,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
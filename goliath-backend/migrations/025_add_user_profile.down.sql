@@ -0,0 +1,6 @@
+-- Remove the self-service profile from users
+ALTER TABLE user DROP COLUMN sex;
+ALTER TABLE user DROP COLUMN date_of_birth;
+ALTER TABLE user DROP COLUMN time_zone;
+ALTER TABLE user DROP COLUMN units;
+ALTER TABLE user DROP COLUMN display_name;
@@ -0,0 +1,9 @@
+-- Add a self-service profile to users
+-- Preferred units and time zone have defaults; the other fields are optional
+ALTER TABLE user ADD COLUMN display_name TEXT;
+ALTER TABLE user ADD COLUMN units TEXT NOT NULL DEFAULT 'Metric' CHECK(units IN ('Metric', 'Imperial'));
+ALTER TABLE user ADD COLUMN time_zone TEXT NOT NULL DEFAULT 'UTC';
+ALTER TABLE user ADD COLUMN date_of_birth TEXT; -- YYYY-MM-DD
+
+-- Sex is only used for analytics, e.g. strength standards
+ALTER TABLE user ADD COLUMN sex TEXT CHECK(sex IS NULL OR sex IN ('Male', 'Female'));
@@ -0,0 +1,7 @@
+-- Remove account status, the registration policy and invites
+DROP INDEX IF EXISTS idx_user_invite_used_when;
+DROP TABLE IF EXISTS user_invite;
+DROP TABLE IF EXISTS registration_policy;
+DROP INDEX IF EXISTS idx_user_status;
+ALTER TABLE user DROP COLUMN status_reason;
+ALTER TABLE user DROP COLUMN status;
@@ -0,0 +1,42 @@
+-- Add account status to users
+-- Disabled and banned users are refused by the API; the reason is only shown to admins
+ALTER TABLE user ADD COLUMN status TEXT NOT NULL DEFAULT 'Active' CHECK(status IN ('Active', 'Disabled', 'Banned'));
+ALTER TABLE user ADD COLUMN status_reason TEXT;
+
+-- Create index on status for filtering
+CREATE INDEX IF NOT EXISTS idx_user_status ON user(status);
+
+-- Create registration_policy table
+-- A single row deciding who may sign up; new users need an invite or an allowlisted email domain
+-- while invite_only is set
+CREATE TABLE IF NOT EXISTS registration_policy (
+    id INTEGER PRIMARY KEY CHECK(id = 1),
+    version INTEGER NOT NULL DEFAULT 1,
+    modified_when TEXT NOT NULL DEFAULT (datetime('now')),
+    modified_by TEXT,
+    invite_only INTEGER NOT NULL DEFAULT 0,
+    allowed_email_domains TEXT -- Comma-separated, e.g. example.com,example.org
+);
+
+INSERT INTO registration_policy (id, modified_by) VALUES (1, 'migration');
+
+-- Create user_invite table
+-- Single-use invite codes; only a hash of the code is stored
+CREATE TABLE IF NOT EXISTS user_invite (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_when TEXT NOT NULL DEFAULT (datetime('now')),
+    created_by TEXT,
+    modified_when TEXT NOT NULL DEFAULT (datetime('now')),
+    modified_by TEXT,
+    code_hash TEXT NOT NULL UNIQUE,
+    email TEXT,         -- Only this email may redeem the invite, if set
+    note TEXT,
+    expires_when TEXT,  -- No expiry if NULL
+    used_when TEXT,
+    used_by_user_id INTEGER,
+    FOREIGN KEY (used_by_user_id) REFERENCES user(id) ON DELETE SET NULL
+);
+
+-- Create index on used_when for listing pending invites
+CREATE INDEX IF NOT EXISTS idx_user_invite_used_when ON user_invite(used_when);
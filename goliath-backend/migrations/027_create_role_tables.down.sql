@@ -0,0 +1,12 @@
+-- Remove roles and permissions, moving users of other roles back to USER
+DROP TRIGGER IF EXISTS user_role_before_update;
+DROP TRIGGER IF EXISTS user_role_before_insert;
+DROP INDEX IF EXISTS idx_user_role;
+ALTER TABLE user RENAME COLUMN role TO role_before_027;
+ALTER TABLE user ADD COLUMN role TEXT NOT NULL DEFAULT 'USER' CHECK (role IN ('USER', 'ADMIN'));
+UPDATE user SET role = CASE WHEN role_before_027 = 'ADMIN' THEN 'ADMIN' ELSE 'USER' END;
+ALTER TABLE user DROP COLUMN role_before_027;
+CREATE INDEX IF NOT EXISTS idx_user_role ON user(role);
+DROP TABLE IF EXISTS role_permission;
+DROP TABLE IF EXISTS role;
+DROP TABLE IF EXISTS permission;
@@ -0,0 +1,79 @@
+-- Create permission table
+-- Permissions are checked by the API, so they are only added by migrations
+CREATE TABLE IF NOT EXISTS permission (
+    name TEXT PRIMARY KEY, -- e.g. exercise:write
+    description TEXT NOT NULL
+);
+
+INSERT INTO permission (name, description) VALUES
+    ('exercise:write', 'Create, update, archive, restore and merge exercises, and import the exercise catalog'),
+    ('catalog:read', 'Export the exercise catalog'),
+    ('muscle:write', 'Manage regions, muscle groups, muscles and exercise areas'),
+    ('user:read', 'List users with their email, role and status'),
+    ('user:status', 'Enable, disable and ban users'),
+    ('user:role', 'Assign roles to users'),
+    ('registration:read', 'View the registration policy and invites'),
+    ('registration:write', 'Change the registration policy, and create and revoke invites'),
+    ('backup:read', 'List and verify database snapshots'),
+    ('backup:write', 'Take database snapshots and restore them'),
+    ('role:read', 'List roles and permissions'),
+    ('role:write', 'Create, update and delete roles');
+
+-- Create role table
+-- Built-in roles cannot be deleted; ADMIN always holds every permission
+CREATE TABLE IF NOT EXISTS role (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_when TEXT NOT NULL DEFAULT (datetime('now')),
+    created_by TEXT,
+    modified_when TEXT NOT NULL DEFAULT (datetime('now')),
+    modified_by TEXT,
+    name TEXT NOT NULL UNIQUE, -- e.g. CATALOG_EDITOR, stored in user.role
+    description TEXT,
+    built_in INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO role (name, description, built_in, created_by, modified_by) VALUES
+    ('USER', 'Manages their own workouts, sessions and programs', 1, 'migration', 'migration'),
+    ('ADMIN', 'Holds every permission', 1, 'migration', 'migration'),
+    ('CATALOG_EDITOR', 'Maintains the exercise catalog and muscle taxonomy', 0, 'migration', 'migration'),
+    ('COACH', 'Coaches athletes', 0, 'migration', 'migration'),
+    ('SUPPORT', 'Helps users with their accounts and invites', 0, 'migration', 'migration'),
+    ('AUDITOR', 'Reads users, registration, backups and roles without changing them', 0, 'migration', 'migration');
+
+-- Create role_permission table
+CREATE TABLE IF NOT EXISTS role_permission (
+    role_id INTEGER NOT NULL,
+    permission TEXT NOT NULL,
+    PRIMARY KEY (role_id, permission),
+    FOREIGN KEY (role_id) REFERENCES role(id) ON DELETE CASCADE,
+    FOREIGN KEY (permission) REFERENCES permission(name) ON DELETE CASCADE
+);
+
+INSERT INTO role_permission (role_id, permission)
+SELECT role.id, permission.name FROM role, permission WHERE role.name = 'ADMIN';
+
+INSERT INTO role_permission (role_id, permission)
+SELECT role.id, permission.name FROM role, permission
+WHERE (role.name = 'CATALOG_EDITOR' AND permission.name IN ('exercise:write', 'catalog:read', 'muscle:write'))
+   OR (role.name = 'SUPPORT' AND permission.name IN ('user:read', 'user:status', 'registration:read', 'registration:write'))
+   OR (role.name = 'AUDITOR' AND permission.name LIKE '%:read');
+
+-- Replace the USER/ADMIN check of user.role with a check against the role table.
+-- SQLite cannot drop a CHECK constraint, so the column is recreated.
+DROP INDEX IF EXISTS idx_user_role;
+ALTER TABLE user RENAME COLUMN role TO role_before_027;
+ALTER TABLE user ADD COLUMN role TEXT NOT NULL DEFAULT 'USER';
+UPDATE user SET role = role_before_027;
+ALTER TABLE user DROP COLUMN role_before_027;
+CREATE INDEX IF NOT EXISTS idx_user_role ON user(role);
+
+CREATE TRIGGER IF NOT EXISTS user_role_before_insert BEFORE INSERT ON user
+WHEN NOT EXISTS (SELECT 1 FROM role WHERE name = new.role) BEGIN
+    SELECT RAISE(ABORT, 'unknown role');
+END;
+
+CREATE TRIGGER IF NOT EXISTS user_role_before_update BEFORE UPDATE OF role ON user
+WHEN NOT EXISTS (SELECT 1 FROM role WHERE name = new.role) BEGIN
+    SELECT RAISE(ABORT, 'unknown role');
+END;
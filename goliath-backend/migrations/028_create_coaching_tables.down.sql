@@ -0,0 +1,8 @@
+-- Remove coaching and the workout audit trail
+DROP INDEX IF EXISTS idx_workout_change_workout_id;
+DROP TABLE IF EXISTS workout_change;
+DROP INDEX IF EXISTS idx_coaching_athlete_user_id;
+DROP INDEX IF EXISTS idx_coaching_open;
+DROP TABLE IF EXISTS coaching;
+UPDATE role SET description = 'Coaches athletes' WHERE name = 'COACH' AND description = 'Coaches athletes who accepted their invite';
+DELETE FROM permission WHERE name = 'athlete:coach';
@@ -0,0 +1,51 @@
+-- Add the permission to coach athletes
+INSERT INTO permission (name, description) VALUES
+    ('athlete:coach', 'Invite athletes and manage the workouts of athletes who accepted');
+
+INSERT INTO role_permission (role_id, permission)
+SELECT id, 'athlete:coach' FROM role WHERE name IN ('ADMIN', 'COACH');
+
+UPDATE role SET description = 'Coaches athletes who accepted their invite' WHERE name = 'COACH' AND description = 'Coaches athletes';
+
+-- Create coaching table
+-- A coach invites an athlete; once accepted the coach may read, create and edit the athlete's
+-- workouts until either of them ends the coaching
+CREATE TABLE IF NOT EXISTS coaching (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_when TEXT NOT NULL DEFAULT (datetime('now')),
+    created_by TEXT,
+    modified_when TEXT NOT NULL DEFAULT (datetime('now')),
+    modified_by TEXT,
+    coach_user_id INTEGER NOT NULL,
+    athlete_user_id INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'Pending' CHECK(status IN ('Pending', 'Active', 'Ended')),
+    accepted_when TEXT,
+    ended_when TEXT,
+    CHECK(coach_user_id != athlete_user_id),
+    FOREIGN KEY (coach_user_id) REFERENCES user(id) ON DELETE CASCADE,
+    FOREIGN KEY (athlete_user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+-- A coach has at most one open invite or coaching per athlete
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coaching_open ON coaching(coach_user_id, athlete_user_id) WHERE status != 'Ended';
+
+-- Create index on athlete_user_id for listing the coaches of an athlete
+CREATE INDEX IF NOT EXISTS idx_coaching_athlete_user_id ON coaching(athlete_user_id);
+
+-- Create workout_change table
+-- Audit trail of changes to workouts, their exercises and sets, and who made them
+CREATE TABLE IF NOT EXISTS workout_change (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    changed_when TEXT NOT NULL DEFAULT (datetime('now')),
+    workout_id INTEGER NOT NULL,
+    user_id INTEGER,           -- Who made the change; the owner or one of their coaches
+    entity_type TEXT NOT NULL CHECK(entity_type IN ('Workout', 'WorkoutExercise', 'WorkoutExerciseSet')),
+    entity_id INTEGER NOT NULL,
+    action TEXT NOT NULL CHECK(action IN ('Created', 'Updated', 'Deleted')),
+    FOREIGN KEY (workout_id) REFERENCES workout(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE SET NULL
+);
+
+-- Create index on workout_id for listing the changes of a workout
+CREATE INDEX IF NOT EXISTS idx_workout_change_workout_id ON workout_change(workout_id);
@@ -0,0 +1,2 @@
+-- Remove the workout permissions; role_permission rows are deleted with them
+DELETE FROM permission WHERE name IN ('workout:read', 'workout:write');
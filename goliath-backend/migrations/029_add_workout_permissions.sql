@@ -0,0 +1,10 @@
+-- Add the permissions to read and change the workouts of every user, e.g. to help users with
+-- their data. Only ADMIN holds them initially.
+INSERT INTO permission (name, description) VALUES
+    ('workout:read', 'Read the workouts of every user'),
+    ('workout:write', 'Change and delete the workouts of every user');
+
+INSERT INTO role_permission (role_id, permission)
+SELECT id, permission.name
+FROM role, permission
+WHERE role.name = 'ADMIN' AND permission.name IN ('workout:read', 'workout:write');
@@ -0,0 +1,3 @@
+-- Remove the movement plane and direction from exercise areas
+ALTER TABLE exercise_area DROP COLUMN direction;
+ALTER TABLE exercise_area DROP COLUMN plane;
@@ -0,0 +1,13 @@
+-- Add the movement plane and direction to exercise areas
+-- Balance reports compute push/pull ratios from them instead of parsing area names
+ALTER TABLE exercise_area ADD COLUMN plane TEXT CHECK(plane IS NULL OR plane IN ('Horizontal', 'Vertical', 'Lateral', 'Legs'));
+ALTER TABLE exercise_area ADD COLUMN direction TEXT CHECK(direction IS NULL OR direction IN ('Push', 'Pull'));
+
+UPDATE exercise_area SET plane = 'Horizontal', direction = 'Push' WHERE name = 'Horizontal Push';
+UPDATE exercise_area SET plane = 'Vertical', direction = 'Push' WHERE name IN ('Vertical Push (Up)', 'Vertical Push (Down)');
+UPDATE exercise_area SET plane = 'Lateral', direction = 'Push' WHERE name = 'Lateral Push';
+UPDATE exercise_area SET plane = 'Legs', direction = 'Push' WHERE name = 'Legs Push';
+UPDATE exercise_area SET plane = 'Vertical', direction = 'Pull' WHERE name = 'Vertical Pull';
+UPDATE exercise_area SET plane = 'Horizontal', direction = 'Pull' WHERE name = 'Horizontal Pull';
+UPDATE exercise_area SET plane = 'Lateral', direction = 'Pull' WHERE name = 'Lateral Pull';
+UPDATE exercise_area SET plane = 'Legs', direction = 'Pull' WHERE name = 'Legs Pull';
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"goliath/middleware"
+)
+
+// verifiedEmailVerifier authenticates every bearer token as one user whose email is verified or not
+type verifiedEmailVerifier struct {
+	email    string
+	verified bool
+}
+
+// VerifyToken returns the user with the email_verified claim of the verifier
+func (v *verifiedEmailVerifier) VerifyToken(ctx context.Context, token string) (*middleware.VerifiedToken, error) {
+	return &middleware.VerifiedToken{
+		UID:    "registration-test",
+		Email:  v.email,
+		Claims: map[string]interface{}{"email_verified": v.verified},
+	}, nil
+}
+
+// TestRegistrationEmailInvite checks that while registration is invite-only, an invite for an email
+// is only redeemed by a token with that email verified
+func TestRegistrationEmailInvite(t *testing.T) {
+	const code = "invite-code"
+	tests := []struct {
+		name     string
+		email    string
+		verified bool
+		wantCode int
+		wantErr  string
+	}{
+		{name: "verified", email: "Invited@example.com", verified: true, wantCode: http.StatusOK},
+		{name: "unverified", email: "invited@example.com", verified: false, wantCode: http.StatusForbidden, wantErr: middleware.ErrInvalidInvite.Code},
+		{name: "other email", email: "other@example.com", verified: true, wantCode: http.StatusForbidden, wantErr: middleware.ErrInvalidInvite.Code},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, db := newTestDB(t)
+			if _, err := db.Exec(`UPDATE registration_policy SET invite_only = 1 WHERE id = 1`); err != nil {
+				t.Fatalf("failed to make registration invite-only: %v", err)
+			}
+			if _, err := db.Exec(`
+				INSERT INTO user_invite (version, created_by, modified_by, code_hash, email)
+				VALUES (1, 'test', 'test', ?, 'invited@example.com')
+			`, middleware.HashInviteCode(code)); err != nil {
+				t.Fatalf("failed to create the invite: %v", err)
+			}
+
+			verifier := &verifiedEmailVerifier{email: tt.email, verified: tt.verified}
+			router := newRouter(cfg, db, verifier, newBackupService(cfg), newPolicy(db))
+
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header.Set("Authorization", "Bearer test")
+			req.Header.Set(middleware.InviteCodeHeader, code)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("got %d %s, want %d", rec.Code, rec.Body.String(), tt.wantCode)
+			}
+			if tt.wantErr != "" {
+				var problem struct {
+					Code string `json:"code"`
+				}
+				if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+					t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+				}
+				if problem.Code != tt.wantErr {
+					t.Errorf("got code %q, want %q", problem.Code, tt.wantErr)
+				}
+			}
+
+			var used bool
+			if err := db.QueryRow(`SELECT used_when IS NOT NULL FROM user_invite`).Scan(&used); err != nil {
+				t.Fatalf("failed to load the invite: %v", err)
+			}
+			if wantUsed := tt.wantErr == ""; used != wantUsed {
+				t.Errorf("invite used = %v, want %v", used, wantUsed)
+			}
+		})
+	}
+}
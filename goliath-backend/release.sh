@@ -71,26 +71,20 @@ echo "✅ Binary built successfully"
 
 # Create a temporary directory for deployment files
 TEMP_DIR=$(mktemp -d)
-mkdir -p "$TEMP_DIR/migrations"
 
 # Copy necessary files
 cp $BINARY_NAME "$TEMP_DIR/"
-cp -r migrations/* "$TEMP_DIR/migrations/"
 cp app-pm2.json "$TEMP_DIR/" 2>/dev/null || echo "Note: app-pm2.json not found, will be created on server"
 
 echo "📦 Syncing files to server..."
 
 # Create remote directory if it doesn't exist
-ssh $REMOTE_USER@$REMOTE_HOST "mkdir -p $REMOTE_PATH/migrations"
+ssh $REMOTE_USER@$REMOTE_HOST "mkdir -p $REMOTE_PATH"
 
 # Copy binary
 echo "Copying binary..."
 scp "$TEMP_DIR/$BINARY_NAME" $REMOTE_USER@$REMOTE_HOST:$REMOTE_PATH/
 
-# Copy migrations
-echo "Copying migrations..."
-scp "$TEMP_DIR/migrations/"* $REMOTE_USER@$REMOTE_HOST:$REMOTE_PATH/migrations/
-
 # Copy or create pm2 config
 if [ -f "$TEMP_DIR/app-pm2.json" ]; then
   echo "Copying pm2 config..."
@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// MuscleSetWork represents one completed set attributed to one of the muscles of its exercise
+type MuscleSetWork struct {
+	PerformedWhen   time.Time
+	MuscleID        int
+	MuscleName      string
+	MuscleGroupID   int
+	MuscleGroupName string
+	RegionID        int
+	RegionName      string
+	Percentage      float64
+	Reps            *int
+	TimeSeconds     *int
+	Weight          *float64
+}
+
+// AnalyticsRepository handles read-only analytics queries over performed workouts
+type AnalyticsRepository struct {
+	BaseRepository
+}
+
+// NewAnalyticsRepository creates a new AnalyticsRepository
+func NewAnalyticsRepository(db *sql.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{
+		BaseRepository: BaseRepository{db: db},
+	}
+}
+
+// GetMuscleWorkForUser retrieves every set of the user's finished sessions started in [from, to),
+// once for each muscle the set's exercise works, with the muscle's taxonomy
+func (r *AnalyticsRepository) GetMuscleWorkForUser(ctx context.Context, userID int, from time.Time, to time.Time) ([]MuscleSetWork, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT s.started_when, m.id, m.name, mg.id, mg.name, rg.id, rg.name,
+		       em.percentage, ss.reps, ss.time_seconds, ss.weight
+		FROM workout_session s
+		JOIN workout_session_set ss ON ss.session_id = s.id
+		JOIN exercise_muscle em ON em.exercise_id = ss.exercise_id
+		JOIN muscle m ON em.muscle_id = m.id
+		JOIN muscle_group mg ON m.muscle_group_id = mg.id
+		JOIN region rg ON mg.region_id = rg.id
+		WHERE s.user_id = ? AND s.finished_when IS NOT NULL
+		  AND s.started_when >= ? AND s.started_when < ?
+		ORDER BY s.started_when
+	`, userID, from.Format("2006-01-02 15:04:05"), to.Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	work := []MuscleSetWork{}
+	for rows.Next() {
+		var w MuscleSetWork
+		var performedWhen string
+		if err := rows.Scan(
+			&performedWhen,
+			&w.MuscleID,
+			&w.MuscleName,
+			&w.MuscleGroupID,
+			&w.MuscleGroupName,
+			&w.RegionID,
+			&w.RegionName,
+			&w.Percentage,
+			&w.Reps,
+			&w.TimeSeconds,
+			&w.Weight,
+		); err != nil {
+			return nil, err
+		}
+		w.PerformedWhen, _ = time.Parse("2006-01-02 15:04:05", performedWhen)
+		work = append(work, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return work, nil
+}
+
+// GetSetCountsByExerciseForUser counts the sets of the user's finished sessions started in [from, to) per exercise
+func (r *AnalyticsRepository) GetSetCountsByExerciseForUser(ctx context.Context, userID int, from time.Time, to time.Time) (map[int]int, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT ss.exercise_id, COUNT(*)
+		FROM workout_session s
+		JOIN workout_session_set ss ON ss.session_id = s.id
+		WHERE s.user_id = ? AND s.finished_when IS NOT NULL
+		  AND s.started_when >= ? AND s.started_when < ?
+		GROUP BY ss.exercise_id
+	`, userID, from.Format("2006-01-02 15:04:05"), to.Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var exerciseID, count int
+		if err := rows.Scan(&exerciseID, &count); err != nil {
+			return nil, err
+		}
+		counts[exerciseID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 
+	"goliath/apperrors"
 	"goliath/middleware"
 )
 
@@ -26,3 +27,23 @@ func (r *BaseRepository) GetExecutor(ctx context.Context) (middleware.DBExecutor
 	return tx, nil
 }
 
+// checkVersionedUpdate checks the result of an UPDATE guarded by the expected version
+// of the row, i.e. "WHERE id = ? AND (? = 0 OR version = ?)" where a version of 0 skips the check.
+// Handlers only pass 0 for If-Match: *; updates without any expected version are refused.
+// When no row was updated the row is either gone (sql.ErrNoRows) or was modified
+// since the caller read it (apperrors.ErrVersionConflict).
+func checkVersionedUpdate(ctx context.Context, executor middleware.DBExecutor, result sql.Result, table string, id int) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	var version int
+	if err := executor.QueryRowContext(ctx, "SELECT version FROM "+table+" WHERE id = ?", id).Scan(&version); err != nil {
+		return err
+	}
+	return apperrors.ErrVersionConflict
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"goliath/middleware"
+)
+
+// CalendarFeedRepository handles database operations for the iCalendar feed tokens of users
+type CalendarFeedRepository struct {
+	BaseRepository
+}
+
+// NewCalendarFeedRepository creates a new CalendarFeedRepository
+func NewCalendarFeedRepository(db *sql.DB) *CalendarFeedRepository {
+	return &CalendarFeedRepository{
+		BaseRepository: BaseRepository{db: db},
+	}
+}
+
+// GetUserIDByTokenHash retrieves the ID of the user whose feed token has the given hash
+func (r *CalendarFeedRepository) GetUserIDByTokenHash(ctx context.Context, tokenHash string) (int, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var userID int
+	err = executor.QueryRowContext(ctx, `
+		SELECT user_id FROM calendar_feed WHERE token_hash = ?
+	`, tokenHash).Scan(&userID)
+	return userID, err
+}
+
+// Save stores the token hash of a user's feed, replacing any previous token
+func (r *CalendarFeedRepository) Save(ctx context.Context, userID int, tokenHash string) error {
+	log.Printf("Starting to rotate calendar feed token for user %d", userID)
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Insert the feed or replace the token of the existing one
+	now := time.Now().Format("2006-01-02 15:04:05")
+	_, err = executor.ExecContext(ctx, `
+		INSERT INTO calendar_feed (version, created_by, modified_by, created_when, modified_when, user_id, token_hash)
+		VALUES (1, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET
+			token_hash = excluded.token_hash,
+			modified_by = excluded.modified_by,
+			modified_when = excluded.modified_when,
+			version = calendar_feed.version + 1
+	`, user.FirebaseUID, user.FirebaseUID, now, now, userID, tokenHash)
+	return err
+}
+
+// DeleteForUser revokes the feed of a user and reports whether there was one
+func (r *CalendarFeedRepository) DeleteForUser(ctx context.Context, userID int) (bool, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := executor.ExecContext(ctx, `DELETE FROM calendar_feed WHERE user_id = ?`, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
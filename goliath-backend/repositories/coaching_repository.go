@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"goliath/entities"
+	"goliath/middleware"
+)
+
+// CoachingRepository handles database operations for coaching relationships
+type CoachingRepository struct {
+	BaseRepository
+}
+
+// NewCoachingRepository creates a new CoachingRepository
+func NewCoachingRepository(db *sql.DB) *CoachingRepository {
+	return &CoachingRepository{
+		BaseRepository: BaseRepository{db: db},
+	}
+}
+
+// coachingQuery selects the columns read by entities.ScanCoaching
+const coachingQuery = `
+	SELECT c.id, c.version, c.created_when, c.created_by, c.modified_when, c.modified_by,
+	       c.coach_user_id, coach.email, c.athlete_user_id, athlete.email, c.status, c.accepted_when, c.ended_when
+	FROM coaching c
+	JOIN user coach ON coach.id = c.coach_user_id
+	JOIN user athlete ON athlete.id = c.athlete_user_id`
+
+// list retrieves the coachings matching a condition, open ones first, newest first
+func (r *CoachingRepository) list(ctx context.Context, condition string, arg interface{}) ([]entities.Coaching, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, coachingQuery+`
+		WHERE `+condition+`
+		ORDER BY c.status = 'Ended', c.id DESC
+	`, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	coachings := []entities.Coaching{}
+	for rows.Next() {
+		coaching, err := entities.ScanCoaching(rows)
+		if err != nil {
+			return nil, err
+		}
+		coachings = append(coachings, *coaching)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return coachings, nil
+}
+
+// ListForCoach retrieves the athletes a coach invited or coaches
+func (r *CoachingRepository) ListForCoach(ctx context.Context, coachID int) ([]entities.Coaching, error) {
+	return r.list(ctx, "c.coach_user_id = ?", coachID)
+}
+
+// ListForAthlete retrieves the coaches who invited or coach an athlete
+func (r *CoachingRepository) ListForAthlete(ctx context.Context, athleteID int) ([]entities.Coaching, error) {
+	return r.list(ctx, "c.athlete_user_id = ?", athleteID)
+}
+
+// GetByID retrieves a single coaching by ID
+func (r *CoachingRepository) GetByID(ctx context.Context, id int) (*entities.Coaching, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return entities.ScanCoaching(executor.QueryRowContext(ctx, coachingQuery+`
+		WHERE c.id = ?
+	`, id))
+}
+
+// HasOpen reports whether a coach already invited or coaches an athlete
+func (r *CoachingRepository) HasOpen(ctx context.Context, coachID int, athleteID int) (bool, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var exists bool
+	err = executor.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM coaching WHERE coach_user_id = ? AND athlete_user_id = ? AND status != 'Ended')
+	`, coachID, athleteID).Scan(&exists)
+	return exists, err
+}
+
+// IsCoaching reports whether a user currently coaches an athlete: the athlete accepted, and the
+// role of the coach still grants the permission to coach
+func (r *CoachingRepository) IsCoaching(ctx context.Context, coachID int, athleteID int) (bool, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var exists bool
+	err = executor.QueryRowContext(ctx, `
+		SELECT EXISTS(
+			SELECT 1
+			FROM coaching c
+			JOIN user coach ON coach.id = c.coach_user_id
+			JOIN role r ON r.name = coach.role
+			JOIN role_permission rp ON rp.role_id = r.id
+			WHERE c.coach_user_id = ? AND c.athlete_user_id = ? AND c.status = 'Active'
+			  AND rp.permission = ?
+		)
+	`, coachID, athleteID, entities.PermissionAthleteCoach).Scan(&exists)
+	return exists, err
+}
+
+// Create invites an athlete to be coached
+func (r *CoachingRepository) Create(ctx context.Context, coachID int, athleteID int) (int64, error) {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return 0, ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		INSERT INTO coaching (version, created_by, modified_by, created_when, modified_when, coach_user_id, athlete_user_id)
+		VALUES (1, ?, ?, ?, ?, ?, ?)
+	`, user.FirebaseUID, user.FirebaseUID, now, now, coachID, athleteID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// UpdateStatus moves a coaching to another status, setting accepted_when or ended_when
+func (r *CoachingRepository) UpdateStatus(ctx context.Context, id int, status entities.CoachingStatus) error {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	_, err = executor.ExecContext(ctx, `
+		UPDATE coaching
+		SET status = ?,
+		    accepted_when = CASE WHEN ? = 'Active' THEN ? ELSE accepted_when END,
+		    ended_when = CASE WHEN ? = 'Ended' THEN ? ELSE ended_when END,
+		    modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ?
+	`, status, status, now, status, now, user.FirebaseUID, now, id)
+	return err
+}
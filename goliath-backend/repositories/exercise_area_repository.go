@@ -3,8 +3,10 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"goliath/entities"
+	"goliath/middleware"
 )
 
 // ExerciseAreaRepository handles database operations for exercise areas
@@ -26,8 +28,8 @@ func (r *ExerciseAreaRepository) GetAll(ctx context.Context) ([]entities.Exercis
 		return nil, err
 	}
 	rows, err := executor.QueryContext(ctx, `
-		SELECT id, version, created_when, created_by, modified_when, modified_by, name 
-		FROM exercise_area 
+		SELECT id, version, created_when, created_by, modified_when, modified_by, name, plane, direction
+		FROM exercise_area
 		ORDER BY id
 	`)
 	if err != nil {
@@ -51,6 +53,31 @@ func (r *ExerciseAreaRepository) GetAll(ctx context.Context) ([]entities.Exercis
 	return exerciseAreas, nil
 }
 
+// ExerciseAreaListSpec describes the sorts and filters of the exercise area list
+var ExerciseAreaListSpec = ListSpec{
+	From:        "exercise_area ea",
+	IDColumn:    "ea.id",
+	Sorts:       map[string][]string{"id": {"ea.id"}, "name": {"ea.name"}},
+	DefaultSort: "id",
+	Filters:     map[string]string{"name": "ea.name"},
+}
+
+// List retrieves one page of exercise areas
+func (r *ExerciseAreaRepository) List(ctx context.Context, params ListParams) ([]entities.ExerciseArea, string, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return listPage(ctx, executor, ExerciseAreaListSpec, params, `
+		SELECT ea.id, ea.version, ea.created_when, ea.created_by, ea.modified_when, ea.modified_by, ea.name, ea.plane, ea.direction
+		FROM exercise_area ea
+		WHERE 1 = 1`, nil,
+		entities.ScanExerciseArea,
+		func(ea *entities.ExerciseArea) int { return ea.ID },
+	)
+}
+
 // GetByMuscleID retrieves exercise areas for a specific muscle
 func (r *ExerciseAreaRepository) GetByMuscleID(ctx context.Context, muscleID int) ([]string, error) {
 	executor, err := r.GetExecutor(ctx)
@@ -119,3 +146,102 @@ func (r *ExerciseAreaRepository) GetAllForMuscles(ctx context.Context) (map[int]
 	return muscleAreasMap, nil
 }
 
+// GetByID retrieves an exercise area by ID
+func (r *ExerciseAreaRepository) GetByID(ctx context.Context, id int) (*entities.ExerciseArea, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT id, version, created_when, created_by, modified_when, modified_by, name, plane, direction
+		FROM exercise_area
+		WHERE id = ?
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, sql.ErrNoRows
+	}
+	return entities.ScanExerciseArea(rows)
+}
+
+// NameExists checks if an exercise area with the given name exists (case-insensitive)
+func (r *ExerciseAreaRepository) NameExists(ctx context.Context, name string) (bool, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return false, err
+	}
+	var count int
+	err = executor.QueryRowContext(ctx, "SELECT COUNT(*) FROM exercise_area WHERE LOWER(name) = LOWER(?)", name).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Create creates a new exercise area
+func (r *ExerciseAreaRepository) Create(ctx context.Context, name string, plane *entities.MovementPlane, direction *entities.MovementDirection) (int64, error) {
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return 0, ErrUserRequired
+	}
+
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		INSERT INTO exercise_area (version, created_by, modified_by, created_when, modified_when, name, plane, direction)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?)
+	`, user.FirebaseUID, user.FirebaseUID, now, now, name, plane, direction)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// Update renames an exercise area and sets its movement
+// A non-zero version must match the stored version, otherwise apperrors.ErrVersionConflict is returned
+func (r *ExerciseAreaRepository) Update(ctx context.Context, id int, version int, name string, plane *entities.MovementPlane, direction *entities.MovementDirection) error {
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		UPDATE exercise_area
+		SET name = ?, plane = ?, direction = ?, modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, name, plane, direction, user.FirebaseUID, now, id, version, version)
+	if err != nil {
+		return err
+	}
+	return checkVersionedUpdate(ctx, executor, result, "exercise_area", id)
+}
+
+// Delete deletes an exercise area together with its muscle links
+func (r *ExerciseAreaRepository) Delete(ctx context.Context, id int) error {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = executor.ExecContext(ctx, "DELETE FROM exercise_area WHERE id = ?", id)
+	return err
+}
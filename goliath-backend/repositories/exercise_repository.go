@@ -26,16 +26,81 @@ func NewExerciseRepository(db *sql.DB) *ExerciseRepository {
 	}
 }
 
-// GetAll retrieves all exercises from the database
+// ExerciseListSpec describes the sorts and filters of the exercise list
+var ExerciseListSpec = ListSpec{
+	From:        "exercise e",
+	IDColumn:    "e.id",
+	Sorts:       map[string][]string{"type": {"e.type", "e.name"}, "name": {"e.name"}},
+	DefaultSort: "type",
+	Filters:     map[string]string{"type": "e.type", "name": "e.name"},
+}
+
+// List retrieves one page of exercises, leaving out archived ones unless includeArchived is set
+func (r *ExerciseRepository) List(ctx context.Context, params ListParams, includeArchived bool) ([]entities.Exercise, string, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	where := "e.archived_when IS NULL"
+	if includeArchived {
+		where = "1 = 1"
+	}
+
+	return listPage(ctx, executor, ExerciseListSpec, params, `
+		SELECT e.id, e.version, e.created_when, e.created_by, e.modified_when, e.modified_by, e.name, e.type, e.archived_when
+		FROM exercise e
+		WHERE `+where, nil,
+		entities.ScanExercise,
+		func(exercise *entities.Exercise) int { return exercise.ID },
+	)
+}
+
+// GetAllActive retrieves all exercises that are not archived, ordered by name
+func (r *ExerciseRepository) GetAllActive(ctx context.Context) ([]entities.Exercise, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT id, version, created_when, created_by, modified_when, modified_by, name, type, archived_when
+		FROM exercise
+		WHERE archived_when IS NULL
+		ORDER BY name ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exercises := []entities.Exercise{}
+	for rows.Next() {
+		exercise, err := entities.ScanExercise(rows)
+		if err != nil {
+			return nil, err
+		}
+		exercises = append(exercises, *exercise)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return exercises, nil
+}
+
+// GetAll retrieves all exercises including archived ones, ordered by name
 func (r *ExerciseRepository) GetAll(ctx context.Context) ([]entities.Exercise, error) {
 	executor, err := r.GetExecutor(ctx)
 	if err != nil {
 		return nil, err
 	}
+
 	rows, err := executor.QueryContext(ctx, `
-		SELECT id, version, created_when, created_by, modified_when, modified_by, name, type
+		SELECT id, version, created_when, created_by, modified_when, modified_by, name, type, archived_when
 		FROM exercise
-		ORDER BY type, name
+		ORDER BY name ASC
 	`)
 	if err != nil {
 		return nil, err
@@ -66,7 +131,7 @@ func (r *ExerciseRepository) GetByID(ctx context.Context, id int) (*entities.Exe
 	}
 	
 	row := executor.QueryRowContext(ctx, `
-		SELECT id, version, created_when, created_by, modified_when, modified_by, name, type
+		SELECT id, version, created_when, created_by, modified_when, modified_by, name, type, archived_when
 		FROM exercise
 		WHERE id = ?
 	`, id)
@@ -74,6 +139,7 @@ func (r *ExerciseRepository) GetByID(ctx context.Context, id int) (*entities.Exe
 	var exercise entities.Exercise
 	var createdWhen, modifiedWhen string
 	var exerciseType string
+	var archivedWhen *string
 	err = row.Scan(
 		&exercise.ID,
 		&exercise.Version,
@@ -83,6 +149,7 @@ func (r *ExerciseRepository) GetByID(ctx context.Context, id int) (*entities.Exe
 		&exercise.ModifiedBy,
 		&exercise.Name,
 		&exerciseType,
+		&archivedWhen,
 	)
 	if err != nil {
 		return nil, err
@@ -91,6 +158,10 @@ func (r *ExerciseRepository) GetByID(ctx context.Context, id int) (*entities.Exe
 	exercise.CreatedWhen, _ = time.Parse("2006-01-02 15:04:05", createdWhen)
 	exercise.ModifiedWhen, _ = time.Parse("2006-01-02 15:04:05", modifiedWhen)
 	exercise.Type = entities.ExerciseType(exerciseType)
+	if archivedWhen != nil {
+		archived, _ := time.Parse("2006-01-02 15:04:05", *archivedWhen)
+		exercise.ArchivedWhen = &archived
+	}
 	exercise.Muscles = []entities.ExerciseMuscle{}
 	exercise.ExerciseAreas = []entities.ExerciseAreaSummary{}
 	
@@ -286,7 +357,8 @@ func (r *ExerciseRepository) Create(ctx context.Context, name string, exerciseTy
 
 // Update updates an existing exercise with associated muscles in a transaction
 // This method requires a transaction to be present in the context (from Transaction middleware)
-func (r *ExerciseRepository) Update(ctx context.Context, id int, name string, exerciseType entities.ExerciseType, muscles []MuscleInput) error {
+// A non-zero version must match the stored version, otherwise apperrors.ErrVersionConflict is returned
+func (r *ExerciseRepository) Update(ctx context.Context, id int, version int, name string, exerciseType entities.ExerciseType, muscles []MuscleInput) error {
 	log.Printf("Starting to update exercise %d", id)
 	
 	// Get user from context
@@ -305,14 +377,17 @@ func (r *ExerciseRepository) Update(ctx context.Context, id int, name string, ex
 	
 	// Update exercise
 	now := time.Now().Format("2006-01-02 15:04:05")
-	_, err = executor.ExecContext(ctx, `
-		UPDATE exercise 
+	result, err := executor.ExecContext(ctx, `
+		UPDATE exercise
 		SET name = ?, type = ?, modified_by = ?, modified_when = ?, version = version + 1
-		WHERE id = ?
-	`, name, exerciseType, user.FirebaseUID, now, id)
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, name, exerciseType, user.FirebaseUID, now, id, version, version)
 	if err != nil {
 		return err
 	}
+	if err := checkVersionedUpdate(ctx, executor, result, "exercise", id); err != nil {
+		return err
+	}
 
 	// Delete existing exercise muscles
 	_, err = executor.ExecContext(ctx, `DELETE FROM exercise_muscle WHERE exercise_id = ?`, id)
@@ -333,3 +408,89 @@ func (r *ExerciseRepository) Update(ctx context.Context, id int, name string, ex
 
 	return nil
 }
+
+// SetArchived archives (soft-deletes) or restores an exercise
+func (r *ExerciseRepository) SetArchived(ctx context.Context, id int, archived bool) error {
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	var archivedWhen interface{}
+	if archived {
+		archivedWhen = now
+	}
+
+	_, err = executor.ExecContext(ctx, `
+		UPDATE exercise
+		SET archived_when = ?, modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ?
+	`, archivedWhen, user.FirebaseUID, now, id)
+	return err
+}
+
+// Merge repoints everything referencing the source exercise to the target exercise and
+// deletes the source. Muscle mappings the target already has keep the target's percentage.
+// Returns the IDs of the users whose performed sets were moved.
+// This method requires a transaction to be present in the context (from Transaction middleware)
+func (r *ExerciseRepository) Merge(ctx context.Context, sourceID int, targetID int) ([]int, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Users with performed sets of the source, whose personal records change
+	rows, err := executor.QueryContext(ctx, `
+		SELECT DISTINCT s.user_id
+		FROM workout_session_set ss
+		JOIN workout_session s ON ss.session_id = s.id
+		WHERE ss.exercise_id = ?
+		ORDER BY s.user_id
+	`, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := []int{}
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, err
+	}
+	rows.Close()
+
+	statements := []string{
+		`UPDATE workout_exercise SET exercise_id = ?, version = version + 1 WHERE exercise_id = ?`,
+		`UPDATE workout_session_set SET exercise_id = ? WHERE exercise_id = ?`,
+		`UPDATE OR IGNORE exercise_muscle SET exercise_id = ? WHERE exercise_id = ?`,
+	}
+	for _, statement := range statements {
+		if _, err := executor.ExecContext(ctx, statement, targetID, sourceID); err != nil {
+			return nil, err
+		}
+	}
+
+	// The target may have gained muscle mappings
+	if _, err := executor.ExecContext(ctx, `UPDATE exercise SET version = version + 1 WHERE id = ?`, targetID); err != nil {
+		return nil, err
+	}
+
+	// Remaining source rows: duplicate muscle mappings and personal records that are recomputed
+	if _, err := executor.ExecContext(ctx, `DELETE FROM exercise WHERE id = ?`, sourceID); err != nil {
+		return nil, err
+	}
+
+	return userIDs, nil
+}
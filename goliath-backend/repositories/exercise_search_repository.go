@@ -0,0 +1,285 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"goliath/apperrors"
+	"goliath/entities"
+)
+
+// Exercise search sort orders
+const (
+	ExerciseSortRelevance = "relevance" // Best full-text match first; requires a query
+	ExerciseSortName      = "name"
+	ExerciseSortNameDesc  = "-name"
+	ExerciseSortType      = "type"
+)
+
+// ExerciseSearchCursor holds the sort key of the last exercise of a page
+type ExerciseSearchCursor struct {
+	ID   int     `json:"id"`
+	Name string  `json:"name,omitempty"`
+	Type string  `json:"type,omitempty"`
+	Rank float64 `json:"rank,omitempty"`
+}
+
+// ExerciseSearchParams represents the filters, sort order and page of an exercise search
+type ExerciseSearchParams struct {
+	Query          string
+	Types          []string
+	MuscleID       *int
+	MuscleGroupID  *int
+	RegionID       *int
+	ExerciseAreaID *int
+	MinPercentage  float64 // Minimum involvement of the muscle/group/region/area filters
+	Sort           string
+	Cursor         *ExerciseSearchCursor
+	Limit          int
+}
+
+// ExerciseSearchHit represents an exercise found by a search with its sort key
+type ExerciseSearchHit struct {
+	Exercise entities.Exercise
+	Cursor   ExerciseSearchCursor
+}
+
+// ftsQuery turns free text into an FTS5 query matching every word as a prefix
+func ftsQuery(text string) string {
+	terms := []string{}
+	for _, word := range strings.Fields(text) {
+		word = strings.ReplaceAll(word, `"`, `""`)
+		terms = append(terms, `"`+word+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// exerciseSearchFrom builds the FROM/WHERE clause shared by searches and facet counts
+func exerciseSearchFrom(params ExerciseSearchParams) (string, []interface{}) {
+	var sb strings.Builder
+	args := []interface{}{}
+
+	sb.WriteString(" FROM exercise e")
+	if params.Query != "" {
+		sb.WriteString(" JOIN exercise_fts ON exercise_fts.rowid = e.id AND exercise_fts MATCH ?")
+		args = append(args, ftsQuery(params.Query))
+	}
+	sb.WriteString(" WHERE e.archived_when IS NULL")
+
+	if len(params.Types) > 0 {
+		sb.WriteString(" AND e.type IN (?" + strings.Repeat(", ?", len(params.Types)-1) + ")")
+		for _, t := range params.Types {
+			args = append(args, t)
+		}
+	}
+	if params.MuscleID != nil {
+		sb.WriteString(` AND EXISTS (
+			SELECT 1 FROM exercise_muscle em
+			WHERE em.exercise_id = e.id AND em.muscle_id = ? AND em.percentage >= ?)`)
+		args = append(args, *params.MuscleID, params.MinPercentage)
+	}
+	if params.MuscleGroupID != nil {
+		sb.WriteString(` AND EXISTS (
+			SELECT 1 FROM exercise_muscle em JOIN muscle m ON em.muscle_id = m.id
+			WHERE em.exercise_id = e.id AND m.muscle_group_id = ? AND em.percentage >= ?)`)
+		args = append(args, *params.MuscleGroupID, params.MinPercentage)
+	}
+	if params.RegionID != nil {
+		sb.WriteString(` AND EXISTS (
+			SELECT 1 FROM exercise_muscle em JOIN muscle m ON em.muscle_id = m.id
+			JOIN muscle_group mg ON m.muscle_group_id = mg.id
+			WHERE em.exercise_id = e.id AND mg.region_id = ? AND em.percentage >= ?)`)
+		args = append(args, *params.RegionID, params.MinPercentage)
+	}
+	if params.ExerciseAreaID != nil {
+		// Same aggregation as GetExerciseAreasForAllExercises: average percentage of the area's muscles
+		sb.WriteString(` AND e.id IN (
+			SELECT em.exercise_id FROM exercise_muscle em
+			JOIN muscle_exercise_area mea ON em.muscle_id = mea.muscle_id
+			WHERE mea.exercise_area_id = ?
+			GROUP BY em.exercise_id
+			HAVING AVG(em.percentage) >= ?)`)
+		args = append(args, *params.ExerciseAreaID, params.MinPercentage)
+	}
+
+	return sb.String(), args
+}
+
+// Search finds exercises matching the search parameters, returning at most Limit+1 hits
+// so callers can tell whether another page follows
+func (r *ExerciseRepository) Search(ctx context.Context, params ExerciseSearchParams) ([]ExerciseSearchHit, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rankExpr := "0.0"
+	if params.Query != "" {
+		rankExpr = "bm25(exercise_fts)"
+	}
+
+	from, args := exerciseSearchFrom(params)
+	query := `SELECT e.id, e.version, e.created_when, e.created_by, e.modified_when, e.modified_by, e.name, e.type, ` +
+		rankExpr + ` AS rank` + from
+
+	// Keyset pagination on the sort key
+	var orderBy string
+	c := params.Cursor
+	switch params.Sort {
+	case ExerciseSortRelevance:
+		orderBy = rankExpr + " ASC, e.id ASC"
+		if c != nil {
+			query += " AND (" + rankExpr + " > ? OR (" + rankExpr + " = ? AND e.id > ?))"
+			args = append(args, c.Rank, c.Rank, c.ID)
+		}
+	case ExerciseSortNameDesc:
+		orderBy = "e.name DESC, e.id DESC"
+		if c != nil {
+			query += " AND (e.name < ? OR (e.name = ? AND e.id < ?))"
+			args = append(args, c.Name, c.Name, c.ID)
+		}
+	case ExerciseSortType:
+		orderBy = "e.type ASC, e.name ASC, e.id ASC"
+		if c != nil {
+			query += " AND (e.type > ? OR (e.type = ? AND (e.name > ? OR (e.name = ? AND e.id > ?))))"
+			args = append(args, c.Type, c.Type, c.Name, c.Name, c.ID)
+		}
+	case ExerciseSortName:
+		orderBy = "e.name ASC, e.id ASC"
+		if c != nil {
+			query += " AND (e.name > ? OR (e.name = ? AND e.id > ?))"
+			args = append(args, c.Name, c.Name, c.ID)
+		}
+	default:
+		return nil, apperrors.Invalid("invalid_sort", "invalid sort: %s", params.Sort)
+	}
+	query += " ORDER BY " + orderBy + " LIMIT ?"
+	args = append(args, params.Limit+1)
+
+	rows, err := executor.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := []ExerciseSearchHit{}
+	for rows.Next() {
+		var e entities.Exercise
+		var createdWhen, modifiedWhen, exerciseType string
+		var rank float64
+		if err := rows.Scan(&e.ID, &e.Version, &createdWhen, &e.CreatedBy, &modifiedWhen, &e.ModifiedBy, &e.Name, &exerciseType, &rank); err != nil {
+			return nil, err
+		}
+		e.CreatedWhen, _ = time.Parse("2006-01-02 15:04:05", createdWhen)
+		e.ModifiedWhen, _ = time.Parse("2006-01-02 15:04:05", modifiedWhen)
+		e.Type = entities.ExerciseType(exerciseType)
+		e.ExerciseAreas = []entities.ExerciseAreaSummary{}
+
+		hit := ExerciseSearchHit{
+			Exercise: e,
+			Cursor:   ExerciseSearchCursor{ID: e.ID, Name: e.Name, Type: exerciseType, Rank: rank},
+		}
+		hits = append(hits, hit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return hits, nil
+}
+
+// facetCounts runs a facet count query over the exercises matching the search
+func (r *ExerciseRepository) facetCounts(ctx context.Context, params ExerciseSearchParams, query string, args ...interface{}) ([]entities.FacetCount, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	from, matchArgs := exerciseSearchFrom(params)
+	rows, err := executor.QueryContext(ctx, "WITH matched AS (SELECT e.id"+from+") "+query, append(matchArgs, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []entities.FacetCount{}
+	for rows.Next() {
+		var fc entities.FacetCount
+		if err := rows.Scan(&fc.ID, &fc.Name, &fc.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, fc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// SearchFacets counts the exercises matching the search per type, muscle, muscle group,
+// region and exercise area. Involvement counts only when it reaches MinPercentage.
+func (r *ExerciseRepository) SearchFacets(ctx context.Context, params ExerciseSearchParams) (*entities.ExerciseFacets, error) {
+	facets := &entities.ExerciseFacets{}
+	var err error
+
+	facets.Types, err = r.facetCounts(ctx, params, `
+		SELECT 0, e.type, COUNT(*)
+		FROM exercise e JOIN matched ON matched.id = e.id
+		GROUP BY e.type ORDER BY e.type`)
+	if err != nil {
+		return nil, err
+	}
+
+	facets.Muscles, err = r.facetCounts(ctx, params, `
+		SELECT m.id, m.name, COUNT(DISTINCT em.exercise_id)
+		FROM exercise_muscle em JOIN matched ON matched.id = em.exercise_id
+		JOIN muscle m ON em.muscle_id = m.id
+		WHERE em.percentage >= ?
+		GROUP BY m.id, m.name ORDER BY m.id`, params.MinPercentage)
+	if err != nil {
+		return nil, err
+	}
+
+	facets.MuscleGroups, err = r.facetCounts(ctx, params, `
+		SELECT mg.id, mg.name, COUNT(DISTINCT em.exercise_id)
+		FROM exercise_muscle em JOIN matched ON matched.id = em.exercise_id
+		JOIN muscle m ON em.muscle_id = m.id
+		JOIN muscle_group mg ON m.muscle_group_id = mg.id
+		WHERE em.percentage >= ?
+		GROUP BY mg.id, mg.name ORDER BY mg.id`, params.MinPercentage)
+	if err != nil {
+		return nil, err
+	}
+
+	facets.Regions, err = r.facetCounts(ctx, params, `
+		SELECT rg.id, rg.name, COUNT(DISTINCT em.exercise_id)
+		FROM exercise_muscle em JOIN matched ON matched.id = em.exercise_id
+		JOIN muscle m ON em.muscle_id = m.id
+		JOIN muscle_group mg ON m.muscle_group_id = mg.id
+		JOIN region rg ON mg.region_id = rg.id
+		WHERE em.percentage >= ?
+		GROUP BY rg.id, rg.name ORDER BY rg.id`, params.MinPercentage)
+	if err != nil {
+		return nil, err
+	}
+
+	facets.ExerciseAreas, err = r.facetCounts(ctx, params, `
+		SELECT area_id, area_name, COUNT(*) FROM (
+			SELECT ea.id AS area_id, ea.name AS area_name, em.exercise_id
+			FROM exercise_muscle em JOIN matched ON matched.id = em.exercise_id
+			JOIN muscle_exercise_area mea ON em.muscle_id = mea.muscle_id
+			JOIN exercise_area ea ON mea.exercise_area_id = ea.id
+			GROUP BY ea.id, ea.name, em.exercise_id
+			HAVING AVG(em.percentage) >= ?
+		)
+		GROUP BY area_id, area_name ORDER BY area_id`, params.MinPercentage)
+	if err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
@@ -3,8 +3,10 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"goliath/entities"
+	"goliath/middleware"
 )
 
 // MuscleGroupRepository handles database operations for muscle groups
@@ -19,37 +21,145 @@ func NewMuscleGroupRepository(db *sql.DB) *MuscleGroupRepository {
 	}
 }
 
-// GetAll retrieves all muscle groups from the database with region information
-func (r *MuscleGroupRepository) GetAll(ctx context.Context) ([]entities.MuscleGroup, error) {
+// MuscleGroupListSpec describes the sorts and filters of the muscle group list
+var MuscleGroupListSpec = ListSpec{
+	From:        "muscle_group mg",
+	IDColumn:    "mg.id",
+	Sorts:       map[string][]string{"region": {"mg.region_id"}, "name": {"mg.name"}},
+	DefaultSort: "region",
+	Filters:     map[string]string{"region_id": "mg.region_id", "name": "mg.name"},
+}
+
+// List retrieves one page of muscle groups with region information
+func (r *MuscleGroupRepository) List(ctx context.Context, params ListParams) ([]entities.MuscleGroup, string, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return listPage(ctx, executor, MuscleGroupListSpec, params, `
+		SELECT mg.id, mg.version, mg.created_when, mg.created_by, mg.modified_when, mg.modified_by,
+		       mg.name, mg.region_id, r.name as region_name
+		FROM muscle_group mg
+		JOIN region r ON mg.region_id = r.id
+		WHERE 1 = 1`, nil,
+		func(rows *sql.Rows) (*entities.MuscleGroup, error) { return entities.ScanMuscleGroup(rows, true) },
+		func(mg *entities.MuscleGroup) int { return mg.ID },
+	)
+}
+
+// GetByID retrieves a muscle group by ID with region information
+func (r *MuscleGroupRepository) GetByID(ctx context.Context, id int) (*entities.MuscleGroup, error) {
 	executor, err := r.GetExecutor(ctx)
 	if err != nil {
 		return nil, err
 	}
+
 	rows, err := executor.QueryContext(ctx, `
-		SELECT mg.id, mg.version, mg.created_when, mg.created_by, mg.modified_when, mg.modified_by, 
+		SELECT mg.id, mg.version, mg.created_when, mg.created_by, mg.modified_when, mg.modified_by,
 		       mg.name, mg.region_id, r.name as region_name
 		FROM muscle_group mg
 		JOIN region r ON mg.region_id = r.id
-		ORDER BY mg.region_id, mg.id
-	`)
+		WHERE mg.id = ?
+	`, id)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	muscleGroups := []entities.MuscleGroup{}
-	for rows.Next() {
-		mg, err := entities.ScanMuscleGroup(rows, true)
-		if err != nil {
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
 			return nil, err
 		}
-		muscleGroups = append(muscleGroups, *mg)
+		return nil, sql.ErrNoRows
 	}
+	return entities.ScanMuscleGroup(rows, true)
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+// NameExists checks if a muscle group with the given name exists (case-insensitive)
+func (r *MuscleGroupRepository) NameExists(ctx context.Context, name string) (bool, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return false, err
+	}
+	var count int
+	err = executor.QueryRowContext(ctx, "SELECT COUNT(*) FROM muscle_group WHERE LOWER(name) = LOWER(?)", name).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CountMuscles counts the muscles of a muscle group
+func (r *MuscleGroupRepository) CountMuscles(ctx context.Context, id int) (int, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	err = executor.QueryRowContext(ctx, "SELECT COUNT(*) FROM muscle WHERE muscle_group_id = ?", id).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Create creates a new muscle group in a region
+func (r *MuscleGroupRepository) Create(ctx context.Context, name string, regionID int) (int64, error) {
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return 0, ErrUserRequired
+	}
+
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
 	}
 
-	return muscleGroups, nil
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		INSERT INTO muscle_group (version, created_by, modified_by, created_when, modified_when, name, region_id)
+		VALUES (1, ?, ?, ?, ?, ?, ?)
+	`, user.FirebaseUID, user.FirebaseUID, now, now, name, regionID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
 }
 
+// Update renames a muscle group and moves it to a region
+// A non-zero version must match the stored version, otherwise apperrors.ErrVersionConflict is returned
+func (r *MuscleGroupRepository) Update(ctx context.Context, id int, version int, name string, regionID int) error {
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		UPDATE muscle_group
+		SET name = ?, region_id = ?, modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, name, regionID, user.FirebaseUID, now, id, version, version)
+	if err != nil {
+		return err
+	}
+	return checkVersionedUpdate(ctx, executor, result, "muscle_group", id)
+}
+
+// Delete deletes a muscle group; fails while muscles still reference it (ON DELETE RESTRICT)
+func (r *MuscleGroupRepository) Delete(ctx context.Context, id int) error {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = executor.ExecContext(ctx, "DELETE FROM muscle_group WHERE id = ?", id)
+	return err
+}
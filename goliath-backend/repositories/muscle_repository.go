@@ -3,8 +3,10 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"goliath/entities"
+	"goliath/middleware"
 )
 
 // MuscleRepository handles database operations for muscles
@@ -53,3 +55,194 @@ func (r *MuscleRepository) GetAll(ctx context.Context) ([]entities.Muscle, error
 	return muscles, nil
 }
 
+// MuscleListSpec describes the sorts and filters of the muscle list
+var MuscleListSpec = ListSpec{
+	From:        "muscle m",
+	IDColumn:    "m.id",
+	Sorts:       map[string][]string{"muscle_group": {"m.muscle_group_id"}, "name": {"m.name"}},
+	DefaultSort: "muscle_group",
+	Filters:     map[string]string{"muscle_group_id": "m.muscle_group_id", "name": "m.name"},
+}
+
+// List retrieves one page of muscles with muscle group information
+func (r *MuscleRepository) List(ctx context.Context, params ListParams) ([]entities.Muscle, string, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return listPage(ctx, executor, MuscleListSpec, params, `
+		SELECT m.id, m.version, m.created_when, m.created_by, m.modified_when, m.modified_by,
+		       m.name, m.muscle_group_id, mg.name as muscle_group_name
+		FROM muscle m
+		JOIN muscle_group mg ON m.muscle_group_id = mg.id
+		WHERE 1 = 1`, nil,
+		func(rows *sql.Rows) (*entities.Muscle, error) { return entities.ScanMuscle(rows, true) },
+		func(m *entities.Muscle) int { return m.ID },
+	)
+}
+
+// GetByID retrieves a muscle by ID with muscle group information
+func (r *MuscleRepository) GetByID(ctx context.Context, id int) (*entities.Muscle, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT m.id, m.version, m.created_when, m.created_by, m.modified_when, m.modified_by,
+		       m.name, m.muscle_group_id, mg.name as muscle_group_name
+		FROM muscle m
+		JOIN muscle_group mg ON m.muscle_group_id = mg.id
+		WHERE m.id = ?
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, sql.ErrNoRows
+	}
+	return entities.ScanMuscle(rows, true)
+}
+
+// NameExists checks if a muscle with the given name exists (case-insensitive)
+func (r *MuscleRepository) NameExists(ctx context.Context, name string) (bool, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return false, err
+	}
+	var count int
+	err = executor.QueryRowContext(ctx, "SELECT COUNT(*) FROM muscle WHERE LOWER(name) = LOWER(?)", name).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CountExercises counts the exercises working a muscle
+func (r *MuscleRepository) CountExercises(ctx context.Context, id int) (int, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	err = executor.QueryRowContext(ctx, "SELECT COUNT(*) FROM exercise_muscle WHERE muscle_id = ?", id).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Create creates a new muscle in a muscle group
+func (r *MuscleRepository) Create(ctx context.Context, name string, muscleGroupID int) (int64, error) {
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return 0, ErrUserRequired
+	}
+
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		INSERT INTO muscle (version, created_by, modified_by, created_when, modified_when, name, muscle_group_id)
+		VALUES (1, ?, ?, ?, ?, ?, ?)
+	`, user.FirebaseUID, user.FirebaseUID, now, now, name, muscleGroupID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// Update renames a muscle and moves it to a muscle group
+// A non-zero version must match the stored version, otherwise apperrors.ErrVersionConflict is returned
+func (r *MuscleRepository) Update(ctx context.Context, id int, version int, name string, muscleGroupID int) error {
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		UPDATE muscle
+		SET name = ?, muscle_group_id = ?, modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, name, muscleGroupID, user.FirebaseUID, now, id, version, version)
+	if err != nil {
+		return err
+	}
+	return checkVersionedUpdate(ctx, executor, result, "muscle", id)
+}
+
+// Delete deletes a muscle together with its exercise area links
+func (r *MuscleRepository) Delete(ctx context.Context, id int) error {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = executor.ExecContext(ctx, "DELETE FROM muscle WHERE id = ?", id)
+	return err
+}
+
+// IsLinkedToExerciseArea checks if a muscle is linked to an exercise area
+func (r *MuscleRepository) IsLinkedToExerciseArea(ctx context.Context, muscleID int, exerciseAreaID int) (bool, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return false, err
+	}
+	var count int
+	err = executor.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM muscle_exercise_area WHERE muscle_id = ? AND exercise_area_id = ?
+	`, muscleID, exerciseAreaID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// LinkExerciseArea links a muscle to an exercise area
+func (r *MuscleRepository) LinkExerciseArea(ctx context.Context, muscleID int, exerciseAreaID int) error {
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	_, err = executor.ExecContext(ctx, `
+		INSERT INTO muscle_exercise_area (muscle_id, exercise_area_id, created_when, created_by)
+		VALUES (?, ?, ?, ?)
+	`, muscleID, exerciseAreaID, now, user.FirebaseUID)
+	return err
+}
+
+// UnlinkExerciseArea removes the link between a muscle and an exercise area
+func (r *MuscleRepository) UnlinkExerciseArea(ctx context.Context, muscleID int, exerciseAreaID int) error {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = executor.ExecContext(ctx, `
+		DELETE FROM muscle_exercise_area WHERE muscle_id = ? AND exercise_area_id = ?
+	`, muscleID, exerciseAreaID)
+	return err
+}
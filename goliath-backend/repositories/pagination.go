@@ -0,0 +1,252 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"goliath/apperrors"
+	"goliath/middleware"
+)
+
+// Page sizes of list queries
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 200
+)
+
+// ErrInvalidCursor is returned when a list cursor cannot be decoded or belongs to another sort order
+var ErrInvalidCursor = apperrors.Invalid("invalid_cursor", "invalid cursor")
+
+// ListSpec describes how a list query can be sorted and filtered.
+// Only whitelisted sorts and filters are ever turned into SQL.
+type ListSpec struct {
+	From        string              // FROM clause of the list query, used to look up the sort key of the last item
+	IDColumn    string              // Unique column breaking ties between equal sort keys, e.g. "w.id"
+	Sorts       map[string][]string // Sort name -> columns; prefix the name with '-' to sort descending
+	DefaultSort string
+	Filters     map[string]string // Filter name -> column, matched exactly
+}
+
+// ListParams represents the requested page, sort order and filters of a list query
+type ListParams struct {
+	Limit   int
+	Sort    string // Empty for the default sort of the list
+	Cursor  *ListCursor
+	Filters map[string]string // Filter name -> value
+}
+
+// ListCursor holds the sort key of the last item of a page
+type ListCursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+	ID     int           `json:"id"`
+}
+
+// EncodeCursor encodes a cursor value as an opaque URL-safe string
+func EncodeCursor(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor decodes a string produced by EncodeCursor into value
+func DecodeCursor(cursor string, value interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+// SortNames returns the accepted sort values of the list, ascending and descending
+func (s ListSpec) SortNames() []string {
+	names := []string{}
+	for name := range s.Sorts {
+		names = append(names, name, "-"+name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortColumns resolves a sort value to its columns and direction
+func (s ListSpec) sortColumns(sortName string) ([]string, bool, error) {
+	if sortName == "" {
+		sortName = s.DefaultSort
+	}
+	desc := strings.HasPrefix(sortName, "-")
+	columns, ok := s.Sorts[strings.TrimPrefix(sortName, "-")]
+	if !ok {
+		return nil, false, apperrors.Invalid("invalid_sort", "invalid sort: %s (allowed: %s)", sortName, strings.Join(s.SortNames(), ", "))
+	}
+	return columns, desc, nil
+}
+
+// Validate checks the sort order, filters and cursor of params against the list
+func (s ListSpec) Validate(params ListParams) error {
+	columns, _, err := s.sortColumns(params.Sort)
+	if err != nil {
+		return err
+	}
+	for name := range params.Filters {
+		if _, ok := s.Filters[name]; !ok {
+			return apperrors.Invalid("invalid_filter", "invalid filter: %s", name)
+		}
+	}
+	if params.Cursor != nil {
+		sortName := params.Sort
+		if sortName == "" {
+			sortName = s.DefaultSort
+		}
+		if params.Cursor.Sort != sortName || len(params.Cursor.Values) != len(columns) {
+			return ErrInvalidCursor
+		}
+	}
+	return nil
+}
+
+// listQuery appends the filters, keyset condition, order and limit of params to a query
+// ending in a WHERE clause. One extra row is requested to detect a following page.
+func (s ListSpec) listQuery(params ListParams, query string, args []interface{}) (string, []interface{}, error) {
+	if err := s.Validate(params); err != nil {
+		return "", nil, err
+	}
+	columns, desc, _ := s.sortColumns(params.Sort)
+
+	// Filters in name order so identical requests produce identical SQL
+	filterNames := make([]string, 0, len(params.Filters))
+	for name := range params.Filters {
+		filterNames = append(filterNames, name)
+	}
+	sort.Strings(filterNames)
+	for _, name := range filterNames {
+		query += " AND " + s.Filters[name] + " = ?"
+		args = append(args, params.Filters[name])
+	}
+
+	op, dir := ">", "ASC"
+	if desc {
+		op, dir = "<", "DESC"
+	}
+
+	// Keyset condition: (c1, ..., cn, id) comes after the cursor in sort order
+	keyColumns := append(append([]string{}, columns...), s.IDColumn)
+	if params.Cursor != nil {
+		keyValues := append(append([]interface{}{}, params.Cursor.Values...), params.Cursor.ID)
+		conditions := []string{}
+		for i := range keyColumns {
+			parts := []string{}
+			for j := 0; j < i; j++ {
+				parts = append(parts, keyColumns[j]+" = ?")
+				args = append(args, keyValues[j])
+			}
+			parts = append(parts, keyColumns[i]+" "+op+" ?")
+			args = append(args, keyValues[i])
+			conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+		}
+		query += " AND (" + strings.Join(conditions, " OR ") + ")"
+	}
+
+	orderBy := []string{}
+	for _, column := range keyColumns {
+		orderBy = append(orderBy, column+" "+dir)
+	}
+	query += " ORDER BY " + strings.Join(orderBy, ", ") + " LIMIT ?"
+	args = append(args, params.Limit+1)
+
+	return query, args, nil
+}
+
+// nextCursor builds the cursor of the page following the item with the given id
+func (s ListSpec) nextCursor(ctx context.Context, executor middleware.DBExecutor, params ListParams, id int) (string, error) {
+	columns, _, _ := s.sortColumns(params.Sort)
+
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	err := executor.QueryRowContext(ctx,
+		"SELECT "+strings.Join(columns, ", ")+" FROM "+s.From+" WHERE "+s.IDColumn+" = ?", id,
+	).Scan(dest...)
+	if err != nil {
+		return "", err
+	}
+
+	// Keep sort keys comparable with the stored column values: TIMESTAMP columns scan as
+	// time.Time and text may scan as []byte, which JSON would encode differently
+	for i, value := range values {
+		switch v := value.(type) {
+		case time.Time:
+			values[i] = v.Format("2006-01-02 15:04:05")
+		case []byte:
+			values[i] = string(v)
+		}
+	}
+
+	sortName := params.Sort
+	if sortName == "" {
+		sortName = s.DefaultSort
+	}
+	return EncodeCursor(ListCursor{Sort: sortName, Values: values, ID: id}), nil
+}
+
+// listPage runs a list query and returns at most params.Limit items together with
+// the cursor of the next page, which is empty on the last page
+func listPage[T any](
+	ctx context.Context,
+	executor middleware.DBExecutor,
+	spec ListSpec,
+	params ListParams,
+	query string,
+	args []interface{},
+	scan func(rows *sql.Rows) (*T, error),
+	id func(item *T) int,
+) ([]T, string, error) {
+	if params.Limit <= 0 {
+		params.Limit = DefaultListLimit
+	}
+
+	query, args, err := spec.listQuery(params, query, args)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := executor.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	items := []T{}
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, "", err
+		}
+		items = append(items, *item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	rows.Close()
+
+	if len(items) <= params.Limit {
+		return items, "", nil
+	}
+
+	items = items[:params.Limit]
+	cursor, err := spec.nextCursor(ctx, executor, params, id(&items[len(items)-1]))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return items, cursor, nil
+}
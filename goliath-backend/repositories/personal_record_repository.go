@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"goliath/entities"
+	"goliath/middleware"
+)
+
+// PersonalRecordRepository handles database operations for personal records
+type PersonalRecordRepository struct {
+	BaseRepository
+}
+
+// NewPersonalRecordRepository creates a new PersonalRecordRepository
+func NewPersonalRecordRepository(db *sql.DB) *PersonalRecordRepository {
+	return &PersonalRecordRepository{
+		BaseRepository: BaseRepository{db: db},
+	}
+}
+
+// queryRecords runs a personal record query and scans the results
+func (r *PersonalRecordRepository) queryRecords(ctx context.Context, where string, args ...interface{}) ([]entities.PersonalRecord, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT pr.id, pr.version, pr.created_when, pr.created_by, pr.modified_when, pr.modified_by,
+		       pr.user_id, pr.exercise_id, pr.record_type, pr.value, pr.reps, pr.time_seconds, pr.weight,
+		       pr.session_set_id, pr.achieved_when, e.name as exercise_name
+		FROM personal_record pr
+		JOIN exercise e ON pr.exercise_id = e.id
+		WHERE `+where+`
+		ORDER BY e.name, pr.record_type, pr.weight
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []entities.PersonalRecord{}
+	for rows.Next() {
+		record, err := entities.ScanPersonalRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// PersonalRecordListSpec describes the sorts and filters of the personal record list
+var PersonalRecordListSpec = ListSpec{
+	From:        "personal_record pr JOIN exercise e ON pr.exercise_id = e.id",
+	IDColumn:    "pr.id",
+	Sorts:       map[string][]string{"exercise": {"e.name", "pr.record_type"}, "achieved_when": {"pr.achieved_when"}},
+	DefaultSort: "exercise",
+	Filters:     map[string]string{"exercise_id": "pr.exercise_id", "record_type": "pr.record_type"},
+}
+
+// ListForUser retrieves one page of the personal records of a user
+func (r *PersonalRecordRepository) ListForUser(ctx context.Context, userID int, params ListParams) ([]entities.PersonalRecord, string, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return listPage(ctx, executor, PersonalRecordListSpec, params, `
+		SELECT pr.id, pr.version, pr.created_when, pr.created_by, pr.modified_when, pr.modified_by,
+		       pr.user_id, pr.exercise_id, pr.record_type, pr.value, pr.reps, pr.time_seconds, pr.weight,
+		       pr.session_set_id, pr.achieved_when, e.name as exercise_name
+		FROM personal_record pr
+		JOIN exercise e ON pr.exercise_id = e.id
+		WHERE pr.user_id = ?`, []interface{}{userID},
+		func(rows *sql.Rows) (*entities.PersonalRecord, error) { return entities.ScanPersonalRecord(rows) },
+		func(record *entities.PersonalRecord) int { return record.ID },
+	)
+}
+
+// GetForUserExercise retrieves the personal records of a user for a single exercise
+func (r *PersonalRecordRepository) GetForUserExercise(ctx context.Context, userID int, exerciseID int) ([]entities.PersonalRecord, error) {
+	return r.queryRecords(ctx, "pr.user_id = ? AND pr.exercise_id = ?", userID, exerciseID)
+}
+
+// GetResultsForUserExercise retrieves every performed set of a user for an exercise
+// as an unclassified record candidate (reps, time, weight, set and session start)
+func (r *PersonalRecordRepository) GetResultsForUserExercise(ctx context.Context, userID int, exerciseID int) ([]entities.PersonalRecord, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT ss.id, ss.reps, ss.time_seconds, ss.weight, s.started_when
+		FROM workout_session_set ss
+		JOIN workout_session s ON ss.session_id = s.id
+		WHERE s.user_id = ? AND ss.exercise_id = ?
+		ORDER BY s.started_when ASC, ss.set_number ASC, ss.id ASC
+	`, userID, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []entities.PersonalRecord{}
+	for rows.Next() {
+		var result entities.PersonalRecord
+		var setID int
+		var startedWhen string
+		if err := rows.Scan(&setID, &result.Reps, &result.TimeSeconds, &result.Weight, &startedWhen); err != nil {
+			return nil, err
+		}
+		result.UserID = userID
+		result.ExerciseID = exerciseID
+		result.SessionSetID = &setID
+		result.AchievedWhen, _ = time.Parse("2006-01-02 15:04:05", startedWhen)
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// ReplaceForUserExercise replaces the stored personal records of a user for an exercise
+func (r *PersonalRecordRepository) ReplaceForUserExercise(ctx context.Context, userID int, exerciseID int, records []entities.PersonalRecord) error {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = executor.ExecContext(ctx, `DELETE FROM personal_record WHERE user_id = ? AND exercise_id = ?`, userID, exerciseID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	for _, pr := range records {
+		_, err := executor.ExecContext(ctx, `
+			INSERT INTO personal_record (version, created_by, modified_by, created_when, modified_when, user_id, exercise_id, record_type, value, reps, time_seconds, weight, session_set_id, achieved_when)
+			VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, user.FirebaseUID, user.FirebaseUID, now, now, userID, exerciseID, pr.RecordType, pr.Value, pr.Reps, pr.TimeSeconds, pr.Weight, pr.SessionSetID, pr.AchievedWhen.Format("2006-01-02 15:04:05"))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"goliath/entities"
+	"goliath/middleware"
+)
+
+// ProgramDayRepository handles database operations for the days of program weeks
+type ProgramDayRepository struct {
+	BaseRepository
+}
+
+// NewProgramDayRepository creates a new ProgramDayRepository
+func NewProgramDayRepository(db *sql.DB) *ProgramDayRepository {
+	return &ProgramDayRepository{
+		BaseRepository: BaseRepository{db: db},
+	}
+}
+
+// GetAllForProgram retrieves the days of every week of a program in one query, keyed by week ID
+func (r *ProgramDayRepository) GetAllForProgram(ctx context.Context, programID int) (map[int][]entities.ProgramDay, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT d.id, d.version, d.created_when, d.created_by, d.modified_when, d.modified_by,
+		       d.program_week_id, d.day_number, d.workout_id, w.name as workout_name
+		FROM program_day d
+		JOIN program_week pw ON d.program_week_id = pw.id
+		JOIN workout w ON d.workout_id = w.id
+		WHERE pw.program_id = ?
+		ORDER BY d.program_week_id, d.day_number ASC
+	`, programID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	daysMap := make(map[int][]entities.ProgramDay)
+	for rows.Next() {
+		day, err := entities.ScanProgramDay(rows)
+		if err != nil {
+			return nil, err
+		}
+		daysMap[day.ProgramWeekID] = append(daysMap[day.ProgramWeekID], *day)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return daysMap, nil
+}
+
+// GetByID retrieves a single day by ID
+func (r *ProgramDayRepository) GetByID(ctx context.Context, id int) (*entities.ProgramDay, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row := executor.QueryRowContext(ctx, `
+		SELECT d.id, d.version, d.created_when, d.created_by, d.modified_when, d.modified_by,
+		       d.program_week_id, d.day_number, d.workout_id, w.name as workout_name
+		FROM program_day d
+		JOIN workout w ON d.workout_id = w.id
+		WHERE d.id = ?
+	`, id)
+
+	return entities.ScanProgramDay(row)
+}
+
+// GetByNumber retrieves the day of a week with the given day number
+func (r *ProgramDayRepository) GetByNumber(ctx context.Context, weekID int, dayNumber int) (*entities.ProgramDay, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row := executor.QueryRowContext(ctx, `
+		SELECT d.id, d.version, d.created_when, d.created_by, d.modified_when, d.modified_by,
+		       d.program_week_id, d.day_number, d.workout_id, w.name as workout_name
+		FROM program_day d
+		JOIN workout w ON d.workout_id = w.id
+		WHERE d.program_week_id = ? AND d.day_number = ?
+	`, weekID, dayNumber)
+
+	return entities.ScanProgramDay(row)
+}
+
+// DayNumberExists checks whether a day of a week other than excludeID has the given number
+func (r *ProgramDayRepository) DayNumberExists(ctx context.Context, weekID int, dayNumber int, excludeID int) (bool, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return false, err
+	}
+	var count int
+	err = executor.QueryRowContext(ctx, "SELECT COUNT(*) FROM program_day WHERE program_week_id = ? AND day_number = ? AND id != ?", weekID, dayNumber, excludeID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Create creates a new day of a program week
+func (r *ProgramDayRepository) Create(ctx context.Context, weekID int, dayNumber int, workoutID int) (int64, error) {
+	log.Printf("Starting to create day %d of program week %d", dayNumber, weekID)
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return 0, ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Insert day
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		INSERT INTO program_day (version, created_by, modified_by, created_when, modified_when, program_week_id, day_number, workout_id)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?)
+	`, user.FirebaseUID, user.FirebaseUID, now, now, weekID, dayNumber, workoutID)
+	if err != nil {
+		return 0, err
+	}
+
+	dayID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Created program day with ID %d", dayID)
+
+	if err := touchProgramOfWeek(ctx, executor, weekID); err != nil {
+		return 0, err
+	}
+
+	return dayID, nil
+}
+
+// Update updates an existing day
+// A non-zero version must match the stored version, otherwise apperrors.ErrVersionConflict is returned
+func (r *ProgramDayRepository) Update(ctx context.Context, id int, version int, dayNumber int, workoutID int) error {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Update day
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		UPDATE program_day
+		SET day_number = ?, workout_id = ?, modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, dayNumber, workoutID, user.FirebaseUID, now, id, version, version)
+	if err != nil {
+		return err
+	}
+	if err := checkVersionedUpdate(ctx, executor, result, "program_day", id); err != nil {
+		return err
+	}
+
+	return touchProgramOfDay(ctx, executor, id)
+}
+
+// Delete deletes a day
+func (r *ProgramDayRepository) Delete(ctx context.Context, id int) error {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = touchProgramOfDay(ctx, executor, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = executor.ExecContext(ctx, `DELETE FROM program_day WHERE id = ?`, id)
+	return err
+}
+
+// touchProgramOfDay moves the version of the program the given day belongs to
+func touchProgramOfDay(ctx context.Context, executor middleware.DBExecutor, dayID int) error {
+	_, err := executor.ExecContext(ctx, `
+		UPDATE program SET version = version + 1
+		WHERE id = (SELECT pw.program_id FROM program_day d JOIN program_week pw ON d.program_week_id = pw.id WHERE d.id = ?)
+	`, dayID)
+	return err
+}
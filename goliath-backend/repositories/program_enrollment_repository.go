@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"goliath/entities"
+	"goliath/middleware"
+)
+
+// ProgramEnrollmentRepository handles database operations for users following programs
+type ProgramEnrollmentRepository struct {
+	BaseRepository
+}
+
+// NewProgramEnrollmentRepository creates a new ProgramEnrollmentRepository
+func NewProgramEnrollmentRepository(db *sql.DB) *ProgramEnrollmentRepository {
+	return &ProgramEnrollmentRepository{
+		BaseRepository: BaseRepository{db: db},
+	}
+}
+
+// EnrollmentListSpec describes the sorts and filters of the enrollment list
+var EnrollmentListSpec = ListSpec{
+	From:        "program_enrollment pe",
+	IDColumn:    "pe.id",
+	Sorts:       map[string][]string{"started_when": {"pe.started_when"}},
+	DefaultSort: "-started_when",
+	Filters:     map[string]string{"program_id": "pe.program_id"},
+}
+
+// ListForUser retrieves one page of enrollments of a user
+func (r *ProgramEnrollmentRepository) ListForUser(ctx context.Context, userID int, params ListParams) ([]entities.ProgramEnrollment, string, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return listPage(ctx, executor, EnrollmentListSpec, params, `
+		SELECT pe.id, pe.version, pe.created_when, pe.created_by, pe.modified_when, pe.modified_by,
+		       pe.user_id, pe.program_id, pe.started_when, pe.current_week, pe.current_day, pe.completed_when,
+		       p.name as program_name
+		FROM program_enrollment pe
+		JOIN program p ON pe.program_id = p.id
+		WHERE pe.user_id = ?`, []interface{}{userID},
+		func(rows *sql.Rows) (*entities.ProgramEnrollment, error) { return entities.ScanProgramEnrollment(rows) },
+		func(enrollment *entities.ProgramEnrollment) int { return enrollment.ID },
+	)
+}
+
+// GetByID retrieves a single enrollment by ID
+func (r *ProgramEnrollmentRepository) GetByID(ctx context.Context, id int) (*entities.ProgramEnrollment, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row := executor.QueryRowContext(ctx, `
+		SELECT pe.id, pe.version, pe.created_when, pe.created_by, pe.modified_when, pe.modified_by,
+		       pe.user_id, pe.program_id, pe.started_when, pe.current_week, pe.current_day, pe.completed_when,
+		       p.name as program_name
+		FROM program_enrollment pe
+		JOIN program p ON pe.program_id = p.id
+		WHERE pe.id = ?
+	`, id)
+
+	return entities.ScanProgramEnrollment(row)
+}
+
+// ActiveExists checks whether a user follows a program in an enrollment other than excludeID that is not completed
+func (r *ProgramEnrollmentRepository) ActiveExists(ctx context.Context, userID int, programID int, excludeID int) (bool, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return false, err
+	}
+	var count int
+	err = executor.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM program_enrollment
+		WHERE user_id = ? AND program_id = ? AND completed_when IS NULL AND id != ?
+	`, userID, programID, excludeID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Create enrolls a user in a program at the given week and day
+func (r *ProgramEnrollmentRepository) Create(ctx context.Context, userID int, programID int, startedWhen time.Time, week int, day int) (int64, error) {
+	log.Printf("Starting to enroll user %d in program %d", userID, programID)
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return 0, ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Insert enrollment
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		INSERT INTO program_enrollment (version, created_by, modified_by, created_when, modified_when, user_id, program_id, started_when, current_week, current_day)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, user.FirebaseUID, user.FirebaseUID, now, now, userID, programID, startedWhen.Format("2006-01-02 15:04:05"), week, day)
+	if err != nil {
+		return 0, err
+	}
+
+	enrollmentID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Created program enrollment with ID %d", enrollmentID)
+
+	return enrollmentID, nil
+}
+
+// UpdatePosition moves an enrollment to the given week and day, completing it when completedWhen is set
+// A non-zero version must match the stored version, otherwise apperrors.ErrVersionConflict is returned
+func (r *ProgramEnrollmentRepository) UpdatePosition(ctx context.Context, id int, version int, week int, day int, completedWhen *time.Time) error {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	var completed *string
+	if completedWhen != nil {
+		formatted := completedWhen.Format("2006-01-02 15:04:05")
+		completed = &formatted
+	}
+
+	// Update enrollment
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		UPDATE program_enrollment
+		SET current_week = ?, current_day = ?, completed_when = ?, modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, week, day, completed, user.FirebaseUID, now, id, version, version)
+	if err != nil {
+		return err
+	}
+	return checkVersionedUpdate(ctx, executor, result, "program_enrollment", id)
+}
+
+// Delete deletes an enrollment
+func (r *ProgramEnrollmentRepository) Delete(ctx context.Context, id int) error {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = executor.ExecContext(ctx, `DELETE FROM program_enrollment WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"goliath/entities"
+	"goliath/middleware"
+)
+
+// ProgramRepository handles database operations for training programs
+type ProgramRepository struct {
+	BaseRepository
+}
+
+// NewProgramRepository creates a new ProgramRepository
+func NewProgramRepository(db *sql.DB) *ProgramRepository {
+	return &ProgramRepository{
+		BaseRepository: BaseRepository{db: db},
+	}
+}
+
+// ProgramListSpec describes the sorts and filters of the program list
+var ProgramListSpec = ListSpec{
+	From:        "program p",
+	IDColumn:    "p.id",
+	Sorts:       map[string][]string{"created_when": {"p.created_when"}, "name": {"p.name"}},
+	DefaultSort: "-created_when",
+	Filters:     map[string]string{"name": "p.name"},
+}
+
+// ListForUser retrieves one page of programs of a user without their weeks
+func (r *ProgramRepository) ListForUser(ctx context.Context, userID int, params ListParams) ([]entities.Program, string, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return listPage(ctx, executor, ProgramListSpec, params, `
+		SELECT p.id, p.version, p.created_when, p.created_by, p.modified_when, p.modified_by,
+		       p.user_id, p.name, p.description, p.weight_increment, p.reps_increment, p.deload_every, p.deload_percentage
+		FROM program p
+		WHERE p.user_id = ?`, []interface{}{userID},
+		func(rows *sql.Rows) (*entities.Program, error) { return entities.ScanProgram(rows) },
+		func(program *entities.Program) int { return program.ID },
+	)
+}
+
+// GetByID retrieves a single program by ID without its weeks
+func (r *ProgramRepository) GetByID(ctx context.Context, id int) (*entities.Program, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row := executor.QueryRowContext(ctx, `
+		SELECT id, version, created_when, created_by, modified_when, modified_by,
+		       user_id, name, description, weight_increment, reps_increment, deload_every, deload_percentage
+		FROM program
+		WHERE id = ?
+	`, id)
+
+	return entities.ScanProgram(row)
+}
+
+// Create creates a new program
+func (r *ProgramRepository) Create(ctx context.Context, userID int, name string, description *string, weightIncrement float64, repsIncrement int, deloadEvery int, deloadPercentage float64) (int64, error) {
+	log.Printf("Starting to create program %s for user %d", name, userID)
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return 0, ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Insert program
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		INSERT INTO program (version, created_by, modified_by, created_when, modified_when, user_id, name, description, weight_increment, reps_increment, deload_every, deload_percentage)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, user.FirebaseUID, user.FirebaseUID, now, now, userID, name, description, weightIncrement, repsIncrement, deloadEvery, deloadPercentage)
+	if err != nil {
+		return 0, err
+	}
+
+	programID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Created program with ID %d", programID)
+
+	return programID, nil
+}
+
+// Update updates an existing program
+// A non-zero version must match the stored version, otherwise apperrors.ErrVersionConflict is returned
+func (r *ProgramRepository) Update(ctx context.Context, id int, version int, name string, description *string, weightIncrement float64, repsIncrement int, deloadEvery int, deloadPercentage float64) error {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Update program
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		UPDATE program
+		SET name = ?, description = ?, weight_increment = ?, reps_increment = ?, deload_every = ?, deload_percentage = ?,
+		    modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, name, description, weightIncrement, repsIncrement, deloadEvery, deloadPercentage, user.FirebaseUID, now, id, version, version)
+	if err != nil {
+		return err
+	}
+	return checkVersionedUpdate(ctx, executor, result, "program", id)
+}
+
+// Delete deletes a program with its weeks, days and enrollments
+func (r *ProgramRepository) Delete(ctx context.Context, id int) error {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = executor.ExecContext(ctx, `DELETE FROM program WHERE id = ?`, id)
+	return err
+}
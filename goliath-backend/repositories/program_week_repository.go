@@ -0,0 +1,224 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"goliath/entities"
+	"goliath/middleware"
+)
+
+// ProgramWeekRepository handles database operations for the weeks of training programs
+type ProgramWeekRepository struct {
+	BaseRepository
+	dayRepo *ProgramDayRepository
+}
+
+// NewProgramWeekRepository creates a new ProgramWeekRepository
+func NewProgramWeekRepository(db *sql.DB) *ProgramWeekRepository {
+	return &ProgramWeekRepository{
+		BaseRepository: BaseRepository{db: db},
+		dayRepo:        NewProgramDayRepository(db),
+	}
+}
+
+// GetAllForProgram retrieves all weeks of a program ordered by week number with their days inline
+func (r *ProgramWeekRepository) GetAllForProgram(ctx context.Context, programID int) ([]entities.ProgramWeek, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT id, version, created_when, created_by, modified_when, modified_by,
+		       program_id, week_number, notes
+		FROM program_week
+		WHERE program_id = ?
+		ORDER BY week_number ASC
+	`, programID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	weeks := []entities.ProgramWeek{}
+	for rows.Next() {
+		week, err := entities.ScanProgramWeek(rows)
+		if err != nil {
+			return nil, err
+		}
+		weeks = append(weeks, *week)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	// Attach days to their weeks
+	daysMap, err := r.dayRepo.GetAllForProgram(ctx, programID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range weeks {
+		if days, ok := daysMap[weeks[i].ID]; ok {
+			weeks[i].Days = days
+		}
+	}
+
+	return weeks, nil
+}
+
+// GetByID retrieves a single week by ID without its days
+func (r *ProgramWeekRepository) GetByID(ctx context.Context, id int) (*entities.ProgramWeek, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row := executor.QueryRowContext(ctx, `
+		SELECT id, version, created_when, created_by, modified_when, modified_by,
+		       program_id, week_number, notes
+		FROM program_week
+		WHERE id = ?
+	`, id)
+
+	return entities.ScanProgramWeek(row)
+}
+
+// GetByNumber retrieves the week of a program with the given week number without its days
+func (r *ProgramWeekRepository) GetByNumber(ctx context.Context, programID int, weekNumber int) (*entities.ProgramWeek, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row := executor.QueryRowContext(ctx, `
+		SELECT id, version, created_when, created_by, modified_when, modified_by,
+		       program_id, week_number, notes
+		FROM program_week
+		WHERE program_id = ? AND week_number = ?
+	`, programID, weekNumber)
+
+	return entities.ScanProgramWeek(row)
+}
+
+// WeekNumberExists checks whether a week of a program other than excludeID has the given number
+func (r *ProgramWeekRepository) WeekNumberExists(ctx context.Context, programID int, weekNumber int, excludeID int) (bool, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return false, err
+	}
+	var count int
+	err = executor.QueryRowContext(ctx, "SELECT COUNT(*) FROM program_week WHERE program_id = ? AND week_number = ? AND id != ?", programID, weekNumber, excludeID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Create creates a new week of a program
+func (r *ProgramWeekRepository) Create(ctx context.Context, programID int, weekNumber int, notes *string) (int64, error) {
+	log.Printf("Starting to create week %d of program %d", weekNumber, programID)
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return 0, ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Insert week
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		INSERT INTO program_week (version, created_by, modified_by, created_when, modified_when, program_id, week_number, notes)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?)
+	`, user.FirebaseUID, user.FirebaseUID, now, now, programID, weekNumber, notes)
+	if err != nil {
+		return 0, err
+	}
+
+	weekID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Created program week with ID %d", weekID)
+
+	if err := touchProgram(ctx, executor, programID); err != nil {
+		return 0, err
+	}
+
+	return weekID, nil
+}
+
+// Update updates an existing week
+// A non-zero version must match the stored version, otherwise apperrors.ErrVersionConflict is returned
+func (r *ProgramWeekRepository) Update(ctx context.Context, id int, version int, weekNumber int, notes *string) error {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Update week
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		UPDATE program_week
+		SET week_number = ?, notes = ?, modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, weekNumber, notes, user.FirebaseUID, now, id, version, version)
+	if err != nil {
+		return err
+	}
+	if err := checkVersionedUpdate(ctx, executor, result, "program_week", id); err != nil {
+		return err
+	}
+
+	return touchProgramOfWeek(ctx, executor, id)
+}
+
+// Delete deletes a week with its days
+func (r *ProgramWeekRepository) Delete(ctx context.Context, id int) error {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = touchProgramOfWeek(ctx, executor, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = executor.ExecContext(ctx, `DELETE FROM program_week WHERE id = ?`, id)
+	return err
+}
+
+// touchProgram moves the version of a program after one of its weeks or days changed.
+// Weeks and days are part of the program representation, so changing one must invalidate
+// the ETag of the program and any update based on it.
+func touchProgram(ctx context.Context, executor middleware.DBExecutor, programID int) error {
+	_, err := executor.ExecContext(ctx, `UPDATE program SET version = version + 1 WHERE id = ?`, programID)
+	return err
+}
+
+// touchProgramOfWeek moves the version of the program the given week belongs to
+func touchProgramOfWeek(ctx context.Context, executor middleware.DBExecutor, weekID int) error {
+	_, err := executor.ExecContext(ctx, `
+		UPDATE program SET version = version + 1
+		WHERE id = (SELECT program_id FROM program_week WHERE id = ?)
+	`, weekID)
+	return err
+}
@@ -3,8 +3,10 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"goliath/entities"
+	"goliath/middleware"
 )
 
 // RegionRepository handles database operations for regions
@@ -19,35 +21,141 @@ func NewRegionRepository(db *sql.DB) *RegionRepository {
 	}
 }
 
-// GetAll retrieves all regions from the database
-func (r *RegionRepository) GetAll(ctx context.Context) ([]entities.Region, error) {
+// RegionListSpec describes the sorts and filters of the region list
+var RegionListSpec = ListSpec{
+	From:        "region r",
+	IDColumn:    "r.id",
+	Sorts:       map[string][]string{"id": {"r.id"}, "name": {"r.name"}},
+	DefaultSort: "id",
+	Filters:     map[string]string{"name": "r.name"},
+}
+
+// List retrieves one page of regions
+func (r *RegionRepository) List(ctx context.Context, params ListParams) ([]entities.Region, string, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return listPage(ctx, executor, RegionListSpec, params, `
+		SELECT r.id, r.version, r.created_when, r.created_by, r.modified_when, r.modified_by, r.name
+		FROM region r
+		WHERE 1 = 1`, nil,
+		entities.ScanRegion,
+		func(region *entities.Region) int { return region.ID },
+	)
+}
+
+// GetByID retrieves a region by ID
+func (r *RegionRepository) GetByID(ctx context.Context, id int) (*entities.Region, error) {
 	executor, err := r.GetExecutor(ctx)
 	if err != nil {
 		return nil, err
 	}
+
 	rows, err := executor.QueryContext(ctx, `
-		SELECT id, version, created_when, created_by, modified_when, modified_by, name 
-		FROM region 
-		ORDER BY id
-	`)
+		SELECT id, version, created_when, created_by, modified_when, modified_by, name
+		FROM region
+		WHERE id = ?
+	`, id)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	regions := []entities.Region{}
-	for rows.Next() {
-		region, err := entities.ScanRegion(rows)
-		if err != nil {
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
 			return nil, err
 		}
-		regions = append(regions, *region)
+		return nil, sql.ErrNoRows
 	}
+	return entities.ScanRegion(rows)
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+// NameExists checks if a region with the given name exists (case-insensitive)
+func (r *RegionRepository) NameExists(ctx context.Context, name string) (bool, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return false, err
+	}
+	var count int
+	err = executor.QueryRowContext(ctx, "SELECT COUNT(*) FROM region WHERE LOWER(name) = LOWER(?)", name).Scan(&count)
+	if err != nil {
+		return false, err
 	}
+	return count > 0, nil
+}
 
-	return regions, nil
+// CountMuscleGroups counts the muscle groups of a region
+func (r *RegionRepository) CountMuscleGroups(ctx context.Context, id int) (int, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	err = executor.QueryRowContext(ctx, "SELECT COUNT(*) FROM muscle_group WHERE region_id = ?", id).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
 }
 
+// Create creates a new region
+func (r *RegionRepository) Create(ctx context.Context, name string) (int64, error) {
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return 0, ErrUserRequired
+	}
+
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		INSERT INTO region (version, created_by, modified_by, created_when, modified_when, name)
+		VALUES (1, ?, ?, ?, ?, ?)
+	`, user.FirebaseUID, user.FirebaseUID, now, now, name)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// Update renames a region
+// A non-zero version must match the stored version, otherwise apperrors.ErrVersionConflict is returned
+func (r *RegionRepository) Update(ctx context.Context, id int, version int, name string) error {
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		UPDATE region
+		SET name = ?, modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, name, user.FirebaseUID, now, id, version, version)
+	if err != nil {
+		return err
+	}
+	return checkVersionedUpdate(ctx, executor, result, "region", id)
+}
+
+// Delete deletes a region; fails while muscle groups still reference it (ON DELETE RESTRICT)
+func (r *RegionRepository) Delete(ctx context.Context, id int) error {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = executor.ExecContext(ctx, "DELETE FROM region WHERE id = ?", id)
+	return err
+}
@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+	"time"
+
+	"goliath/entities"
+	"goliath/middleware"
+)
+
+// RegistrationRepository handles database operations for the registration policy and invites
+type RegistrationRepository struct {
+	BaseRepository
+}
+
+// NewRegistrationRepository creates a new RegistrationRepository
+func NewRegistrationRepository(db *sql.DB) *RegistrationRepository {
+	return &RegistrationRepository{
+		BaseRepository: BaseRepository{db: db},
+	}
+}
+
+// GetPolicy retrieves the registration policy
+func (r *RegistrationRepository) GetPolicy(ctx context.Context) (*entities.RegistrationPolicy, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy entities.RegistrationPolicy
+	var domains *string
+	err = executor.QueryRowContext(ctx, `
+		SELECT version, modified_when, modified_by, invite_only, allowed_email_domains
+		FROM registration_policy
+		WHERE id = 1
+	`).Scan(&policy.Version, &policy.ModifiedWhen, &policy.ModifiedBy, &policy.InviteOnly, &domains)
+	if err != nil {
+		return nil, err
+	}
+
+	policy.AllowedEmailDomains = []string{}
+	if domains != nil && *domains != "" {
+		policy.AllowedEmailDomains = strings.Split(*domains, ",")
+	}
+	return &policy, nil
+}
+
+// UpdatePolicy replaces the registration policy
+func (r *RegistrationRepository) UpdatePolicy(ctx context.Context, version int, policy entities.RegistrationPolicy) error {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	var domains *string
+	if len(policy.AllowedEmailDomains) > 0 {
+		joined := strings.Join(policy.AllowedEmailDomains, ",")
+		domains = &joined
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		UPDATE registration_policy
+		SET invite_only = ?, allowed_email_domains = ?, modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = 1 AND (? = 0 OR version = ?)
+	`, policy.InviteOnly, domains, user.FirebaseUID, now, version, version)
+	if err != nil {
+		return err
+	}
+	return checkVersionedUpdate(ctx, executor, result, "registration_policy", 1)
+}
+
+// GetInvites retrieves all invites, pending ones first, newest first
+func (r *RegistrationRepository) GetInvites(ctx context.Context) ([]entities.UserInvite, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT id, version, created_when, created_by, modified_when, modified_by,
+		       email, note, expires_when, used_when, used_by_user_id
+		FROM user_invite
+		ORDER BY used_when IS NOT NULL, id DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := []entities.UserInvite{}
+	for rows.Next() {
+		invite, err := entities.ScanUserInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, *invite)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return invites, nil
+}
+
+// GetInviteByID retrieves a single invite by ID
+func (r *RegistrationRepository) GetInviteByID(ctx context.Context, id int) (*entities.UserInvite, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	invite, err := entities.ScanUserInvite(executor.QueryRowContext(ctx, `
+		SELECT id, version, created_when, created_by, modified_when, modified_by,
+		       email, note, expires_when, used_when, used_by_user_id
+		FROM user_invite
+		WHERE id = ?
+	`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return invite, nil
+}
+
+// CreateInvite stores an invite under the hash of its code. Expiry is stored in UTC.
+func (r *RegistrationRepository) CreateInvite(ctx context.Context, codeHash string, invite entities.UserInvite) (int64, error) {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return 0, ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var expiresWhen *string
+	if invite.ExpiresWhen != nil {
+		expires := invite.ExpiresWhen.UTC().Format("2006-01-02 15:04:05")
+		expiresWhen = &expires
+	}
+
+	// Insert invite
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		INSERT INTO user_invite (version, created_by, modified_by, created_when, modified_when, code_hash, email, note, expires_when)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, user.FirebaseUID, user.FirebaseUID, now, now, codeHash, invite.Email, invite.Note, expiresWhen)
+	if err != nil {
+		return 0, err
+	}
+
+	inviteID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Created invite with ID %d", inviteID)
+
+	return inviteID, nil
+}
+
+// DeleteInvite revokes an invite
+func (r *RegistrationRepository) DeleteInvite(ctx context.Context, id int) error {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = executor.ExecContext(ctx, `DELETE FROM user_invite WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,268 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"goliath/entities"
+	"goliath/middleware"
+)
+
+// RoleRepository handles database operations for roles and their permissions
+type RoleRepository struct {
+	BaseRepository
+}
+
+// NewRoleRepository creates a new RoleRepository
+func NewRoleRepository(db *sql.DB) *RoleRepository {
+	return &RoleRepository{
+		BaseRepository: BaseRepository{db: db},
+	}
+}
+
+// GetPermissions retrieves every permission roles can grant
+func (r *RoleRepository) GetPermissions(ctx context.Context) ([]entities.PermissionDefinition, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `SELECT name, description FROM permission ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []entities.PermissionDefinition{}
+	for rows.Next() {
+		var permission entities.PermissionDefinition
+		if err := rows.Scan(&permission.Name, &permission.Description); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// GetAll retrieves all roles with their permissions, built-in roles first
+func (r *RoleRepository) GetAll(ctx context.Context) ([]entities.Role, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT id, version, created_when, created_by, modified_when, modified_by, name, description, built_in
+		FROM role
+		ORDER BY built_in DESC, name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []entities.Role{}
+	for rows.Next() {
+		role, err := entities.ScanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, *role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Attach permissions
+	permissions, err := r.getPermissionsForAllRoles(ctx, executor)
+	if err != nil {
+		return nil, err
+	}
+	for i := range roles {
+		if granted, ok := permissions[roles[i].ID]; ok {
+			roles[i].Permissions = granted
+		}
+	}
+
+	return roles, nil
+}
+
+// getPermissionsForAllRoles retrieves the permissions of every role, keyed by role ID
+func (r *RoleRepository) getPermissionsForAllRoles(ctx context.Context, executor middleware.DBExecutor) (map[int][]entities.Permission, error) {
+	rows, err := executor.QueryContext(ctx, `SELECT role_id, permission FROM role_permission ORDER BY permission`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := make(map[int][]entities.Permission)
+	for rows.Next() {
+		var roleID int
+		var permission entities.Permission
+		if err := rows.Scan(&roleID, &permission); err != nil {
+			return nil, err
+		}
+		permissions[roleID] = append(permissions[roleID], permission)
+	}
+
+	return permissions, rows.Err()
+}
+
+// GetByName retrieves a role with its permissions by name
+func (r *RoleRepository) GetByName(ctx context.Context, name string) (*entities.Role, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := entities.ScanRole(executor.QueryRowContext(ctx, `
+		SELECT id, version, created_when, created_by, modified_when, modified_by, name, description, built_in
+		FROM role
+		WHERE name = ?
+	`, name))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `SELECT permission FROM role_permission WHERE role_id = ? ORDER BY permission`, role.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var permission entities.Permission
+		if err := rows.Scan(&permission); err != nil {
+			return nil, err
+		}
+		role.Permissions = append(role.Permissions, permission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// CountUsers counts the users holding a role
+func (r *RoleRepository) CountUsers(ctx context.Context, name string) (int, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	err = executor.QueryRowContext(ctx, "SELECT COUNT(*) FROM user WHERE role = ?", name).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Create creates a new role with its permissions
+func (r *RoleRepository) Create(ctx context.Context, role entities.Role) (int64, error) {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return 0, ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Insert role
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		INSERT INTO role (version, created_by, modified_by, created_when, modified_when, name, description)
+		VALUES (1, ?, ?, ?, ?, ?, ?)
+	`, user.FirebaseUID, user.FirebaseUID, now, now, role.Name, role.Description)
+	if err != nil {
+		return 0, err
+	}
+
+	roleID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := insertRolePermissions(ctx, executor, roleID, role.Permissions); err != nil {
+		return 0, err
+	}
+
+	return roleID, nil
+}
+
+// Update replaces the description and permissions of a role
+// A non-zero version must match the stored version, otherwise apperrors.ErrVersionConflict is returned
+func (r *RoleRepository) Update(ctx context.Context, id int, version int, role entities.Role) error {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Update role
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		UPDATE role
+		SET description = ?, modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, role.Description, user.FirebaseUID, now, id, version, version)
+	if err != nil {
+		return err
+	}
+	if err := checkVersionedUpdate(ctx, executor, result, "role", id); err != nil {
+		return err
+	}
+
+	// Replace permissions
+	_, err = executor.ExecContext(ctx, `DELETE FROM role_permission WHERE role_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return insertRolePermissions(ctx, executor, int64(id), role.Permissions)
+}
+
+// insertRolePermissions grants permissions to a role
+func insertRolePermissions(ctx context.Context, executor middleware.DBExecutor, roleID int64, permissions []entities.Permission) error {
+	for _, permission := range permissions {
+		_, err := executor.ExecContext(ctx, `
+			INSERT INTO role_permission (role_id, permission)
+			VALUES (?, ?)
+		`, roleID, permission)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete deletes a role and its permissions
+func (r *RoleRepository) Delete(ctx context.Context, id int) error {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = executor.ExecContext(ctx, "DELETE FROM role WHERE id = ?", id)
+	return err
+}
@@ -0,0 +1,225 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+	"time"
+
+	"goliath/entities"
+	"goliath/middleware"
+)
+
+// ScheduleRepository handles database operations for workout schedules
+type ScheduleRepository struct {
+	BaseRepository
+}
+
+// NewScheduleRepository creates a new ScheduleRepository
+func NewScheduleRepository(db *sql.DB) *ScheduleRepository {
+	return &ScheduleRepository{
+		BaseRepository: BaseRepository{db: db},
+	}
+}
+
+// ScheduleListSpec describes the sorts and filters of the schedule list
+var ScheduleListSpec = ListSpec{
+	From:        "workout_schedule ws JOIN workout w ON ws.workout_id = w.id",
+	IDColumn:    "ws.id",
+	Sorts:       map[string][]string{"start_date": {"ws.start_date"}, "workout_name": {"w.name"}},
+	DefaultSort: "start_date",
+	Filters:     map[string]string{"workout_id": "ws.workout_id"},
+}
+
+// ListForUser retrieves one page of workout schedules of a user
+func (r *ScheduleRepository) ListForUser(ctx context.Context, userID int, params ListParams) ([]entities.WorkoutSchedule, string, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return listPage(ctx, executor, ScheduleListSpec, params, `
+		SELECT ws.id, ws.version, ws.created_when, ws.created_by, ws.modified_when, ws.modified_by,
+		       ws.user_id, ws.workout_id, ws.start_date, ws.end_date, ws.weekdays, ws.interval_weeks, ws.notes,
+		       w.name as workout_name
+		FROM workout_schedule ws
+		JOIN workout w ON ws.workout_id = w.id
+		WHERE ws.user_id = ?`, []interface{}{userID},
+		func(rows *sql.Rows) (*entities.WorkoutSchedule, error) { return entities.ScanWorkoutSchedule(rows) },
+		func(schedule *entities.WorkoutSchedule) int { return schedule.ID },
+	)
+}
+
+// GetAllForUser retrieves all workout schedules of a user ordered by start date
+func (r *ScheduleRepository) GetAllForUser(ctx context.Context, userID int) ([]entities.WorkoutSchedule, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT ws.id, ws.version, ws.created_when, ws.created_by, ws.modified_when, ws.modified_by,
+		       ws.user_id, ws.workout_id, ws.start_date, ws.end_date, ws.weekdays, ws.interval_weeks, ws.notes,
+		       w.name as workout_name
+		FROM workout_schedule ws
+		JOIN workout w ON ws.workout_id = w.id
+		WHERE ws.user_id = ?
+		ORDER BY ws.start_date ASC, ws.id ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSchedules(rows)
+}
+
+// GetOverlapping retrieves the workout schedules of a user that may plan a workout in [from, to]
+func (r *ScheduleRepository) GetOverlapping(ctx context.Context, userID int, from, to string) ([]entities.WorkoutSchedule, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT ws.id, ws.version, ws.created_when, ws.created_by, ws.modified_when, ws.modified_by,
+		       ws.user_id, ws.workout_id, ws.start_date, ws.end_date, ws.weekdays, ws.interval_weeks, ws.notes,
+		       w.name as workout_name
+		FROM workout_schedule ws
+		JOIN workout w ON ws.workout_id = w.id
+		WHERE ws.user_id = ? AND ws.start_date <= ?
+		  AND (ws.end_date IS NULL OR ws.end_date >= ?)
+		  AND (ws.weekdays IS NOT NULL OR ws.start_date >= ?)
+		ORDER BY ws.start_date ASC, ws.id ASC
+	`, userID, to, from, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSchedules(rows)
+}
+
+// scanSchedules reads all workout schedules of a result set
+func scanSchedules(rows *sql.Rows) ([]entities.WorkoutSchedule, error) {
+	schedules := []entities.WorkoutSchedule{}
+	for rows.Next() {
+		schedule, err := entities.ScanWorkoutSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+// GetByID retrieves a single workout schedule by ID
+func (r *ScheduleRepository) GetByID(ctx context.Context, id int) (*entities.WorkoutSchedule, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row := executor.QueryRowContext(ctx, `
+		SELECT ws.id, ws.version, ws.created_when, ws.created_by, ws.modified_when, ws.modified_by,
+		       ws.user_id, ws.workout_id, ws.start_date, ws.end_date, ws.weekdays, ws.interval_weeks, ws.notes,
+		       w.name as workout_name
+		FROM workout_schedule ws
+		JOIN workout w ON ws.workout_id = w.id
+		WHERE ws.id = ?
+	`, id)
+
+	return entities.ScanWorkoutSchedule(row)
+}
+
+// Create plans a workout for a user
+func (r *ScheduleRepository) Create(ctx context.Context, userID int, schedule entities.WorkoutSchedule) (int64, error) {
+	log.Printf("Starting to schedule workout %d for user %d", schedule.WorkoutID, userID)
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return 0, ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Insert workout schedule
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		INSERT INTO workout_schedule (version, created_by, modified_by, created_when, modified_when, user_id, workout_id, start_date, end_date, weekdays, interval_weeks, notes)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, user.FirebaseUID, user.FirebaseUID, now, now, userID, schedule.WorkoutID, schedule.StartDate, schedule.EndDate,
+		joinWeekdays(schedule.Weekdays), schedule.IntervalWeeks, schedule.Notes)
+	if err != nil {
+		return 0, err
+	}
+
+	scheduleID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Created workout schedule with ID %d", scheduleID)
+
+	return scheduleID, nil
+}
+
+// Update replaces the plan of a workout schedule
+// A non-zero version must match the stored version, otherwise apperrors.ErrVersionConflict is returned
+func (r *ScheduleRepository) Update(ctx context.Context, id int, version int, schedule entities.WorkoutSchedule) error {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Update workout schedule
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		UPDATE workout_schedule
+		SET workout_id = ?, start_date = ?, end_date = ?, weekdays = ?, interval_weeks = ?, notes = ?,
+		    modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, schedule.WorkoutID, schedule.StartDate, schedule.EndDate, joinWeekdays(schedule.Weekdays), schedule.IntervalWeeks, schedule.Notes,
+		user.FirebaseUID, now, id, version, version)
+	if err != nil {
+		return err
+	}
+	return checkVersionedUpdate(ctx, executor, result, "workout_schedule", id)
+}
+
+// Delete deletes a workout schedule
+func (r *ScheduleRepository) Delete(ctx context.Context, id int) error {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = executor.ExecContext(ctx, `DELETE FROM workout_schedule WHERE id = ?`, id)
+	return err
+}
+
+// joinWeekdays stores weekdays as a comma separated list, NULL for a one-off schedule
+func joinWeekdays(weekdays []string) *string {
+	if len(weekdays) == 0 {
+		return nil
+	}
+	joined := strings.Join(weekdays, ",")
+	return &joined
+}
@@ -0,0 +1,224 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"goliath/entities"
+	"goliath/middleware"
+)
+
+// SessionRepository handles database operations for workout sessions
+type SessionRepository struct {
+	BaseRepository
+}
+
+// NewSessionRepository creates a new SessionRepository
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{
+		BaseRepository: BaseRepository{db: db},
+	}
+}
+
+// SessionListSpec describes the sorts and filters of the session list
+var SessionListSpec = ListSpec{
+	From:        "workout_session s",
+	IDColumn:    "s.id",
+	Sorts:       map[string][]string{"started_when": {"s.started_when"}, "name": {"s.name"}},
+	DefaultSort: "-started_when",
+	Filters:     map[string]string{"workout_id": "s.workout_id", "name": "s.name"},
+}
+
+// ListForUser retrieves one page of workout sessions of a user
+func (r *SessionRepository) ListForUser(ctx context.Context, userID int, params ListParams) ([]entities.WorkoutSession, string, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return listPage(ctx, executor, SessionListSpec, params, `
+		SELECT s.id, s.version, s.created_when, s.created_by, s.modified_when, s.modified_by,
+		       s.user_id, s.workout_id, s.name, s.started_when, s.finished_when, s.notes
+		FROM workout_session s
+		WHERE s.user_id = ?`, []interface{}{userID},
+		func(rows *sql.Rows) (*entities.WorkoutSession, error) { return entities.ScanWorkoutSession(rows) },
+		func(session *entities.WorkoutSession) int { return session.ID },
+	)
+}
+
+// GetByID retrieves a single workout session by ID
+func (r *SessionRepository) GetByID(ctx context.Context, id int) (*entities.WorkoutSession, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row := executor.QueryRowContext(ctx, `
+		SELECT id, version, created_when, created_by, modified_when, modified_by,
+		       user_id, workout_id, name, started_when, finished_when, notes
+		FROM workout_session
+		WHERE id = ?
+	`, id)
+
+	return entities.ScanWorkoutSession(row)
+}
+
+// GetAllForUser retrieves all workout sessions of a user ordered by start time
+func (r *SessionRepository) GetAllForUser(ctx context.Context, userID int) ([]entities.WorkoutSession, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT id, version, created_when, created_by, modified_when, modified_by,
+		       user_id, workout_id, name, started_when, finished_when, notes
+		FROM workout_session
+		WHERE user_id = ?
+		ORDER BY started_when ASC, id ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []entities.WorkoutSession{}
+	for rows.Next() {
+		session, err := entities.ScanWorkoutSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// SessionExists checks whether a user has a session with the given name started at the given time
+func (r *SessionRepository) SessionExists(ctx context.Context, userID int, name string, startedWhen time.Time) (bool, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return false, err
+	}
+	var count int
+	err = executor.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM workout_session
+		WHERE user_id = ? AND name = ? AND started_when = ?
+	`, userID, name, startedWhen.Format("2006-01-02 15:04:05")).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetFinishedInRange retrieves the finished sessions of a user started in [from, to) ordered by start time
+func (r *SessionRepository) GetFinishedInRange(ctx context.Context, userID int, from, to time.Time) ([]entities.WorkoutSession, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT id, version, created_when, created_by, modified_when, modified_by,
+		       user_id, workout_id, name, started_when, finished_when, notes
+		FROM workout_session
+		WHERE user_id = ? AND finished_when IS NOT NULL
+		  AND started_when >= ? AND started_when < ?
+		ORDER BY started_when ASC, id ASC
+	`, userID, from.Format("2006-01-02 15:04:05"), to.Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []entities.WorkoutSession{}
+	for rows.Next() {
+		session, err := entities.ScanWorkoutSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Create starts a new workout session
+func (r *SessionRepository) Create(ctx context.Context, userID int, workoutID *int, name string, startedWhen time.Time, notes *string) (int64, error) {
+	log.Printf("Starting to create workout session %s for user %d", name, userID)
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return 0, ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Insert workout session
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		INSERT INTO workout_session (version, created_by, modified_by, created_when, modified_when, user_id, workout_id, name, started_when, notes)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, user.FirebaseUID, user.FirebaseUID, now, now, userID, workoutID, name, startedWhen.Format("2006-01-02 15:04:05"), notes)
+	if err != nil {
+		return 0, err
+	}
+
+	sessionID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Created workout session with ID %d", sessionID)
+
+	return sessionID, nil
+}
+
+// Finish records the end of a workout session
+func (r *SessionRepository) Finish(ctx context.Context, id int, finishedWhen time.Time, notes *string) error {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Keep existing notes when none are given
+	now := time.Now().Format("2006-01-02 15:04:05")
+	_, err = executor.ExecContext(ctx, `
+		UPDATE workout_session
+		SET finished_when = ?, notes = COALESCE(?, notes), modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ?
+	`, finishedWhen.Format("2006-01-02 15:04:05"), notes, user.FirebaseUID, now, id)
+	return err
+}
+
+// Delete deletes a workout session and its sets
+func (r *SessionRepository) Delete(ctx context.Context, id int) error {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = executor.ExecContext(ctx, `DELETE FROM workout_session WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"goliath/entities"
+	"goliath/middleware"
+)
+
+// SessionSetRepository handles database operations for performed workout session sets
+type SessionSetRepository struct {
+	BaseRepository
+}
+
+// NewSessionSetRepository creates a new SessionSetRepository
+func NewSessionSetRepository(db *sql.DB) *SessionSetRepository {
+	return &SessionSetRepository{
+		BaseRepository: BaseRepository{db: db},
+	}
+}
+
+// GetAllForSession retrieves all performed sets for a specific session
+func (r *SessionSetRepository) GetAllForSession(ctx context.Context, sessionID int) ([]entities.WorkoutSessionSet, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT
+			ss.id, ss.version, ss.created_when, ss.created_by, ss.modified_when, ss.modified_by,
+			ss.session_id, ss.workout_exercise_id, ss.exercise_id, ss.set_number, ss.reps, ss.time_seconds, ss.weight, ss.notes,
+			e.name as exercise_name, e.type as exercise_type
+		FROM workout_session_set ss
+		JOIN exercise e ON ss.exercise_id = e.id
+		WHERE ss.session_id = ?
+		ORDER BY ss.set_number ASC, ss.id ASC
+	`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sets := []entities.WorkoutSessionSet{}
+	for rows.Next() {
+		set, err := entities.ScanWorkoutSessionSet(rows)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, *set)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sets, nil
+}
+
+// GetByID retrieves a single performed set by ID
+func (r *SessionSetRepository) GetByID(ctx context.Context, id int) (*entities.WorkoutSessionSet, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row := executor.QueryRowContext(ctx, `
+		SELECT
+			ss.id, ss.version, ss.created_when, ss.created_by, ss.modified_when, ss.modified_by,
+			ss.session_id, ss.workout_exercise_id, ss.exercise_id, ss.set_number, ss.reps, ss.time_seconds, ss.weight, ss.notes,
+			e.name as exercise_name, e.type as exercise_type
+		FROM workout_session_set ss
+		JOIN exercise e ON ss.exercise_id = e.id
+		WHERE ss.id = ?
+	`, id)
+
+	return entities.ScanWorkoutSessionSet(row)
+}
+
+// Create records a new performed set
+func (r *SessionSetRepository) Create(ctx context.Context, sessionID int, workoutExerciseID *int, exerciseID int, setNumber int, reps *int, timeSeconds *int, weight *float64, notes *string) (int64, error) {
+	log.Printf("Starting to create set for session %d, exercise %d", sessionID, exerciseID)
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return 0, ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Insert session set
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		INSERT INTO workout_session_set (version, created_by, modified_by, created_when, modified_when, session_id, workout_exercise_id, exercise_id, set_number, reps, time_seconds, weight, notes)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, user.FirebaseUID, user.FirebaseUID, now, now, sessionID, workoutExerciseID, exerciseID, setNumber, reps, timeSeconds, weight, notes)
+	if err != nil {
+		return 0, err
+	}
+
+	setID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Created session set with ID %d", setID)
+
+	return setID, nil
+}
+
+// Update updates the recorded result of a performed set
+// A non-zero version must match the stored version, otherwise apperrors.ErrVersionConflict is returned
+func (r *SessionSetRepository) Update(ctx context.Context, id int, version int, setNumber int, reps *int, timeSeconds *int, weight *float64, notes *string) error {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Update session set
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		UPDATE workout_session_set
+		SET set_number = ?, reps = ?, time_seconds = ?, weight = ?, notes = ?, modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, setNumber, reps, timeSeconds, weight, notes, user.FirebaseUID, now, id, version, version)
+	if err != nil {
+		return err
+	}
+	return checkVersionedUpdate(ctx, executor, result, "workout_session_set", id)
+}
+
+// Delete deletes a performed set
+func (r *SessionSetRepository) Delete(ctx context.Context, id int) error {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = executor.ExecContext(ctx, `DELETE FROM workout_session_set WHERE id = ?`, id)
+	return err
+}
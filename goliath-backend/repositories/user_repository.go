@@ -3,8 +3,10 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"goliath/entities"
+	"goliath/middleware"
 )
 
 // UserRepository handles database operations for users
@@ -19,71 +21,48 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	}
 }
 
-// GetAll retrieves all users from the database
-func (r *UserRepository) GetAll(ctx context.Context) ([]entities.User, error) {
+// userColumns are the columns read by entities.ScanUser
+const userColumns = `u.id, u.version, u.created_when, u.created_by, u.modified_when, u.modified_by, u.email, u.role, u.firebase_uid,
+		       u.status, u.status_reason, u.display_name, u.units, u.time_zone, u.date_of_birth, u.sex`
+
+// UserListSpec describes the sorts and filters of the user list
+var UserListSpec = ListSpec{
+	From:        "user u",
+	IDColumn:    "u.id",
+	Sorts:       map[string][]string{"created_when": {"u.created_when"}, "email": {"u.email"}},
+	DefaultSort: "-created_when",
+	Filters:     map[string]string{"role": "u.role", "email": "u.email", "status": "u.status"},
+}
+
+// List retrieves one page of users
+func (r *UserRepository) List(ctx context.Context, params ListParams) ([]entities.User, string, error) {
 	executor, err := r.GetExecutor(ctx)
 	if err != nil {
-		return nil, err
-	}
-	rows, err := executor.QueryContext(ctx, `
-		SELECT id, version, created_when, created_by, modified_when, modified_by, email, role, firebase_uid
-		FROM user
-		ORDER BY created_when DESC
-	`)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	users := []entities.User{}
-	for rows.Next() {
-		var user entities.User
-		if err := rows.Scan(
-			&user.ID,
-			&user.Version,
-			&user.CreatedWhen,
-			&user.CreatedBy,
-			&user.ModifiedWhen,
-			&user.ModifiedBy,
-			&user.Email,
-			&user.Role,
-			&user.FirebaseUID,
-		); err != nil {
-			return nil, err
-		}
-		users = append(users, user)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return users, nil
+	return listPage(ctx, executor, UserListSpec, params, `
+		SELECT `+userColumns+`
+		FROM user u
+		WHERE 1 = 1`, nil,
+		func(rows *sql.Rows) (*entities.User, error) {
+			return entities.ScanUser(rows)
+		},
+		func(user *entities.User) int { return user.ID },
+	)
 }
 
-// GetByID retrieves a user by ID
-func (r *UserRepository) GetByID(ctx context.Context, id int) (*entities.User, error) {
+// getBy retrieves the user matching a condition on the user table
+func (r *UserRepository) getBy(ctx context.Context, condition string, arg interface{}) (*entities.User, error) {
 	executor, err := r.GetExecutor(ctx)
 	if err != nil {
 		return nil, err
 	}
-	var user entities.User
-	err = executor.QueryRowContext(ctx, `
-		SELECT id, version, created_when, created_by, modified_when, modified_by, email, role, firebase_uid
-		FROM user
-		WHERE id = ?
-	`, id).Scan(
-		&user.ID,
-		&user.Version,
-		&user.CreatedWhen,
-		&user.CreatedBy,
-		&user.ModifiedWhen,
-		&user.ModifiedBy,
-		&user.Email,
-		&user.Role,
-		&user.FirebaseUID,
-	)
 
+	user, err := entities.ScanUser(executor.QueryRowContext(ctx, `
+		SELECT `+userColumns+`
+		FROM user u
+		WHERE `+condition, arg))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -91,72 +70,101 @@ func (r *UserRepository) GetByID(ctx context.Context, id int) (*entities.User, e
 		return nil, err
 	}
 
-	return &user, nil
+	return user, nil
+}
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(ctx context.Context, id int) (*entities.User, error) {
+	return r.getBy(ctx, "u.id = ?", id)
 }
 
 // GetByEmail retrieves a user by email
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
+	return r.getBy(ctx, "u.email = ?", email)
+}
+
+// GetByFirebaseUID retrieves a user by Firebase UID
+func (r *UserRepository) GetByFirebaseUID(ctx context.Context, firebaseUID string) (*entities.User, error) {
+	return r.getBy(ctx, "u.firebase_uid = ?", firebaseUID)
+}
+
+// UpdateProfile updates the profile of a user
+func (r *UserRepository) UpdateProfile(ctx context.Context, id int, version int, profile entities.UserProfile) error {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
 	executor, err := r.GetExecutor(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	var user entities.User
-	err = executor.QueryRowContext(ctx, `
-		SELECT id, version, created_when, created_by, modified_when, modified_by, email, role, firebase_uid
-		FROM user
-		WHERE email = ?
-	`, email).Scan(
-		&user.ID,
-		&user.Version,
-		&user.CreatedWhen,
-		&user.CreatedBy,
-		&user.ModifiedWhen,
-		&user.ModifiedBy,
-		&user.Email,
-		&user.Role,
-		&user.FirebaseUID,
-	)
 
+	// Update profile
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		UPDATE user
+		SET display_name = ?, units = ?, time_zone = ?, date_of_birth = ?, sex = ?,
+		    modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, profile.DisplayName, profile.Units, profile.TimeZone, profile.DateOfBirth, profile.Sex,
+		user.FirebaseUID, now, id, version, version)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		return nil, err
+		return err
 	}
-
-	return &user, nil
+	return checkVersionedUpdate(ctx, executor, result, "user", id)
 }
 
-// GetByFirebaseUID retrieves a user by Firebase UID
-func (r *UserRepository) GetByFirebaseUID(ctx context.Context, firebaseUID string) (*entities.User, error) {
+// UpdateRole changes the role of a user
+func (r *UserRepository) UpdateRole(ctx context.Context, id int, version int, role string) error {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
 	executor, err := r.GetExecutor(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	var user entities.User
-	err = executor.QueryRowContext(ctx, `
-		SELECT id, version, created_when, created_by, modified_when, modified_by, email, role, firebase_uid
-		FROM user
-		WHERE firebase_uid = ?
-	`, firebaseUID).Scan(
-		&user.ID,
-		&user.Version,
-		&user.CreatedWhen,
-		&user.CreatedBy,
-		&user.ModifiedWhen,
-		&user.ModifiedBy,
-		&user.Email,
-		&user.Role,
-		&user.FirebaseUID,
-	)
 
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		UPDATE user
+		SET role = ?, modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, role, user.FirebaseUID, now, id, version, version)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		return nil, err
+		return err
 	}
-
-	return &user, nil
+	return checkVersionedUpdate(ctx, executor, result, "user", id)
 }
 
+// UpdateStatus enables, disables or bans a user
+func (r *UserRepository) UpdateStatus(ctx context.Context, id int, version int, status entities.UserStatus, reason *string) error {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		UPDATE user
+		SET status = ?, status_reason = ?, modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, status, reason, user.FirebaseUID, now, id, version, version)
+	if err != nil {
+		return err
+	}
+	return checkVersionedUpdate(ctx, executor, result, "user", id)
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"goliath/entities"
+	"goliath/middleware"
+)
+
+// WorkoutChangeRepository handles database operations for the audit trail of workouts
+type WorkoutChangeRepository struct {
+	BaseRepository
+}
+
+// NewWorkoutChangeRepository creates a new WorkoutChangeRepository
+func NewWorkoutChangeRepository(db *sql.DB) *WorkoutChangeRepository {
+	return &WorkoutChangeRepository{
+		BaseRepository: BaseRepository{db: db},
+	}
+}
+
+// WorkoutChangeListSpec describes the sorts and filters of the changes of a workout
+var WorkoutChangeListSpec = ListSpec{
+	From:        "workout_change wc",
+	IDColumn:    "wc.id",
+	Sorts:       map[string][]string{"changed_when": {"wc.changed_when"}},
+	DefaultSort: "-changed_when",
+	Filters:     map[string]string{"user_id": "wc.user_id", "entity_type": "wc.entity_type", "action": "wc.action"},
+}
+
+// ListForWorkout retrieves one page of the changes of a workout
+func (r *WorkoutChangeRepository) ListForWorkout(ctx context.Context, workoutID int, params ListParams) ([]entities.WorkoutChange, string, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return listPage(ctx, executor, WorkoutChangeListSpec, params, `
+		SELECT wc.id, wc.changed_when, wc.workout_id, wc.user_id, u.email, wc.entity_type, wc.entity_id, wc.action
+		FROM workout_change wc
+		LEFT JOIN user u ON u.id = wc.user_id
+		WHERE wc.workout_id = ?`, []interface{}{workoutID},
+		entities.ScanWorkoutChange,
+		func(change *entities.WorkoutChange) int { return change.ID },
+	)
+}
+
+// Record adds a change made by the user of the request to the audit trail of a workout
+func (r *WorkoutChangeRepository) Record(ctx context.Context, workoutID int, entityType entities.WorkoutChangeEntity, entityID int, action entities.WorkoutChangeAction) error {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	_, err = executor.ExecContext(ctx, `
+		INSERT INTO workout_change (changed_when, workout_id, user_id, entity_type, entity_id, action)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, now, workoutID, user.ID, entityType, entityID, action)
+	return err
+}
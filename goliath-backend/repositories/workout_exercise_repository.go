@@ -13,16 +13,70 @@ import (
 // WorkoutExerciseRepository handles database operations for workout exercises
 type WorkoutExerciseRepository struct {
 	BaseRepository
+	setRepo *WorkoutExerciseSetRepository
 }
 
 // NewWorkoutExerciseRepository creates a new WorkoutExerciseRepository
 func NewWorkoutExerciseRepository(db *sql.DB) *WorkoutExerciseRepository {
 	return &WorkoutExerciseRepository{
 		BaseRepository: BaseRepository{db: db},
+		setRepo:        NewWorkoutExerciseSetRepository(db),
 	}
 }
 
-// GetAllForWorkout retrieves all exercises for a specific workout
+// applySetSummary attaches individual sets to a workout exercise and replaces
+// its scalar fields with a summary of them: the number of non-warm-up sets and
+// the reps, time and weight of the top set, the heaviest one that is not a warm-up,
+// so the summary always describes a set that was actually planned
+func applySetSummary(we *entities.WorkoutExercise, sets []entities.WorkoutExerciseSet) {
+	we.SetDetails = sets
+	if len(sets) == 0 {
+		return
+	}
+
+	count := 0
+	var top *entities.WorkoutExerciseSet
+	for i := range sets {
+		set := &sets[i]
+		if set.SetType == entities.SetTypeWarmUp {
+			continue
+		}
+		count++
+		if top == nil || isHeavierSet(set, top) {
+			top = set
+		}
+	}
+
+	we.Sets = &count
+	we.Reps, we.TimeSeconds, we.Weight = nil, nil, nil
+	if top != nil {
+		we.Reps = top.Reps
+		we.TimeSeconds = top.TimeSeconds
+		we.Weight = top.Weight
+	}
+}
+
+// isHeavierSet reports whether a set is above another one: the heavier set wins,
+// then the one with more reps, then the longer one
+func isHeavierSet(set *entities.WorkoutExerciseSet, other *entities.WorkoutExerciseSet) bool {
+	if weight, otherWeight := orZero(set.Weight), orZero(other.Weight); weight != otherWeight {
+		return weight > otherWeight
+	}
+	if reps, otherReps := orZero(set.Reps), orZero(other.Reps); reps != otherReps {
+		return reps > otherReps
+	}
+	return orZero(set.TimeSeconds) > orZero(other.TimeSeconds)
+}
+
+// orZero returns the value of an optional field, the zero value when unset
+func orZero[T int | float64](value *T) T {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+// GetAllForWorkout retrieves all exercises for a specific workout with their individual sets inline
 func (r *WorkoutExerciseRepository) GetAllForWorkout(ctx context.Context, workoutID int) ([]entities.WorkoutExercise, error) {
 	executor, err := r.GetExecutor(ctx)
 	if err != nil {
@@ -56,6 +110,16 @@ func (r *WorkoutExerciseRepository) GetAllForWorkout(ctx context.Context, workou
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	rows.Close()
+
+	// Attach individual sets to their exercises
+	setsMap, err := r.setRepo.GetAllForWorkout(ctx, workoutID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range exercises {
+		applySetSummary(&exercises[i], setsMap[exercises[i].ID])
+	}
 
 	return exercises, nil
 }
@@ -103,6 +167,13 @@ func (r *WorkoutExerciseRepository) GetByID(ctx context.Context, id int) (*entit
 
 	we.CreatedWhen, _ = time.Parse("2006-01-02 15:04:05", createdWhen)
 	we.ModifiedWhen, _ = time.Parse("2006-01-02 15:04:05", modifiedWhen)
+
+	// Attach individual sets
+	sets, err := r.setRepo.GetAllForWorkoutExercise(ctx, we.ID)
+	if err != nil {
+		return nil, err
+	}
+	applySetSummary(&we, sets)
 	
 	return &we, nil
 }
@@ -145,7 +216,8 @@ func (r *WorkoutExerciseRepository) Create(ctx context.Context, workoutID int, e
 }
 
 // Update updates an existing workout exercise
-func (r *WorkoutExerciseRepository) Update(ctx context.Context, id int, position int, sets *int, reps *int, timeSeconds *int, weight *float64, notes *string) error {
+// A non-zero version must match the stored version, otherwise apperrors.ErrVersionConflict is returned
+func (r *WorkoutExerciseRepository) Update(ctx context.Context, id int, version int, position int, sets *int, reps *int, timeSeconds *int, weight *float64, notes *string) error {
 	log.Printf("Starting to update workout exercise %d", id)
 	
 	// Get user from context
@@ -164,16 +236,15 @@ func (r *WorkoutExerciseRepository) Update(ctx context.Context, id int, position
 	
 	// Update workout exercise
 	now := time.Now().Format("2006-01-02 15:04:05")
-	_, err = executor.ExecContext(ctx, `
-		UPDATE workout_exercise 
+	result, err := executor.ExecContext(ctx, `
+		UPDATE workout_exercise
 		SET position = ?, sets = ?, reps = ?, time_seconds = ?, weight = ?, notes = ?, modified_by = ?, modified_when = ?, version = version + 1
-		WHERE id = ?
-	`, position, sets, reps, timeSeconds, weight, notes, user.FirebaseUID, now, id)
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, position, sets, reps, timeSeconds, weight, notes, user.FirebaseUID, now, id, version, version)
 	if err != nil {
 		return err
 	}
-
-	return nil
+	return checkVersionedUpdate(ctx, executor, result, "workout_exercise", id)
 }
 
 // Delete deletes a workout exercise
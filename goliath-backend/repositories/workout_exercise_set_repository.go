@@ -0,0 +1,226 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"goliath/entities"
+	"goliath/middleware"
+)
+
+// WorkoutExerciseSetRepository handles database operations for planned sets of workout exercises
+type WorkoutExerciseSetRepository struct {
+	BaseRepository
+}
+
+// NewWorkoutExerciseSetRepository creates a new WorkoutExerciseSetRepository
+func NewWorkoutExerciseSetRepository(db *sql.DB) *WorkoutExerciseSetRepository {
+	return &WorkoutExerciseSetRepository{
+		BaseRepository: BaseRepository{db: db},
+	}
+}
+
+// GetAllForWorkoutExercise retrieves all sets of a workout exercise ordered by position
+func (r *WorkoutExerciseSetRepository) GetAllForWorkoutExercise(ctx context.Context, workoutExerciseID int) ([]entities.WorkoutExerciseSet, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT id, version, created_when, created_by, modified_when, modified_by,
+		       workout_exercise_id, position, set_type, reps, time_seconds, weight, rpe
+		FROM workout_exercise_set
+		WHERE workout_exercise_id = ?
+		ORDER BY position ASC, id ASC
+	`, workoutExerciseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sets := []entities.WorkoutExerciseSet{}
+	for rows.Next() {
+		set, err := entities.ScanWorkoutExerciseSet(rows)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, *set)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sets, nil
+}
+
+// GetAllForWorkout retrieves the sets of every exercise in a workout in one query, keyed by workout exercise ID
+func (r *WorkoutExerciseSetRepository) GetAllForWorkout(ctx context.Context, workoutID int) (map[int][]entities.WorkoutExerciseSet, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT s.id, s.version, s.created_when, s.created_by, s.modified_when, s.modified_by,
+		       s.workout_exercise_id, s.position, s.set_type, s.reps, s.time_seconds, s.weight, s.rpe
+		FROM workout_exercise_set s
+		JOIN workout_exercise we ON s.workout_exercise_id = we.id
+		WHERE we.workout_id = ?
+		ORDER BY s.workout_exercise_id, s.position ASC, s.id ASC
+	`, workoutID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	setsMap := make(map[int][]entities.WorkoutExerciseSet)
+	for rows.Next() {
+		set, err := entities.ScanWorkoutExerciseSet(rows)
+		if err != nil {
+			return nil, err
+		}
+		setsMap[set.WorkoutExerciseID] = append(setsMap[set.WorkoutExerciseID], *set)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return setsMap, nil
+}
+
+// GetByID retrieves a single set by ID
+func (r *WorkoutExerciseSetRepository) GetByID(ctx context.Context, id int) (*entities.WorkoutExerciseSet, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row := executor.QueryRowContext(ctx, `
+		SELECT id, version, created_when, created_by, modified_when, modified_by,
+		       workout_exercise_id, position, set_type, reps, time_seconds, weight, rpe
+		FROM workout_exercise_set
+		WHERE id = ?
+	`, id)
+
+	return entities.ScanWorkoutExerciseSet(row)
+}
+
+// Create creates a new set for a workout exercise
+func (r *WorkoutExerciseSetRepository) Create(ctx context.Context, workoutExerciseID int, position int, setType entities.SetType, reps *int, timeSeconds *int, weight *float64, rpe *float64) (int64, error) {
+	log.Printf("Starting to create set for workout exercise %d", workoutExerciseID)
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return 0, ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Insert set
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		INSERT INTO workout_exercise_set (version, created_by, modified_by, created_when, modified_when, workout_exercise_id, position, set_type, reps, time_seconds, weight, rpe)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, user.FirebaseUID, user.FirebaseUID, now, now, workoutExerciseID, position, setType, reps, timeSeconds, weight, rpe)
+	if err != nil {
+		return 0, err
+	}
+
+	setID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Created workout exercise set with ID %d", setID)
+
+	if err := touchWorkoutExercise(ctx, executor, user, workoutExerciseID); err != nil {
+		return 0, err
+	}
+
+	return setID, nil
+}
+
+// Update updates an existing set
+// A non-zero version must match the stored version, otherwise apperrors.ErrVersionConflict is returned
+func (r *WorkoutExerciseSetRepository) Update(ctx context.Context, id int, version int, position int, setType entities.SetType, reps *int, timeSeconds *int, weight *float64, rpe *float64) error {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Update set
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		UPDATE workout_exercise_set
+		SET position = ?, set_type = ?, reps = ?, time_seconds = ?, weight = ?, rpe = ?, modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, position, setType, reps, timeSeconds, weight, rpe, user.FirebaseUID, now, id, version, version)
+	if err != nil {
+		return err
+	}
+	if err := checkVersionedUpdate(ctx, executor, result, "workout_exercise_set", id); err != nil {
+		return err
+	}
+
+	return touchWorkoutExerciseOfSet(ctx, executor, user, id)
+}
+
+// Delete deletes a set
+func (r *WorkoutExerciseSetRepository) Delete(ctx context.Context, id int) error {
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return ErrUserRequired
+	}
+
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = touchWorkoutExerciseOfSet(ctx, executor, user, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = executor.ExecContext(ctx, `DELETE FROM workout_exercise_set WHERE id = ?`, id)
+	return err
+}
+
+// touchWorkoutExercise records a change of a workout exercise after one of its sets changed.
+// Sets are part of the workout exercise representation (set_details and the summary built from
+// them), so changing one must move its version, invalidating its ETag and any update based on it.
+func touchWorkoutExercise(ctx context.Context, executor middleware.DBExecutor, user *entities.User, workoutExerciseID int) error {
+	now := time.Now().Format("2006-01-02 15:04:05")
+	_, err := executor.ExecContext(ctx, `
+		UPDATE workout_exercise SET modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ?
+	`, user.FirebaseUID, now, workoutExerciseID)
+	return err
+}
+
+// touchWorkoutExerciseOfSet records a change of the workout exercise the given set belongs to
+func touchWorkoutExerciseOfSet(ctx context.Context, executor middleware.DBExecutor, user *entities.User, setID int) error {
+	var workoutExerciseID int
+	err := executor.QueryRowContext(ctx, `SELECT workout_exercise_id FROM workout_exercise_set WHERE id = ?`, setID).Scan(&workoutExerciseID)
+	if err != nil {
+		return err
+	}
+	return touchWorkoutExercise(ctx, executor, user, workoutExerciseID)
+}
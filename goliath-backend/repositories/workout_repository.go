@@ -22,18 +22,68 @@ func NewWorkoutRepository(db *sql.DB) *WorkoutRepository {
 	}
 }
 
-// GetAllForUser retrieves all workouts for a specific user
+// WorkoutListSpec describes the sorts and filters of the workout list
+var WorkoutListSpec = ListSpec{
+	From:        "workout w",
+	IDColumn:    "w.id",
+	Sorts:       map[string][]string{"created_when": {"w.created_when"}, "name": {"w.name"}},
+	DefaultSort: "-created_when",
+	Filters:     map[string]string{"name": "w.name"},
+}
+
+// TemplateListSpec describes the sorts and filters of the template library
+var TemplateListSpec = ListSpec{
+	From:        "workout w",
+	IDColumn:    "w.id",
+	Sorts:       map[string][]string{"created_when": {"w.created_when"}, "name": {"w.name"}},
+	DefaultSort: "-created_when",
+	Filters:     map[string]string{"name": "w.name"}, // Authors are not revealed
+}
+
+// ListForUser retrieves one page of workouts for a specific user
+func (r *WorkoutRepository) ListForUser(ctx context.Context, userID int, params ListParams) ([]entities.Workout, string, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return listPage(ctx, executor, WorkoutListSpec, params, `
+		SELECT w.id, w.version, w.created_when, w.created_by, w.modified_when, w.modified_by, w.name, w.user_id, w.shareable, w.source_workout_id
+		FROM workout w
+		WHERE w.user_id = ?`, []interface{}{userID},
+		entities.ScanWorkout,
+		func(workout *entities.Workout) int { return workout.ID },
+	)
+}
+
+// ListShareable retrieves one page of the workouts shared as templates by any user
+func (r *WorkoutRepository) ListShareable(ctx context.Context, params ListParams) ([]entities.Workout, string, error) {
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return listPage(ctx, executor, TemplateListSpec, params, `
+		SELECT w.id, w.version, w.created_when, w.created_by, w.modified_when, w.modified_by, w.name, w.user_id, w.shareable, w.source_workout_id
+		FROM workout w
+		WHERE w.shareable = 1`, nil,
+		entities.ScanWorkout,
+		func(workout *entities.Workout) int { return workout.ID },
+	)
+}
+
+// GetAllForUser retrieves all workouts of a user in creation order
 func (r *WorkoutRepository) GetAllForUser(ctx context.Context, userID int) ([]entities.Workout, error) {
 	executor, err := r.GetExecutor(ctx)
 	if err != nil {
 		return nil, err
 	}
-	
+
 	rows, err := executor.QueryContext(ctx, `
-		SELECT id, version, created_when, created_by, modified_when, modified_by, name, user_id
+		SELECT id, version, created_when, created_by, modified_when, modified_by, name, user_id, shareable, source_workout_id
 		FROM workout
 		WHERE user_id = ?
-		ORDER BY created_when DESC
+		ORDER BY id ASC
 	`, userID)
 	if err != nil {
 		return nil, err
@@ -64,7 +114,7 @@ func (r *WorkoutRepository) GetByID(ctx context.Context, id int) (*entities.Work
 	}
 	
 	row := executor.QueryRowContext(ctx, `
-		SELECT id, version, created_when, created_by, modified_when, modified_by, name, user_id
+		SELECT id, version, created_when, created_by, modified_when, modified_by, name, user_id, shareable, source_workout_id
 		FROM workout
 		WHERE id = ?
 	`, id)
@@ -80,6 +130,8 @@ func (r *WorkoutRepository) GetByID(ctx context.Context, id int) (*entities.Work
 		&workout.ModifiedBy,
 		&workout.Name,
 		&workout.UserID,
+		&workout.Shareable,
+		&workout.SourceWorkoutID,
 	)
 	if err != nil {
 		return nil, err
@@ -92,7 +144,7 @@ func (r *WorkoutRepository) GetByID(ctx context.Context, id int) (*entities.Work
 }
 
 // Create creates a new workout
-func (r *WorkoutRepository) Create(ctx context.Context, name string, userID int) (int64, error) {
+func (r *WorkoutRepository) Create(ctx context.Context, name string, userID int, shareable bool) (int64, error) {
 	log.Printf("Starting to create workout %s for user %d", name, userID)
 	
 	// Get user from context
@@ -112,9 +164,9 @@ func (r *WorkoutRepository) Create(ctx context.Context, name string, userID int)
 	// Insert workout
 	now := time.Now().Format("2006-01-02 15:04:05")
 	result, err := executor.ExecContext(ctx, `
-		INSERT INTO workout (version, created_by, modified_by, created_when, modified_when, name, user_id)
-		VALUES (1, ?, ?, ?, ?, ?, ?)
-	`, user.FirebaseUID, user.FirebaseUID, now, now, name, userID)
+		INSERT INTO workout (version, created_by, modified_by, created_when, modified_when, name, user_id, shareable)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?)
+	`, user.FirebaseUID, user.FirebaseUID, now, now, name, userID, shareable)
 	if err != nil {
 		return 0, err
 	}
@@ -129,7 +181,8 @@ func (r *WorkoutRepository) Create(ctx context.Context, name string, userID int)
 }
 
 // Update updates an existing workout
-func (r *WorkoutRepository) Update(ctx context.Context, id int, name string) error {
+// A non-zero version must match the stored version, otherwise apperrors.ErrVersionConflict is returned
+func (r *WorkoutRepository) Update(ctx context.Context, id int, version int, name string, shareable bool) error {
 	log.Printf("Starting to update workout %d", id)
 	
 	// Get user from context
@@ -148,16 +201,100 @@ func (r *WorkoutRepository) Update(ctx context.Context, id int, name string) err
 	
 	// Update workout
 	now := time.Now().Format("2006-01-02 15:04:05")
-	_, err = executor.ExecContext(ctx, `
-		UPDATE workout 
-		SET name = ?, modified_by = ?, modified_when = ?, version = version + 1
-		WHERE id = ?
-	`, name, user.FirebaseUID, now, id)
+	result, err := executor.ExecContext(ctx, `
+		UPDATE workout
+		SET name = ?, shareable = ?, modified_by = ?, modified_when = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+	`, name, shareable, user.FirebaseUID, now, id, version, version)
 	if err != nil {
 		return err
 	}
+	return checkVersionedUpdate(ctx, executor, result, "workout", id)
+}
+
+// Clone copies a workout with its exercises and their individual sets into a new private workout of userID.
+// The clone records the workout it was copied from.
+func (r *WorkoutRepository) Clone(ctx context.Context, sourceID int, userID int, name string) (int64, error) {
+	log.Printf("Starting to clone workout %d for user %d", sourceID, userID)
+
+	// Get user from context
+	user, hasUser := middleware.GetUserFromContext(ctx)
+	if !hasUser {
+		return 0, ErrUserRequired
+	}
+
+	// Get executor (must be a transaction)
+	executor, err := r.GetExecutor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Insert workout
+	now := time.Now().Format("2006-01-02 15:04:05")
+	result, err := executor.ExecContext(ctx, `
+		INSERT INTO workout (version, created_by, modified_by, created_when, modified_when, name, user_id, shareable, source_workout_id)
+		VALUES (1, ?, ?, ?, ?, ?, ?, 0, ?)
+	`, user.FirebaseUID, user.FirebaseUID, now, now, name, userID, sourceID)
+	if err != nil {
+		return 0, err
+	}
+	workoutID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	// Collect the exercises first, the transaction cannot insert while rows are open
+	rows, err := executor.QueryContext(ctx, `
+		SELECT id FROM workout_exercise WHERE workout_id = ? ORDER BY position ASC, id ASC
+	`, sourceID)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
 
-	return nil
+	sourceExerciseIDs := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return 0, err
+		}
+		sourceExerciseIDs = append(sourceExerciseIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	rows.Close()
+
+	// Copy each exercise, then its individual sets
+	for _, sourceExerciseID := range sourceExerciseIDs {
+		result, err := executor.ExecContext(ctx, `
+			INSERT INTO workout_exercise (version, created_by, modified_by, created_when, modified_when, workout_id, exercise_id, position, sets, reps, time_seconds, weight, notes)
+			SELECT 1, ?, ?, ?, ?, ?, exercise_id, position, sets, reps, time_seconds, weight, notes
+			FROM workout_exercise
+			WHERE id = ?
+		`, user.FirebaseUID, user.FirebaseUID, now, now, workoutID, sourceExerciseID)
+		if err != nil {
+			return 0, err
+		}
+		workoutExerciseID, err := result.LastInsertId()
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = executor.ExecContext(ctx, `
+			INSERT INTO workout_exercise_set (version, created_by, modified_by, created_when, modified_when, workout_exercise_id, position, set_type, reps, time_seconds, weight, rpe)
+			SELECT 1, ?, ?, ?, ?, ?, position, set_type, reps, time_seconds, weight, rpe
+			FROM workout_exercise_set
+			WHERE workout_exercise_id = ?
+			ORDER BY position ASC, id ASC
+		`, user.FirebaseUID, user.FirebaseUID, now, now, workoutExerciseID, sourceExerciseID)
+		if err != nil {
+			return 0, err
+		}
+	}
+	log.Printf("Cloned workout %d into workout %d with %d exercises", sourceID, workoutID, len(sourceExerciseIDs))
+
+	return workoutID, nil
 }
 
 // Delete deletes a workout
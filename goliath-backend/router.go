@@ -0,0 +1,350 @@
+package main
+
+import (
+	"database/sql"
+
+	"goliath/apperrors"
+	"goliath/authorization"
+	"goliath/config"
+	"goliath/entities"
+	"goliath/handlers"
+	"goliath/middleware"
+	"goliath/repositories"
+	"goliath/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newPolicy creates the authorization policy over the workouts in the database, shared with the
+// athletes' coaches, and the private sessions, programs, enrollments and schedules
+func newPolicy(db *sql.DB) *authorization.Policy {
+	return authorization.NewPolicy(
+		authorization.WorkoutKinds(
+			repositories.NewWorkoutRepository(db),
+			repositories.NewWorkoutExerciseRepository(db),
+			repositories.NewWorkoutExerciseSetRepository(db),
+			authorization.CoachingGrant(repositories.NewCoachingRepository(db)),
+		),
+		authorization.SessionKinds(
+			repositories.NewSessionRepository(db),
+			repositories.NewSessionSetRepository(db),
+		),
+		authorization.ProgramKinds(
+			repositories.NewProgramRepository(db),
+			repositories.NewProgramWeekRepository(db),
+			repositories.NewProgramDayRepository(db),
+			repositories.NewProgramEnrollmentRepository(db),
+		),
+		authorization.ScheduleKinds(repositories.NewScheduleRepository(db)),
+	)
+}
+
+// newRouter wires the repositories, services and handlers over the database and registers every
+// route of the API. Handlers of workouts, and of requests referring to them, ask policy for access.
+func newRouter(cfg *config.Config, db *sql.DB, tokenVerifier middleware.TokenVerifier, backupService *services.BackupService, policy handlers.Authorizer) *gin.Engine {
+	// Initialize repositories
+	regionRepo := repositories.NewRegionRepository(db)
+	muscleGroupRepo := repositories.NewMuscleGroupRepository(db)
+	exerciseAreaRepo := repositories.NewExerciseAreaRepository(db)
+	muscleRepo := repositories.NewMuscleRepository(db)
+	exerciseRepo := repositories.NewExerciseRepository(db)
+	userRepo := repositories.NewUserRepository(db)
+	workoutRepo := repositories.NewWorkoutRepository(db)
+	workoutExerciseRepo := repositories.NewWorkoutExerciseRepository(db)
+	workoutExerciseSetRepo := repositories.NewWorkoutExerciseSetRepository(db)
+	sessionRepo := repositories.NewSessionRepository(db)
+	sessionSetRepo := repositories.NewSessionSetRepository(db)
+	personalRecordRepo := repositories.NewPersonalRecordRepository(db)
+	analyticsRepo := repositories.NewAnalyticsRepository(db)
+	programRepo := repositories.NewProgramRepository(db)
+	programWeekRepo := repositories.NewProgramWeekRepository(db)
+	programDayRepo := repositories.NewProgramDayRepository(db)
+	enrollmentRepo := repositories.NewProgramEnrollmentRepository(db)
+	scheduleRepo := repositories.NewScheduleRepository(db)
+	calendarFeedRepo := repositories.NewCalendarFeedRepository(db)
+	registrationRepo := repositories.NewRegistrationRepository(db)
+	roleRepo := repositories.NewRoleRepository(db)
+	coachingRepo := repositories.NewCoachingRepository(db)
+	workoutChangeRepo := repositories.NewWorkoutChangeRepository(db)
+
+	// Initialize services
+	muscleService := services.NewMuscleService(muscleRepo, muscleGroupRepo, regionRepo, exerciseAreaRepo)
+	personalRecordService := services.NewPersonalRecordService(personalRecordRepo, exerciseRepo)
+	exerciseService := services.NewExerciseService(exerciseRepo, personalRecordService)
+	catalogService := services.NewCatalogService(exerciseRepo, muscleRepo, exerciseService)
+	userService := services.NewUserService(userRepo, roleRepo)
+	roleService := services.NewRoleService(roleRepo)
+	registrationService := services.NewRegistrationService(registrationRepo)
+	workoutService := services.NewWorkoutService(workoutRepo, workoutExerciseRepo, workoutExerciseSetRepo, exerciseRepo, workoutChangeRepo)
+	coachingService := services.NewCoachingService(coachingRepo, userRepo, workoutService)
+	analyticsService := services.NewAnalyticsService(analyticsRepo, muscleRepo, exerciseRepo, exerciseAreaRepo, workoutExerciseRepo)
+	sessionService := services.NewSessionService(sessionRepo, sessionSetRepo, workoutRepo, workoutExerciseRepo, exerciseRepo, personalRecordService)
+	programService := services.NewProgramService(programRepo, programWeekRepo, programDayRepo, enrollmentRepo, workoutExerciseRepo)
+	scheduleService := services.NewScheduleService(scheduleRepo, calendarFeedRepo, sessionRepo)
+	archiveService := services.NewArchiveService(workoutRepo, workoutExerciseRepo, workoutExerciseSetRepo, sessionRepo, sessionSetRepo, exerciseRepo, personalRecordService)
+
+	// Initialize handlers
+	muscleHandlers := handlers.NewMuscleHandlers(muscleService)
+	exerciseHandlers := handlers.NewExerciseHandlers(exerciseService)
+	catalogHandlers := handlers.NewCatalogHandlers(catalogService)
+	userHandlers := handlers.NewUserHandlers(userService)
+	registrationHandlers := handlers.NewRegistrationHandlers(registrationService)
+	roleHandlers := handlers.NewRoleHandlers(roleService)
+	workoutHandlers := handlers.NewWorkoutHandlers(workoutService, policy)
+	templateHandlers := handlers.NewTemplateHandlers(workoutService)
+	coachingHandlers := handlers.NewCoachingHandlers(coachingService)
+	sessionHandlers := handlers.NewSessionHandlers(sessionService, policy)
+	personalRecordHandlers := handlers.NewPersonalRecordHandlers(personalRecordService)
+	analyticsHandlers := handlers.NewAnalyticsHandlers(analyticsService, policy)
+	programHandlers := handlers.NewProgramHandlers(programService, policy)
+	scheduleHandlers := handlers.NewScheduleHandlers(scheduleService, policy)
+	archiveHandlers := handlers.NewArchiveHandlers(archiveService)
+	backupHandlers := handlers.NewBackupHandlers(backupService)
+
+	// Setup router - the request log is written at info level and below, gin's debug output
+	// (including the route table) only at debug level
+	if cfg.Log.Level != config.LogLevelDebug {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	r := gin.New()
+	if cfg.Log.Level == config.LogLevelDebug || cfg.Log.Level == config.LogLevelInfo {
+		r.Use(gin.Logger())
+	}
+	r.Use(gin.Recovery())
+
+	// Apply global middleware in order:
+	// 1. CORS - handle cross-origin requests from the allowed origins
+	r.Use(middleware.CORS(cfg.Server.CORSAllowedOrigins))
+
+	// 2. JWT (optional) - extract user info from token if present
+	r.Use(middleware.JWT(middleware.JWTConfig{
+		Verifier: tokenVerifier,
+		Required: false, // Allow requests without JWT
+	}))
+
+	// 3. User Loader - load full user details if JWT was present
+	r.Use(middleware.UserLoader(db))
+
+	// 4. Transaction - wrap ALL requests in database transaction
+	// Required because all repository operations now require a transaction
+	r.Use(middleware.Transaction(db))
+
+	// 5. Errors - write errors recorded by handlers as RFC 7807 problem responses
+	// Must run inside Transaction so failed requests are rolled back
+	r.Use(middleware.Errors())
+
+	r.NoRoute(func(c *gin.Context) {
+		middleware.WriteProblem(c, apperrors.NotFound("route_not_found", "no route for %s %s", c.Request.Method, c.Request.URL.Path))
+	})
+
+	// Health check endpoint (public, no auth required)
+	r.GET("/hello", func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"message": "hello",
+		})
+	})
+
+	// Public routes - no authentication required
+	public := r.Group("/")
+	{
+		// Muscle-related routes
+		public.GET("/regions", muscleHandlers.GetRegions)
+		public.GET("/muscle-groups", muscleHandlers.GetMuscleGroups)
+		public.GET("/exercise-areas", muscleHandlers.GetExerciseAreas)
+		public.GET("/muscles", muscleHandlers.GetMuscles)
+
+		// Exercise-related routes
+		public.GET("/exercises", exerciseHandlers.GetExercises)
+		public.GET("/exercises/search", exerciseHandlers.SearchExercises)
+		public.GET("/exercises/:id", exerciseHandlers.GetExercise)
+		public.GET("/exercise-types", exerciseHandlers.GetExerciseTypes)
+		public.GET("/set-types", workoutHandlers.GetSetTypes)
+
+		if cfg.Features.CalendarFeeds {
+			public.GET("/calendar/feeds/:token", scheduleHandlers.GetCalendarFeed)
+		}
+	}
+
+	// Authenticated user routes - requires authentication but not admin
+	auth := r.Group("/")
+	auth.Use(middleware.RequireAuth())
+	{
+		// Profile routes - the current user's own account
+		auth.GET("/me", userHandlers.GetMe)
+		auth.PUT("/me", userHandlers.UpdateMe)
+
+		// Workout routes - users can access their own workouts and those of athletes they coach
+		auth.GET("/workouts", workoutHandlers.GetWorkouts)
+		auth.GET("/workouts/:id", workoutHandlers.GetWorkout)
+		auth.POST("/workouts", workoutHandlers.CreateWorkout)
+		auth.PUT("/workouts/:id", workoutHandlers.UpdateWorkout)
+		auth.DELETE("/workouts/:id", workoutHandlers.DeleteWorkout)
+		auth.POST("/workouts/:id/duplicate", workoutHandlers.DuplicateWorkout)
+		auth.GET("/workouts/:id/changes", workoutHandlers.GetWorkoutChanges)
+
+		// Template routes - workouts shared by any user, cloned into the user's own workouts
+		auth.GET("/templates", templateHandlers.GetTemplates)
+		auth.GET("/templates/:id", templateHandlers.GetTemplate)
+		auth.GET("/templates/:id/exercises", templateHandlers.GetTemplateExercises)
+		auth.POST("/templates/:id/clone", templateHandlers.CloneTemplate)
+
+		// Workout exercise routes - manage exercises within workouts
+		auth.GET("/workouts/:id/exercises", workoutHandlers.GetWorkoutExercises)
+		auth.GET("/workouts/:id/exercises/:exercise_id", workoutHandlers.GetWorkoutExercise)
+		auth.POST("/workouts/:id/exercises", workoutHandlers.AddExerciseToWorkout)
+		auth.PUT("/workouts/:id/exercises/:exercise_id", workoutHandlers.UpdateWorkoutExercise)
+		auth.DELETE("/workouts/:id/exercises/:exercise_id", workoutHandlers.RemoveExerciseFromWorkout)
+
+		// Workout exercise set routes - individual sets (pyramids, drop sets, ...)
+		auth.GET("/workouts/:id/exercises/:exercise_id/sets", workoutHandlers.GetWorkoutExerciseSets)
+		auth.POST("/workouts/:id/exercises/:exercise_id/sets", workoutHandlers.AddSetToWorkoutExercise)
+		auth.PUT("/workouts/:id/exercises/:exercise_id/sets/:set_id", workoutHandlers.UpdateWorkoutExerciseSet)
+		auth.DELETE("/workouts/:id/exercises/:exercise_id/sets/:set_id", workoutHandlers.RemoveSetFromWorkoutExercise)
+
+		// Session routes - performed workouts and their actual set results
+		auth.GET("/sessions", sessionHandlers.GetSessions)
+		auth.GET("/sessions/:id", sessionHandlers.GetSession)
+		auth.POST("/sessions", sessionHandlers.StartSession)
+		auth.POST("/sessions/:id/finish", sessionHandlers.FinishSession)
+		auth.DELETE("/sessions/:id", sessionHandlers.DeleteSession)
+		auth.POST("/sessions/:id/sets", sessionHandlers.RecordSet)
+		auth.PUT("/sessions/:id/sets/:set_id", sessionHandlers.UpdateSet)
+		auth.DELETE("/sessions/:id/sets/:set_id", sessionHandlers.DeleteSet)
+
+		// Program routes - multi-week plans of the user's workouts with weekly progression
+		auth.GET("/programs", programHandlers.GetPrograms)
+		auth.GET("/programs/:id", programHandlers.GetProgram)
+		auth.POST("/programs", programHandlers.CreateProgram)
+		auth.PUT("/programs/:id", programHandlers.UpdateProgram)
+		auth.DELETE("/programs/:id", programHandlers.DeleteProgram)
+		auth.POST("/programs/:id/weeks", programHandlers.AddWeekToProgram)
+		auth.PUT("/programs/:id/weeks/:week_id", programHandlers.UpdateProgramWeek)
+		auth.DELETE("/programs/:id/weeks/:week_id", programHandlers.RemoveWeekFromProgram)
+		auth.POST("/programs/:id/weeks/:week_id/days", programHandlers.AddDayToProgramWeek)
+		auth.PUT("/programs/:id/weeks/:week_id/days/:day_id", programHandlers.UpdateProgramDay)
+		auth.DELETE("/programs/:id/weeks/:week_id/days/:day_id", programHandlers.RemoveDayFromProgramWeek)
+		auth.GET("/programs/:id/prescription", programHandlers.GetPrescription)
+
+		// Enrollment routes - the user's progress through programs
+		auth.POST("/programs/:id/enroll", programHandlers.EnrollInProgram)
+		auth.GET("/me/enrollments", programHandlers.GetMyEnrollments)
+		auth.GET("/me/enrollments/:id", programHandlers.GetMyEnrollment)
+		auth.PUT("/me/enrollments/:id", programHandlers.UpdateMyEnrollment)
+		auth.DELETE("/me/enrollments/:id", programHandlers.DeleteMyEnrollment)
+		auth.POST("/me/enrollments/:id/advance", programHandlers.AdvanceMyEnrollment)
+		auth.GET("/me/enrollments/:id/prescription", programHandlers.GetMyEnrollmentPrescription)
+
+		// Schedule routes - recurring plans of the user's workouts and the resulting calendar
+		auth.GET("/schedules", scheduleHandlers.GetSchedules)
+		auth.GET("/schedules/:id", scheduleHandlers.GetSchedule)
+		auth.POST("/schedules", scheduleHandlers.CreateSchedule)
+		auth.PUT("/schedules/:id", scheduleHandlers.UpdateSchedule)
+		auth.DELETE("/schedules/:id", scheduleHandlers.DeleteSchedule)
+		auth.GET("/me/calendar", scheduleHandlers.GetCalendar)
+		auth.POST("/me/calendar/feed", scheduleHandlers.RotateCalendarFeed)
+		auth.DELETE("/me/calendar/feed", scheduleHandlers.RevokeCalendarFeed)
+
+		// Personal record routes - detected automatically from performed sets
+		auth.GET("/me/records", personalRecordHandlers.GetMyRecords)
+		auth.GET("/exercises/:id/records", personalRecordHandlers.GetExerciseRecords)
+
+		// Coaching routes - athletes accept or revoke the coaches who may manage their workouts
+		auth.GET("/me/coaches", coachingHandlers.GetCoaches)
+		auth.POST("/me/coaches/:id/accept", coachingHandlers.AcceptCoach)
+		auth.DELETE("/me/coaches/:id", coachingHandlers.RevokeCoach)
+
+		// Archive routes - the user's workouts and sessions as a file, and imports from other trackers
+		auth.GET("/me/export", archiveHandlers.Export)
+		if cfg.Features.Imports {
+			auth.POST("/me/import", archiveHandlers.Import)
+		}
+
+		// Analytics routes - computed from finished sessions
+		auth.GET("/me/analytics/muscle-volume", analyticsHandlers.GetMuscleVolume)
+		auth.GET("/me/analytics/balance", analyticsHandlers.GetWeeklyBalance)
+		auth.GET("/workouts/:id/balance", analyticsHandlers.GetWorkoutBalance)
+	}
+
+	// Permission routes - require a permission granted by the role of the user, see GET /roles.
+	// ADMIN holds every permission.
+	exerciseWrite := middleware.RequirePermission(entities.PermissionExerciseWrite)
+	catalogRead := middleware.RequirePermission(entities.PermissionCatalogRead)
+	muscleWrite := middleware.RequirePermission(entities.PermissionMuscleWrite)
+	userRead := middleware.RequirePermission(entities.PermissionUserRead)
+	userRole := middleware.RequirePermission(entities.PermissionUserRole)
+	userStatus := middleware.RequirePermission(entities.PermissionUserStatus)
+	registrationRead := middleware.RequirePermission(entities.PermissionRegistrationRead)
+	registrationWrite := middleware.RequirePermission(entities.PermissionRegistrationWrite)
+	backupRead := middleware.RequirePermission(entities.PermissionBackupRead)
+	backupWrite := middleware.RequirePermission(entities.PermissionBackupWrite)
+	roleRead := middleware.RequirePermission(entities.PermissionRoleRead)
+	roleWrite := middleware.RequirePermission(entities.PermissionRoleWrite)
+	athleteCoach := middleware.RequirePermission(entities.PermissionAthleteCoach)
+	{
+		// Exercise editing (transaction is already global)
+		r.POST("/exercises", exerciseWrite, exerciseHandlers.CreateExercise)
+		r.PUT("/exercises/:id", exerciseWrite, exerciseHandlers.UpdateExercise)
+		r.DELETE("/exercises/:id", exerciseWrite, exerciseHandlers.ArchiveExercise)
+		r.POST("/exercises/:id/restore", exerciseWrite, exerciseHandlers.RestoreExercise)
+		r.POST("/exercises/:id/merge", exerciseWrite, exerciseHandlers.MergeExercise)
+
+		// User administration - lists every user's email, role and Firebase UID; nobody can
+		// change their own role or status
+		r.GET("/users", userRead, userHandlers.GetUsers)
+		r.GET("/users/:id", userRead, userHandlers.GetUser)
+		r.PUT("/users/:id/role", userRole, userHandlers.UpdateUserRole)
+		r.PUT("/users/:id/status", userStatus, userHandlers.UpdateUserStatus)
+
+		// Coaching - invite athletes and manage their workouts once they accept
+		r.GET("/me/athletes", athleteCoach, coachingHandlers.GetAthletes)
+		r.POST("/me/athletes", athleteCoach, coachingHandlers.InviteAthlete)
+		r.DELETE("/me/athletes/:id", athleteCoach, coachingHandlers.EndAthlete)
+		r.GET("/me/athletes/:id/workouts", athleteCoach, coachingHandlers.GetAthleteWorkouts)
+		r.POST("/me/athletes/:id/workouts", athleteCoach, coachingHandlers.CreateAthleteWorkout)
+
+		// Roles and the permissions they grant
+		r.GET("/permissions", roleRead, roleHandlers.GetPermissions)
+		r.GET("/roles", roleRead, roleHandlers.GetRoles)
+		r.GET("/roles/:name", roleRead, roleHandlers.GetRole)
+		r.POST("/roles", roleWrite, roleHandlers.CreateRole)
+		r.PUT("/roles/:name", roleWrite, roleHandlers.UpdateRole)
+		r.DELETE("/roles/:name", roleWrite, roleHandlers.DeleteRole)
+
+		// Registration - who may sign up, and single-use invites while registration is invite-only
+		r.GET("/registration", registrationRead, registrationHandlers.GetRegistrationPolicy)
+		r.PUT("/registration", registrationWrite, registrationHandlers.UpdateRegistrationPolicy)
+		r.GET("/invites", registrationRead, registrationHandlers.GetInvites)
+		r.POST("/invites", registrationWrite, registrationHandlers.CreateInvite)
+		r.DELETE("/invites/:id", registrationWrite, registrationHandlers.DeleteInvite)
+
+		// Exercise catalog as a file, to keep it in version control outside the database
+		r.GET("/catalog/export", catalogRead, catalogHandlers.ExportCatalog)
+		if cfg.Features.Imports {
+			r.POST("/catalog/import", exerciseWrite, catalogHandlers.ImportCatalog)
+		}
+
+		// Muscle taxonomy management - seeded by migrations, maintained by catalog editors
+		r.POST("/regions", muscleWrite, muscleHandlers.CreateRegion)
+		r.PUT("/regions/:id", muscleWrite, muscleHandlers.UpdateRegion)
+		r.DELETE("/regions/:id", muscleWrite, muscleHandlers.DeleteRegion)
+		r.POST("/muscle-groups", muscleWrite, muscleHandlers.CreateMuscleGroup)
+		r.PUT("/muscle-groups/:id", muscleWrite, muscleHandlers.UpdateMuscleGroup)
+		r.DELETE("/muscle-groups/:id", muscleWrite, muscleHandlers.DeleteMuscleGroup)
+		r.POST("/muscles", muscleWrite, muscleHandlers.CreateMuscle)
+		r.PUT("/muscles/:id", muscleWrite, muscleHandlers.UpdateMuscle)
+		r.DELETE("/muscles/:id", muscleWrite, muscleHandlers.DeleteMuscle)
+		r.POST("/muscles/:id/exercise-areas/:area_id", muscleWrite, muscleHandlers.LinkMuscleExerciseArea)
+		r.DELETE("/muscles/:id/exercise-areas/:area_id", muscleWrite, muscleHandlers.UnlinkMuscleExerciseArea)
+		r.POST("/exercise-areas", muscleWrite, muscleHandlers.CreateExerciseArea)
+		r.PUT("/exercise-areas/:id", muscleWrite, muscleHandlers.UpdateExerciseArea)
+		r.DELETE("/exercise-areas/:id", muscleWrite, muscleHandlers.DeleteExerciseArea)
+
+		// Database snapshots - also taken on a schedule, see backup.interval
+		r.GET("/backups", backupRead, backupHandlers.GetBackups)
+		r.POST("/backups", backupWrite, backupHandlers.CreateBackup)
+		r.POST("/backups/:name/verify", backupRead, backupHandlers.VerifyBackup)
+		r.POST("/backups/:name/restore", backupWrite, backupHandlers.RestoreBackup)
+	}
+
+	return r
+}
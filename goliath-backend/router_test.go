@@ -0,0 +1,368 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"goliath/apperrors"
+	"goliath/authorization"
+	"goliath/config"
+	"goliath/handlers"
+	"goliath/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authorizeCall is what a handler asked the policy for
+type authorizeCall struct {
+	Action   authorization.Action
+	Resource authorization.Resource
+}
+
+// errDenied is returned by the recording authorizer for every request
+var errDenied = apperrors.Forbidden("test_denied", "denied by the test")
+
+// recordingAuthorizer records what handlers ask for and denies every call after the first allow
+// ones, so requests stop before reaching services
+type recordingAuthorizer struct {
+	calls []authorizeCall
+	allow int
+}
+
+// Authorize records the call and denies it unless it is one of the first allow calls
+func (a *recordingAuthorizer) Authorize(ctx context.Context, action authorization.Action, resource authorization.Resource) error {
+	a.calls = append(a.calls, authorizeCall{action, resource})
+	if len(a.calls) <= a.allow {
+		return nil
+	}
+	return errDenied
+}
+
+// IDs in the URLs of the tests, and of the workout their bodies refer to
+const (
+	workoutID, workoutExerciseID, setID    = 10, 11, 12
+	referencedWorkoutID                    = 20
+	sessionID, sessionSetID                = 30, 31
+	programID, programWeekID, programDayID = 40, 41, 42
+	enrollmentID                           = 43
+	scheduleID                             = 50
+)
+
+// Authorizations of the routes of the tests
+var (
+	readWorkout           = []authorizeCall{{authorization.ActionRead, authorization.Workout(workoutID)}}
+	writeWorkout          = []authorizeCall{{authorization.ActionWrite, authorization.Workout(workoutID)}}
+	deleteWorkout         = []authorizeCall{{authorization.ActionDelete, authorization.Workout(workoutID)}}
+	readWorkoutExercise   = []authorizeCall{{authorization.ActionRead, authorization.WorkoutExercise(workoutID, workoutExerciseID)}}
+	writeWorkoutExercise  = []authorizeCall{{authorization.ActionWrite, authorization.WorkoutExercise(workoutID, workoutExerciseID)}}
+	writeSet              = []authorizeCall{{authorization.ActionWrite, authorization.WorkoutExerciseSet(workoutID, workoutExerciseID, setID)}}
+	readReferencedWorkout = []authorizeCall{{authorization.ActionRead, authorization.Workout(referencedWorkoutID)}}
+
+	readSession     = []authorizeCall{{authorization.ActionRead, authorization.Session(sessionID)}}
+	writeSession    = []authorizeCall{{authorization.ActionWrite, authorization.Session(sessionID)}}
+	deleteSession   = []authorizeCall{{authorization.ActionDelete, authorization.Session(sessionID)}}
+	writeSessionSet = []authorizeCall{{authorization.ActionWrite, authorization.SessionSet(sessionID, sessionSetID)}}
+
+	readProgram      = []authorizeCall{{authorization.ActionRead, authorization.Program(programID)}}
+	writeProgram     = []authorizeCall{{authorization.ActionWrite, authorization.Program(programID)}}
+	deleteProgram    = []authorizeCall{{authorization.ActionDelete, authorization.Program(programID)}}
+	writeProgramWeek = []authorizeCall{{authorization.ActionWrite, authorization.ProgramWeek(programID, programWeekID)}}
+	writeProgramDay  = []authorizeCall{{authorization.ActionWrite, authorization.ProgramDay(programID, programWeekID, programDayID)}}
+	readEnrollment   = []authorizeCall{{authorization.ActionRead, authorization.Enrollment(enrollmentID)}}
+	writeEnrollment  = []authorizeCall{{authorization.ActionWrite, authorization.Enrollment(enrollmentID)}}
+	deleteEnrollment = []authorizeCall{{authorization.ActionDelete, authorization.Enrollment(enrollmentID)}}
+
+	readSchedule   = []authorizeCall{{authorization.ActionRead, authorization.Schedule(scheduleID)}}
+	writeSchedule  = []authorizeCall{{authorization.ActionWrite, authorization.Schedule(scheduleID)}}
+	deleteSchedule = []authorizeCall{{authorization.ActionDelete, authorization.Schedule(scheduleID)}}
+)
+
+// then returns the calls of a route authorizing several resources, in order
+func then(calls ...[]authorizeCall) []authorizeCall {
+	var all []authorizeCall
+	for _, c := range calls {
+		all = append(all, c...)
+	}
+	return all
+}
+
+// Bodies of the routes of the tests that refer to a workout
+const (
+	referencedWorkoutBody  = `{"workout_id": 20}`
+	referencedScheduleBody = `{"workout_id": 20, "start_date": "2026-01-05"}`
+	referencedDayBody      = `{"workout_id": 20, "day_number": 1}`
+)
+
+// routeTest is a route of the router and what its handler must authorize
+type routeTest struct {
+	route string // Method and path as registered
+	path  string // Path of the request; empty for routes without a resource to authorize
+	body  string
+	want  []authorizeCall
+}
+
+// routeTests lists every route. Routes acting on a resource of the authorization package, or
+// referring to a workout in their body, are requested with the IDs above; the others only need to
+// be listed.
+var routeTests = []routeTest{
+	{route: "GET /hello"},
+
+	// Public routes
+	{route: "GET /regions"},
+	{route: "GET /muscle-groups"},
+	{route: "GET /exercise-areas"},
+	{route: "GET /muscles"},
+	{route: "GET /exercises"},
+	{route: "GET /exercises/search"},
+	{route: "GET /exercises/:id"},
+	{route: "GET /exercise-types"},
+	{route: "GET /set-types"},
+	{route: "GET /calendar/feeds/:token"},
+
+	// Profile
+	{route: "GET /me"},
+	{route: "PUT /me"},
+
+	// Workouts
+	{route: "GET /workouts"},
+	{route: "POST /workouts"},
+	{"GET /workouts/:id", "/workouts/10", "", readWorkout},
+	{"PUT /workouts/:id", "/workouts/10", `{}`, writeWorkout},
+	{"DELETE /workouts/:id", "/workouts/10", "", deleteWorkout},
+	{"POST /workouts/:id/duplicate", "/workouts/10/duplicate", "", writeWorkout},
+	{"GET /workouts/:id/changes", "/workouts/10/changes", "", readWorkout},
+	{"GET /workouts/:id/balance", "/workouts/10/balance", "", readWorkout},
+
+	// Workout exercises and their sets
+	{"GET /workouts/:id/exercises", "/workouts/10/exercises", "", readWorkout},
+	{"POST /workouts/:id/exercises", "/workouts/10/exercises", `{}`, writeWorkout},
+	{"GET /workouts/:id/exercises/:exercise_id", "/workouts/10/exercises/11", "", readWorkoutExercise},
+	{"PUT /workouts/:id/exercises/:exercise_id", "/workouts/10/exercises/11", `{}`, writeWorkoutExercise},
+	{"DELETE /workouts/:id/exercises/:exercise_id", "/workouts/10/exercises/11", "", writeWorkoutExercise},
+	{"GET /workouts/:id/exercises/:exercise_id/sets", "/workouts/10/exercises/11/sets", "", readWorkoutExercise},
+	{"POST /workouts/:id/exercises/:exercise_id/sets", "/workouts/10/exercises/11/sets", `{}`, writeWorkoutExercise},
+	{"PUT /workouts/:id/exercises/:exercise_id/sets/:set_id", "/workouts/10/exercises/11/sets/12", `{}`, writeSet},
+	{"DELETE /workouts/:id/exercises/:exercise_id/sets/:set_id", "/workouts/10/exercises/11/sets/12", "", writeSet},
+
+	// Templates
+	{route: "GET /templates"},
+	{route: "GET /templates/:id"},
+	{route: "GET /templates/:id/exercises"},
+	{route: "POST /templates/:id/clone"},
+
+	// Sessions
+	{route: "GET /sessions"},
+	{"GET /sessions/:id", "/sessions/30", "", readSession},
+	{"POST /sessions", "/sessions", referencedWorkoutBody, readReferencedWorkout},
+	{"POST /sessions/:id/finish", "/sessions/30/finish", "", writeSession},
+	{"DELETE /sessions/:id", "/sessions/30", "", deleteSession},
+	{"POST /sessions/:id/sets", "/sessions/30/sets", `{}`, writeSession},
+	{"PUT /sessions/:id/sets/:set_id", "/sessions/30/sets/31", `{}`, writeSessionSet},
+	{"DELETE /sessions/:id/sets/:set_id", "/sessions/30/sets/31", "", writeSessionSet},
+
+	// Programs and enrollments
+	{route: "GET /programs"},
+	{"GET /programs/:id", "/programs/40", "", readProgram},
+	{route: "POST /programs"},
+	{"PUT /programs/:id", "/programs/40", `{}`, writeProgram},
+	{"DELETE /programs/:id", "/programs/40", "", deleteProgram},
+	{"POST /programs/:id/weeks", "/programs/40/weeks", `{}`, writeProgram},
+	{"PUT /programs/:id/weeks/:week_id", "/programs/40/weeks/41", `{}`, writeProgramWeek},
+	{"DELETE /programs/:id/weeks/:week_id", "/programs/40/weeks/41", "", writeProgramWeek},
+	{"POST /programs/:id/weeks/:week_id/days", "/programs/40/weeks/41/days", referencedDayBody, then(writeProgramWeek, readReferencedWorkout)},
+	{"PUT /programs/:id/weeks/:week_id/days/:day_id", "/programs/40/weeks/41/days/42", referencedDayBody, then(writeProgramDay, readReferencedWorkout)},
+	{"DELETE /programs/:id/weeks/:week_id/days/:day_id", "/programs/40/weeks/41/days/42", "", writeProgramDay},
+	{"GET /programs/:id/prescription", "/programs/40/prescription?week=1&day=1", "", readProgram},
+	{"POST /programs/:id/enroll", "/programs/40/enroll", "", readProgram},
+	{route: "GET /me/enrollments"},
+	{"GET /me/enrollments/:id", "/me/enrollments/43", "", readEnrollment},
+	{"PUT /me/enrollments/:id", "/me/enrollments/43", `{}`, writeEnrollment},
+	{"DELETE /me/enrollments/:id", "/me/enrollments/43", "", deleteEnrollment},
+	{"POST /me/enrollments/:id/advance", "/me/enrollments/43/advance", "", writeEnrollment},
+	{"GET /me/enrollments/:id/prescription", "/me/enrollments/43/prescription", "", readEnrollment},
+
+	// Schedules and the calendar
+	{route: "GET /schedules"},
+	{"GET /schedules/:id", "/schedules/50", "", readSchedule},
+	{"POST /schedules", "/schedules", referencedScheduleBody, readReferencedWorkout},
+	{"PUT /schedules/:id", "/schedules/50", referencedScheduleBody, then(writeSchedule, readReferencedWorkout)},
+	{"DELETE /schedules/:id", "/schedules/50", "", deleteSchedule},
+	{route: "GET /me/calendar"},
+	{route: "POST /me/calendar/feed"},
+	{route: "DELETE /me/calendar/feed"},
+
+	// Personal records
+	{route: "GET /me/records"},
+	{route: "GET /exercises/:id/records"},
+
+	// Coaching
+	{route: "GET /me/coaches"},
+	{route: "POST /me/coaches/:id/accept"},
+	{route: "DELETE /me/coaches/:id"},
+	{route: "GET /me/athletes"},
+	{route: "POST /me/athletes"},
+	{route: "DELETE /me/athletes/:id"},
+	{route: "GET /me/athletes/:id/workouts"},
+	{route: "POST /me/athletes/:id/workouts"},
+
+	// Archives and analytics
+	{route: "GET /me/export"},
+	{route: "POST /me/import"},
+	{route: "GET /me/analytics/muscle-volume"},
+	{route: "GET /me/analytics/balance"},
+
+	// Exercise editing and the catalog
+	{route: "POST /exercises"},
+	{route: "PUT /exercises/:id"},
+	{route: "DELETE /exercises/:id"},
+	{route: "POST /exercises/:id/restore"},
+	{route: "POST /exercises/:id/merge"},
+	{route: "GET /catalog/export"},
+	{route: "POST /catalog/import"},
+
+	// Administration
+	{route: "GET /users"},
+	{route: "GET /users/:id"},
+	{route: "PUT /users/:id/role"},
+	{route: "PUT /users/:id/status"},
+	{route: "GET /permissions"},
+	{route: "GET /roles"},
+	{route: "GET /roles/:name"},
+	{route: "POST /roles"},
+	{route: "PUT /roles/:name"},
+	{route: "DELETE /roles/:name"},
+	{route: "GET /registration"},
+	{route: "PUT /registration"},
+	{route: "GET /invites"},
+	{route: "POST /invites"},
+	{route: "DELETE /invites/:id"},
+
+	// Muscle taxonomy
+	{route: "POST /regions"},
+	{route: "PUT /regions/:id"},
+	{route: "DELETE /regions/:id"},
+	{route: "POST /muscle-groups"},
+	{route: "PUT /muscle-groups/:id"},
+	{route: "DELETE /muscle-groups/:id"},
+	{route: "POST /muscles"},
+	{route: "PUT /muscles/:id"},
+	{route: "DELETE /muscles/:id"},
+	{route: "POST /muscles/:id/exercise-areas/:area_id"},
+	{route: "DELETE /muscles/:id/exercise-areas/:area_id"},
+	{route: "POST /exercise-areas"},
+	{route: "PUT /exercise-areas/:id"},
+	{route: "DELETE /exercise-areas/:id"},
+
+	// Backups
+	{route: "GET /backups"},
+	{route: "POST /backups"},
+	{route: "POST /backups/:name/verify"},
+	{route: "POST /backups/:name/restore"},
+}
+
+// newTestRouter creates the router over a new database, with every feature enabled, every bearer
+// token authenticating as one user and the given authorizer
+func newTestRouter(t *testing.T, authorizer handlers.Authorizer) *gin.Engine {
+	t.Helper()
+	cfg, db := newTestDB(t)
+	verifier := middleware.NewStaticVerifier("route-test", "route-test@example.com")
+	return newRouter(cfg, db, verifier, newBackupService(cfg), authorizer)
+}
+
+// newTestDB creates a new database in a temporary directory and the default configuration using it
+func newTestDB(t *testing.T) (*config.Config, *sql.DB) {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := config.Default()
+	cfg.Database.Path = filepath.Join(dir, "goliath.db")
+	cfg.Backup.Dir = filepath.Join(dir, "backups")
+	cfg.Log.Level = config.LogLevelWarn
+
+	db, err := InitDB(cfg.Database)
+	if err != nil {
+		t.Fatalf("failed to initialize the database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return cfg, db
+}
+
+// TestRoutesListed checks that the tests list exactly the routes of the router, so a new route
+// cannot skip the authorization tests
+func TestRoutesListed(t *testing.T) {
+	router := newTestRouter(t, &recordingAuthorizer{})
+
+	listed := make(map[string]bool, len(routeTests))
+	for _, rt := range routeTests {
+		if listed[rt.route] {
+			t.Errorf("%s is listed twice", rt.route)
+		}
+		listed[rt.route] = true
+	}
+
+	registered := make(map[string]bool)
+	for _, info := range router.Routes() {
+		route := info.Method + " " + info.Path
+		registered[route] = true
+		if !listed[route] {
+			t.Errorf("%s is not listed in routeTests", route)
+		}
+	}
+	for route := range listed {
+		if !registered[route] {
+			t.Errorf("%s is listed in routeTests but not registered", route)
+		}
+	}
+}
+
+// TestRoutesAuthorize requests every route acting on a resource through the router and checks
+// that its handler asks for the expected actions on the resources of the URL and body, in order,
+// and stops when any of them is denied
+func TestRoutesAuthorize(t *testing.T) {
+	authorizer := &recordingAuthorizer{}
+	router := newTestRouter(t, authorizer)
+
+	for _, rt := range routeTests {
+		if rt.path == "" {
+			continue
+		}
+		t.Run(rt.route, func(t *testing.T) {
+			// Deny each call in turn, allowing the ones before it
+			for denied := range rt.want {
+				authorizer.calls = nil
+				authorizer.allow = denied
+				method := strings.Fields(rt.route)[0]
+				req := httptest.NewRequest(method, rt.path, strings.NewReader(rt.body))
+				req.Header.Set("Authorization", "Bearer test")
+				if rt.body != "" {
+					req.Header.Set("Content-Type", "application/json")
+				}
+				if method == http.MethodPut {
+					req.Header.Set("If-Match", `"1"`) // Updates are refused without an expected version
+				}
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+
+				if want := rt.want[:denied+1]; !reflect.DeepEqual(authorizer.calls, want) {
+					t.Errorf("denying call %d: authorized %+v, want %+v", denied+1, authorizer.calls, want)
+				}
+
+				var problem struct {
+					Code string `json:"code"`
+				}
+				if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+					t.Fatalf("denying call %d: failed to decode response %q: %v", denied+1, rec.Body.String(), err)
+				}
+				if rec.Code != http.StatusForbidden || problem.Code != errDenied.Code {
+					t.Errorf("denying call %d: got %d %q, want %d %q", denied+1, rec.Code, problem.Code, http.StatusForbidden, errDenied.Code)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"goliath/apperrors"
+	"goliath/entities"
+	"goliath/repositories"
+)
+
+// Granularity values supported by the analytics endpoints
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// AnalyticsService handles training analytics over performed workouts
+type AnalyticsService struct {
+	analyticsRepo       *repositories.AnalyticsRepository
+	muscleRepo          *repositories.MuscleRepository
+	exerciseRepo        *repositories.ExerciseRepository
+	exerciseAreaRepo    *repositories.ExerciseAreaRepository
+	workoutExerciseRepo *repositories.WorkoutExerciseRepository
+}
+
+// NewAnalyticsService creates a new AnalyticsService
+func NewAnalyticsService(
+	analyticsRepo *repositories.AnalyticsRepository,
+	muscleRepo *repositories.MuscleRepository,
+	exerciseRepo *repositories.ExerciseRepository,
+	exerciseAreaRepo *repositories.ExerciseAreaRepository,
+	workoutExerciseRepo *repositories.WorkoutExerciseRepository,
+) *AnalyticsService {
+	return &AnalyticsService{
+		analyticsRepo:       analyticsRepo,
+		muscleRepo:          muscleRepo,
+		exerciseRepo:        exerciseRepo,
+		exerciseAreaRepo:    exerciseAreaRepo,
+		workoutExerciseRepo: workoutExerciseRepo,
+	}
+}
+
+// MuscleVolumeReport represents muscle training volume over a date range
+type MuscleVolumeReport struct {
+	From             time.Time                       `json:"from"`
+	To               time.Time                       `json:"to"`
+	Granularity      string                          `json:"granularity"`
+	Periods          []entities.TrainingVolumePeriod `json:"periods"`
+	Totals           entities.TrainingVolumePeriod   `json:"totals"`
+	UntrainedMuscles []entities.TrainingVolume       `json:"untrained_muscles"` // Muscles with no volume in the range
+}
+
+// PeriodStart truncates a time to the start of its day, ISO week (Monday) or month in UTC
+func PeriodStart(t time.Time, granularity string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch granularity {
+	case GranularityWeek:
+		offset := (int(day.Weekday()) + 6) % 7 // Days since Monday
+		return day.AddDate(0, 0, -offset)
+	case GranularityMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// ValidateGranularity checks that a granularity is supported
+func ValidateGranularity(granularity string) error {
+	switch granularity {
+	case GranularityDay, GranularityWeek, GranularityMonth:
+		return nil
+	}
+	return apperrors.Invalid("invalid_granularity", "invalid granularity: %s", granularity).WithField("granularity", "must be one of day, week, month")
+}
+
+// volumeAccumulator sums training volume by ID while keeping names
+type volumeAccumulator map[int]*entities.TrainingVolume
+
+// add adds a fraction of a set's work to the volume of an entity
+func (a volumeAccumulator) add(id int, name string, fraction float64, w repositories.MuscleSetWork) {
+	v, ok := a[id]
+	if !ok {
+		v = &entities.TrainingVolume{ID: id, Name: name}
+		a[id] = v
+	}
+	v.Sets += fraction
+	if w.Reps != nil {
+		v.Reps += fraction * float64(*w.Reps)
+		if w.Weight != nil {
+			v.Load += fraction * float64(*w.Reps) * *w.Weight
+		}
+	}
+	if w.TimeSeconds != nil {
+		v.TimeSeconds += fraction * float64(*w.TimeSeconds)
+	}
+}
+
+// list returns the accumulated volumes ordered by effective sets, highest first
+func (a volumeAccumulator) list() []entities.TrainingVolume {
+	volumes := make([]entities.TrainingVolume, 0, len(a))
+	for _, v := range a {
+		volumes = append(volumes, *v)
+	}
+	sort.Slice(volumes, func(i, j int) bool {
+		if volumes[i].Sets != volumes[j].Sets {
+			return volumes[i].Sets > volumes[j].Sets
+		}
+		return volumes[i].ID < volumes[j].ID
+	})
+	return volumes
+}
+
+// volumeBucket accumulates the volume of one period across the muscle taxonomy
+type volumeBucket struct {
+	muscles      volumeAccumulator
+	muscleGroups volumeAccumulator
+	regions      volumeAccumulator
+}
+
+func newVolumeBucket() *volumeBucket {
+	return &volumeBucket{
+		muscles:      volumeAccumulator{},
+		muscleGroups: volumeAccumulator{},
+		regions:      volumeAccumulator{},
+	}
+}
+
+// add rolls a set's work up through muscle, muscle group and region
+func (b *volumeBucket) add(w repositories.MuscleSetWork) {
+	fraction := w.Percentage / 100
+	b.muscles.add(w.MuscleID, w.MuscleName, fraction, w)
+	b.muscleGroups.add(w.MuscleGroupID, w.MuscleGroupName, fraction, w)
+	b.regions.add(w.RegionID, w.RegionName, fraction, w)
+}
+
+// period converts the bucket into a period result
+func (b *volumeBucket) period(start time.Time) entities.TrainingVolumePeriod {
+	return entities.TrainingVolumePeriod{
+		PeriodStart:  start,
+		Muscles:      b.muscles.list(),
+		MuscleGroups: b.muscleGroups.list(),
+		Regions:      b.regions.list(),
+	}
+}
+
+// GetMuscleVolume computes a user's training volume per muscle, muscle group and region from
+// finished sessions in [from, to), multiplying completed sets, reps and load by each muscle's
+// percentage in the exercise
+func (s *AnalyticsService) GetMuscleVolume(ctx context.Context, userID int, from time.Time, to time.Time, granularity string) (*MuscleVolumeReport, error) {
+	if err := ValidateGranularity(granularity); err != nil {
+		return nil, err
+	}
+	if !from.Before(to) {
+		return nil, apperrors.Invalid("invalid_date_range", "invalid date range: from must be before to")
+	}
+
+	work, err := s.analyticsRepo.GetMuscleWorkForUser(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make(map[time.Time]*volumeBucket)
+	starts := []time.Time{}
+	totals := newVolumeBucket()
+	for _, w := range work {
+		start := PeriodStart(w.PerformedWhen, granularity)
+		bucket, ok := buckets[start]
+		if !ok {
+			bucket = newVolumeBucket()
+			buckets[start] = bucket
+			starts = append(starts, start)
+		}
+		bucket.add(w)
+		totals.add(w)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	report := &MuscleVolumeReport{
+		From:             from,
+		To:               to,
+		Granularity:      granularity,
+		Periods:          make([]entities.TrainingVolumePeriod, 0, len(starts)),
+		Totals:           totals.period(PeriodStart(from, granularity)),
+		UntrainedMuscles: []entities.TrainingVolume{},
+	}
+	for _, start := range starts {
+		report.Periods = append(report.Periods, buckets[start].period(start))
+	}
+
+	// Report muscles that received no volume at all
+	muscles, err := s.muscleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range muscles {
+		if _, trained := totals.muscles[m.ID]; !trained {
+			report.UntrainedMuscles = append(report.UntrainedMuscles, entities.TrainingVolume{ID: m.ID, Name: m.Name})
+		}
+	}
+
+	return report, nil
+}
+
+// GetWorkoutBalance computes the exercise area balance of a workout.
+// Each exercise counts once per planned set (once when no sets are planned).
+func (s *AnalyticsService) GetWorkoutBalance(ctx context.Context, workoutID int) (*entities.BalanceReport, error) {
+	workoutExercises, err := s.workoutExerciseRepo.GetAllForWorkout(ctx, workoutID)
+	if err != nil {
+		return nil, err
+	}
+
+	setsByExercise := make(map[int]float64)
+	for _, we := range workoutExercises {
+		sets := 1.0
+		if we.Sets != nil && *we.Sets > 0 {
+			sets = float64(*we.Sets)
+		}
+		setsByExercise[we.ExerciseID] += sets
+	}
+
+	return s.computeBalance(ctx, setsByExercise)
+}
+
+// GetWeeklyBalance computes the exercise area balance of the sets a user performed in the
+// finished sessions of the ISO week containing the given day
+func (s *AnalyticsService) GetWeeklyBalance(ctx context.Context, userID int, day time.Time) (*entities.BalanceReport, error) {
+	from := PeriodStart(day, GranularityWeek)
+	to := from.AddDate(0, 0, 7)
+
+	counts, err := s.analyticsRepo.GetSetCountsByExerciseForUser(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	setsByExercise := make(map[int]float64, len(counts))
+	for exerciseID, count := range counts {
+		setsByExercise[exerciseID] = float64(count)
+	}
+
+	return s.computeBalance(ctx, setsByExercise)
+}
+
+// computeBalance combines the ExerciseAreaSummary percentages of exercises weighted by set counts
+func (s *AnalyticsService) computeBalance(ctx context.Context, setsByExercise map[int]float64) (*entities.BalanceReport, error) {
+	areasByExercise, err := s.exerciseRepo.GetExerciseAreasForAllExercises(ctx)
+	if err != nil {
+		return nil, err
+	}
+	allAreas, err := s.exerciseAreaRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[int]*entities.AreaBalance)
+	total := 0.0
+	for exerciseID, sets := range setsByExercise {
+		for _, area := range areasByExercise[exerciseID] {
+			balance, ok := scores[area.ExerciseAreaID]
+			if !ok {
+				balance = &entities.AreaBalance{ExerciseAreaID: area.ExerciseAreaID, ExerciseAreaName: area.ExerciseAreaName}
+				scores[area.ExerciseAreaID] = balance
+			}
+			balance.Score += sets * area.Percentage
+			total += sets * area.Percentage
+		}
+	}
+
+	report := &entities.BalanceReport{
+		Areas:          make([]entities.AreaBalance, 0, len(scores)),
+		PushPull:       []entities.PushPullRatio{},
+		UntouchedAreas: []entities.ExerciseArea{},
+	}
+	for _, balance := range scores {
+		if total > 0 {
+			balance.Share = balance.Score / total * 100
+		}
+		report.Areas = append(report.Areas, *balance)
+	}
+	sort.Slice(report.Areas, func(i, j int) bool {
+		if report.Areas[i].Score != report.Areas[j].Score {
+			return report.Areas[i].Score > report.Areas[j].Score
+		}
+		return report.Areas[i].ExerciseAreaID < report.Areas[j].ExerciseAreaID
+	})
+
+	// Push/pull per plane, from the plane and direction of the areas
+	areasByID := make(map[int]entities.ExerciseArea, len(allAreas))
+	for _, area := range allAreas {
+		areasByID[area.ID] = area
+	}
+	ratios := make(map[entities.MovementPlane]*entities.PushPullRatio)
+	totalRatio := &entities.PushPullRatio{Plane: "Total"}
+	for _, plane := range entities.MovementPlanes {
+		ratios[plane] = &entities.PushPullRatio{Plane: string(plane)}
+	}
+	for _, balance := range report.Areas {
+		area := areasByID[balance.ExerciseAreaID]
+		if area.Plane == nil || area.Direction == nil {
+			continue
+		}
+		ratio, ok := ratios[*area.Plane]
+		if !ok {
+			continue
+		}
+		switch *area.Direction {
+		case entities.MovementDirectionPush:
+			ratio.Push += balance.Score
+			totalRatio.Push += balance.Score
+		case entities.MovementDirectionPull:
+			ratio.Pull += balance.Score
+			totalRatio.Pull += balance.Score
+		}
+	}
+	for _, plane := range entities.MovementPlanes {
+		report.PushPull = append(report.PushPull, *ratios[plane])
+	}
+	report.PushPull = append(report.PushPull, *totalRatio)
+	for i := range report.PushPull {
+		if report.PushPull[i].Pull > 0 {
+			ratio := report.PushPull[i].Push / report.PushPull[i].Pull
+			report.PushPull[i].Ratio = &ratio
+		}
+	}
+
+	for _, area := range allAreas {
+		if _, touched := scores[area.ID]; !touched {
+			report.UntouchedAreas = append(report.UntouchedAreas, area)
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,520 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"goliath/apperrors"
+	"goliath/entities"
+)
+
+// Kinds of rows of the Goliath CSV archive. Exercise rows belong to the workout row above them,
+// set rows to the exercise or session row above them.
+const (
+	csvRecordWorkout         = "workout"
+	csvRecordWorkoutExercise = "workout_exercise"
+	csvRecordWorkoutSet      = "workout_set"
+	csvRecordSession         = "session"
+	csvRecordSessionSet      = "session_set"
+)
+
+// archiveCSVHeader is the header of the Goliath CSV archive
+var archiveCSVHeader = []string{
+	"record", "name", "workout", "shareable", "started_when", "finished_when", "exercise", "position",
+	"sets", "set_number", "set_type", "reps", "time_seconds", "weight", "rpe", "notes",
+}
+
+// importTimeLayouts are the timestamp layouts accepted in CSV imports
+var importTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2 Jan 2006, 15:04", // Hevy
+	"2006-01-02",
+}
+
+// WriteArchiveCSV writes an archive as Goliath CSV, one row per workout, exercise, set and session
+func WriteArchiveCSV(w io.Writer, archive *Archive) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(archiveCSVHeader); err != nil {
+		return err
+	}
+
+	row := func(values map[string]string) error {
+		record := make([]string, len(archiveCSVHeader))
+		for i, column := range archiveCSVHeader {
+			record[i] = values[column]
+		}
+		return writer.Write(record)
+	}
+
+	for _, workout := range archive.Workouts {
+		if err := row(map[string]string{"record": csvRecordWorkout, "name": workout.Name, "shareable": strconv.FormatBool(workout.Shareable)}); err != nil {
+			return err
+		}
+		for _, exercise := range workout.Exercises {
+			if err := row(map[string]string{
+				"record":       csvRecordWorkoutExercise,
+				"exercise":     exercise.Exercise,
+				"position":     strconv.Itoa(exercise.Position),
+				"sets":         formatOptInt(exercise.Sets),
+				"reps":         formatOptInt(exercise.Reps),
+				"time_seconds": formatOptInt(exercise.TimeSeconds),
+				"weight":       formatOptFloat(exercise.Weight),
+				"notes":        formatOptString(exercise.Notes),
+			}); err != nil {
+				return err
+			}
+			for _, set := range exercise.SetDetails {
+				if err := row(map[string]string{
+					"record":       csvRecordWorkoutSet,
+					"position":     strconv.Itoa(set.Position),
+					"set_type":     string(set.SetType),
+					"reps":         formatOptInt(set.Reps),
+					"time_seconds": formatOptInt(set.TimeSeconds),
+					"weight":       formatOptFloat(set.Weight),
+					"rpe":          formatOptFloat(set.RPE),
+				}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, session := range archive.Sessions {
+		finished := ""
+		if session.FinishedWhen != nil {
+			finished = session.FinishedWhen.UTC().Format(time.RFC3339)
+		}
+		if err := row(map[string]string{
+			"record":        csvRecordSession,
+			"name":          session.Name,
+			"workout":       formatOptString(session.Workout),
+			"started_when":  session.StartedWhen.UTC().Format(time.RFC3339),
+			"finished_when": finished,
+			"notes":         formatOptString(session.Notes),
+		}); err != nil {
+			return err
+		}
+		for _, set := range session.Sets {
+			if err := row(map[string]string{
+				"record":       csvRecordSessionSet,
+				"exercise":     set.Exercise,
+				"set_number":   strconv.Itoa(set.SetNumber),
+				"reps":         formatOptInt(set.Reps),
+				"time_seconds": formatOptInt(set.TimeSeconds),
+				"weight":       formatOptFloat(set.Weight),
+				"notes":        formatOptString(set.Notes),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatOptInt formats an optional integer, empty when absent
+func formatOptInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+// formatOptFloat formats an optional number without trailing zeros, empty when absent
+func formatOptFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+// formatOptString formats an optional string, empty when absent
+func formatOptString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// csvTable is a CSV file read with its header, with columns looked up by lower case name
+type csvTable struct {
+	columns map[string]int
+	rows    [][]string
+}
+
+// readCSVTable reads a CSV file with a header row, ignoring a byte order mark
+func readCSVTable(data []byte) (*csvTable, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, apperrors.Invalid("invalid_import", "import file is not valid CSV").Wrap(err)
+	}
+	if len(records) == 0 {
+		return nil, apperrors.Invalid("invalid_import", "import file is empty")
+	}
+
+	table := &csvTable{columns: make(map[string]int), rows: records[1:]}
+	for i, name := range records[0] {
+		table.columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	return table, nil
+}
+
+// has reports whether the table has all of the given columns
+func (t *csvTable) has(columns ...string) bool {
+	for _, column := range columns {
+		if _, ok := t.columns[column]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// csvRow is one row of a csvTable with the problems found while reading it
+type csvRow struct {
+	table    *csvTable
+	values   []string
+	location string
+	problems *importProblems
+}
+
+// each calls fn for every row of the table, locating rows by line number
+func (t *csvTable) each(problems *importProblems, fn func(row csvRow)) {
+	for i, values := range t.rows {
+		fn(csvRow{table: t, values: values, location: fmt.Sprintf("line %d", i+2), problems: problems})
+	}
+}
+
+// str returns the trimmed value of a column, empty when the column or value is missing
+func (r csvRow) str(column string) string {
+	i, ok := r.table.columns[column]
+	if !ok || i >= len(r.values) {
+		return ""
+	}
+	return strings.TrimSpace(r.values[i])
+}
+
+// optStr returns the value of a column, nil when empty
+func (r csvRow) optStr(column string) *string {
+	value := r.str(column)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// optInt parses an integer column, nil when empty
+func (r csvRow) optInt(column string) *int {
+	value := r.str(column)
+	if value == "" {
+		return nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		// Trackers write whole numbers like reps as decimals, e.g. "8.0"
+		f, ferr := strconv.ParseFloat(value, 64)
+		if ferr != nil || f != float64(int(f)) {
+			r.problems.add(r.location, "%s must be a whole number", column)
+			return nil
+		}
+		parsed = int(f)
+	}
+	return &parsed
+}
+
+// optFloat parses a numeric column, nil when empty
+func (r csvRow) optFloat(column string) *float64 {
+	value := r.str(column)
+	if value == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		r.problems.add(r.location, "%s must be a number", column)
+		return nil
+	}
+	return &parsed
+}
+
+// optTime parses a timestamp column in UTC, nil when empty
+func (r csvRow) optTime(column string) *time.Time {
+	value := r.str(column)
+	if value == "" {
+		return nil
+	}
+	for _, layout := range importTimeLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			parsed = parsed.UTC()
+			return &parsed
+		}
+	}
+	r.problems.add(r.location, "%s must be a timestamp like 2006-01-02 15:04:05", column)
+	return nil
+}
+
+// positive drops zero and negative values trackers write for unused fields
+func positive[T int | float64](value *T) *T {
+	if value == nil || *value <= 0 {
+		return nil
+	}
+	return value
+}
+
+// detectCSVFormat identifies a CSV import by its header, empty when it is not recognized
+func detectCSVFormat(data []byte) string {
+	table, err := readCSVTable(data)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case table.has("record", "exercise", "set_number"):
+		return ArchiveFormatCSV
+	case table.has("exercise name", "set order", "workout name"):
+		return ArchiveFormatStrong
+	case table.has("exercise_title", "set_index", "start_time"):
+		return ArchiveFormatHevy
+	}
+	return ""
+}
+
+// parseGoliathCSV reads a CSV archive written by WriteArchiveCSV
+func parseGoliathCSV(data []byte) (*Archive, error) {
+	table, err := readCSVTable(data)
+	if err != nil {
+		return nil, err
+	}
+	if !table.has(archiveCSVHeader...) {
+		return nil, apperrors.Invalid("invalid_import", "import file is missing columns of the CSV archive").WithField("line 1", "must have the columns %s", strings.Join(archiveCSVHeader, ","))
+	}
+
+	archive := &Archive{Workouts: []ArchiveWorkout{}, Sessions: []ArchiveSession{}}
+	var problems importProblems
+	var workout *ArchiveWorkout
+	var exercise *ArchiveWorkoutExercise
+	var session *ArchiveSession
+	table.each(&problems, func(row csvRow) {
+		switch record := row.str("record"); record {
+		case csvRecordWorkout:
+			archive.Workouts = append(archive.Workouts, ArchiveWorkout{
+				Name:      row.str("name"),
+				Shareable: row.str("shareable") == "true",
+				Exercises: []ArchiveWorkoutExercise{},
+				location:  row.location,
+			})
+			workout, exercise, session = &archive.Workouts[len(archive.Workouts)-1], nil, nil
+		case csvRecordWorkoutExercise:
+			if workout == nil {
+				problems.add(row.location, "workout_exercise row must follow a workout row")
+				return
+			}
+			position := row.optInt("position")
+			workout.Exercises = append(workout.Exercises, ArchiveWorkoutExercise{
+				Exercise:    row.str("exercise"),
+				Position:    valueOr(position, 0),
+				Sets:        row.optInt("sets"),
+				Reps:        row.optInt("reps"),
+				TimeSeconds: row.optInt("time_seconds"),
+				Weight:      row.optFloat("weight"),
+				Notes:       row.optStr("notes"),
+				location:    row.location,
+			})
+			exercise = &workout.Exercises[len(workout.Exercises)-1]
+		case csvRecordWorkoutSet:
+			if exercise == nil {
+				problems.add(row.location, "workout_set row must follow a workout_exercise row")
+				return
+			}
+			position := row.optInt("position")
+			exercise.SetDetails = append(exercise.SetDetails, ArchiveWorkoutSet{
+				Position:    valueOr(position, 0),
+				SetType:     entities.SetType(row.str("set_type")),
+				Reps:        row.optInt("reps"),
+				TimeSeconds: row.optInt("time_seconds"),
+				Weight:      row.optFloat("weight"),
+				RPE:         row.optFloat("rpe"),
+				location:    row.location,
+			})
+		case csvRecordSession:
+			started := row.optTime("started_when")
+			archive.Sessions = append(archive.Sessions, ArchiveSession{
+				Name:         row.str("name"),
+				Workout:      row.optStr("workout"),
+				StartedWhen:  valueOr(started, time.Time{}),
+				FinishedWhen: row.optTime("finished_when"),
+				Notes:        row.optStr("notes"),
+				Sets:         []ArchiveSessionSet{},
+				location:     row.location,
+			})
+			workout, exercise, session = nil, nil, &archive.Sessions[len(archive.Sessions)-1]
+		case csvRecordSessionSet:
+			if session == nil {
+				problems.add(row.location, "session_set row must follow a session row")
+				return
+			}
+			setNumber := row.optInt("set_number")
+			session.Sets = append(session.Sets, ArchiveSessionSet{
+				Exercise:    row.str("exercise"),
+				SetNumber:   valueOr(setNumber, 0),
+				Reps:        row.optInt("reps"),
+				TimeSeconds: row.optInt("time_seconds"),
+				Weight:      row.optFloat("weight"),
+				Notes:       row.optStr("notes"),
+				location:    row.location,
+			})
+		default:
+			problems.add(row.location, "unknown record %q", record)
+		}
+	})
+
+	return archive, problems.err()
+}
+
+// valueOr dereferences an optional value, returning fallback when absent
+func valueOr[T any](value *T, fallback T) T {
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
+
+// sessionGrouper collects the rows of tracker exports, which repeat the session on every set row
+type sessionGrouper struct {
+	archive  *Archive
+	sessions map[string]int
+}
+
+// newSessionGrouper creates an empty history archive
+func newSessionGrouper() *sessionGrouper {
+	return &sessionGrouper{
+		archive:  &Archive{Workouts: []ArchiveWorkout{}, Sessions: []ArchiveSession{}},
+		sessions: make(map[string]int),
+	}
+}
+
+// session returns the session of a row, keyed by its start and name, creating it on first sight
+func (g *sessionGrouper) session(row csvRow, name string, startedWhen time.Time) *ArchiveSession {
+	key := startedWhen.Format(time.RFC3339) + "|" + name
+	i, ok := g.sessions[key]
+	if !ok {
+		g.archive.Sessions = append(g.archive.Sessions, ArchiveSession{
+			Name:        name,
+			StartedWhen: startedWhen,
+			Sets:        []ArchiveSessionSet{},
+			location:    row.location,
+		})
+		i = len(g.archive.Sessions) - 1
+		g.sessions[key] = i
+	}
+	return &g.archive.Sessions[i]
+}
+
+// parseTrackerDuration parses durations like "1h 5m" or "45m 30s"
+func parseTrackerDuration(value string) (time.Duration, bool) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+	if value == "" {
+		return 0, false
+	}
+	duration, err := time.ParseDuration(value)
+	return duration, err == nil && duration > 0
+}
+
+// parseStrongCSV reads a workout history exported by Strong, one row per performed set
+func parseStrongCSV(data []byte) (*Archive, error) {
+	table, err := readCSVTable(data)
+	if err != nil {
+		return nil, err
+	}
+	if !table.has("date", "workout name", "exercise name", "set order") {
+		return nil, apperrors.Invalid("invalid_import", "import file is missing columns of a Strong export").WithField("line 1", "must have the columns Date, Workout Name, Exercise Name, Set Order")
+	}
+
+	grouper := newSessionGrouper()
+	var problems importProblems
+	table.each(&problems, func(row csvRow) {
+		// Strong lists rest timers as rows without a set
+		if strings.EqualFold(row.str("set order"), "rest timer") {
+			return
+		}
+		started := row.optTime("date")
+		if started == nil {
+			problems.add(row.location, "date is required")
+			return
+		}
+
+		session := grouper.session(row, row.str("workout name"), *started)
+		if duration, ok := parseTrackerDuration(row.str("duration")); ok && session.FinishedWhen == nil {
+			finished := started.Add(duration)
+			session.FinishedWhen = &finished
+		}
+		if session.Notes == nil {
+			session.Notes = row.optStr("workout notes")
+		}
+		session.Sets = append(session.Sets, ArchiveSessionSet{
+			Exercise:    row.str("exercise name"),
+			Reps:        positive(row.optInt("reps")),
+			TimeSeconds: positive(row.optInt("seconds")),
+			Weight:      positive(row.optFloat("weight")),
+			Notes:       row.optStr("notes"),
+			location:    row.location,
+		})
+	})
+
+	return grouper.archive, problems.err()
+}
+
+// parseHevyCSV reads a workout history exported by Hevy, one row per performed set
+func parseHevyCSV(data []byte) (*Archive, error) {
+	table, err := readCSVTable(data)
+	if err != nil {
+		return nil, err
+	}
+	if !table.has("title", "start_time", "exercise_title", "set_index") {
+		return nil, apperrors.Invalid("invalid_import", "import file is missing columns of a Hevy export").WithField("line 1", "must have the columns title, start_time, exercise_title, set_index")
+	}
+	weightColumn := "weight_kg"
+	if !table.has(weightColumn) {
+		weightColumn = "weight_lbs"
+	}
+
+	grouper := newSessionGrouper()
+	var problems importProblems
+	table.each(&problems, func(row csvRow) {
+		started := row.optTime("start_time")
+		if started == nil {
+			problems.add(row.location, "start_time is required")
+			return
+		}
+
+		session := grouper.session(row, row.str("title"), *started)
+		if session.FinishedWhen == nil {
+			session.FinishedWhen = row.optTime("end_time")
+		}
+		if session.Notes == nil {
+			session.Notes = row.optStr("description")
+		}
+		set := ArchiveSessionSet{
+			Exercise:    row.str("exercise_title"),
+			Reps:        positive(row.optInt("reps")),
+			TimeSeconds: positive(row.optInt("duration_seconds")),
+			Weight:      positive(row.optFloat(weightColumn)),
+			location:    row.location,
+		}
+		// Exercise notes are repeated on every set; keep them on the first one
+		if index := row.optInt("set_index"); index != nil && *index == 0 {
+			set.Notes = row.optStr("exercise_notes")
+		}
+		session.Sets = append(session.Sets, set)
+	})
+
+	return grouper.archive, problems.err()
+}
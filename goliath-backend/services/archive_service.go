@@ -0,0 +1,538 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"goliath/apperrors"
+	"goliath/entities"
+	"goliath/repositories"
+)
+
+// Formats of exported and imported archives
+const (
+	ArchiveFormatJSON   = "json"   // Goliath archive document
+	ArchiveFormatCSV    = "csv"    // Goliath archive, one row per record
+	ArchiveFormatStrong = "strong" // Workout history exported by Strong (import only)
+	ArchiveFormatHevy   = "hevy"   // Workout history exported by Hevy (import only)
+)
+
+// ArchiveVersion is the version of the archive layout written by exports
+const ArchiveVersion = 1
+
+// MaxImportProblems caps the invalid rows listed when an import is rejected
+const MaxImportProblems = 50
+
+// Archive represents everything a user planned and performed, with exercises referenced by
+// name so it can be imported into another account or instance
+type Archive struct {
+	Format       string           `json:"format"` // Always "goliath"
+	Version      int              `json:"version"`
+	ExportedWhen time.Time        `json:"exported_when"`
+	Workouts     []ArchiveWorkout `json:"workouts"`
+	Sessions     []ArchiveSession `json:"sessions"`
+}
+
+// ArchiveWorkout represents a workout with its exercises
+type ArchiveWorkout struct {
+	Name      string                   `json:"name"`
+	Shareable bool                     `json:"shareable"`
+	Exercises []ArchiveWorkoutExercise `json:"exercises"`
+	location  string
+}
+
+// ArchiveWorkoutExercise represents a planned exercise of a workout
+type ArchiveWorkoutExercise struct {
+	Exercise    string              `json:"exercise"` // Exercise name
+	Position    int                 `json:"position"`
+	Sets        *int                `json:"sets,omitempty"`
+	Reps        *int                `json:"reps,omitempty"`
+	TimeSeconds *int                `json:"time_seconds,omitempty"`
+	Weight      *float64            `json:"weight,omitempty"`
+	Notes       *string             `json:"notes,omitempty"`
+	SetDetails  []ArchiveWorkoutSet `json:"set_details,omitempty"`
+	location    string
+}
+
+// ArchiveWorkoutSet represents a planned set of a workout exercise
+type ArchiveWorkoutSet struct {
+	Position    int              `json:"position"`
+	SetType     entities.SetType `json:"set_type"`
+	Reps        *int             `json:"reps,omitempty"`
+	TimeSeconds *int             `json:"time_seconds,omitempty"`
+	Weight      *float64         `json:"weight,omitempty"`
+	RPE         *float64         `json:"rpe,omitempty"`
+	location    string
+}
+
+// ArchiveSession represents a performed workout session with its sets
+type ArchiveSession struct {
+	Name         string              `json:"name"`
+	Workout      *string             `json:"workout,omitempty"` // Name of the workout the session was started from
+	StartedWhen  time.Time           `json:"started_when"`
+	FinishedWhen *time.Time          `json:"finished_when,omitempty"`
+	Notes        *string             `json:"notes,omitempty"`
+	Sets         []ArchiveSessionSet `json:"sets"`
+	location     string
+}
+
+// ArchiveSessionSet represents a performed set of a session
+type ArchiveSessionSet struct {
+	Exercise    string   `json:"exercise"` // Exercise name
+	SetNumber   int      `json:"set_number"`
+	Reps        *int     `json:"reps,omitempty"`
+	TimeSeconds *int     `json:"time_seconds,omitempty"`
+	Weight      *float64 `json:"weight,omitempty"`
+	Notes       *string  `json:"notes,omitempty"`
+	location    string
+}
+
+// ImportCounts counts the records of an import
+type ImportCounts struct {
+	Workouts         int `json:"workouts"`
+	WorkoutExercises int `json:"workout_exercises"`
+	WorkoutSets      int `json:"workout_sets"`
+	Sessions         int `json:"sessions"`
+	SessionSets      int `json:"session_sets"`
+}
+
+// UnmatchedRow represents a row left out of an import because its exercise is not in the catalog
+type UnmatchedRow struct {
+	Location string `json:"location"` // e.g. "line 12" or "sessions[3].sets[0]"
+	Exercise string `json:"exercise"`
+}
+
+// ImportReport describes what an import created, or would create on a dry run
+type ImportReport struct {
+	Format          string          `json:"format"`
+	DryRun          bool            `json:"dry_run"`
+	Created         ImportCounts    `json:"created"`
+	SkippedSessions int             `json:"skipped_sessions"` // Sessions already present with the same name and start
+	Matches         []ExerciseMatch `json:"matches"`          // How each exercise name of the file was resolved
+	Unmatched       []UnmatchedRow  `json:"unmatched"`
+}
+
+// importProblems collects the invalid rows of an import file
+type importProblems struct {
+	fields []apperrors.FieldError
+}
+
+// add records an invalid row
+func (p *importProblems) add(location string, format string, args ...interface{}) {
+	p.fields = append(p.fields, apperrors.FieldError{Field: location, Message: fmt.Sprintf(format, args...)})
+}
+
+// err returns a validation error listing the first invalid rows, or nil when there are none
+func (p *importProblems) err() error {
+	if len(p.fields) == 0 {
+		return nil
+	}
+	invalid := apperrors.Invalid("invalid_import", "import file has %d invalid rows", len(p.fields))
+	for i, field := range p.fields {
+		if i == MaxImportProblems {
+			break
+		}
+		invalid = invalid.WithField(field.Field, "%s", field.Message)
+	}
+	return invalid
+}
+
+// ArchiveService handles business logic for exporting and importing a user's training data
+type ArchiveService struct {
+	workoutRepo            *repositories.WorkoutRepository
+	workoutExerciseRepo    *repositories.WorkoutExerciseRepository
+	workoutExerciseSetRepo *repositories.WorkoutExerciseSetRepository
+	sessionRepo            *repositories.SessionRepository
+	sessionSetRepo         *repositories.SessionSetRepository
+	exerciseRepo           *repositories.ExerciseRepository
+	recordService          *PersonalRecordService
+}
+
+// NewArchiveService creates a new ArchiveService
+func NewArchiveService(
+	workoutRepo *repositories.WorkoutRepository,
+	workoutExerciseRepo *repositories.WorkoutExerciseRepository,
+	workoutExerciseSetRepo *repositories.WorkoutExerciseSetRepository,
+	sessionRepo *repositories.SessionRepository,
+	sessionSetRepo *repositories.SessionSetRepository,
+	exerciseRepo *repositories.ExerciseRepository,
+	recordService *PersonalRecordService,
+) *ArchiveService {
+	return &ArchiveService{
+		workoutRepo:            workoutRepo,
+		workoutExerciseRepo:    workoutExerciseRepo,
+		workoutExerciseSetRepo: workoutExerciseSetRepo,
+		sessionRepo:            sessionRepo,
+		sessionSetRepo:         sessionSetRepo,
+		exerciseRepo:           exerciseRepo,
+		recordService:          recordService,
+	}
+}
+
+// Export collects the workouts, workout exercises with their sets and sessions with their sets of a user
+func (s *ArchiveService) Export(ctx context.Context, userID int) (*Archive, error) {
+	archive := &Archive{
+		Format:       "goliath",
+		Version:      ArchiveVersion,
+		ExportedWhen: time.Now().UTC(),
+		Workouts:     []ArchiveWorkout{},
+		Sessions:     []ArchiveSession{},
+	}
+
+	workouts, err := s.workoutRepo.GetAllForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	workoutNames := make(map[int]string, len(workouts))
+	for _, workout := range workouts {
+		workoutNames[workout.ID] = workout.Name
+
+		exercises, err := s.workoutExerciseRepo.GetAllForWorkout(ctx, workout.ID)
+		if err != nil {
+			return nil, err
+		}
+		archived := ArchiveWorkout{Name: workout.Name, Shareable: workout.Shareable, Exercises: []ArchiveWorkoutExercise{}}
+		for _, we := range exercises {
+			exercise := ArchiveWorkoutExercise{
+				Exercise:    we.ExerciseName,
+				Position:    we.Position,
+				Sets:        we.Sets,
+				Reps:        we.Reps,
+				TimeSeconds: we.TimeSeconds,
+				Weight:      we.Weight,
+				Notes:       we.Notes,
+			}
+			for _, set := range we.SetDetails {
+				exercise.SetDetails = append(exercise.SetDetails, ArchiveWorkoutSet{
+					Position:    set.Position,
+					SetType:     set.SetType,
+					Reps:        set.Reps,
+					TimeSeconds: set.TimeSeconds,
+					Weight:      set.Weight,
+					RPE:         set.RPE,
+				})
+			}
+			archived.Exercises = append(archived.Exercises, exercise)
+		}
+		archive.Workouts = append(archive.Workouts, archived)
+	}
+
+	sessions, err := s.sessionRepo.GetAllForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		sets, err := s.sessionSetRepo.GetAllForSession(ctx, session.ID)
+		if err != nil {
+			return nil, err
+		}
+		archived := ArchiveSession{
+			Name:         session.Name,
+			StartedWhen:  session.StartedWhen,
+			FinishedWhen: session.FinishedWhen,
+			Notes:        session.Notes,
+			Sets:         []ArchiveSessionSet{},
+		}
+		if session.WorkoutID != nil {
+			if name, ok := workoutNames[*session.WorkoutID]; ok {
+				archived.Workout = &name
+			}
+		}
+		for _, set := range sets {
+			archived.Sets = append(archived.Sets, ArchiveSessionSet{
+				Exercise:    set.ExerciseName,
+				SetNumber:   set.SetNumber,
+				Reps:        set.Reps,
+				TimeSeconds: set.TimeSeconds,
+				Weight:      set.Weight,
+				Notes:       set.Notes,
+			})
+		}
+		archive.Sessions = append(archive.Sessions, archived)
+	}
+
+	return archive, nil
+}
+
+// DetectArchiveFormat guesses the format of an import file from its content
+func DetectArchiveFormat(data []byte) string {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return ArchiveFormatJSON
+	}
+	return detectCSVFormat(data)
+}
+
+// parseArchive reads an import file of the given format and validates its rows
+func parseArchive(format string, data []byte) (*Archive, error) {
+	var archive *Archive
+	var err error
+	switch format {
+	case ArchiveFormatJSON:
+		archive, err = parseJSONArchive(data)
+	case ArchiveFormatCSV:
+		archive, err = parseGoliathCSV(data)
+	case ArchiveFormatStrong:
+		archive, err = parseStrongCSV(data)
+	case ArchiveFormatHevy:
+		archive, err = parseHevyCSV(data)
+	default:
+		return nil, apperrors.Invalid("invalid_import_format", "unknown import format %q", format).WithField("format", "must be one of json, csv, strong, hevy")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return archive, validateArchive(archive)
+}
+
+// parseJSONArchive reads a Goliath archive document and locates its rows by JSON path
+func parseJSONArchive(data []byte) (*Archive, error) {
+	var archive Archive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, apperrors.Invalid("invalid_import", "import file is not a valid archive document").Wrap(err)
+	}
+	if archive.Format != "goliath" {
+		return nil, apperrors.Invalid("invalid_import", "import file is not a Goliath archive").WithField("format", "must be goliath")
+	}
+
+	for i := range archive.Workouts {
+		workout := &archive.Workouts[i]
+		workout.location = fmt.Sprintf("workouts[%d]", i)
+		for j := range workout.Exercises {
+			exercise := &workout.Exercises[j]
+			exercise.location = fmt.Sprintf("%s.exercises[%d]", workout.location, j)
+			for k := range exercise.SetDetails {
+				exercise.SetDetails[k].location = fmt.Sprintf("%s.set_details[%d]", exercise.location, k)
+			}
+		}
+	}
+	for i := range archive.Sessions {
+		session := &archive.Sessions[i]
+		session.location = fmt.Sprintf("sessions[%d]", i)
+		for j := range session.Sets {
+			session.Sets[j].location = fmt.Sprintf("%s.sets[%d]", session.location, j)
+		}
+	}
+
+	return &archive, nil
+}
+
+// validSetType reports whether a set type is one of the known kinds of planned sets
+func validSetType(setType entities.SetType) bool {
+	switch setType {
+	case entities.SetTypeWarmUp, entities.SetTypeWorking, entities.SetTypeDrop, entities.SetTypeFailure:
+		return true
+	}
+	return false
+}
+
+// validateArchive checks the rows of a parsed archive, defaulting empty set types to Working
+func validateArchive(archive *Archive) error {
+	var problems importProblems
+	for i := range archive.Workouts {
+		workout := &archive.Workouts[i]
+		if workout.Name == "" {
+			problems.add(workout.location, "workout name is required")
+		}
+		for j := range workout.Exercises {
+			exercise := &workout.Exercises[j]
+			if exercise.Exercise == "" {
+				problems.add(exercise.location, "exercise name is required")
+			}
+			for k := range exercise.SetDetails {
+				set := &exercise.SetDetails[k]
+				if set.SetType == "" {
+					set.SetType = entities.SetTypeWorking
+				}
+				if !validSetType(set.SetType) {
+					problems.add(set.location, "set type must be one of WarmUp, Working, Drop, Failure")
+				}
+			}
+		}
+	}
+	for i := range archive.Sessions {
+		session := &archive.Sessions[i]
+		if session.Name == "" {
+			problems.add(session.location, "session name is required")
+		}
+		if session.StartedWhen.IsZero() {
+			problems.add(session.location, "session start is required")
+		}
+		session.StartedWhen = session.StartedWhen.UTC()
+		if session.FinishedWhen != nil {
+			finished := session.FinishedWhen.UTC()
+			session.FinishedWhen = &finished
+			if finished.Before(session.StartedWhen) {
+				problems.add(session.location, "session cannot finish before it started")
+			}
+		}
+		for j := range session.Sets {
+			if session.Sets[j].Exercise == "" {
+				problems.add(session.Sets[j].location, "exercise name is required")
+			}
+		}
+	}
+	return problems.err()
+}
+
+// Import reads an archive, Strong or Hevy export and creates its workouts and sessions for the
+// user within the request transaction. Exercise names are matched to the catalog; rows whose
+// exercise cannot be matched are left out and reported. A dry run only reports what would be
+// created. Sessions already present with the same name and start are skipped.
+func (s *ArchiveService) Import(ctx context.Context, userID int, format string, data []byte, dryRun bool) (*ImportReport, error) {
+	if format == "" {
+		format = DetectArchiveFormat(data)
+		if format == "" {
+			return nil, apperrors.Invalid("invalid_import_format", "import format could not be detected").WithField("format", "must be given as json, csv, strong or hevy")
+		}
+	}
+	log.Printf("Service: importing %s file of %d bytes for user %d (dry run: %t)", format, len(data), userID, dryRun)
+
+	archive, err := parseArchive(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	exercises, err := s.exerciseRepo.GetAllActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matcher := newExerciseMatcher(exercises)
+
+	report := &ImportReport{Format: format, DryRun: dryRun, Matches: []ExerciseMatch{}, Unmatched: []UnmatchedRow{}}
+	matches := make(map[string]*ExerciseMatch)
+	resolve := func(name string, location string) (int, bool) {
+		match, seen := matches[name]
+		if !seen {
+			if found, ok := matcher.match(name); ok {
+				match = &found
+				report.Matches = append(report.Matches, found)
+			}
+			matches[name] = match
+		}
+		if match == nil {
+			report.Unmatched = append(report.Unmatched, UnmatchedRow{Location: location, Exercise: name})
+			return 0, false
+		}
+		return match.ExerciseID, true
+	}
+
+	workoutIDs := make(map[string]int)
+	for _, workout := range archive.Workouts {
+		var workoutID int64
+		if !dryRun {
+			if workoutID, err = s.workoutRepo.Create(ctx, workout.Name, userID, workout.Shareable); err != nil {
+				return nil, fmt.Errorf("failed to import workout: %w", err)
+			}
+			if _, exists := workoutIDs[workout.Name]; !exists {
+				workoutIDs[workout.Name] = int(workoutID)
+			}
+		}
+		report.Created.Workouts++
+
+		for i, exercise := range workout.Exercises {
+			exerciseID, ok := resolve(exercise.Exercise, exercise.location)
+			if !ok {
+				continue
+			}
+			position := exercise.Position
+			if position <= 0 {
+				position = i + 1
+			}
+
+			var workoutExerciseID int64
+			if !dryRun {
+				workoutExerciseID, err = s.workoutExerciseRepo.Create(ctx, int(workoutID), exerciseID, position, exercise.Sets, exercise.Reps, exercise.TimeSeconds, exercise.Weight, exercise.Notes)
+				if err != nil {
+					return nil, fmt.Errorf("failed to import workout exercise: %w", err)
+				}
+			}
+			report.Created.WorkoutExercises++
+
+			for j, set := range exercise.SetDetails {
+				setPosition := set.Position
+				if setPosition <= 0 {
+					setPosition = j + 1
+				}
+				if !dryRun {
+					if _, err := s.workoutExerciseSetRepo.Create(ctx, int(workoutExerciseID), setPosition, set.SetType, set.Reps, set.TimeSeconds, set.Weight, set.RPE); err != nil {
+						return nil, fmt.Errorf("failed to import workout set: %w", err)
+					}
+				}
+				report.Created.WorkoutSets++
+			}
+		}
+	}
+
+	recordExercises := make(map[int]bool)
+	for _, session := range archive.Sessions {
+		exists, err := s.sessionRepo.SessionExists(ctx, userID, session.Name, session.StartedWhen)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check session existence: %w", err)
+		}
+		if exists {
+			report.SkippedSessions++
+			continue
+		}
+
+		var sessionID int64
+		if !dryRun {
+			var workoutID *int
+			if session.Workout != nil {
+				if id, ok := workoutIDs[*session.Workout]; ok {
+					workoutID = &id
+				}
+			}
+			if sessionID, err = s.sessionRepo.Create(ctx, userID, workoutID, session.Name, session.StartedWhen, session.Notes); err != nil {
+				return nil, fmt.Errorf("failed to import session: %w", err)
+			}
+			if session.FinishedWhen != nil {
+				if err := s.sessionRepo.Finish(ctx, int(sessionID), *session.FinishedWhen, nil); err != nil {
+					return nil, fmt.Errorf("failed to import session: %w", err)
+				}
+			}
+		}
+		report.Created.Sessions++
+
+		setNumbers := make(map[int]int)
+		for _, set := range session.Sets {
+			exerciseID, ok := resolve(set.Exercise, set.location)
+			if !ok {
+				continue
+			}
+			setNumber := set.SetNumber
+			if setNumber <= 0 {
+				setNumber = setNumbers[exerciseID] + 1
+			}
+			if setNumber > setNumbers[exerciseID] {
+				setNumbers[exerciseID] = setNumber
+			}
+
+			if !dryRun {
+				if _, err := s.sessionSetRepo.Create(ctx, int(sessionID), nil, exerciseID, setNumber, set.Reps, set.TimeSeconds, set.Weight, set.Notes); err != nil {
+					return nil, fmt.Errorf("failed to import session set: %w", err)
+				}
+				recordExercises[exerciseID] = true
+			}
+			report.Created.SessionSets++
+		}
+	}
+
+	// Imported history may set new personal records
+	exerciseIDs := make([]int, 0, len(recordExercises))
+	for exerciseID := range recordExercises {
+		exerciseIDs = append(exerciseIDs, exerciseID)
+	}
+	sort.Ints(exerciseIDs)
+	for _, exerciseID := range exerciseIDs {
+		if _, err := s.recordService.RefreshRecords(ctx, userID, exerciseID, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
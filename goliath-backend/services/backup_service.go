@@ -0,0 +1,415 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"goliath/apperrors"
+
+	"modernc.org/sqlite"
+)
+
+// SnapshotLabelPreRestore labels the snapshot of the live database taken before a restore
+const SnapshotLabelPreRestore = "pre-restore"
+
+// snapshotTimeLayout is the UTC timestamp in snapshot file names, which sorts chronologically
+const snapshotTimeLayout = "20060102-150405.000"
+
+// snapshotPattern matches snapshot file names: goliath-<timestamp>[-<label>].db
+var snapshotPattern = regexp.MustCompile(`^goliath-(\d{8}-\d{6}\.\d{3})(?:-([a-z-]+))?\.db$`)
+
+// Errors returned by the BackupService
+var (
+	ErrSnapshotNotFound = apperrors.NotFound("snapshot_not_found", "snapshot not found")
+)
+
+// BackupConfig configures where snapshots are written and how they are checked
+type BackupConfig struct {
+	DatabasePath   string                 // Live database file
+	Dir            string                 // Directory snapshots are written to
+	Keep           int                    // Scheduled and manual snapshots kept; older ones are deleted after each backup, 0 keeps all
+	KeepPreRestore int                    // Pre-restore snapshots kept, counted apart from the others; 0 keeps all
+	ApplicationID  int                    // PRAGMA application_id every snapshot must have
+	Migrate        func(db *sql.DB) error // Brings a snapshot to the schema of this binary before it is restored
+}
+
+// Snapshot represents a backup file of the database
+type Snapshot struct {
+	Name        string    `json:"name"`
+	Label       string    `json:"label,omitempty"` // e.g. pre-restore
+	CreatedWhen time.Time `json:"created_when"`
+	SizeBytes   int64     `json:"size_bytes"`
+}
+
+// SnapshotVerification represents a snapshot that passed verification
+type SnapshotVerification struct {
+	Snapshot
+	SchemaVersion int `json:"schema_version"` // Version of the last migration applied to the snapshot
+}
+
+// RestoreResult describes a restore
+type RestoreResult struct {
+	Restored   SnapshotVerification `json:"restored"`
+	PreRestore Snapshot             `json:"pre_restore"` // Snapshot of the database as it was before the restore
+}
+
+// BackupService takes, verifies and restores snapshots of the database. It opens its own
+// connections so snapshots do not wait for the connection held by the request transaction.
+type BackupService struct {
+	config BackupConfig
+	mu     sync.Mutex // One backup or restore at a time
+}
+
+// NewBackupService creates a new BackupService
+func NewBackupService(config BackupConfig) *BackupService {
+	return &BackupService{
+		config: config,
+	}
+}
+
+// openSQLite opens a separate connection to a database file with the given pragmas
+func openSQLite(path string, pragmas ...string) (*sql.DB, error) {
+	dsn := "file:" + path + "?_pragma=busy_timeout(10000)"
+	for _, pragma := range pragmas {
+		dsn += "&_pragma=" + pragma
+	}
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// parseSnapshot describes a snapshot file, false when the name is not a snapshot name
+func parseSnapshot(info os.FileInfo) (Snapshot, bool) {
+	matches := snapshotPattern.FindStringSubmatch(info.Name())
+	if matches == nil || !info.Mode().IsRegular() {
+		return Snapshot{}, false
+	}
+	createdWhen, err := time.Parse(snapshotTimeLayout, matches[1])
+	if err != nil {
+		return Snapshot{}, false
+	}
+	return Snapshot{Name: info.Name(), Label: matches[2], CreatedWhen: createdWhen, SizeBytes: info.Size()}, true
+}
+
+// snapshotPath resolves a snapshot name to its file, rejecting anything but snapshot names
+func (s *BackupService) snapshotPath(name string) (string, Snapshot, error) {
+	if !snapshotPattern.MatchString(name) {
+		return "", Snapshot{}, ErrSnapshotNotFound
+	}
+	path := filepath.Join(s.config.Dir, name)
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", Snapshot{}, ErrSnapshotNotFound.Wrap(err)
+		}
+		return "", Snapshot{}, err
+	}
+	snapshot, ok := parseSnapshot(info)
+	if !ok {
+		return "", Snapshot{}, ErrSnapshotNotFound
+	}
+	return path, snapshot, nil
+}
+
+// List returns the snapshots in the backup directory, newest first
+func (s *BackupService) List() ([]Snapshot, error) {
+	entries, err := os.ReadDir(s.config.Dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Snapshot{}, nil
+		}
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	snapshots := []Snapshot{}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if snapshot, ok := parseSnapshot(info); ok {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		if !snapshots[i].CreatedWhen.Equal(snapshots[j].CreatedWhen) {
+			return snapshots[i].CreatedWhen.After(snapshots[j].CreatedWhen)
+		}
+		return snapshots[i].Name > snapshots[j].Name
+	})
+	return snapshots, nil
+}
+
+// Create takes a consistent snapshot of the live database with VACUUM INTO, verifies it and
+// deletes the oldest snapshots beyond the configured number to keep
+func (s *BackupService) Create(ctx context.Context) (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.create(ctx, "")
+}
+
+// create writes a snapshot with an optional label; the caller holds the lock
+func (s *BackupService) create(ctx context.Context, label string) (*Snapshot, error) {
+	if err := os.MkdirAll(s.config.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	name := "goliath-" + time.Now().UTC().Format(snapshotTimeLayout)
+	if label != "" {
+		name += "-" + label
+	}
+	name += ".db"
+	path := filepath.Join(s.config.Dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return nil, apperrors.Conflict("snapshot_exists", "snapshot %s already exists", name)
+	}
+
+	db, err := openSQLite(s.config.DatabasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	// VACUUM INTO reads in a single transaction, so the snapshot is consistent while the
+	// server keeps writing to the WAL
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	verification, err := s.verify(ctx, path)
+	if err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("snapshot %s failed verification: %w", name, err)
+	}
+
+	if err := s.rotate(label); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Service: wrote snapshot %s (%d bytes, schema version %d)", name, verification.SizeBytes, verification.SchemaVersion)
+	return &verification.Snapshot, nil
+}
+
+// rotate deletes the oldest snapshots with the label of a new one beyond the number kept for it.
+// Scheduled and manual snapshots are unlabeled and count towards Keep; pre-restore snapshots only
+// count towards KeepPreRestore, so restores never push out backups and backups never delete the
+// snapshot that undoes a restore.
+func (s *BackupService) rotate(label string) error {
+	keep := s.config.Keep
+	if label == SnapshotLabelPreRestore {
+		keep = s.config.KeepPreRestore
+	}
+	if keep <= 0 {
+		return nil
+	}
+	snapshots, err := s.List()
+	if err != nil {
+		return err
+	}
+	kept := 0
+	for _, snapshot := range snapshots {
+		if snapshot.Label != label {
+			continue
+		}
+		if kept < keep {
+			kept++
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.config.Dir, snapshot.Name)); err != nil {
+			return fmt.Errorf("failed to delete snapshot %s: %w", snapshot.Name, err)
+		}
+		log.Printf("Service: deleted old snapshot %s", snapshot.Name)
+	}
+	return nil
+}
+
+// Verify checks that a snapshot is an intact Goliath database
+func (s *BackupService) Verify(ctx context.Context, name string) (*SnapshotVerification, error) {
+	path, _, err := s.snapshotPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.verify(ctx, path)
+}
+
+// verify checks the application_id and the integrity of a snapshot file
+func (s *BackupService) verify(ctx context.Context, path string) (*SnapshotVerification, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	db, err := openSQLite(path, "query_only(1)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer db.Close()
+
+	var applicationID int
+	if err := db.QueryRowContext(ctx, "PRAGMA application_id").Scan(&applicationID); err != nil {
+		return nil, apperrors.Invalid("invalid_snapshot", "snapshot %s is not a SQLite database", info.Name()).Wrap(err)
+	}
+	if applicationID != s.config.ApplicationID {
+		return nil, apperrors.Invalid("invalid_snapshot", "snapshot %s was not created by Goliath: application_id is 0x%X, expected 0x%X", info.Name(), applicationID, s.config.ApplicationID)
+	}
+
+	var check string
+	if err := db.QueryRowContext(ctx, "PRAGMA quick_check").Scan(&check); err != nil {
+		return nil, fmt.Errorf("failed to check snapshot: %w", err)
+	}
+	if check != "ok" {
+		return nil, apperrors.Invalid("invalid_snapshot", "snapshot %s is corrupt: %s", info.Name(), check)
+	}
+
+	verification := &SnapshotVerification{
+		Snapshot: Snapshot{Name: info.Name(), CreatedWhen: info.ModTime().UTC(), SizeBytes: info.Size()},
+	}
+	if snapshot, ok := parseSnapshot(info); ok {
+		verification.Snapshot = snapshot
+	}
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&verification.SchemaVersion); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot schema version: %w", err)
+	}
+	return verification, nil
+}
+
+// Restore replaces the content of the live database with a snapshot. The snapshot is verified
+// and migrated to the schema of this binary on a copy first, and the live database is
+// snapshotted before it is overwritten so the restore can itself be undone.
+func (s *BackupService) Restore(ctx context.Context, name string) (*RestoreResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path, _, err := s.snapshotPath(name)
+	if err != nil {
+		return nil, err
+	}
+	verification, err := s.verify(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Migrate a copy, leaving the snapshot as it was taken
+	staging := filepath.Join(s.config.Dir, ".restore-"+name)
+	if err := copyFile(path, staging); err != nil {
+		return nil, fmt.Errorf("failed to copy snapshot: %w", err)
+	}
+	defer os.Remove(staging)
+	if err := s.migrateStaging(staging); err != nil {
+		return nil, apperrors.Invalid("incompatible_snapshot", "snapshot %s cannot be migrated to this version: %v", name, err).Wrap(err)
+	}
+
+	preRestore, err := s.create(ctx, SnapshotLabelPreRestore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot the database before restoring: %w", err)
+	}
+
+	if err := s.restoreFrom(ctx, staging); err != nil {
+		return nil, fmt.Errorf("failed to restore snapshot %s: %w", name, err)
+	}
+
+	log.Printf("Service: restored snapshot %s (previous database saved as %s)", name, preRestore.Name)
+	return &RestoreResult{Restored: *verification, PreRestore: *preRestore}, nil
+}
+
+// migrateStaging runs the pending migrations on a copy of a snapshot
+func (s *BackupService) migrateStaging(path string) error {
+	db, err := openSQLite(path, "foreign_keys(1)")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return s.config.Migrate(db)
+}
+
+// restoreFrom copies every page of a database file into the live database with the SQLite
+// online backup API, so connections of the server see the restored content right away
+func (s *BackupService) restoreFrom(ctx context.Context, path string) error {
+	db, err := openSQLite(s.config.DatabasePath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		restorer, ok := driverConn.(interface {
+			NewRestore(srcURI string) (*sqlite.Backup, error)
+		})
+		if !ok {
+			return errors.New("database driver does not support online restore")
+		}
+		backup, err := restorer.NewRestore(path)
+		if err != nil {
+			return err
+		}
+		for more := true; more; {
+			if more, err = backup.Step(-1); err != nil {
+				backup.Finish()
+				return err
+			}
+		}
+		return backup.Finish()
+	})
+}
+
+// copyFile copies a file, syncing the copy to disk
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// Schedule takes a snapshot every interval until ctx is done
+func (s *BackupService) Schedule(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.Create(ctx); err != nil {
+					log.Printf("Scheduled backup failed: %v", err)
+				}
+			}
+		}
+	}()
+	log.Printf("Backups scheduled every %s to %s (keeping %d, %d pre-restore)", interval, s.config.Dir, s.config.Keep, s.config.KeepPreRestore)
+}
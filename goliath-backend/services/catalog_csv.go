@@ -0,0 +1,114 @@
+package services
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"goliath/apperrors"
+)
+
+// catalogCSVHeader is the header of the catalog CSV, one row per muscle of an exercise.
+// The areas column repeats the exercise areas of the exercise as "Area:percentage" pairs
+// separated by semicolons; it is derived from the muscles and ignored on import.
+var catalogCSVHeader = []string{"name", "type", "archived", "muscle", "percentage", "areas"}
+
+// catalogCSVRequired are the columns a catalog CSV import must have
+var catalogCSVRequired = []string{"name", "type", "muscle", "percentage"}
+
+// WriteCatalogCSV writes a catalog as CSV, one row per muscle of each exercise
+func WriteCatalogCSV(w io.Writer, catalog *Catalog) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(catalogCSVHeader); err != nil {
+		return err
+	}
+
+	for _, exercise := range catalog.Exercises {
+		areas := make([]string, len(exercise.Areas))
+		for i, area := range exercise.Areas {
+			areas[i] = area.Area + ":" + strconv.FormatFloat(area.Percentage, 'f', -1, 64)
+		}
+		row := []string{exercise.Name, exercise.Type, strconv.FormatBool(exercise.Archived), "", "", strings.Join(areas, ";")}
+
+		// An exercise without muscles still gets a row so it is not lost
+		if len(exercise.Muscles) == 0 {
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+		for _, muscle := range exercise.Muscles {
+			row[3] = muscle.Muscle
+			row[4] = strconv.FormatFloat(muscle.Percentage, 'f', -1, 64)
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// parseCatalogCSV reads a catalog CSV written by WriteCatalogCSV. Rows with the same exercise
+// name, regardless of case, are one exercise; its type and archived flag come from its first row.
+func parseCatalogCSV(data []byte) (*Catalog, error) {
+	table, err := readCSVTable(data)
+	if err != nil {
+		return nil, err
+	}
+	if !table.has(catalogCSVRequired...) {
+		return nil, apperrors.Invalid("invalid_import", "import file is missing columns of the catalog CSV").WithField("line 1", "must have the columns %s", strings.Join(catalogCSVRequired, ","))
+	}
+
+	var problems importProblems
+	catalog := &Catalog{Format: catalogFormatName, Version: CatalogVersion, Exercises: []CatalogExercise{}}
+	index := make(map[string]int)
+	table.each(&problems, func(row csvRow) {
+		name := row.str("name")
+		archived := false
+		if value := row.str("archived"); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				problems.add(row.location, "archived must be true or false")
+			}
+			archived = parsed
+		}
+
+		i, ok := index[strings.ToLower(name)]
+		if !ok || name == "" {
+			// Rows without a name are kept apart so validation reports each of them
+			i = len(catalog.Exercises)
+			catalog.Exercises = append(catalog.Exercises, CatalogExercise{
+				Name:     name,
+				Type:     row.str("type"),
+				Archived: archived,
+				location: row.location,
+			})
+			if name != "" {
+				index[strings.ToLower(name)] = i
+			}
+		} else {
+			exercise := catalog.Exercises[i]
+			if row.str("type") != exercise.Type {
+				problems.add(row.location, "type must match the first row of the exercise at %s", exercise.location)
+			}
+			if archived != exercise.Archived {
+				problems.add(row.location, "archived must match the first row of the exercise at %s", exercise.location)
+			}
+		}
+
+		if muscle := row.str("muscle"); muscle != "" || row.str("percentage") != "" {
+			catalog.Exercises[i].Muscles = append(catalog.Exercises[i].Muscles, CatalogMuscle{
+				Muscle:     muscle,
+				Percentage: valueOr(row.optFloat("percentage"), 0),
+				location:   row.location,
+			})
+		}
+	})
+
+	if err := problems.err(); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
@@ -0,0 +1,423 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"goliath/apperrors"
+	"goliath/entities"
+	"goliath/repositories"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of exported and imported exercise catalogs
+const (
+	CatalogFormatJSON = "json"
+	CatalogFormatYAML = "yaml"
+	CatalogFormatCSV  = "csv"
+)
+
+// CatalogVersion is the version of the catalog layout written by exports
+const CatalogVersion = 1
+
+// catalogFormatName marks JSON and YAML documents as exercise catalogs
+const catalogFormatName = "goliath-catalog"
+
+// Actions a catalog import takes on an exercise
+const (
+	CatalogActionCreate = "create"
+	CatalogActionUpdate = "update"
+)
+
+// Catalog represents the exercise catalog with muscles referenced by name. It carries no
+// timestamps or IDs so exports of an unchanged catalog are identical and diff cleanly in git.
+type Catalog struct {
+	Format        string            `json:"format" yaml:"format"` // Always "goliath-catalog"
+	Version       int               `json:"version" yaml:"version"`
+	ExerciseTypes []string          `json:"exercise_types" yaml:"exercise_types"` // Informational; ignored on import
+	Exercises     []CatalogExercise `json:"exercises" yaml:"exercises"`
+}
+
+// CatalogExercise represents an exercise of the catalog with its muscle percentages
+type CatalogExercise struct {
+	Name     string          `json:"name" yaml:"name"`
+	Type     string          `json:"type" yaml:"type"`
+	Archived bool            `json:"archived,omitempty" yaml:"archived,omitempty"`
+	Muscles  []CatalogMuscle `json:"muscles" yaml:"muscles"`
+	Areas    []CatalogArea   `json:"areas,omitempty" yaml:"areas,omitempty"` // Derived from the muscles; ignored on import
+	location string
+}
+
+// CatalogMuscle represents the share of a muscle in an exercise
+type CatalogMuscle struct {
+	Muscle     string  `json:"muscle" yaml:"muscle"` // Muscle name
+	Percentage float64 `json:"percentage" yaml:"percentage"`
+	location   string
+}
+
+// CatalogArea represents the share of an exercise area in an exercise
+type CatalogArea struct {
+	Area       string  `json:"area" yaml:"area"` // Exercise area name
+	Percentage float64 `json:"percentage" yaml:"percentage"`
+}
+
+// CatalogFieldChange describes one difference between a catalog exercise and the imported file
+type CatalogFieldChange struct {
+	Field string      `json:"field"` // name, type, archived or muscles.<muscle name>
+	From  interface{} `json:"from"`  // Nil for an added muscle
+	To    interface{} `json:"to"`    // Nil for a removed muscle
+}
+
+// CatalogExerciseChange describes what an import does to one exercise
+type CatalogExerciseChange struct {
+	Action     string               `json:"action"` // create or update
+	Name       string               `json:"name"`
+	ExerciseID int                  `json:"exercise_id,omitempty"` // Omitted for exercises a dry run would create
+	Changes    []CatalogFieldChange `json:"changes,omitempty"`     // Omitted for created exercises
+}
+
+// CatalogImportReport describes the changes an import made, or would make on a dry run
+type CatalogImportReport struct {
+	Format    string                  `json:"format"`
+	DryRun    bool                    `json:"dry_run"`
+	Created   int                     `json:"created"`
+	Updated   int                     `json:"updated"`
+	Unchanged int                     `json:"unchanged"`
+	Changes   []CatalogExerciseChange `json:"changes"`
+	NotInFile []string                `json:"not_in_file"` // Exercises the file does not mention; they are left as they are
+}
+
+// catalogEntry is a validated exercise of an imported catalog with its muscles resolved
+type catalogEntry struct {
+	name         string
+	exerciseType entities.ExerciseType
+	archived     bool
+	muscles      []repositories.MuscleInput
+}
+
+// CatalogService handles business logic for exporting and bulk importing the exercise catalog
+type CatalogService struct {
+	exerciseRepo    *repositories.ExerciseRepository
+	muscleRepo      *repositories.MuscleRepository
+	exerciseService *ExerciseService
+}
+
+// NewCatalogService creates a new CatalogService
+func NewCatalogService(exerciseRepo *repositories.ExerciseRepository, muscleRepo *repositories.MuscleRepository, exerciseService *ExerciseService) *CatalogService {
+	return &CatalogService{
+		exerciseRepo:    exerciseRepo,
+		muscleRepo:      muscleRepo,
+		exerciseService: exerciseService,
+	}
+}
+
+// Export collects all exercises, archived ones included, with their muscle percentages and exercise areas
+func (s *CatalogService) Export(ctx context.Context) (*Catalog, error) {
+	exercises, err := s.exerciseRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	musclesMap, err := s.exerciseRepo.GetMusclesForAllExercises(ctx)
+	if err != nil {
+		return nil, err
+	}
+	areasMap, err := s.exerciseRepo.GetExerciseAreasForAllExercises(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := &Catalog{
+		Format:        catalogFormatName,
+		Version:       CatalogVersion,
+		ExerciseTypes: s.exerciseService.GetExerciseTypes(),
+		Exercises:     []CatalogExercise{},
+	}
+	for _, exercise := range exercises {
+		entry := CatalogExercise{
+			Name:     exercise.Name,
+			Type:     string(exercise.Type),
+			Archived: exercise.ArchivedWhen != nil,
+			Muscles:  []CatalogMuscle{},
+		}
+		for _, muscle := range musclesMap[exercise.ID] {
+			entry.Muscles = append(entry.Muscles, CatalogMuscle{Muscle: muscle.MuscleName, Percentage: muscle.Percentage})
+		}
+		for _, area := range areasMap[exercise.ID] {
+			entry.Areas = append(entry.Areas, CatalogArea{Area: area.ExerciseAreaName, Percentage: math.Round(area.Percentage*100) / 100})
+		}
+
+		// Equal percentages come back in any order; fix it so exports are stable
+		sort.SliceStable(entry.Muscles, func(i, j int) bool {
+			if entry.Muscles[i].Percentage != entry.Muscles[j].Percentage {
+				return entry.Muscles[i].Percentage > entry.Muscles[j].Percentage
+			}
+			return entry.Muscles[i].Muscle < entry.Muscles[j].Muscle
+		})
+		sort.SliceStable(entry.Areas, func(i, j int) bool {
+			if entry.Areas[i].Percentage != entry.Areas[j].Percentage {
+				return entry.Areas[i].Percentage > entry.Areas[j].Percentage
+			}
+			return entry.Areas[i].Area < entry.Areas[j].Area
+		})
+
+		catalog.Exercises = append(catalog.Exercises, entry)
+	}
+
+	return catalog, nil
+}
+
+// WriteCatalogYAML writes a catalog as a YAML document
+func WriteCatalogYAML(w io.Writer, catalog *Catalog) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(catalog); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// DetectCatalogFormat guesses the format of a catalog file from its content
+func DetectCatalogFormat(data []byte) string {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return CatalogFormatJSON
+	}
+	if table, err := readCSVTable(data); err == nil && table.has(catalogCSVRequired...) {
+		return CatalogFormatCSV
+	}
+	return CatalogFormatYAML
+}
+
+// parseCatalog reads a catalog file of the given format
+func parseCatalog(format string, data []byte) (*Catalog, error) {
+	var catalog Catalog
+	switch format {
+	case CatalogFormatJSON:
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, apperrors.Invalid("invalid_import", "import file is not a valid catalog document").Wrap(err)
+		}
+	case CatalogFormatYAML:
+		if err := yaml.Unmarshal(data, &catalog); err != nil {
+			return nil, apperrors.Invalid("invalid_import", "import file is not a valid catalog document").Wrap(err)
+		}
+	case CatalogFormatCSV:
+		return parseCatalogCSV(data)
+	default:
+		return nil, apperrors.Invalid("invalid_import_format", "unknown catalog format %q", format).WithField("format", "must be one of json, yaml, csv")
+	}
+	if catalog.Format != catalogFormatName {
+		return nil, apperrors.Invalid("invalid_import", "import file is not a Goliath exercise catalog").WithField("format", "must be %s", catalogFormatName)
+	}
+
+	for i := range catalog.Exercises {
+		exercise := &catalog.Exercises[i]
+		exercise.location = fmt.Sprintf("exercises[%d]", i)
+		for j := range exercise.Muscles {
+			exercise.Muscles[j].location = fmt.Sprintf("%s.muscles[%d]", exercise.location, j)
+		}
+	}
+	return &catalog, nil
+}
+
+// resolveCatalog validates the exercises of a catalog against the exercise types and known
+// muscles, reporting every invalid row at once
+func resolveCatalog(catalog *Catalog, exerciseTypes []string, muscles []entities.Muscle) ([]catalogEntry, error) {
+	muscleIDs := make(map[string]int, len(muscles))
+	for _, muscle := range muscles {
+		muscleIDs[strings.ToLower(muscle.Name)] = muscle.ID
+	}
+	validTypes := make(map[string]bool, len(exerciseTypes))
+	for _, t := range exerciseTypes {
+		validTypes[t] = true
+	}
+
+	var problems importProblems
+	entries := make([]catalogEntry, 0, len(catalog.Exercises))
+	seen := make(map[string]string, len(catalog.Exercises))
+	for _, exercise := range catalog.Exercises {
+		entry := catalogEntry{
+			name:         strings.TrimSpace(exercise.Name),
+			exerciseType: entities.ExerciseType(exercise.Type),
+			archived:     exercise.Archived,
+		}
+
+		if entry.name == "" {
+			problems.add(exercise.location, "name is required")
+		} else if first, ok := seen[strings.ToLower(entry.name)]; ok {
+			problems.add(exercise.location, "exercise %q is already listed at %s", entry.name, first)
+		} else {
+			seen[strings.ToLower(entry.name)] = exercise.location
+		}
+		if !validTypes[exercise.Type] {
+			problems.add(exercise.location, "type must be one of %s", strings.Join(exerciseTypes, ", "))
+		}
+		if len(exercise.Muscles) == 0 {
+			problems.add(exercise.location, "must have at least one muscle")
+		}
+
+		listed := make(map[int]bool, len(exercise.Muscles))
+		for _, muscle := range exercise.Muscles {
+			muscleID, ok := muscleIDs[strings.ToLower(strings.TrimSpace(muscle.Muscle))]
+			switch {
+			case !ok:
+				problems.add(muscle.location, "unknown muscle %q", muscle.Muscle)
+			case listed[muscleID]:
+				problems.add(muscle.location, "muscle %q is listed twice", muscle.Muscle)
+			default:
+				listed[muscleID] = true
+			}
+			if muscle.Percentage < 1 || muscle.Percentage > 100 {
+				problems.add(muscle.location, "percentage must be between 1 and 100")
+			}
+			entry.muscles = append(entry.muscles, repositories.MuscleInput{MuscleID: muscleID, Percentage: muscle.Percentage})
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := problems.err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// diffCatalogExercise lists the differences between a stored exercise and its imported entry
+func diffCatalogExercise(exercise entities.Exercise, muscles []entities.ExerciseMuscle, entry catalogEntry, muscleNames map[int]string) []CatalogFieldChange {
+	changes := []CatalogFieldChange{}
+	if exercise.Name != entry.name {
+		changes = append(changes, CatalogFieldChange{Field: "name", From: exercise.Name, To: entry.name})
+	}
+	if exercise.Type != entry.exerciseType {
+		changes = append(changes, CatalogFieldChange{Field: "type", From: string(exercise.Type), To: string(entry.exerciseType)})
+	}
+	if archived := exercise.ArchivedWhen != nil; archived != entry.archived {
+		changes = append(changes, CatalogFieldChange{Field: "archived", From: archived, To: entry.archived})
+	}
+
+	current := make(map[int]float64, len(muscles))
+	for _, muscle := range muscles {
+		current[muscle.MuscleID] = muscle.Percentage
+	}
+	imported := make(map[int]bool, len(entry.muscles))
+	for _, muscle := range entry.muscles {
+		imported[muscle.MuscleID] = true
+		field := "muscles." + muscleNames[muscle.MuscleID]
+		if percentage, ok := current[muscle.MuscleID]; !ok {
+			changes = append(changes, CatalogFieldChange{Field: field, To: muscle.Percentage})
+		} else if percentage != muscle.Percentage {
+			changes = append(changes, CatalogFieldChange{Field: field, From: percentage, To: muscle.Percentage})
+		}
+	}
+	for _, muscle := range muscles {
+		if !imported[muscle.MuscleID] {
+			changes = append(changes, CatalogFieldChange{Field: "muscles." + muscle.MuscleName, From: muscle.Percentage})
+		}
+	}
+
+	return changes
+}
+
+// Import validates a catalog file and upserts its exercises by name, matched regardless of case.
+// Exercises the file does not mention are left as they are. On a dry run nothing is written
+// and the report shows the changes the import would make.
+func (s *CatalogService) Import(ctx context.Context, format string, data []byte, dryRun bool) (*CatalogImportReport, error) {
+	if format == "" {
+		format = DetectCatalogFormat(data)
+	}
+	catalog, err := parseCatalog(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	muscles, err := s.muscleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get muscles: %w", err)
+	}
+	entries, err := resolveCatalog(catalog, s.exerciseService.GetExerciseTypes(), muscles)
+	if err != nil {
+		return nil, err
+	}
+	muscleNames := make(map[int]string, len(muscles))
+	for _, muscle := range muscles {
+		muscleNames[muscle.ID] = muscle.Name
+	}
+
+	exercises, err := s.exerciseRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exercises: %w", err)
+	}
+	musclesMap, err := s.exerciseRepo.GetMusclesForAllExercises(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exercise muscles: %w", err)
+	}
+	existing := make(map[string]entities.Exercise, len(exercises))
+	for _, exercise := range exercises {
+		existing[strings.ToLower(exercise.Name)] = exercise
+	}
+
+	report := &CatalogImportReport{Format: format, DryRun: dryRun, Changes: []CatalogExerciseChange{}, NotInFile: []string{}}
+	inFile := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		key := strings.ToLower(entry.name)
+		inFile[key] = true
+
+		exercise, ok := existing[key]
+		if !ok {
+			change := CatalogExerciseChange{Action: CatalogActionCreate, Name: entry.name}
+			if !dryRun {
+				exerciseID, err := s.exerciseRepo.Create(ctx, entry.name, entry.exerciseType, entry.muscles)
+				if err != nil {
+					return nil, fmt.Errorf("failed to create exercise: %w", err)
+				}
+				if entry.archived {
+					if err := s.exerciseRepo.SetArchived(ctx, int(exerciseID), true); err != nil {
+						return nil, fmt.Errorf("failed to archive exercise: %w", err)
+					}
+				}
+				change.ExerciseID = int(exerciseID)
+			}
+			report.Changes = append(report.Changes, change)
+			report.Created++
+			continue
+		}
+
+		changes := diffCatalogExercise(exercise, musclesMap[exercise.ID], entry, muscleNames)
+		if len(changes) == 0 {
+			report.Unchanged++
+			continue
+		}
+		if !dryRun {
+			onlyArchived := len(changes) == 1 && changes[0].Field == "archived"
+			if !onlyArchived {
+				if err := s.exerciseRepo.Update(ctx, exercise.ID, 0, entry.name, entry.exerciseType, entry.muscles); err != nil {
+					return nil, fmt.Errorf("failed to update exercise: %w", err)
+				}
+			}
+			if (exercise.ArchivedWhen != nil) != entry.archived {
+				if err := s.exerciseRepo.SetArchived(ctx, exercise.ID, entry.archived); err != nil {
+					return nil, fmt.Errorf("failed to archive exercise: %w", err)
+				}
+			}
+		}
+		report.Changes = append(report.Changes, CatalogExerciseChange{Action: CatalogActionUpdate, Name: entry.name, ExerciseID: exercise.ID, Changes: changes})
+		report.Updated++
+	}
+
+	for _, exercise := range exercises {
+		if !inFile[strings.ToLower(exercise.Name)] {
+			report.NotInFile = append(report.NotInFile, exercise.Name)
+		}
+	}
+
+	if !dryRun {
+		log.Printf("Service: imported exercise catalog (%d created, %d updated, %d unchanged)", report.Created, report.Updated, report.Unchanged)
+	}
+	return report, nil
+}
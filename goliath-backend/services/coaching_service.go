@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"goliath/apperrors"
+	"goliath/entities"
+	"goliath/repositories"
+)
+
+// Errors of coaching
+var (
+	ErrCoachingNotFound  = apperrors.NotFound("coaching_not_found", "coaching not found")
+	ErrCoachingEnded     = apperrors.Conflict("coaching_ended", "coaching has already ended")
+	ErrCoachingNotActive = apperrors.Conflict("coaching_not_active", "athlete has not accepted the coaching invite")
+)
+
+// CoachingInviteInput represents input for inviting an athlete
+type CoachingInviteInput struct {
+	Email string `json:"email" binding:"required"` // Email of the athlete's account
+}
+
+// CoachingService handles business logic for coaches and their athletes
+type CoachingService struct {
+	coachingRepo   *repositories.CoachingRepository
+	userRepo       *repositories.UserRepository
+	workoutService *WorkoutService
+}
+
+// NewCoachingService creates a new CoachingService
+func NewCoachingService(coachingRepo *repositories.CoachingRepository, userRepo *repositories.UserRepository, workoutService *WorkoutService) *CoachingService {
+	return &CoachingService{
+		coachingRepo:   coachingRepo,
+		userRepo:       userRepo,
+		workoutService: workoutService,
+	}
+}
+
+// GetAthletes retrieves the coachings of a coach
+func (s *CoachingService) GetAthletes(ctx context.Context, coachID int) ([]entities.Coaching, error) {
+	coachings, err := s.coachingRepo.ListForCoach(ctx, coachID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get athletes: %w", err)
+	}
+	return coachings, nil
+}
+
+// GetCoaches retrieves the coachings of an athlete
+func (s *CoachingService) GetCoaches(ctx context.Context, athleteID int) ([]entities.Coaching, error) {
+	coachings, err := s.coachingRepo.ListForAthlete(ctx, athleteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coaches: %w", err)
+	}
+	return coachings, nil
+}
+
+// InviteAthlete invites the user with an email to be coached. The coach gets access to the
+// athlete's workouts once the athlete accepts.
+func (s *CoachingService) InviteAthlete(ctx context.Context, coachID int, input CoachingInviteInput) (int64, error) {
+	email := strings.TrimSpace(input.Email)
+	athlete, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get athlete: %w", err)
+	}
+	if athlete == nil {
+		return 0, apperrors.Invalid("unknown_athlete", "no user with email %q", email).WithField("email", "must be the email of a registered user")
+	}
+	if athlete.ID == coachID {
+		return 0, apperrors.Invalid("invalid_athlete", "Invalid athlete: coaches cannot coach themselves").WithField("email", "must not be your own email")
+	}
+
+	open, err := s.coachingRepo.HasOpen(ctx, coachID, athlete.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check coaching existence: %w", err)
+	}
+	if open {
+		return 0, apperrors.Conflict("coaching_exists", "athlete has already been invited or is already coached")
+	}
+
+	coachingID, err := s.coachingRepo.Create(ctx, coachID, athlete.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create coaching: %w", err)
+	}
+	return coachingID, nil
+}
+
+// getForCoach loads a coaching of a coach; coachings of other coaches are reported as not found
+func (s *CoachingService) getForCoach(ctx context.Context, coachID int, id int) (*entities.Coaching, error) {
+	coaching, err := s.coachingRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrCoachingNotFound)
+	}
+	if coaching.CoachUserID != coachID {
+		return nil, ErrCoachingNotFound
+	}
+	return coaching, nil
+}
+
+// getForAthlete loads a coaching of an athlete; coachings of other athletes are reported as not found
+func (s *CoachingService) getForAthlete(ctx context.Context, athleteID int, id int) (*entities.Coaching, error) {
+	coaching, err := s.coachingRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrCoachingNotFound)
+	}
+	if coaching.AthleteUserID != athleteID {
+		return nil, ErrCoachingNotFound
+	}
+	return coaching, nil
+}
+
+// end ends a coaching that has not ended yet
+func (s *CoachingService) end(ctx context.Context, coaching *entities.Coaching) error {
+	if coaching.Status == entities.CoachingStatusEnded {
+		return ErrCoachingEnded
+	}
+	if err := s.coachingRepo.UpdateStatus(ctx, coaching.ID, entities.CoachingStatusEnded); err != nil {
+		return fmt.Errorf("failed to end coaching: %w", err)
+	}
+	return nil
+}
+
+// EndAthlete ends a coaching as its coach, withdrawing the invite if it is still pending
+func (s *CoachingService) EndAthlete(ctx context.Context, coachID int, id int) error {
+	coaching, err := s.getForCoach(ctx, coachID, id)
+	if err != nil {
+		return err
+	}
+	return s.end(ctx, coaching)
+}
+
+// AcceptCoach accepts the pending invite of a coach, granting them access to the athlete's workouts
+func (s *CoachingService) AcceptCoach(ctx context.Context, athleteID int, id int) error {
+	coaching, err := s.getForAthlete(ctx, athleteID, id)
+	if err != nil {
+		return err
+	}
+	switch coaching.Status {
+	case entities.CoachingStatusEnded:
+		return ErrCoachingEnded
+	case entities.CoachingStatusActive:
+		return apperrors.Conflict("coaching_accepted", "coaching invite has already been accepted")
+	}
+
+	if err := s.coachingRepo.UpdateStatus(ctx, coaching.ID, entities.CoachingStatusActive); err != nil {
+		return fmt.Errorf("failed to accept coaching: %w", err)
+	}
+	return nil
+}
+
+// RevokeCoach ends a coaching as its athlete: declines a pending invite or revokes the coach's access
+func (s *CoachingService) RevokeCoach(ctx context.Context, athleteID int, id int) error {
+	coaching, err := s.getForAthlete(ctx, athleteID, id)
+	if err != nil {
+		return err
+	}
+	return s.end(ctx, coaching)
+}
+
+// getActiveForCoach loads a coaching of a coach the athlete accepted
+func (s *CoachingService) getActiveForCoach(ctx context.Context, coachID int, id int) (*entities.Coaching, error) {
+	coaching, err := s.getForCoach(ctx, coachID, id)
+	if err != nil {
+		return nil, err
+	}
+	switch coaching.Status {
+	case entities.CoachingStatusEnded:
+		return nil, ErrCoachingEnded
+	case entities.CoachingStatusPending:
+		return nil, ErrCoachingNotActive
+	}
+	return coaching, nil
+}
+
+// GetAthleteWorkouts retrieves one page of the workouts of a coached athlete
+func (s *CoachingService) GetAthleteWorkouts(ctx context.Context, coachID int, id int, params repositories.ListParams) ([]entities.Workout, string, error) {
+	coaching, err := s.getActiveForCoach(ctx, coachID, id)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.workoutService.GetUserWorkouts(ctx, coaching.AthleteUserID, params)
+}
+
+// CreateAthleteWorkout creates a workout owned by a coached athlete
+func (s *CoachingService) CreateAthleteWorkout(ctx context.Context, coachID int, id int, input CreateWorkoutInput) (int64, error) {
+	coaching, err := s.getActiveForCoach(ctx, coachID, id)
+	if err != nil {
+		return 0, err
+	}
+	return s.workoutService.CreateWorkout(ctx, coaching.AthleteUserID, input)
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"goliath/apperrors"
+)
+
+// Errors shared by several services
+var (
+	ErrWorkoutNotFound         = apperrors.NotFound("workout_not_found", "workout not found")
+	ErrWorkoutForbidden        = apperrors.Forbidden("workout_forbidden", "workout does not belong to user or an athlete they coach")
+	ErrWorkoutExerciseNotFound = apperrors.NotFound("workout_exercise_not_found", "workout exercise not found")
+	ErrExerciseNotFound        = apperrors.NotFound("exercise_not_found", "exercise not found")
+	ErrSessionNotFound         = apperrors.NotFound("session_not_found", "session not found")
+	ErrSessionFinished         = apperrors.Conflict("session_finished", "session already finished")
+	ErrSetNotFound             = apperrors.NotFound("set_not_found", "set not found")
+	ErrUserNotFound            = apperrors.NotFound("user_not_found", "user not found")
+	ErrInviteNotFound          = apperrors.NotFound("invite_not_found", "invite not found")
+)
+
+// notFound reports a failed lookup: notFoundErr when the row does not exist,
+// err itself otherwise so database failures are not mistaken for missing rows
+func notFound(err error, notFoundErr *apperrors.Error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundErr.Wrap(err)
+	}
+	return err
+}
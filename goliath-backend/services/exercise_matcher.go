@@ -0,0 +1,109 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"goliath/entities"
+)
+
+// MinExerciseMatchScore is the lowest similarity at which an imported exercise name is matched
+const MinExerciseMatchScore = 0.6
+
+// ExerciseMatch describes how an imported exercise name was resolved to a catalog exercise
+type ExerciseMatch struct {
+	Name         string  `json:"name"` // Name as it appears in the imported file
+	ExerciseID   int     `json:"exercise_id"`
+	ExerciseName string  `json:"exercise_name"`
+	Score        float64 `json:"score"` // 1 when the names have the same words
+}
+
+// exerciseMatcher resolves free-text exercise names against the exercise catalog
+type exerciseMatcher struct {
+	exercises []entities.Exercise
+	tokens    [][]string
+}
+
+// newExerciseMatcher prepares the catalog for matching
+func newExerciseMatcher(exercises []entities.Exercise) *exerciseMatcher {
+	m := &exerciseMatcher{
+		exercises: exercises,
+		tokens:    make([][]string, len(exercises)),
+	}
+	for i, exercise := range exercises {
+		m.tokens[i] = nameTokens(exercise.Name)
+	}
+	return m
+}
+
+// nameTokens splits a name into lower case words without punctuation, a plural "s" and
+// duplicates, sorted so word order does not matter ("Squat (Barbell)" and "Barbell Squat")
+func nameTokens(name string) []string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := make(map[string]bool, len(words))
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		if len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+			word = strings.TrimSuffix(word, "s")
+		}
+		if !seen[word] {
+			seen[word] = true
+			tokens = append(tokens, word)
+		}
+	}
+	sort.Strings(tokens)
+	return tokens
+}
+
+// tokenSimilarity scores two sorted token lists: the share of the shorter name found in the
+// longer one, averaged with the Jaccard index so the closest of several containing names wins
+func tokenSimilarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			common++
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	shorter := math.Min(float64(len(a)), float64(len(b)))
+	union := float64(len(a) + len(b) - common)
+	return (float64(common)/shorter + float64(common)/union) / 2
+}
+
+// match returns the catalog exercise closest to name, or false when none is similar enough.
+// Ties keep the exercise that comes first in the catalog.
+func (m *exerciseMatcher) match(name string) (ExerciseMatch, bool) {
+	tokens := nameTokens(name)
+
+	best := -1
+	bestScore := 0.0
+	for i := range m.exercises {
+		score := tokenSimilarity(tokens, m.tokens[i])
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 || bestScore < MinExerciseMatchScore {
+		return ExerciseMatch{Name: name}, false
+	}
+
+	return ExerciseMatch{
+		Name:         name,
+		ExerciseID:   m.exercises[best].ID,
+		ExerciseName: m.exercises[best].Name,
+		Score:        math.Round(bestScore*100) / 100,
+	}, true
+}
@@ -4,39 +4,44 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 
+	"goliath/apperrors"
 	"goliath/entities"
 	"goliath/repositories"
 )
 
 // ExerciseService handles business logic for exercise-related operations
 type ExerciseService struct {
-	exerciseRepo *repositories.ExerciseRepository
+	exerciseRepo  *repositories.ExerciseRepository
+	recordService *PersonalRecordService
 }
 
 // NewExerciseService creates a new ExerciseService
-func NewExerciseService(exerciseRepo *repositories.ExerciseRepository) *ExerciseService {
+func NewExerciseService(exerciseRepo *repositories.ExerciseRepository, recordService *PersonalRecordService) *ExerciseService {
 	return &ExerciseService{
-		exerciseRepo: exerciseRepo,
+		exerciseRepo:  exerciseRepo,
+		recordService: recordService,
 	}
 }
 
-// GetAllExercises retrieves all exercises with their associated exercise areas
-func (s *ExerciseService) GetAllExercises(ctx context.Context) ([]entities.Exercise, error) {
-	// Get all exercises
-	exercises, err := s.exerciseRepo.GetAll(ctx)
+// GetAllExercises retrieves one page of exercises with their associated exercise areas.
+// Archived exercises are only included on request.
+func (s *ExerciseService) GetAllExercises(ctx context.Context, params repositories.ListParams, includeArchived bool) ([]entities.Exercise, string, error) {
+	// Get a page of exercises
+	exercises, nextCursor, err := s.exerciseRepo.List(ctx, params, includeArchived)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	if len(exercises) == 0 {
-		return exercises, nil
+		return exercises, nextCursor, nil
 	}
 
 	// Get exercise areas for all exercises
 	exerciseAreasMap, err := s.exerciseRepo.GetExerciseAreasForAllExercises(ctx)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// Assign exercise areas to exercises
@@ -48,7 +53,7 @@ func (s *ExerciseService) GetAllExercises(ctx context.Context) ([]entities.Exerc
 		}
 	}
 
-	return exercises, nil
+	return exercises, nextCursor, nil
 }
 
 // GetExerciseByID retrieves a single exercise with its muscles
@@ -97,7 +102,7 @@ func (s *ExerciseService) CreateExercise(ctx context.Context, input CreateExerci
 		}
 	}
 	if !validType {
-		return 0, fmt.Errorf("invalid exercise type: %s", input.Type)
+		return 0, apperrors.Invalid("invalid_exercise_type", "invalid exercise type: %s", input.Type).WithField("type", "must be one of %s", strings.Join(s.GetExerciseTypes(), ", "))
 	}
 
 	// Check if exercise name already exists
@@ -107,7 +112,7 @@ func (s *ExerciseService) CreateExercise(ctx context.Context, input CreateExerci
 		return 0, fmt.Errorf("failed to check exercise existence: %w", err)
 	}
 	if exists {
-		return 0, fmt.Errorf("exercise with name '%s' already exists", input.Name)
+		return 0, apperrors.Conflict("exercise_name_taken", "exercise with name '%s' already exists", input.Name).WithField("name", "is already used by another exercise")
 	}
 
 	// Create exercise
@@ -124,6 +129,7 @@ type UpdateExerciseInput struct {
 	Name    string                     `json:"name" binding:"required,min=1"`
 	Type    string                     `json:"type" binding:"required"`
 	Muscles []repositories.MuscleInput `json:"muscles" binding:"required,min=1,dive"`
+	Version int                        `json:"version"` // Expected version; 0 (If-Match: *) skips the check
 }
 
 // UpdateExercise updates an existing exercise with validation
@@ -139,13 +145,13 @@ func (s *ExerciseService) UpdateExercise(ctx context.Context, id int, input Upda
 		}
 	}
 	if !validType {
-		return fmt.Errorf("invalid exercise type: %s", input.Type)
+		return apperrors.Invalid("invalid_exercise_type", "invalid exercise type: %s", input.Type).WithField("type", "must be one of %s", strings.Join(s.GetExerciseTypes(), ", "))
 	}
 
 	// Check if exercise exists
 	existingExercise, err := s.exerciseRepo.GetByID(ctx, id)
 	if err != nil {
-		return fmt.Errorf("exercise not found: %w", err)
+		return notFound(err, ErrExerciseNotFound)
 	}
 
 	// Check if new name conflicts with another exercise (if name is being changed)
@@ -155,15 +161,212 @@ func (s *ExerciseService) UpdateExercise(ctx context.Context, id int, input Upda
 			return fmt.Errorf("failed to check exercise existence: %w", err)
 		}
 		if exists {
-			return fmt.Errorf("exercise with name '%s' already exists", input.Name)
+			return apperrors.Conflict("exercise_name_taken", "exercise with name '%s' already exists", input.Name).WithField("name", "is already used by another exercise")
 		}
 	}
 
 	// Update exercise
-	err = s.exerciseRepo.Update(ctx, id, input.Name, entities.ExerciseType(input.Type), input.Muscles)
+	err = s.exerciseRepo.Update(ctx, id, input.Version, input.Name, entities.ExerciseType(input.Type), input.Muscles)
 	if err != nil {
 		return fmt.Errorf("failed to update exercise: %w", err)
 	}
 
 	return nil
 }
+
+// ArchiveExercise soft-deletes an exercise: it disappears from the catalog and search
+// but stays in the workouts and sessions that already use it
+func (s *ExerciseService) ArchiveExercise(ctx context.Context, id int) error {
+	exercise, err := s.exerciseRepo.GetByID(ctx, id)
+	if err != nil {
+		return notFound(err, ErrExerciseNotFound)
+	}
+	if exercise.ArchivedWhen != nil {
+		return apperrors.Conflict("exercise_already_archived", "exercise is already archived")
+	}
+
+	if err := s.exerciseRepo.SetArchived(ctx, id, true); err != nil {
+		return fmt.Errorf("failed to archive exercise: %w", err)
+	}
+	return nil
+}
+
+// RestoreExercise brings an archived exercise back into the catalog
+func (s *ExerciseService) RestoreExercise(ctx context.Context, id int) error {
+	exercise, err := s.exerciseRepo.GetByID(ctx, id)
+	if err != nil {
+		return notFound(err, ErrExerciseNotFound)
+	}
+	if exercise.ArchivedWhen == nil {
+		return apperrors.Conflict("exercise_not_archived", "exercise is not archived")
+	}
+
+	if err := s.exerciseRepo.SetArchived(ctx, id, false); err != nil {
+		return fmt.Errorf("failed to restore exercise: %w", err)
+	}
+	return nil
+}
+
+// MergeExercisesInput represents input for merging a duplicate exercise into another
+type MergeExercisesInput struct {
+	TargetExerciseID int `json:"target_exercise_id" binding:"required"`
+}
+
+// MergeExercises merges a duplicate exercise into the target exercise. Workout exercises,
+// performed sets and muscle mappings are repointed to the target, the duplicate is deleted
+// and the personal records of affected users are recomputed. The request transaction makes
+// this all-or-nothing.
+func (s *ExerciseService) MergeExercises(ctx context.Context, sourceID int, input MergeExercisesInput) error {
+	if sourceID == input.TargetExerciseID {
+		return apperrors.Invalid("invalid_merge_target", "cannot merge an exercise into itself").WithField("target_exercise_id", "must differ from the merged exercise")
+	}
+
+	source, err := s.exerciseRepo.GetByID(ctx, sourceID)
+	if err != nil {
+		return notFound(err, ErrExerciseNotFound)
+	}
+	target, err := s.exerciseRepo.GetByID(ctx, input.TargetExerciseID)
+	if err != nil {
+		return notFound(err, apperrors.Invalid("invalid_merge_target", "exercise %d not found", input.TargetExerciseID).WithField("target_exercise_id", "no exercise with this ID"))
+	}
+
+	// Performed sets and records only make sense within one exercise type
+	if source.Type != target.Type {
+		return apperrors.Invalid("invalid_merge_target", "cannot merge %s exercise into %s exercise", source.Type, target.Type).WithField("target_exercise_id", "must have the same type as the merged exercise")
+	}
+
+	userIDs, err := s.exerciseRepo.Merge(ctx, sourceID, target.ID)
+	if err != nil {
+		return fmt.Errorf("failed to merge exercises: %w", err)
+	}
+
+	for _, userID := range userIDs {
+		if _, err := s.recordService.RefreshRecords(ctx, userID, target.ID, nil); err != nil {
+			return fmt.Errorf("failed to refresh personal records: %w", err)
+		}
+	}
+
+	log.Printf("Merged exercise %d into %d (%d user(s) affected)", sourceID, target.ID, len(userIDs))
+	return nil
+}
+
+// SearchExercisesInput represents the query of an exercise search
+type SearchExercisesInput struct {
+	Query          string
+	Types          []string
+	MuscleID       *int
+	MuscleGroupID  *int
+	RegionID       *int
+	ExerciseAreaID *int
+	MinPercentage  float64
+	Sort           string // relevance, name, -name or type; defaults to relevance with a query, name otherwise
+	Cursor         string // Opaque cursor returned as next_cursor by the previous page
+	Limit          int
+}
+
+// SearchExercisesResult represents one page of exercise search results with facet counts
+type SearchExercisesResult struct {
+	Exercises  []entities.Exercise      `json:"exercises"`
+	Count      int                      `json:"count"`
+	NextCursor *string                  `json:"next_cursor"` // Nil on the last page
+	Facets     *entities.ExerciseFacets `json:"facets"`
+}
+
+// SearchExercises searches exercises by name and facets, returning one page of results
+// together with facet counts over all matching exercises
+func (s *ExerciseService) SearchExercises(ctx context.Context, input SearchExercisesInput) (*SearchExercisesResult, error) {
+	params := repositories.ExerciseSearchParams{
+		Query:          strings.TrimSpace(input.Query),
+		MuscleID:       input.MuscleID,
+		MuscleGroupID:  input.MuscleGroupID,
+		RegionID:       input.RegionID,
+		ExerciseAreaID: input.ExerciseAreaID,
+		MinPercentage:  input.MinPercentage,
+		Sort:           input.Sort,
+		Limit:          input.Limit,
+	}
+
+	// Validate exercise types
+	for _, t := range input.Types {
+		validType := false
+		for _, valid := range s.GetExerciseTypes() {
+			if t == valid {
+				validType = true
+				break
+			}
+		}
+		if !validType {
+			return nil, apperrors.Invalid("invalid_exercise_type", "invalid exercise type: %s", t).WithField("type", "must be one of %s", strings.Join(s.GetExerciseTypes(), ", "))
+		}
+		params.Types = append(params.Types, t)
+	}
+
+	if params.MinPercentage < 0 || params.MinPercentage > 100 {
+		return nil, apperrors.Invalid("invalid_min_percentage", "min_percentage must be between 0 and 100").WithField("min_percentage", "must be between 0 and 100")
+	}
+
+	if params.Limit == 0 {
+		params.Limit = repositories.DefaultListLimit
+	}
+	if params.Limit < 0 || params.Limit > repositories.MaxListLimit {
+		return nil, apperrors.Invalid("invalid_limit", "limit must be between 1 and %d", repositories.MaxListLimit).WithField("limit", "must be between 1 and %d", repositories.MaxListLimit)
+	}
+
+	// Validate sort order; relevance only makes sense for a text query
+	switch params.Sort {
+	case "":
+		params.Sort = repositories.ExerciseSortName
+		if params.Query != "" {
+			params.Sort = repositories.ExerciseSortRelevance
+		}
+	case repositories.ExerciseSortRelevance:
+		if params.Query == "" {
+			return nil, apperrors.Invalid("invalid_sort", "sort by relevance requires a query").WithField("sort", "relevance requires q")
+		}
+	case repositories.ExerciseSortName, repositories.ExerciseSortNameDesc, repositories.ExerciseSortType:
+	default:
+		return nil, apperrors.Invalid("invalid_sort", "invalid sort: %s", params.Sort).WithField("sort", "must be one of relevance, name, -name, type")
+	}
+
+	if input.Cursor != "" {
+		var cursor repositories.ExerciseSearchCursor
+		if err := repositories.DecodeCursor(input.Cursor, &cursor); err != nil {
+			return nil, err
+		}
+		params.Cursor = &cursor
+	}
+
+	hits, err := s.exerciseRepo.Search(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SearchExercisesResult{Exercises: []entities.Exercise{}}
+	if len(hits) > params.Limit {
+		hits = hits[:params.Limit]
+		nextCursor := repositories.EncodeCursor(hits[len(hits)-1].Cursor)
+		result.NextCursor = &nextCursor
+	}
+
+	if len(hits) > 0 {
+		// Get exercise areas for all exercises
+		exerciseAreasMap, err := s.exerciseRepo.GetExerciseAreasForAllExercises(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range hits {
+			if areas, ok := exerciseAreasMap[hit.Exercise.ID]; ok {
+				hit.Exercise.ExerciseAreas = areas
+			}
+			result.Exercises = append(result.Exercises, hit.Exercise)
+		}
+	}
+	result.Count = len(result.Exercises)
+
+	result.Facets, err = s.exerciseRepo.SearchFacets(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
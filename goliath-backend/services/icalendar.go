@@ -0,0 +1,80 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"goliath/entities"
+)
+
+// Layouts of iCalendar DATE and UTC DATE-TIME values
+const (
+	icalDateLayout     = "20060102"
+	icalDateTimeLayout = "20060102T150405Z"
+)
+
+// icalEscape escapes a TEXT value (RFC 5545, section 3.3.11)
+func icalEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+// writeICalLine writes a content line folded at 75 octets without splitting UTF-8 sequences
+func writeICalLine(b *strings.Builder, line string) {
+	for len(line) > 75 {
+		cut := 75
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// renderICalendar renders workout schedules as all-day events stamped with generatedWhen, with a
+// weekly RRULE for recurring schedules. Schedules that never plan a workout are left out.
+func renderICalendar(schedules []entities.WorkoutSchedule, generatedWhen time.Time) string {
+	var b strings.Builder
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:-//Goliath//Workout Schedule//EN")
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+	writeICalLine(&b, "X-WR-CALNAME:Goliath workouts")
+
+	for _, schedule := range schedules {
+		rule := newScheduleRule(schedule)
+		start, ok := rule.firstOccurrence()
+		if !ok {
+			continue
+		}
+
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, "UID:schedule-"+strconv.Itoa(schedule.ID)+"@goliath")
+		writeICalLine(&b, "DTSTAMP:"+generatedWhen.UTC().Format(icalDateTimeLayout))
+		writeICalLine(&b, "DTSTART;VALUE=DATE:"+start.Format(icalDateLayout))
+		writeICalLine(&b, "DTEND;VALUE=DATE:"+start.AddDate(0, 0, 1).Format(icalDateLayout))
+		if len(schedule.Weekdays) > 0 {
+			byDay := make([]string, len(schedule.Weekdays))
+			for i, weekday := range schedule.Weekdays {
+				byDay[i] = strings.ToUpper(weekday[:2])
+			}
+			rrule := "RRULE:FREQ=WEEKLY;INTERVAL=" + strconv.Itoa(rule.interval) + ";WKST=MO;BYDAY=" + strings.Join(byDay, ",")
+			if rule.end != nil {
+				rrule += ";UNTIL=" + rule.end.Format(icalDateLayout)
+			}
+			writeICalLine(&b, rrule)
+		}
+		writeICalLine(&b, "SUMMARY:"+icalEscape(schedule.WorkoutName))
+		if schedule.Notes != nil && *schedule.Notes != "" {
+			writeICalLine(&b, "DESCRIPTION:"+icalEscape(*schedule.Notes))
+		}
+		writeICalLine(&b, "END:VEVENT")
+	}
+
+	writeICalLine(&b, "END:VCALENDAR")
+	return b.String()
+}
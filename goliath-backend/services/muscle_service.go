@@ -2,11 +2,22 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	"goliath/apperrors"
 	"goliath/entities"
 	"goliath/repositories"
 )
 
+// Errors of the muscle taxonomy
+var (
+	ErrRegionNotFound       = apperrors.NotFound("region_not_found", "region not found")
+	ErrMuscleGroupNotFound  = apperrors.NotFound("muscle_group_not_found", "muscle group not found")
+	ErrMuscleNotFound       = apperrors.NotFound("muscle_not_found", "muscle not found")
+	ErrExerciseAreaNotFound = apperrors.NotFound("exercise_area_not_found", "exercise area not found")
+)
+
 // MuscleService handles business logic for muscle-related operations
 type MuscleService struct {
 	muscleRepo       *repositories.MuscleRepository
@@ -30,18 +41,18 @@ func NewMuscleService(
 	}
 }
 
-// GetAllMuscles retrieves all muscles with their exercise areas
-func (s *MuscleService) GetAllMuscles(ctx context.Context) ([]entities.Muscle, error) {
-	// Get all muscles
-	muscles, err := s.muscleRepo.GetAll(ctx)
+// GetAllMuscles retrieves one page of muscles with their exercise areas
+func (s *MuscleService) GetAllMuscles(ctx context.Context, params repositories.ListParams) ([]entities.Muscle, string, error) {
+	// Get a page of muscles
+	muscles, nextCursor, err := s.muscleRepo.List(ctx, params)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// Get exercise areas for all muscles
 	muscleAreasMap, err := s.exerciseAreaRepo.GetAllForMuscles(ctx)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// Assign exercise areas to muscles
@@ -53,21 +64,374 @@ func (s *MuscleService) GetAllMuscles(ctx context.Context) ([]entities.Muscle, e
 		}
 	}
 
-	return muscles, nil
+	return muscles, nextCursor, nil
+}
+
+// GetAllMuscleGroups retrieves one page of muscle groups
+func (s *MuscleService) GetAllMuscleGroups(ctx context.Context, params repositories.ListParams) ([]entities.MuscleGroup, string, error) {
+	return s.muscleGroupRepo.List(ctx, params)
+}
+
+// GetAllRegions retrieves one page of regions
+func (s *MuscleService) GetAllRegions(ctx context.Context, params repositories.ListParams) ([]entities.Region, string, error) {
+	return s.regionRepo.List(ctx, params)
 }
 
-// GetAllMuscleGroups retrieves all muscle groups
-func (s *MuscleService) GetAllMuscleGroups(ctx context.Context) ([]entities.MuscleGroup, error) {
-	return s.muscleGroupRepo.GetAll(ctx)
+// GetAllExerciseAreas retrieves one page of exercise areas
+func (s *MuscleService) GetAllExerciseAreas(ctx context.Context, params repositories.ListParams) ([]entities.ExerciseArea, string, error) {
+	return s.exerciseAreaRepo.List(ctx, params)
 }
 
-// GetAllRegions retrieves all regions
-func (s *MuscleService) GetAllRegions(ctx context.Context) ([]entities.Region, error) {
-	return s.regionRepo.GetAll(ctx)
+// SaveRegionInput represents input for creating or renaming a region
+type SaveRegionInput struct {
+	Name    string `json:"name" binding:"required,min=1"`
+	Version int    `json:"version"` // Expected version when renaming; 0 (If-Match: *) skips the check
 }
 
-// GetAllExerciseAreas retrieves all exercise areas
-func (s *MuscleService) GetAllExerciseAreas(ctx context.Context) ([]entities.ExerciseArea, error) {
-	return s.exerciseAreaRepo.GetAll(ctx)
+// CreateRegion creates a new region with a unique name
+func (s *MuscleService) CreateRegion(ctx context.Context, input SaveRegionInput) (int64, error) {
+	exists, err := s.regionRepo.NameExists(ctx, input.Name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check region existence: %w", err)
+	}
+	if exists {
+		return 0, apperrors.Conflict("region_name_taken", "region with name '%s' already exists", input.Name).WithField("name", "is already used by another region")
+	}
+
+	regionID, err := s.regionRepo.Create(ctx, input.Name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create region: %w", err)
+	}
+	return regionID, nil
 }
 
+// UpdateRegion renames a region
+func (s *MuscleService) UpdateRegion(ctx context.Context, id int, input SaveRegionInput) error {
+	region, err := s.regionRepo.GetByID(ctx, id)
+	if err != nil {
+		return notFound(err, ErrRegionNotFound)
+	}
+
+	if !strings.EqualFold(input.Name, region.Name) {
+		exists, err := s.regionRepo.NameExists(ctx, input.Name)
+		if err != nil {
+			return fmt.Errorf("failed to check region existence: %w", err)
+		}
+		if exists {
+			return apperrors.Conflict("region_name_taken", "region with name '%s' already exists", input.Name).WithField("name", "is already used by another region")
+		}
+	}
+
+	if err := s.regionRepo.Update(ctx, id, input.Version, input.Name); err != nil {
+		return fmt.Errorf("failed to update region: %w", err)
+	}
+	return nil
+}
+
+// DeleteRegion deletes a region that no longer has muscle groups
+func (s *MuscleService) DeleteRegion(ctx context.Context, id int) error {
+	if _, err := s.regionRepo.GetByID(ctx, id); err != nil {
+		return notFound(err, ErrRegionNotFound)
+	}
+
+	count, err := s.regionRepo.CountMuscleGroups(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to check region usage: %w", err)
+	}
+	if count > 0 {
+		return apperrors.Conflict("region_in_use", "region is still used by %d muscle group(s)", count)
+	}
+
+	if err := s.regionRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete region: %w", err)
+	}
+	return nil
+}
+
+// SaveMuscleGroupInput represents input for creating or updating a muscle group
+type SaveMuscleGroupInput struct {
+	Name     string `json:"name" binding:"required,min=1"`
+	RegionID int    `json:"region_id" binding:"required"`
+	Version  int    `json:"version"` // Expected version when updating; 0 (If-Match: *) skips the check
+}
+
+// CreateMuscleGroup creates a new muscle group in an existing region
+func (s *MuscleService) CreateMuscleGroup(ctx context.Context, input SaveMuscleGroupInput) (int64, error) {
+	if _, err := s.regionRepo.GetByID(ctx, input.RegionID); err != nil {
+		return 0, notFound(err, apperrors.Invalid("unknown_region", "region %d not found", input.RegionID).WithField("region_id", "no region with this ID"))
+	}
+
+	exists, err := s.muscleGroupRepo.NameExists(ctx, input.Name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check muscle group existence: %w", err)
+	}
+	if exists {
+		return 0, apperrors.Conflict("muscle_group_name_taken", "muscle group with name '%s' already exists", input.Name).WithField("name", "is already used by another muscle group")
+	}
+
+	muscleGroupID, err := s.muscleGroupRepo.Create(ctx, input.Name, input.RegionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create muscle group: %w", err)
+	}
+	return muscleGroupID, nil
+}
+
+// UpdateMuscleGroup renames a muscle group and moves it to another region
+func (s *MuscleService) UpdateMuscleGroup(ctx context.Context, id int, input SaveMuscleGroupInput) error {
+	muscleGroup, err := s.muscleGroupRepo.GetByID(ctx, id)
+	if err != nil {
+		return notFound(err, ErrMuscleGroupNotFound)
+	}
+
+	if _, err := s.regionRepo.GetByID(ctx, input.RegionID); err != nil {
+		return notFound(err, apperrors.Invalid("unknown_region", "region %d not found", input.RegionID).WithField("region_id", "no region with this ID"))
+	}
+
+	if !strings.EqualFold(input.Name, muscleGroup.Name) {
+		exists, err := s.muscleGroupRepo.NameExists(ctx, input.Name)
+		if err != nil {
+			return fmt.Errorf("failed to check muscle group existence: %w", err)
+		}
+		if exists {
+			return apperrors.Conflict("muscle_group_name_taken", "muscle group with name '%s' already exists", input.Name).WithField("name", "is already used by another muscle group")
+		}
+	}
+
+	if err := s.muscleGroupRepo.Update(ctx, id, input.Version, input.Name, input.RegionID); err != nil {
+		return fmt.Errorf("failed to update muscle group: %w", err)
+	}
+	return nil
+}
+
+// DeleteMuscleGroup deletes a muscle group that no longer has muscles
+func (s *MuscleService) DeleteMuscleGroup(ctx context.Context, id int) error {
+	if _, err := s.muscleGroupRepo.GetByID(ctx, id); err != nil {
+		return notFound(err, ErrMuscleGroupNotFound)
+	}
+
+	count, err := s.muscleGroupRepo.CountMuscles(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to check muscle group usage: %w", err)
+	}
+	if count > 0 {
+		return apperrors.Conflict("muscle_group_in_use", "muscle group is still used by %d muscle(s)", count)
+	}
+
+	if err := s.muscleGroupRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete muscle group: %w", err)
+	}
+	return nil
+}
+
+// SaveMuscleInput represents input for creating or updating a muscle
+type SaveMuscleInput struct {
+	Name            string `json:"name" binding:"required,min=1"`
+	MuscleGroupID   int    `json:"muscle_group_id" binding:"required"`
+	ExerciseAreaIDs []int  `json:"exercise_area_ids"` // Only used on create; links are managed separately afterwards
+	Version         int    `json:"version"`           // Expected version when updating; 0 (If-Match: *) skips the check
+}
+
+// CreateMuscle creates a new muscle in an existing muscle group, linked to the given exercise areas
+func (s *MuscleService) CreateMuscle(ctx context.Context, input SaveMuscleInput) (int64, error) {
+	if _, err := s.muscleGroupRepo.GetByID(ctx, input.MuscleGroupID); err != nil {
+		return 0, notFound(err, apperrors.Invalid("unknown_muscle_group", "muscle group %d not found", input.MuscleGroupID).WithField("muscle_group_id", "no muscle group with this ID"))
+	}
+	for _, areaID := range input.ExerciseAreaIDs {
+		if _, err := s.exerciseAreaRepo.GetByID(ctx, areaID); err != nil {
+			return 0, notFound(err, apperrors.Invalid("unknown_exercise_area", "exercise area %d not found", areaID).WithField("exercise_area_ids", "no exercise area with ID %d", areaID))
+		}
+	}
+
+	exists, err := s.muscleRepo.NameExists(ctx, input.Name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check muscle existence: %w", err)
+	}
+	if exists {
+		return 0, apperrors.Conflict("muscle_name_taken", "muscle with name '%s' already exists", input.Name).WithField("name", "is already used by another muscle")
+	}
+
+	muscleID, err := s.muscleRepo.Create(ctx, input.Name, input.MuscleGroupID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create muscle: %w", err)
+	}
+
+	linked := map[int]bool{}
+	for _, areaID := range input.ExerciseAreaIDs {
+		if linked[areaID] {
+			continue
+		}
+		if err := s.muscleRepo.LinkExerciseArea(ctx, int(muscleID), areaID); err != nil {
+			return 0, fmt.Errorf("failed to link exercise area: %w", err)
+		}
+		linked[areaID] = true
+	}
+
+	return muscleID, nil
+}
+
+// UpdateMuscle renames a muscle and moves it to another muscle group
+func (s *MuscleService) UpdateMuscle(ctx context.Context, id int, input SaveMuscleInput) error {
+	muscle, err := s.muscleRepo.GetByID(ctx, id)
+	if err != nil {
+		return notFound(err, ErrMuscleNotFound)
+	}
+
+	if _, err := s.muscleGroupRepo.GetByID(ctx, input.MuscleGroupID); err != nil {
+		return notFound(err, apperrors.Invalid("unknown_muscle_group", "muscle group %d not found", input.MuscleGroupID).WithField("muscle_group_id", "no muscle group with this ID"))
+	}
+
+	if !strings.EqualFold(input.Name, muscle.Name) {
+		exists, err := s.muscleRepo.NameExists(ctx, input.Name)
+		if err != nil {
+			return fmt.Errorf("failed to check muscle existence: %w", err)
+		}
+		if exists {
+			return apperrors.Conflict("muscle_name_taken", "muscle with name '%s' already exists", input.Name).WithField("name", "is already used by another muscle")
+		}
+	}
+
+	if err := s.muscleRepo.Update(ctx, id, input.Version, input.Name, input.MuscleGroupID); err != nil {
+		return fmt.Errorf("failed to update muscle: %w", err)
+	}
+	return nil
+}
+
+// DeleteMuscle deletes a muscle that no exercise works. Although exercise_muscle cascades,
+// silently dropping a muscle from exercises would change their percentages.
+func (s *MuscleService) DeleteMuscle(ctx context.Context, id int) error {
+	if _, err := s.muscleRepo.GetByID(ctx, id); err != nil {
+		return notFound(err, ErrMuscleNotFound)
+	}
+
+	count, err := s.muscleRepo.CountExercises(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to check muscle usage: %w", err)
+	}
+	if count > 0 {
+		return apperrors.Conflict("muscle_in_use", "muscle is still used by %d exercise(s)", count)
+	}
+
+	if err := s.muscleRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete muscle: %w", err)
+	}
+	return nil
+}
+
+// LinkMuscleExerciseArea links a muscle to an exercise area
+func (s *MuscleService) LinkMuscleExerciseArea(ctx context.Context, muscleID int, exerciseAreaID int) error {
+	if _, err := s.muscleRepo.GetByID(ctx, muscleID); err != nil {
+		return notFound(err, ErrMuscleNotFound)
+	}
+	if _, err := s.exerciseAreaRepo.GetByID(ctx, exerciseAreaID); err != nil {
+		return notFound(err, ErrExerciseAreaNotFound)
+	}
+
+	linked, err := s.muscleRepo.IsLinkedToExerciseArea(ctx, muscleID, exerciseAreaID)
+	if err != nil {
+		return fmt.Errorf("failed to check muscle exercise area link: %w", err)
+	}
+	if linked {
+		return apperrors.Conflict("muscle_already_linked", "muscle is already linked to exercise area")
+	}
+
+	if err := s.muscleRepo.LinkExerciseArea(ctx, muscleID, exerciseAreaID); err != nil {
+		return fmt.Errorf("failed to link exercise area: %w", err)
+	}
+	return nil
+}
+
+// UnlinkMuscleExerciseArea removes the link between a muscle and an exercise area
+func (s *MuscleService) UnlinkMuscleExerciseArea(ctx context.Context, muscleID int, exerciseAreaID int) error {
+	linked, err := s.muscleRepo.IsLinkedToExerciseArea(ctx, muscleID, exerciseAreaID)
+	if err != nil {
+		return fmt.Errorf("failed to check muscle exercise area link: %w", err)
+	}
+	if !linked {
+		return apperrors.NotFound("link_not_found", "muscle %d is not linked to exercise area %d", muscleID, exerciseAreaID)
+	}
+
+	if err := s.muscleRepo.UnlinkExerciseArea(ctx, muscleID, exerciseAreaID); err != nil {
+		return fmt.Errorf("failed to unlink exercise area: %w", err)
+	}
+	return nil
+}
+
+// SaveExerciseAreaInput represents input for creating or updating an exercise area
+type SaveExerciseAreaInput struct {
+	Name      string                      `json:"name" binding:"required,min=1"`
+	Plane     *entities.MovementPlane     `json:"plane,omitempty" binding:"omitempty,oneof=Horizontal Vertical Lateral Legs"`
+	Direction *entities.MovementDirection `json:"direction,omitempty" binding:"omitempty,oneof=Push Pull"`
+	Version   int                         `json:"version"` // Expected version when updating; 0 (If-Match: *) skips the check
+}
+
+// validateMovement checks that push and pull areas have both a plane and a direction
+func validateMovement(input SaveExerciseAreaInput) error {
+	if (input.Plane == nil) != (input.Direction == nil) {
+		invalid := apperrors.Invalid("invalid_movement", "plane and direction must be set together")
+		if input.Plane == nil {
+			return invalid.WithField("plane", "is required with direction")
+		}
+		return invalid.WithField("direction", "is required with plane")
+	}
+	return nil
+}
+
+// CreateExerciseArea creates a new exercise area with a unique name
+func (s *MuscleService) CreateExerciseArea(ctx context.Context, input SaveExerciseAreaInput) (int64, error) {
+	if err := validateMovement(input); err != nil {
+		return 0, err
+	}
+
+	exists, err := s.exerciseAreaRepo.NameExists(ctx, input.Name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check exercise area existence: %w", err)
+	}
+	if exists {
+		return 0, apperrors.Conflict("exercise_area_name_taken", "exercise area with name '%s' already exists", input.Name).WithField("name", "is already used by another exercise area")
+	}
+
+	exerciseAreaID, err := s.exerciseAreaRepo.Create(ctx, input.Name, input.Plane, input.Direction)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create exercise area: %w", err)
+	}
+	return exerciseAreaID, nil
+}
+
+// UpdateExerciseArea renames an exercise area and replaces its plane and direction
+func (s *MuscleService) UpdateExerciseArea(ctx context.Context, id int, input SaveExerciseAreaInput) error {
+	if err := validateMovement(input); err != nil {
+		return err
+	}
+
+	exerciseArea, err := s.exerciseAreaRepo.GetByID(ctx, id)
+	if err != nil {
+		return notFound(err, ErrExerciseAreaNotFound)
+	}
+
+	if !strings.EqualFold(input.Name, exerciseArea.Name) {
+		exists, err := s.exerciseAreaRepo.NameExists(ctx, input.Name)
+		if err != nil {
+			return fmt.Errorf("failed to check exercise area existence: %w", err)
+		}
+		if exists {
+			return apperrors.Conflict("exercise_area_name_taken", "exercise area with name '%s' already exists", input.Name).WithField("name", "is already used by another exercise area")
+		}
+	}
+
+	if err := s.exerciseAreaRepo.Update(ctx, id, input.Version, input.Name, input.Plane, input.Direction); err != nil {
+		return fmt.Errorf("failed to update exercise area: %w", err)
+	}
+	return nil
+}
+
+// DeleteExerciseArea deletes an exercise area; its muscle links are removed with it
+func (s *MuscleService) DeleteExerciseArea(ctx context.Context, id int) error {
+	if _, err := s.exerciseAreaRepo.GetByID(ctx, id); err != nil {
+		return notFound(err, ErrExerciseAreaNotFound)
+	}
+
+	if err := s.exerciseAreaRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete exercise area: %w", err)
+	}
+	return nil
+}
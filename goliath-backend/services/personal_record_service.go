@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"goliath/entities"
+	"goliath/repositories"
+)
+
+// PersonalRecordService handles detection and retrieval of personal records
+type PersonalRecordService struct {
+	personalRecordRepo *repositories.PersonalRecordRepository
+	exerciseRepo       *repositories.ExerciseRepository
+}
+
+// NewPersonalRecordService creates a new PersonalRecordService
+func NewPersonalRecordService(personalRecordRepo *repositories.PersonalRecordRepository, exerciseRepo *repositories.ExerciseRepository) *PersonalRecordService {
+	return &PersonalRecordService{
+		personalRecordRepo: personalRecordRepo,
+		exerciseRepo:       exerciseRepo,
+	}
+}
+
+// GetUserRecords retrieves one page of the personal records of a user
+func (s *PersonalRecordService) GetUserRecords(ctx context.Context, userID int, params repositories.ListParams) ([]entities.PersonalRecord, string, error) {
+	return s.personalRecordRepo.ListForUser(ctx, userID, params)
+}
+
+// GetUserExerciseRecords retrieves the personal records of a user for one exercise
+func (s *PersonalRecordService) GetUserExerciseRecords(ctx context.Context, userID int, exerciseID int) ([]entities.PersonalRecord, error) {
+	if _, err := s.exerciseRepo.GetByID(ctx, exerciseID); err != nil {
+		return nil, notFound(err, ErrExerciseNotFound)
+	}
+	return s.personalRecordRepo.GetForUserExercise(ctx, userID, exerciseID)
+}
+
+// RefreshRecords recomputes the stored personal records of a user for an exercise from all
+// performed sets and returns the records newly set by the given set (if any)
+func (s *PersonalRecordService) RefreshRecords(ctx context.Context, userID int, exerciseID int, setID *int) ([]entities.PersonalRecord, error) {
+	exercise, err := s.exerciseRepo.GetByID(ctx, exerciseID)
+	if err != nil {
+		return nil, notFound(err, ErrExerciseNotFound)
+	}
+
+	previous, err := s.personalRecordRepo.GetForUserExercise(ctx, userID, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.personalRecordRepo.GetResultsForUserExercise(ctx, userID, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+
+	records := computePersonalRecords(exercise.Type, results)
+	if err := s.personalRecordRepo.ReplaceForUserExercise(ctx, userID, exerciseID, records); err != nil {
+		return nil, fmt.Errorf("failed to store personal records: %w", err)
+	}
+
+	newRecords := []entities.PersonalRecord{}
+	if setID == nil {
+		return newRecords, nil
+	}
+
+	previousByKey := make(map[string]entities.PersonalRecord, len(previous))
+	for _, pr := range previous {
+		previousByKey[personalRecordKey(pr)] = pr
+	}
+	for _, pr := range records {
+		if pr.SessionSetID == nil || *pr.SessionSetID != *setID {
+			continue
+		}
+		old, existed := previousByKey[personalRecordKey(pr)]
+		if !existed || pr.Value > old.Value {
+			pr.ExerciseName = exercise.Name
+			newRecords = append(newRecords, pr)
+		}
+	}
+
+	return newRecords, nil
+}
+
+// personalRecordKey identifies a record slot: its type plus the weight for reps-at-weight records
+func personalRecordKey(pr entities.PersonalRecord) string {
+	if pr.RecordType == entities.PersonalRecordMaxRepsAtWeight {
+		weight := 0.0
+		if pr.Weight != nil {
+			weight = *pr.Weight
+		}
+		return fmt.Sprintf("%s@%g", pr.RecordType, weight)
+	}
+	return string(pr.RecordType)
+}
+
+// estimateOneRepMax estimates a one-rep max with the Epley formula
+func estimateOneRepMax(weight float64, reps int) float64 {
+	if reps == 1 {
+		return weight
+	}
+	return math.Round(weight*(1+float64(reps)/30)*100) / 100
+}
+
+// computePersonalRecords derives the best records from performed results, ordered
+// chronologically. Which records apply depends on the exercise type; ties keep the
+// earliest result.
+func computePersonalRecords(exerciseType entities.ExerciseType, results []entities.PersonalRecord) []entities.PersonalRecord {
+	best := make(map[string]entities.PersonalRecord)
+	consider := func(result entities.PersonalRecord, recordType entities.PersonalRecordType, value float64) {
+		candidate := result
+		candidate.RecordType = recordType
+		candidate.Value = value
+		key := personalRecordKey(candidate)
+		if current, ok := best[key]; !ok || value > current.Value {
+			best[key] = candidate
+		}
+	}
+
+	for _, result := range results {
+		switch exerciseType {
+		case entities.ExerciseTypeReps:
+			if result.Reps == nil || *result.Reps <= 0 {
+				continue
+			}
+			consider(result, entities.PersonalRecordMaxRepsAtWeight, float64(*result.Reps))
+			if result.Weight != nil && *result.Weight > 0 {
+				consider(result, entities.PersonalRecordMaxWeight, *result.Weight)
+				consider(result, entities.PersonalRecordEstimated1RM, estimateOneRepMax(*result.Weight, *result.Reps))
+			}
+		case entities.ExerciseTypeIsometric:
+			if result.TimeSeconds != nil && *result.TimeSeconds > 0 {
+				consider(result, entities.PersonalRecordLongestHold, float64(*result.TimeSeconds))
+			}
+		case entities.ExerciseTypeEccentric:
+			if result.TimeSeconds != nil && *result.TimeSeconds > 0 {
+				consider(result, entities.PersonalRecordLongestEccentric, float64(*result.TimeSeconds))
+			}
+		}
+	}
+
+	records := make([]entities.PersonalRecord, 0, len(best))
+	for _, pr := range best {
+		records = append(records, pr)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return personalRecordKey(records[i]) < personalRecordKey(records[j])
+	})
+
+	return records
+}
@@ -0,0 +1,524 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"goliath/apperrors"
+	"goliath/entities"
+	"goliath/repositories"
+)
+
+// Errors of the program service
+var (
+	ErrProgramNotFound     = apperrors.NotFound("program_not_found", "program not found")
+	ErrProgramWeekNotFound = apperrors.NotFound("program_week_not_found", "program week not found")
+	ErrProgramDayNotFound  = apperrors.NotFound("program_day_not_found", "program day not found")
+	ErrEnrollmentNotFound  = apperrors.NotFound("enrollment_not_found", "enrollment not found")
+	ErrEnrollmentCompleted = apperrors.Conflict("enrollment_completed", "program already completed")
+)
+
+// DefaultDeloadPercentage is the share of the weights kept on deload weeks when none is given
+const DefaultDeloadPercentage = 60
+
+// ProgramService handles business logic for training programs and enrollments. It does not check
+// who may access a program or enrollment: callers authorize the user with the authorization package
+// first, which also verifies that weeks and days belong to the program of the URL.
+type ProgramService struct {
+	programRepo         *repositories.ProgramRepository
+	programWeekRepo     *repositories.ProgramWeekRepository
+	programDayRepo      *repositories.ProgramDayRepository
+	enrollmentRepo      *repositories.ProgramEnrollmentRepository
+	workoutExerciseRepo *repositories.WorkoutExerciseRepository
+}
+
+// NewProgramService creates a new ProgramService
+func NewProgramService(
+	programRepo *repositories.ProgramRepository,
+	programWeekRepo *repositories.ProgramWeekRepository,
+	programDayRepo *repositories.ProgramDayRepository,
+	enrollmentRepo *repositories.ProgramEnrollmentRepository,
+	workoutExerciseRepo *repositories.WorkoutExerciseRepository,
+) *ProgramService {
+	return &ProgramService{
+		programRepo:         programRepo,
+		programWeekRepo:     programWeekRepo,
+		programDayRepo:      programDayRepo,
+		enrollmentRepo:      enrollmentRepo,
+		workoutExerciseRepo: workoutExerciseRepo,
+	}
+}
+
+// GetUserPrograms retrieves one page of programs for a user without their weeks
+func (s *ProgramService) GetUserPrograms(ctx context.Context, userID int, params repositories.ListParams) ([]entities.Program, string, error) {
+	return s.programRepo.ListForUser(ctx, userID, params)
+}
+
+// getProgram loads a program
+func (s *ProgramService) getProgram(ctx context.Context, id int) (*entities.Program, error) {
+	program, err := s.programRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrProgramNotFound)
+	}
+	return program, nil
+}
+
+// GetProgramByID retrieves a program with its weeks and days
+func (s *ProgramService) GetProgramByID(ctx context.Context, id int) (*entities.Program, error) {
+	program, err := s.getProgram(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	weeks, err := s.programWeekRepo.GetAllForProgram(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	program.Weeks = weeks
+
+	return program, nil
+}
+
+// ProgramInput represents input for creating or updating a program
+type ProgramInput struct {
+	Name             string   `json:"name" binding:"required,min=1"`
+	Description      *string  `json:"description,omitempty"`
+	WeightIncrement  float64  `json:"weight_increment" binding:"min=0"`                              // Weight added every week
+	RepsIncrement    int      `json:"reps_increment" binding:"min=0"`                                // Reps added every week
+	DeloadEvery      int      `json:"deload_every" binding:"min=0"`                                  // Every Nth week is a deload week; 0 for none
+	DeloadPercentage *float64 `json:"deload_percentage,omitempty" binding:"omitempty,min=1,max=100"` // Defaults to 60
+	Version          int      `json:"version"`                                                       // Expected version when updating; 0 (If-Match: *) skips the check
+}
+
+// deloadPercentage resolves the deload percentage of the input
+func (input ProgramInput) deloadPercentage() float64 {
+	if input.DeloadPercentage == nil {
+		return DefaultDeloadPercentage
+	}
+	return *input.DeloadPercentage
+}
+
+// CreateProgram creates a new program for a user
+func (s *ProgramService) CreateProgram(ctx context.Context, userID int, input ProgramInput) (int64, error) {
+	log.Printf("Service: creating program %s for user %d", input.Name, userID)
+
+	id, err := s.programRepo.Create(ctx, userID, input.Name, input.Description, input.WeightIncrement, input.RepsIncrement, input.DeloadEvery, input.deloadPercentage())
+	if err != nil {
+		return 0, fmt.Errorf("failed to create program: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateProgram updates a program
+func (s *ProgramService) UpdateProgram(ctx context.Context, id int, input ProgramInput) error {
+	if err := s.programRepo.Update(ctx, id, input.Version, input.Name, input.Description, input.WeightIncrement, input.RepsIncrement, input.DeloadEvery, input.deloadPercentage()); err != nil {
+		return fmt.Errorf("failed to update program: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteProgram deletes a program with its weeks, days and enrollments
+func (s *ProgramService) DeleteProgram(ctx context.Context, id int) error {
+	if err := s.programRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete program: %w", err)
+	}
+
+	return nil
+}
+
+// ProgramWeekInput represents input for creating or updating a program week
+type ProgramWeekInput struct {
+	WeekNumber int     `json:"week_number" binding:"required,min=1"`
+	Notes      *string `json:"notes,omitempty"`
+	Version    int     `json:"version"` // Expected version when updating; 0 (If-Match: *) skips the check
+}
+
+// checkWeekNumber verifies no other week of the program has the given number
+func (s *ProgramService) checkWeekNumber(ctx context.Context, programID int, weekNumber int, weekID int) error {
+	exists, err := s.programWeekRepo.WeekNumberExists(ctx, programID, weekNumber, weekID)
+	if err != nil {
+		return fmt.Errorf("failed to check week existence: %w", err)
+	}
+	if exists {
+		return apperrors.Conflict("week_number_taken", "program already has a week %d", weekNumber).WithField("week_number", "is already used by another week")
+	}
+	return nil
+}
+
+// AddWeekToProgram adds a week to a program
+func (s *ProgramService) AddWeekToProgram(ctx context.Context, programID int, input ProgramWeekInput) (int64, error) {
+	if err := s.checkWeekNumber(ctx, programID, input.WeekNumber, 0); err != nil {
+		return 0, err
+	}
+
+	id, err := s.programWeekRepo.Create(ctx, programID, input.WeekNumber, input.Notes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add week to program: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateProgramWeek updates a week of a program
+func (s *ProgramService) UpdateProgramWeek(ctx context.Context, programID int, weekID int, input ProgramWeekInput) error {
+	if err := s.checkWeekNumber(ctx, programID, input.WeekNumber, weekID); err != nil {
+		return err
+	}
+
+	if err := s.programWeekRepo.Update(ctx, weekID, input.Version, input.WeekNumber, input.Notes); err != nil {
+		return fmt.Errorf("failed to update program week: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveWeekFromProgram removes a week and its days from a program
+func (s *ProgramService) RemoveWeekFromProgram(ctx context.Context, weekID int) error {
+	if err := s.programWeekRepo.Delete(ctx, weekID); err != nil {
+		return fmt.Errorf("failed to remove program week: %w", err)
+	}
+
+	return nil
+}
+
+// ProgramDayInput represents input for creating or updating a program day
+type ProgramDayInput struct {
+	DayNumber int `json:"day_number" binding:"required,min=1"`
+	WorkoutID int `json:"workout_id" binding:"required"`
+	Version   int `json:"version"` // Expected version when updating; 0 (If-Match: *) skips the check
+}
+
+// checkDay verifies no other day of the week has the number of a day. Handlers authorize access
+// to its workout.
+func (s *ProgramService) checkDay(ctx context.Context, weekID int, dayID int, input ProgramDayInput) error {
+	exists, err := s.programDayRepo.DayNumberExists(ctx, weekID, input.DayNumber, dayID)
+	if err != nil {
+		return fmt.Errorf("failed to check day existence: %w", err)
+	}
+	if exists {
+		return apperrors.Conflict("day_number_taken", "program week already has a day %d", input.DayNumber).WithField("day_number", "is already used by another day")
+	}
+	return nil
+}
+
+// AddDayToProgramWeek adds a day with a workout to a program week
+func (s *ProgramService) AddDayToProgramWeek(ctx context.Context, weekID int, input ProgramDayInput) (int64, error) {
+	if err := s.checkDay(ctx, weekID, 0, input); err != nil {
+		return 0, err
+	}
+
+	id, err := s.programDayRepo.Create(ctx, weekID, input.DayNumber, input.WorkoutID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add day to program week: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateProgramDay updates a day of a program week
+func (s *ProgramService) UpdateProgramDay(ctx context.Context, weekID int, dayID int, input ProgramDayInput) error {
+	if err := s.checkDay(ctx, weekID, dayID, input); err != nil {
+		return err
+	}
+
+	if err := s.programDayRepo.Update(ctx, dayID, input.Version, input.DayNumber, input.WorkoutID); err != nil {
+		return fmt.Errorf("failed to update program day: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveDayFromProgramWeek removes a day from a program week
+func (s *ProgramService) RemoveDayFromProgramWeek(ctx context.Context, dayID int) error {
+	if err := s.programDayRepo.Delete(ctx, dayID); err != nil {
+		return fmt.Errorf("failed to remove program day: %w", err)
+	}
+
+	return nil
+}
+
+// weekProgression returns the increments of a program week and whether it is a deload week.
+// Deload weeks do not progress: the increments grow with the number of regular weeks before the week.
+func weekProgression(program *entities.Program, weekNumber int) (float64, int, bool) {
+	steps := weekNumber - 1
+	deload := false
+	if program.DeloadEvery > 0 {
+		steps -= (weekNumber - 1) / program.DeloadEvery
+		deload = weekNumber%program.DeloadEvery == 0
+	}
+	return float64(steps) * program.WeightIncrement, steps * program.RepsIncrement, deload
+}
+
+// progressWeight applies the weight increment and deload to a planned weight
+func progressWeight(weight *float64, increment float64, deloadPercentage float64, deload bool) *float64 {
+	if weight == nil {
+		return nil
+	}
+	progressed := *weight + increment
+	if deload {
+		progressed *= deloadPercentage / 100
+	}
+	progressed = math.Round(progressed*100) / 100
+	return &progressed
+}
+
+// progressReps applies the reps increment to planned reps
+func progressReps(reps *int, increment int) *int {
+	if reps == nil {
+		return nil
+	}
+	progressed := *reps + increment
+	return &progressed
+}
+
+// applyProgression replaces the planned weights and reps of workout exercises with those of a program week.
+// Warm-up sets are left as planned.
+func applyProgression(exercises []entities.WorkoutExercise, program *entities.Program, weightIncrement float64, repsIncrement int, deload bool) {
+	for i := range exercises {
+		exercise := &exercises[i]
+		exercise.Weight = progressWeight(exercise.Weight, weightIncrement, program.DeloadPercentage, deload)
+		exercise.Reps = progressReps(exercise.Reps, repsIncrement)
+
+		for j := range exercise.SetDetails {
+			set := &exercise.SetDetails[j]
+			if set.SetType == entities.SetTypeWarmUp {
+				continue
+			}
+			set.Weight = progressWeight(set.Weight, weightIncrement, program.DeloadPercentage, deload)
+			set.Reps = progressReps(set.Reps, repsIncrement)
+		}
+	}
+}
+
+// prescription builds the concrete workout of one day of a program
+func (s *ProgramService) prescription(ctx context.Context, program *entities.Program, weekNumber int, dayNumber int) (*entities.ProgramPrescription, error) {
+	week, err := s.programWeekRepo.GetByNumber(ctx, program.ID, weekNumber)
+	if err != nil {
+		return nil, notFound(err, ErrProgramWeekNotFound)
+	}
+	day, err := s.programDayRepo.GetByNumber(ctx, week.ID, dayNumber)
+	if err != nil {
+		return nil, notFound(err, ErrProgramDayNotFound)
+	}
+
+	exercises, err := s.workoutExerciseRepo.GetAllForWorkout(ctx, day.WorkoutID)
+	if err != nil {
+		return nil, err
+	}
+
+	weightIncrement, repsIncrement, deload := weekProgression(program, weekNumber)
+	applyProgression(exercises, program, weightIncrement, repsIncrement, deload)
+
+	return &entities.ProgramPrescription{
+		ProgramID:       program.ID,
+		WeekNumber:      weekNumber,
+		DayNumber:       dayNumber,
+		WorkoutID:       day.WorkoutID,
+		WorkoutName:     day.WorkoutName,
+		Deload:          deload,
+		WeightIncrement: weightIncrement,
+		RepsIncrement:   repsIncrement,
+		Exercises:       exercises,
+	}, nil
+}
+
+// GetPrescription retrieves the concrete workout of a week and day of a program
+func (s *ProgramService) GetPrescription(ctx context.Context, programID int, weekNumber int, dayNumber int) (*entities.ProgramPrescription, error) {
+	program, err := s.getProgram(ctx, programID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.prescription(ctx, program, weekNumber, dayNumber)
+}
+
+// programPosition identifies a day of a program by its week and day numbers
+type programPosition struct {
+	week int
+	day  int
+}
+
+// programSchedule returns every day of a program in training order
+func (s *ProgramService) programSchedule(ctx context.Context, programID int) ([]programPosition, error) {
+	weeks, err := s.programWeekRepo.GetAllForProgram(ctx, programID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := []programPosition{}
+	for _, week := range weeks {
+		for _, day := range week.Days {
+			schedule = append(schedule, programPosition{week: week.WeekNumber, day: day.DayNumber})
+		}
+	}
+	return schedule, nil
+}
+
+// GetUserEnrollments retrieves one page of program enrollments for a user
+func (s *ProgramService) GetUserEnrollments(ctx context.Context, userID int, params repositories.ListParams) ([]entities.ProgramEnrollment, string, error) {
+	return s.enrollmentRepo.ListForUser(ctx, userID, params)
+}
+
+// GetEnrollmentByID retrieves an enrollment
+func (s *ProgramService) GetEnrollmentByID(ctx context.Context, id int) (*entities.ProgramEnrollment, error) {
+	enrollment, err := s.enrollmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrEnrollmentNotFound)
+	}
+	return enrollment, nil
+}
+
+// EnrollInput represents input for enrolling in a program
+type EnrollInput struct {
+	StartedWhen *time.Time `json:"started_when,omitempty"` // Defaults to now
+}
+
+// EnrollInProgram starts following a program at its first day
+func (s *ProgramService) EnrollInProgram(ctx context.Context, programID int, userID int, input EnrollInput) (int64, error) {
+	// A program can only be followed once at a time
+	active, err := s.enrollmentRepo.ActiveExists(ctx, userID, programID, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check enrollment existence: %w", err)
+	}
+	if active {
+		return 0, apperrors.Conflict("already_enrolled", "already enrolled in program %d", programID)
+	}
+
+	schedule, err := s.programSchedule(ctx, programID)
+	if err != nil {
+		return 0, err
+	}
+	if len(schedule) == 0 {
+		return 0, apperrors.Conflict("program_empty", "program has no days to follow")
+	}
+
+	startedWhen := time.Now().UTC()
+	if input.StartedWhen != nil {
+		startedWhen = input.StartedWhen.UTC()
+	}
+
+	log.Printf("Service: enrolling user %d in program %d", userID, programID)
+	id, err := s.enrollmentRepo.Create(ctx, userID, programID, startedWhen, schedule[0].week, schedule[0].day)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enroll in program: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetEnrollmentPrescription retrieves the concrete workout of the next day of an enrollment
+func (s *ProgramService) GetEnrollmentPrescription(ctx context.Context, id int) (*entities.ProgramPrescription, error) {
+	enrollment, err := s.GetEnrollmentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment.CompletedWhen != nil {
+		return nil, ErrEnrollmentCompleted
+	}
+
+	program, err := s.getProgram(ctx, enrollment.ProgramID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.prescription(ctx, program, enrollment.CurrentWeek, enrollment.CurrentDay)
+}
+
+// AdvanceEnrollmentInput represents input for marking the current day of an enrollment as done
+type AdvanceEnrollmentInput struct {
+	Version int `json:"version"` // Expected version; 0 (If-Match: *) skips the check
+}
+
+// AdvanceEnrollment moves an enrollment to the next day of its program, completing it after the last day
+func (s *ProgramService) AdvanceEnrollment(ctx context.Context, id int, input AdvanceEnrollmentInput) error {
+	enrollment, err := s.GetEnrollmentByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if enrollment.CompletedWhen != nil {
+		return ErrEnrollmentCompleted
+	}
+
+	schedule, err := s.programSchedule(ctx, enrollment.ProgramID)
+	if err != nil {
+		return err
+	}
+
+	// The next day is the first one after the current position, which may have been removed since
+	for _, position := range schedule {
+		if position.week > enrollment.CurrentWeek || (position.week == enrollment.CurrentWeek && position.day > enrollment.CurrentDay) {
+			if err := s.enrollmentRepo.UpdatePosition(ctx, id, input.Version, position.week, position.day, nil); err != nil {
+				return fmt.Errorf("failed to advance enrollment: %w", err)
+			}
+			return nil
+		}
+	}
+
+	completedWhen := time.Now().UTC()
+	if err := s.enrollmentRepo.UpdatePosition(ctx, id, input.Version, enrollment.CurrentWeek, enrollment.CurrentDay, &completedWhen); err != nil {
+		return fmt.Errorf("failed to complete enrollment: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateEnrollmentInput represents input for moving an enrollment to another day
+type UpdateEnrollmentInput struct {
+	CurrentWeek int `json:"current_week" binding:"required,min=1"`
+	CurrentDay  int `json:"current_day" binding:"required,min=1"`
+	Version     int `json:"version"` // Expected version; 0 (If-Match: *) skips the check
+}
+
+// UpdateEnrollment moves an enrollment to a day of its program, reopening it if it was completed
+func (s *ProgramService) UpdateEnrollment(ctx context.Context, id int, input UpdateEnrollmentInput) error {
+	enrollment, err := s.GetEnrollmentByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	schedule, err := s.programSchedule(ctx, enrollment.ProgramID)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, position := range schedule {
+		if position.week == input.CurrentWeek && position.day == input.CurrentDay {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return apperrors.Invalid("unknown_program_day", "program has no day %d in week %d", input.CurrentDay, input.CurrentWeek).WithField("current_day", "no such day in week %d", input.CurrentWeek)
+	}
+
+	// Reopening a completed enrollment must not clash with a newer one of the same program
+	if enrollment.CompletedWhen != nil {
+		active, err := s.enrollmentRepo.ActiveExists(ctx, enrollment.UserID, enrollment.ProgramID, id)
+		if err != nil {
+			return fmt.Errorf("failed to check enrollment existence: %w", err)
+		}
+		if active {
+			return apperrors.Conflict("already_enrolled", "already enrolled in program %d", enrollment.ProgramID)
+		}
+	}
+
+	if err := s.enrollmentRepo.UpdatePosition(ctx, id, input.Version, input.CurrentWeek, input.CurrentDay, nil); err != nil {
+		return fmt.Errorf("failed to update enrollment: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteEnrollment stops following a program
+func (s *ProgramService) DeleteEnrollment(ctx context.Context, id int) error {
+	if err := s.enrollmentRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete enrollment: %w", err)
+	}
+
+	return nil
+}
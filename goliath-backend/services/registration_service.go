@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"goliath/apperrors"
+	"goliath/entities"
+	"goliath/middleware"
+	"goliath/repositories"
+)
+
+// maxInviteDays is the longest an invite can stay valid
+const maxInviteDays = 365
+
+// emailDomainPattern matches a lowercase domain name such as example.com
+var emailDomainPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)+$`)
+
+// RegistrationPolicyInput represents input for updating the registration policy
+type RegistrationPolicyInput struct {
+	InviteOnly          bool     `json:"invite_only"`
+	AllowedEmailDomains []string `json:"allowed_email_domains"` // Verified emails of these domains need no invite
+	Version             int      `json:"version"`               // Expected version when updating; 0 (If-Match: *) skips the check
+}
+
+// InviteInput represents input for creating an invite
+type InviteInput struct {
+	Email         *string `json:"email"`           // Restricts the invite to one email, if set
+	Note          *string `json:"note"`            // Who the invite is for, shown to admins
+	ExpiresInDays *int    `json:"expires_in_days"` // Never expires if not set
+}
+
+// RegistrationService handles business logic for who may sign up
+type RegistrationService struct {
+	registrationRepo *repositories.RegistrationRepository
+}
+
+// NewRegistrationService creates a new RegistrationService
+func NewRegistrationService(registrationRepo *repositories.RegistrationRepository) *RegistrationService {
+	return &RegistrationService{
+		registrationRepo: registrationRepo,
+	}
+}
+
+// GetPolicy retrieves the registration policy
+func (s *RegistrationService) GetPolicy(ctx context.Context) (*entities.RegistrationPolicy, error) {
+	policy, err := s.registrationRepo.GetPolicy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registration policy: %w", err)
+	}
+	return policy, nil
+}
+
+// UpdatePolicy switches invite-only registration on or off and replaces the allowed email domains
+func (s *RegistrationService) UpdatePolicy(ctx context.Context, input RegistrationPolicyInput) error {
+	policy := entities.RegistrationPolicy{InviteOnly: input.InviteOnly}
+	seen := make(map[string]bool)
+	for _, domain := range input.AllowedEmailDomains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "@")
+		if !emailDomainPattern.MatchString(domain) {
+			return apperrors.Invalid("invalid_registration_policy", "Invalid email domain %q", domain).WithField("allowed_email_domains", "must be domain names like example.com")
+		}
+		if !seen[domain] {
+			seen[domain] = true
+			policy.AllowedEmailDomains = append(policy.AllowedEmailDomains, domain)
+		}
+	}
+
+	if err := s.registrationRepo.UpdatePolicy(ctx, input.Version, policy); err != nil {
+		return fmt.Errorf("failed to update registration policy: %w", err)
+	}
+	return nil
+}
+
+// GetInvites retrieves all invites
+func (s *RegistrationService) GetInvites(ctx context.Context) ([]entities.UserInvite, error) {
+	invites, err := s.registrationRepo.GetInvites(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invites: %w", err)
+	}
+	return invites, nil
+}
+
+// CreateInvite issues a single-use invite code. Only a hash of the code is stored, so it is
+// returned with the invite this once and cannot be shown again.
+func (s *RegistrationService) CreateInvite(ctx context.Context, input InviteInput) (*entities.UserInvite, error) {
+	var invite entities.UserInvite
+
+	if input.Email != nil && strings.TrimSpace(*input.Email) != "" {
+		address, err := mail.ParseAddress(strings.TrimSpace(*input.Email))
+		if err != nil || address.Name != "" {
+			return nil, apperrors.Invalid("invalid_invite", "Invalid email %q", *input.Email).WithField("email", "must be an email address")
+		}
+		invite.Email = &address.Address
+	}
+	if input.Note != nil {
+		if note := strings.TrimSpace(*input.Note); note != "" {
+			invite.Note = &note
+		}
+	}
+	if input.ExpiresInDays != nil {
+		days := *input.ExpiresInDays
+		if days < 1 || days > maxInviteDays {
+			return nil, apperrors.Invalid("invalid_invite", "Invalid expiry of %d days", days).WithField("expires_in_days", "must be between 1 and %d", maxInviteDays)
+		}
+		expires := time.Now().UTC().AddDate(0, 0, days).Truncate(time.Second)
+		invite.ExpiresWhen = &expires
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate invite code: %w", err)
+	}
+	code := base64.RawURLEncoding.EncodeToString(buf)
+
+	id, err := s.registrationRepo.CreateInvite(ctx, middleware.HashInviteCode(code), invite)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invite: %w", err)
+	}
+
+	created, err := s.registrationRepo.GetInviteByID(ctx, int(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+	if created == nil {
+		return nil, ErrInviteNotFound
+	}
+	created.Code = code
+	return created, nil
+}
+
+// DeleteInvite revokes an unused invite
+func (s *RegistrationService) DeleteInvite(ctx context.Context, id int) error {
+	invite, err := s.registrationRepo.GetInviteByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get invite: %w", err)
+	}
+	if invite == nil {
+		return ErrInviteNotFound
+	}
+	if invite.UsedWhen != nil {
+		return apperrors.Conflict("invite_used", "invite has already been used")
+	}
+
+	if err := s.registrationRepo.DeleteInvite(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete invite: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"goliath/apperrors"
+	"goliath/entities"
+	"goliath/repositories"
+)
+
+// Errors of role management
+var (
+	ErrRoleNotFound     = apperrors.NotFound("role_not_found", "role not found")
+	ErrRoleBuiltIn      = apperrors.Forbidden("role_built_in", "built-in roles cannot be deleted, and the ADMIN role always holds every permission")
+	ErrRoleNotGrantable = apperrors.Forbidden("insufficient_permissions", "cannot grant permissions you do not hold")
+)
+
+// roleNamePattern matches role names such as CATALOG_EDITOR
+var roleNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{1,31}$`)
+
+// CreateRoleInput represents input for creating a role
+type CreateRoleInput struct {
+	Name        string                `json:"name" binding:"required"` // Upper case, e.g. CATALOG_EDITOR; cannot be changed
+	Description *string               `json:"description"`
+	Permissions []entities.Permission `json:"permissions"`
+}
+
+// UpdateRoleInput represents input for updating a role
+type UpdateRoleInput struct {
+	Description *string               `json:"description"`
+	Permissions []entities.Permission `json:"permissions"` // Replaces the permissions of the role
+	Version     int                   `json:"version"`     // Expected version when updating; 0 (If-Match: *) skips the check
+}
+
+// RoleService handles business logic for roles and permissions
+type RoleService struct {
+	roleRepo *repositories.RoleRepository
+}
+
+// NewRoleService creates a new RoleService
+func NewRoleService(roleRepo *repositories.RoleRepository) *RoleService {
+	return &RoleService{
+		roleRepo: roleRepo,
+	}
+}
+
+// GetPermissions retrieves every permission roles can grant
+func (s *RoleService) GetPermissions(ctx context.Context) ([]entities.PermissionDefinition, error) {
+	permissions, err := s.roleRepo.GetPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+// GetRoles retrieves all roles with their permissions
+func (s *RoleService) GetRoles(ctx context.Context) ([]entities.Role, error) {
+	roles, err := s.roleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles: %w", err)
+	}
+	return roles, nil
+}
+
+// GetRole retrieves a role with its permissions by name
+func (s *RoleService) GetRole(ctx context.Context, name string) (*entities.Role, error) {
+	role, err := s.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+	return role, nil
+}
+
+// CreateRole creates a role. Actors can only grant permissions they hold themselves.
+func (s *RoleService) CreateRole(ctx context.Context, actor *entities.User, input CreateRoleInput) (int64, error) {
+	if !roleNamePattern.MatchString(input.Name) {
+		return 0, apperrors.Invalid("invalid_role", "Invalid role name %q", input.Name).WithField("name", "must be 2 to 32 upper case letters, digits or underscores, e.g. CATALOG_EDITOR")
+	}
+	existing, err := s.roleRepo.GetByName(ctx, input.Name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check role existence: %w", err)
+	}
+	if existing != nil {
+		return 0, apperrors.Conflict("role_name_taken", "role with name '%s' already exists", input.Name).WithField("name", "is already used by another role")
+	}
+
+	permissions, err := s.validatePermissions(ctx, actor, input.Permissions)
+	if err != nil {
+		return 0, err
+	}
+
+	roleID, err := s.roleRepo.Create(ctx, entities.Role{Name: input.Name, Description: trimmedDescription(input.Description), Permissions: permissions})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create role: %w", err)
+	}
+	return roleID, nil
+}
+
+// UpdateRole replaces the description and permissions of a role. Actors must hold every
+// permission the role grants before and after the update.
+func (s *RoleService) UpdateRole(ctx context.Context, actor *entities.User, name string, input UpdateRoleInput) error {
+	role, err := s.GetRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role.Name == entities.RoleAdmin {
+		return ErrRoleBuiltIn
+	}
+	if err := ensureGrantable(actor, role.Permissions); err != nil {
+		return err
+	}
+
+	permissions, err := s.validatePermissions(ctx, actor, input.Permissions)
+	if err != nil {
+		return err
+	}
+
+	role.Description = trimmedDescription(input.Description)
+	role.Permissions = permissions
+	if err := s.roleRepo.Update(ctx, role.ID, input.Version, *role); err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+	return nil
+}
+
+// DeleteRole deletes a role that is not built in and no user holds
+func (s *RoleService) DeleteRole(ctx context.Context, name string) error {
+	role, err := s.GetRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role.BuiltIn {
+		return ErrRoleBuiltIn
+	}
+
+	count, err := s.roleRepo.CountUsers(ctx, role.Name)
+	if err != nil {
+		return fmt.Errorf("failed to check role usage: %w", err)
+	}
+	if count > 0 {
+		return apperrors.Conflict("role_in_use", "role is still held by %d user(s)", count)
+	}
+
+	if err := s.roleRepo.Delete(ctx, role.ID); err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+	return nil
+}
+
+// validatePermissions checks that permissions exist and that the actor holds them, and removes
+// duplicates
+func (s *RoleService) validatePermissions(ctx context.Context, actor *entities.User, permissions []entities.Permission) ([]entities.Permission, error) {
+	definitions, err := s.roleRepo.GetPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+	known := make(map[entities.Permission]bool, len(definitions))
+	for _, definition := range definitions {
+		known[definition.Name] = true
+	}
+
+	unique := []entities.Permission{}
+	seen := make(map[entities.Permission]bool)
+	for _, permission := range permissions {
+		if !known[permission] {
+			return nil, apperrors.Invalid("invalid_permission", "Unknown permission %q", permission).WithField("permissions", "must be permissions listed by GET /permissions")
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			unique = append(unique, permission)
+		}
+	}
+
+	if err := ensureGrantable(actor, unique); err != nil {
+		return nil, err
+	}
+	return unique, nil
+}
+
+// ensureGrantable refuses actors who do not hold every one of the permissions, so nobody can
+// give others, or themselves through another user, more than they have
+func ensureGrantable(actor *entities.User, permissions []entities.Permission) error {
+	for _, permission := range permissions {
+		if !actor.HasPermission(permission) {
+			return ErrRoleNotGrantable
+		}
+	}
+	return nil
+}
+
+// trimmedDescription returns a description without surrounding spaces, nil when it is blank
+func trimmedDescription(description *string) *string {
+	if description == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*description)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
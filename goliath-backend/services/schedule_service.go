@@ -0,0 +1,406 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"goliath/apperrors"
+	"goliath/entities"
+	"goliath/repositories"
+)
+
+// Errors of the schedule service
+var (
+	ErrScheduleNotFound     = apperrors.NotFound("schedule_not_found", "schedule not found")
+	ErrCalendarFeedNotFound = apperrors.NotFound("calendar_feed_not_found", "calendar feed not found")
+)
+
+// MaxCalendarDays is the longest date range a calendar can be requested for
+const MaxCalendarDays = 366
+
+// dateLayout is the layout of schedule and calendar dates
+const dateLayout = "2006-01-02"
+
+// weekdayNames are the accepted weekday names in calendar order, starting on Monday
+var weekdayNames = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// ScheduleService handles business logic for workout schedules, the calendar and its iCalendar
+// feed. It does not check who may access a schedule: callers authorize the user with the
+// authorization package first.
+type ScheduleService struct {
+	scheduleRepo     *repositories.ScheduleRepository
+	calendarFeedRepo *repositories.CalendarFeedRepository
+	sessionRepo      *repositories.SessionRepository
+}
+
+// NewScheduleService creates a new ScheduleService
+func NewScheduleService(
+	scheduleRepo *repositories.ScheduleRepository,
+	calendarFeedRepo *repositories.CalendarFeedRepository,
+	sessionRepo *repositories.SessionRepository,
+) *ScheduleService {
+	return &ScheduleService{
+		scheduleRepo:     scheduleRepo,
+		calendarFeedRepo: calendarFeedRepo,
+		sessionRepo:      sessionRepo,
+	}
+}
+
+// GetUserSchedules retrieves one page of workout schedules for a user
+func (s *ScheduleService) GetUserSchedules(ctx context.Context, userID int, params repositories.ListParams) ([]entities.WorkoutSchedule, string, error) {
+	return s.scheduleRepo.ListForUser(ctx, userID, params)
+}
+
+// GetScheduleByID retrieves a workout schedule
+func (s *ScheduleService) GetScheduleByID(ctx context.Context, id int) (*entities.WorkoutSchedule, error) {
+	schedule, err := s.scheduleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrScheduleNotFound)
+	}
+	return schedule, nil
+}
+
+// ScheduleInput represents input for creating or updating a workout schedule
+type ScheduleInput struct {
+	WorkoutID     int      `json:"workout_id" binding:"required"`
+	StartDate     string   `json:"start_date" binding:"required"`                   // YYYY-MM-DD; the only date of a one-off schedule
+	EndDate       *string  `json:"end_date,omitempty"`                              // YYYY-MM-DD, inclusive; recurring schedules only
+	Weekdays      []string `json:"weekdays,omitempty"`                              // e.g. ["Mon", "Wed", "Fri"]; omitted for a one-off schedule
+	IntervalWeeks int      `json:"interval_weeks" binding:"omitempty,min=1,max=52"` // Repeat every N weeks; defaults to 1
+	Notes         *string  `json:"notes,omitempty"`
+	Version       int      `json:"version"` // Expected version when updating; 0 (If-Match: *) skips the check
+}
+
+// parseWeekday returns the weekday of a name like "Mon" or "monday"
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if len(name) < 3 {
+		return 0, false
+	}
+	for i, weekday := range weekdayNames {
+		full := strings.ToLower(time.Weekday((i + 1) % 7).String())
+		if name == strings.ToLower(weekday) || name == full {
+			return time.Weekday((i + 1) % 7), true
+		}
+	}
+	return 0, false
+}
+
+// toSchedule validates the input and normalizes its weekdays to calendar order without duplicates
+func (input ScheduleInput) toSchedule() (entities.WorkoutSchedule, error) {
+	start, err := time.Parse(dateLayout, input.StartDate)
+	if err != nil {
+		return entities.WorkoutSchedule{}, apperrors.Invalid("invalid_date", "Invalid start date, expected YYYY-MM-DD").WithField("start_date", "must be a date like 2006-01-02")
+	}
+
+	days := make(map[time.Weekday]bool)
+	for _, name := range input.Weekdays {
+		weekday, ok := parseWeekday(name)
+		if !ok {
+			return entities.WorkoutSchedule{}, apperrors.Invalid("invalid_weekday", "Invalid weekday %q", name).WithField("weekdays", "must only contain Mon, Tue, Wed, Thu, Fri, Sat or Sun")
+		}
+		days[weekday] = true
+	}
+	var weekdays []string
+	for i, name := range weekdayNames {
+		if days[time.Weekday((i+1)%7)] {
+			weekdays = append(weekdays, name)
+		}
+	}
+
+	if input.EndDate != nil {
+		if len(weekdays) == 0 {
+			return entities.WorkoutSchedule{}, apperrors.Invalid("invalid_schedule", "An end date requires weekdays to repeat on").WithField("end_date", "is only allowed with weekdays")
+		}
+		end, err := time.Parse(dateLayout, *input.EndDate)
+		if err != nil {
+			return entities.WorkoutSchedule{}, apperrors.Invalid("invalid_date", "Invalid end date, expected YYYY-MM-DD").WithField("end_date", "must be a date like 2006-01-02")
+		}
+		if end.Before(start) {
+			return entities.WorkoutSchedule{}, apperrors.Invalid("invalid_date_range", "invalid date range: end date must not be before start date").WithField("end_date", "must not be before start_date")
+		}
+	}
+
+	interval := input.IntervalWeeks
+	if interval == 0 {
+		interval = 1
+	}
+
+	return entities.WorkoutSchedule{
+		WorkoutID:     input.WorkoutID,
+		StartDate:     input.StartDate,
+		EndDate:       input.EndDate,
+		Weekdays:      weekdays,
+		IntervalWeeks: interval,
+		Notes:         input.Notes,
+	}, nil
+}
+
+// CreateSchedule plans a workout on a date or on recurring weekdays
+func (s *ScheduleService) CreateSchedule(ctx context.Context, userID int, input ScheduleInput) (int64, error) {
+	log.Printf("Service: scheduling workout %d for user %d", input.WorkoutID, userID)
+
+	schedule, err := input.toSchedule()
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := s.scheduleRepo.Create(ctx, userID, schedule)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create schedule: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateSchedule replaces the plan of a workout schedule
+func (s *ScheduleService) UpdateSchedule(ctx context.Context, id int, input ScheduleInput) error {
+	schedule, err := input.toSchedule()
+	if err != nil {
+		return err
+	}
+
+	if err := s.scheduleRepo.Update(ctx, id, input.Version, schedule); err != nil {
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSchedule deletes a workout schedule
+func (s *ScheduleService) DeleteSchedule(ctx context.Context, id int) error {
+	if err := s.scheduleRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+
+	return nil
+}
+
+// scheduleRule is a workout schedule with its dates parsed for expansion
+type scheduleRule struct {
+	start    time.Time
+	end      *time.Time
+	weekdays map[time.Weekday]bool
+	interval int
+}
+
+// newScheduleRule parses the dates of a stored workout schedule
+func newScheduleRule(schedule entities.WorkoutSchedule) scheduleRule {
+	rule := scheduleRule{weekdays: make(map[time.Weekday]bool), interval: schedule.IntervalWeeks}
+	rule.start, _ = time.Parse(dateLayout, schedule.StartDate)
+	if schedule.EndDate != nil {
+		end, _ := time.Parse(dateLayout, *schedule.EndDate)
+		rule.end = &end
+	}
+	for _, name := range schedule.Weekdays {
+		if weekday, ok := parseWeekday(name); ok {
+			rule.weekdays[weekday] = true
+		}
+	}
+	if rule.interval < 1 {
+		rule.interval = 1
+	}
+	return rule
+}
+
+// weekStart returns the Monday of the week of a date
+func weekStart(date time.Time) time.Time {
+	return date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+}
+
+// occursOn reports whether the rule plans its workout on a date. Recurring rules repeat on
+// their weekdays every interval weeks, counted from the week of the start date.
+func (rule scheduleRule) occursOn(date time.Time) bool {
+	if date.Before(rule.start) || (rule.end != nil && date.After(*rule.end)) {
+		return false
+	}
+	if len(rule.weekdays) == 0 {
+		return date.Equal(rule.start)
+	}
+	if !rule.weekdays[date.Weekday()] {
+		return false
+	}
+	weeks := int(weekStart(date).Sub(weekStart(rule.start)).Hours()/24) / 7
+	return weeks%rule.interval == 0
+}
+
+// firstOccurrence returns the first date the rule plans its workout on, if any
+func (rule scheduleRule) firstOccurrence() (time.Time, bool) {
+	for date := rule.start; date.Before(rule.start.AddDate(0, 0, 7*rule.interval)); date = date.AddDate(0, 0, 1) {
+		if rule.occursOn(date) {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// CalendarSummary counts the entries of a calendar by status
+type CalendarSummary struct {
+	Planned   int `json:"planned"`
+	Completed int `json:"completed"`
+	Missed    int `json:"missed"`
+	Unplanned int `json:"unplanned"`
+}
+
+// add counts an entry
+func (summary *CalendarSummary) add(status entities.CalendarEntryStatus) {
+	switch status {
+	case entities.CalendarEntryPlanned:
+		summary.Planned++
+	case entities.CalendarEntryCompleted:
+		summary.Completed++
+	case entities.CalendarEntryMissed:
+		summary.Missed++
+	case entities.CalendarEntryUnplanned:
+		summary.Unplanned++
+	}
+}
+
+// Calendar represents the planned and performed workouts of a user per day
+type Calendar struct {
+	From    string                 `json:"from"` // YYYY-MM-DD
+	To      string                 `json:"to"`   // YYYY-MM-DD, inclusive
+	Days    []entities.CalendarDay `json:"days"`
+	Summary CalendarSummary        `json:"summary"`
+}
+
+// GetCalendar lays out a user's schedules on every day in [from, to) and compares them with the
+// finished sessions started on the same (UTC) day. A planned workout is completed by a finished
+// session of the same workout; sessions that complete no plan are listed as unplanned.
+func (s *ScheduleService) GetCalendar(ctx context.Context, userID int, from time.Time, to time.Time) (*Calendar, error) {
+	if !from.Before(to) {
+		return nil, apperrors.Invalid("invalid_date_range", "invalid date range: from must be before to")
+	}
+	if to.Sub(from) > MaxCalendarDays*24*time.Hour {
+		return nil, apperrors.Invalid("invalid_date_range", "invalid date range: at most %d days can be requested", MaxCalendarDays)
+	}
+
+	last := to.AddDate(0, 0, -1)
+	schedules, err := s.scheduleRepo.GetOverlapping(ctx, userID, from.Format(dateLayout), last.Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.sessionRepo.GetFinishedInRange(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]scheduleRule, len(schedules))
+	for i, schedule := range schedules {
+		rules[i] = newScheduleRule(schedule)
+	}
+	sessionsByDate := make(map[string][]entities.WorkoutSession)
+	for _, session := range sessions {
+		date := session.StartedWhen.Format(dateLayout)
+		sessionsByDate[date] = append(sessionsByDate[date], session)
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	calendar := &Calendar{From: from.Format(dateLayout), To: last.Format(dateLayout), Days: []entities.CalendarDay{}}
+	for date := from; date.Before(to); date = date.AddDate(0, 0, 1) {
+		day := entities.CalendarDay{Date: date.Format(dateLayout), Entries: []entities.CalendarEntry{}}
+		daySessions := sessionsByDate[day.Date]
+		used := make([]bool, len(daySessions))
+
+		for i := range schedules {
+			if !rules[i].occursOn(date) {
+				continue
+			}
+			entry := entities.CalendarEntry{
+				Status:     entities.CalendarEntryPlanned,
+				ScheduleID: &schedules[i].ID,
+				WorkoutID:  &schedules[i].WorkoutID,
+				Name:       schedules[i].WorkoutName,
+			}
+			for j, session := range daySessions {
+				if !used[j] && session.WorkoutID != nil && *session.WorkoutID == schedules[i].WorkoutID {
+					used[j] = true
+					entry.Status = entities.CalendarEntryCompleted
+					entry.SessionID = &daySessions[j].ID
+					break
+				}
+			}
+			if entry.Status == entities.CalendarEntryPlanned && date.Before(today) {
+				entry.Status = entities.CalendarEntryMissed
+			}
+			day.Entries = append(day.Entries, entry)
+		}
+
+		for j := range daySessions {
+			if used[j] {
+				continue
+			}
+			day.Entries = append(day.Entries, entities.CalendarEntry{
+				Status:    entities.CalendarEntryUnplanned,
+				WorkoutID: daySessions[j].WorkoutID,
+				Name:      daySessions[j].Name,
+				SessionID: &daySessions[j].ID,
+			})
+		}
+
+		for _, entry := range day.Entries {
+			calendar.Summary.add(entry.Status)
+		}
+		calendar.Days = append(calendar.Days, day)
+	}
+
+	return calendar, nil
+}
+
+// hashFeedToken returns the hash under which a feed token is stored
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RotateCalendarFeed issues a new secret token for the user's iCalendar feed, revoking the
+// previous one. Only a hash of the token is stored, so it cannot be shown again.
+func (s *ScheduleService) RotateCalendarFeed(ctx context.Context, userID int) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate calendar feed token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	if err := s.calendarFeedRepo.Save(ctx, userID, hashFeedToken(token)); err != nil {
+		return "", fmt.Errorf("failed to save calendar feed token: %w", err)
+	}
+
+	return token, nil
+}
+
+// RevokeCalendarFeed deletes the user's iCalendar feed token
+func (s *ScheduleService) RevokeCalendarFeed(ctx context.Context, userID int) error {
+	deleted, err := s.calendarFeedRepo.DeleteForUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke calendar feed: %w", err)
+	}
+	if !deleted {
+		return ErrCalendarFeedNotFound
+	}
+	return nil
+}
+
+// GetCalendarFeed renders the schedules of the user owning a feed token as an iCalendar document
+func (s *ScheduleService) GetCalendarFeed(ctx context.Context, token string) (string, error) {
+	userID, err := s.calendarFeedRepo.GetUserIDByTokenHash(ctx, hashFeedToken(token))
+	if err != nil {
+		return "", notFound(err, ErrCalendarFeedNotFound)
+	}
+
+	schedules, err := s.scheduleRepo.GetAllForUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	return renderICalendar(schedules, time.Now()), nil
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"goliath/apperrors"
+	"goliath/entities"
+	"goliath/repositories"
+)
+
+// SessionService handles business logic for performed workout sessions. It does not check who may
+// access a session: callers authorize the user with the authorization package first, which also
+// verifies that sets belong to the session of the URL.
+type SessionService struct {
+	sessionRepo         *repositories.SessionRepository
+	sessionSetRepo      *repositories.SessionSetRepository
+	workoutRepo         *repositories.WorkoutRepository
+	workoutExerciseRepo *repositories.WorkoutExerciseRepository
+	exerciseRepo        *repositories.ExerciseRepository
+	recordService       *PersonalRecordService
+}
+
+// NewSessionService creates a new SessionService
+func NewSessionService(
+	sessionRepo *repositories.SessionRepository,
+	sessionSetRepo *repositories.SessionSetRepository,
+	workoutRepo *repositories.WorkoutRepository,
+	workoutExerciseRepo *repositories.WorkoutExerciseRepository,
+	exerciseRepo *repositories.ExerciseRepository,
+	recordService *PersonalRecordService,
+) *SessionService {
+	return &SessionService{
+		sessionRepo:         sessionRepo,
+		sessionSetRepo:      sessionSetRepo,
+		workoutRepo:         workoutRepo,
+		workoutExerciseRepo: workoutExerciseRepo,
+		exerciseRepo:        exerciseRepo,
+		recordService:       recordService,
+	}
+}
+
+// GetUserSessions retrieves one page of sessions for a user without their sets
+func (s *SessionService) GetUserSessions(ctx context.Context, userID int, params repositories.ListParams) ([]entities.WorkoutSession, string, error) {
+	return s.sessionRepo.ListForUser(ctx, userID, params)
+}
+
+// getSession loads a session
+func (s *SessionService) getSession(ctx context.Context, id int) (*entities.WorkoutSession, error) {
+	session, err := s.sessionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrSessionNotFound)
+	}
+	return session, nil
+}
+
+// getOpenSession loads a session that is not finished yet. The sets of finished sessions are
+// history that personal records and analytics are computed from, so they cannot be changed.
+func (s *SessionService) getOpenSession(ctx context.Context, id int) (*entities.WorkoutSession, error) {
+	session, err := s.getSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.FinishedWhen != nil {
+		return nil, ErrSessionFinished
+	}
+	return session, nil
+}
+
+// GetSessionByID retrieves a session with its performed sets
+func (s *SessionService) GetSessionByID(ctx context.Context, id int) (*entities.WorkoutSession, error) {
+	session, err := s.getSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sets, err := s.sessionSetRepo.GetAllForSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	session.Sets = sets
+
+	return session, nil
+}
+
+// StartSessionInput represents input for starting a workout session
+type StartSessionInput struct {
+	WorkoutID   *int       `json:"workout_id,omitempty"`
+	Name        string     `json:"name,omitempty"`
+	StartedWhen *time.Time `json:"started_when,omitempty"` // Defaults to now; set to log a past workout
+	Notes       *string    `json:"notes,omitempty"`
+}
+
+// StartSession starts a new session, optionally from a workout
+func (s *SessionService) StartSession(ctx context.Context, userID int, input StartSessionInput) (int64, error) {
+	name := input.Name
+	if input.WorkoutID != nil {
+		// Handlers authorize access to the workout
+		workout, err := s.workoutRepo.GetByID(ctx, *input.WorkoutID)
+		if err != nil {
+			return 0, notFound(err, ErrWorkoutNotFound)
+		}
+		if name == "" {
+			name = workout.Name
+		}
+	}
+	if name == "" {
+		return 0, apperrors.Invalid("name_required", "name is required when no workout is given").WithField("name", "is required when no workout is given")
+	}
+
+	startedWhen := time.Now().UTC()
+	if input.StartedWhen != nil {
+		startedWhen = input.StartedWhen.UTC()
+	}
+
+	log.Printf("Service: starting session %s for user %d", name, userID)
+	id, err := s.sessionRepo.Create(ctx, userID, input.WorkoutID, name, startedWhen, input.Notes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start session: %w", err)
+	}
+
+	return id, nil
+}
+
+// FinishSessionInput represents input for finishing a workout session
+type FinishSessionInput struct {
+	FinishedWhen *time.Time `json:"finished_when,omitempty"` // Defaults to now
+	Notes        *string    `json:"notes,omitempty"`
+}
+
+// FinishSession records the end time of a session
+func (s *SessionService) FinishSession(ctx context.Context, id int, input FinishSessionInput) error {
+	session, err := s.getOpenSession(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	finishedWhen := time.Now().UTC()
+	if input.FinishedWhen != nil {
+		finishedWhen = input.FinishedWhen.UTC()
+	}
+	if finishedWhen.Before(session.StartedWhen) {
+		return apperrors.Invalid("invalid_finished_when", "session cannot finish before it started").WithField("finished_when", "must not be before started_when")
+	}
+
+	if err := s.sessionRepo.Finish(ctx, id, finishedWhen, input.Notes); err != nil {
+		return fmt.Errorf("failed to finish session: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSession deletes a session and recomputes the affected personal records of its owner
+func (s *SessionService) DeleteSession(ctx context.Context, id int) error {
+	session, err := s.getSession(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	sets, err := s.sessionSetRepo.GetAllForSession(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.sessionRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	refreshed := make(map[int]bool)
+	for _, set := range sets {
+		if refreshed[set.ExerciseID] {
+			continue
+		}
+		refreshed[set.ExerciseID] = true
+		if _, err := s.recordService.RefreshRecords(ctx, session.UserID, set.ExerciseID, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RecordSetInput represents input for recording a performed set
+type RecordSetInput struct {
+	WorkoutExerciseID *int     `json:"workout_exercise_id,omitempty"`
+	ExerciseID        int      `json:"exercise_id"` // Optional when workout_exercise_id is given
+	SetNumber         int      `json:"set_number"`  // Defaults to the next set of the exercise
+	Reps              *int     `json:"reps,omitempty"`
+	TimeSeconds       *int     `json:"time_seconds,omitempty"`
+	Weight            *float64 `json:"weight,omitempty"`
+	Notes             *string  `json:"notes,omitempty"`
+}
+
+// RecordSet records a performed set in an unfinished session and returns the personal records
+// the set achieved
+func (s *SessionService) RecordSet(ctx context.Context, sessionID int, input RecordSetInput) (int64, []entities.PersonalRecord, error) {
+	session, err := s.getOpenSession(ctx, sessionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	exerciseID := input.ExerciseID
+	if input.WorkoutExerciseID != nil {
+		// The planned exercise must come from the workout the session was started from
+		invalidWorkoutExercise := apperrors.Invalid("invalid_workout_exercise", "workout exercise does not belong to session workout").WithField("workout_exercise_id", "must belong to the workout of the session")
+		workoutExercise, err := s.workoutExerciseRepo.GetByID(ctx, *input.WorkoutExerciseID)
+		if err != nil {
+			return 0, nil, notFound(err, invalidWorkoutExercise)
+		}
+		if session.WorkoutID == nil || workoutExercise.WorkoutID != *session.WorkoutID {
+			return 0, nil, invalidWorkoutExercise
+		}
+		if exerciseID == 0 {
+			exerciseID = workoutExercise.ExerciseID
+		}
+		if exerciseID != workoutExercise.ExerciseID {
+			return 0, nil, apperrors.Invalid("exercise_mismatch", "exercise does not match workout exercise").WithField("exercise_id", "must match the exercise of the workout exercise")
+		}
+	}
+	if exerciseID == 0 {
+		return 0, nil, apperrors.Invalid("exercise_required", "exercise_id or workout_exercise_id is required").WithField("exercise_id", "is required without workout_exercise_id")
+	}
+	if _, err := s.exerciseRepo.GetByID(ctx, exerciseID); err != nil {
+		return 0, nil, notFound(err, apperrors.Invalid("unknown_exercise", "exercise %d not found", exerciseID).WithField("exercise_id", "no exercise with this ID"))
+	}
+
+	setNumber := input.SetNumber
+	if setNumber <= 0 {
+		sets, err := s.sessionSetRepo.GetAllForSession(ctx, sessionID)
+		if err != nil {
+			return 0, nil, err
+		}
+		setNumber = 1
+		for _, set := range sets {
+			if set.ExerciseID == exerciseID && set.SetNumber >= setNumber {
+				setNumber = set.SetNumber + 1
+			}
+		}
+	}
+
+	id, err := s.sessionSetRepo.Create(ctx, sessionID, input.WorkoutExerciseID, exerciseID, setNumber, input.Reps, input.TimeSeconds, input.Weight, input.Notes)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to record set: %w", err)
+	}
+
+	setID := int(id)
+	newRecords, err := s.recordService.RefreshRecords(ctx, session.UserID, exerciseID, &setID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return id, newRecords, nil
+}
+
+// UpdateSetInput represents input for correcting a performed set
+type UpdateSetInput struct {
+	SetNumber   int      `json:"set_number" binding:"min=1"`
+	Reps        *int     `json:"reps,omitempty"`
+	TimeSeconds *int     `json:"time_seconds,omitempty"`
+	Weight      *float64 `json:"weight,omitempty"`
+	Notes       *string  `json:"notes,omitempty"`
+	Version     int      `json:"version"` // Expected version; 0 (If-Match: *) skips the check
+}
+
+// getOpenSet loads a set of an unfinished session with the session
+func (s *SessionService) getOpenSet(ctx context.Context, sessionID int, setID int) (*entities.WorkoutSession, *entities.WorkoutSessionSet, error) {
+	session, err := s.getOpenSession(ctx, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	set, err := s.sessionSetRepo.GetByID(ctx, setID)
+	if err != nil {
+		return nil, nil, notFound(err, ErrSetNotFound)
+	}
+	return session, set, nil
+}
+
+// UpdateSet updates a performed set of an unfinished session and returns the personal records
+// the corrected set achieved
+func (s *SessionService) UpdateSet(ctx context.Context, sessionID int, setID int, input UpdateSetInput) ([]entities.PersonalRecord, error) {
+	session, set, err := s.getOpenSet(ctx, sessionID, setID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionSetRepo.Update(ctx, setID, input.Version, input.SetNumber, input.Reps, input.TimeSeconds, input.Weight, input.Notes); err != nil {
+		return nil, fmt.Errorf("failed to update set: %w", err)
+	}
+
+	return s.recordService.RefreshRecords(ctx, session.UserID, set.ExerciseID, &setID)
+}
+
+// DeleteSet deletes a performed set of an unfinished session and recomputes personal records
+func (s *SessionService) DeleteSet(ctx context.Context, sessionID int, setID int) error {
+	session, set, err := s.getOpenSet(ctx, sessionID, setID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.sessionSetRepo.Delete(ctx, setID); err != nil {
+		return fmt.Errorf("failed to delete set: %w", err)
+	}
+
+	_, err = s.recordService.RefreshRecords(ctx, session.UserID, set.ExerciseID, nil)
+	return err
+}
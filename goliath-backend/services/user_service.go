@@ -2,26 +2,117 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // Time zones are validated the same on hosts without a zoneinfo database
+	"unicode/utf8"
 
+	"goliath/apperrors"
 	"goliath/entities"
 	"goliath/repositories"
 )
 
+// maxDisplayNameLength is the longest display name accepted, in characters
+const maxDisplayNameLength = 100
+
+// ProfileInput represents input for updating the profile of the current user
+type ProfileInput struct {
+	DisplayName *string `json:"display_name"`
+	Units       string  `json:"units"`         // Metric or Imperial; defaults to Metric
+	TimeZone    string  `json:"time_zone"`     // IANA name, e.g. Europe/Berlin; defaults to UTC
+	DateOfBirth *string `json:"date_of_birth"` // YYYY-MM-DD
+	Sex         *string `json:"sex"`           // Male or Female
+	Version     int     `json:"version"`       // Expected version when updating; 0 (If-Match: *) skips the check
+}
+
+// toProfile validates the input and normalizes its blank fields to their defaults
+func (input ProfileInput) toProfile(today time.Time) (entities.UserProfile, error) {
+	profile := entities.UserProfile{Units: entities.UnitsMetric, TimeZone: "UTC"}
+
+	if input.DisplayName != nil {
+		if name := strings.TrimSpace(*input.DisplayName); name != "" {
+			if utf8.RuneCountInString(name) > maxDisplayNameLength {
+				return profile, apperrors.Invalid("invalid_profile", "Display name is too long").WithField("display_name", "must be at most %d characters", maxDisplayNameLength)
+			}
+			profile.DisplayName = &name
+		}
+	}
+
+	switch units := entities.Units(input.Units); units {
+	case "":
+	case entities.UnitsMetric, entities.UnitsImperial:
+		profile.Units = units
+	default:
+		return profile, apperrors.Invalid("invalid_profile", "Invalid units %q", input.Units).WithField("units", "must be Metric or Imperial")
+	}
+
+	if input.TimeZone != "" {
+		if _, err := time.LoadLocation(input.TimeZone); err != nil || input.TimeZone == "Local" {
+			return profile, apperrors.Invalid("invalid_profile", "Unknown time zone %q", input.TimeZone).WithField("time_zone", "must be an IANA time zone like Europe/Berlin")
+		}
+		profile.TimeZone = input.TimeZone
+	}
+
+	if input.DateOfBirth != nil && *input.DateOfBirth != "" {
+		born, err := time.Parse(dateLayout, *input.DateOfBirth)
+		if err != nil {
+			return profile, apperrors.Invalid("invalid_date", "Invalid date of birth, expected YYYY-MM-DD").WithField("date_of_birth", "must be a date like 2006-01-02")
+		}
+		if born.After(today) || born.Year() < 1900 {
+			return profile, apperrors.Invalid("invalid_date", "Invalid date of birth").WithField("date_of_birth", "must be between 1900-01-01 and today")
+		}
+		profile.DateOfBirth = input.DateOfBirth
+	}
+
+	if input.Sex != nil && *input.Sex != "" {
+		sex := entities.Sex(*input.Sex)
+		if sex != entities.SexMale && sex != entities.SexFemale {
+			return profile, apperrors.Invalid("invalid_profile", "Invalid sex %q", *input.Sex).WithField("sex", "must be Male or Female")
+		}
+		profile.Sex = &sex
+	}
+
+	return profile, nil
+}
+
+// UserRoleInput represents input for changing the role of a user
+type UserRoleInput struct {
+	Role    string `json:"role" binding:"required"` // Name of a role, e.g. USER or CATALOG_EDITOR
+	Version int    `json:"version"`                 // Expected version when updating; 0 (If-Match: *) skips the check
+}
+
+// StatusInput represents input for enabling, disabling or banning a user
+type StatusInput struct {
+	Status  string  `json:"status" binding:"required"` // Active, Disabled or Banned
+	Reason  *string `json:"reason"`                    // Shown to admins only; cleared when the user is reactivated
+	Version int     `json:"version"`                   // Expected version when updating; 0 (If-Match: *) skips the check
+}
+
+// Errors for users managing other users. As nobody can change their own account, and only
+// holders of every permission can change an admin, at least one active admin always remains.
+var (
+	ErrCannotModifySelf = apperrors.Forbidden("cannot_modify_self", "cannot change your own role or status")
+	ErrUserOutranks     = apperrors.Forbidden("insufficient_permissions", "cannot change a user whose role has permissions you do not hold")
+)
+
 // UserService handles business logic for user-related operations
 type UserService struct {
 	userRepo *repositories.UserRepository
+	roleRepo *repositories.RoleRepository
 }
 
 // NewUserService creates a new UserService
-func NewUserService(userRepo *repositories.UserRepository) *UserService {
+func NewUserService(userRepo *repositories.UserRepository, roleRepo *repositories.RoleRepository) *UserService {
 	return &UserService{
 		userRepo: userRepo,
+		roleRepo: roleRepo,
 	}
 }
 
-// GetAllUsers retrieves all users
-func (s *UserService) GetAllUsers(ctx context.Context) ([]entities.User, error) {
-	return s.userRepo.GetAll(ctx)
+// GetAllUsers retrieves one page of users
+func (s *UserService) GetAllUsers(ctx context.Context, params repositories.ListParams) ([]entities.User, string, error) {
+	return s.userRepo.List(ctx, params)
 }
 
 // GetUserByID retrieves a user by ID
@@ -39,3 +130,123 @@ func (s *UserService) GetUserByFirebaseUID(ctx context.Context, firebaseUID stri
 	return s.userRepo.GetByFirebaseUID(ctx, firebaseUID)
 }
 
+// GetProfile retrieves the current user with their profile and permissions
+func (s *UserService) GetProfile(ctx context.Context, userID int) (*entities.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	role, err := s.roleRepo.GetByName(ctx, user.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	if role != nil {
+		user.Permissions = role.Permissions
+	}
+	return user, nil
+}
+
+// UpdateProfile replaces the profile of the current user
+func (s *UserService) UpdateProfile(ctx context.Context, userID int, input ProfileInput) error {
+	profile, err := input.toProfile(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdateProfile(ctx, userID, input.Version, profile); err != nil {
+		return fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	return nil
+}
+
+// GetUser retrieves any user, for admins
+func (s *UserService) GetUser(ctx context.Context, id int) (*entities.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// getOtherUser retrieves the user the actor is about to change. Actors cannot change themselves,
+// so they cannot lock themselves out, nor users whose role has permissions they do not hold.
+func (s *UserService) getOtherUser(ctx context.Context, actor *entities.User, id int) (*entities.User, error) {
+	if id == actor.ID {
+		return nil, ErrCannotModifySelf
+	}
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.roleRepo.GetByName(ctx, user.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	if role != nil {
+		for _, permission := range role.Permissions {
+			if !actor.HasPermission(permission) {
+				return nil, ErrUserOutranks
+			}
+		}
+	}
+	return user, nil
+}
+
+// ChangeRole assigns a role to a user. Actors can only assign roles whose permissions they hold.
+func (s *UserService) ChangeRole(ctx context.Context, actor *entities.User, id int, input UserRoleInput) error {
+	role, err := s.roleRepo.GetByName(ctx, input.Role)
+	if err != nil {
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+	if role == nil {
+		return apperrors.Invalid("invalid_role", "Unknown role %q", input.Role).WithField("role", "must be a role listed by GET /roles")
+	}
+	if err := ensureGrantable(actor, role.Permissions); err != nil {
+		return err
+	}
+
+	if _, err := s.getOtherUser(ctx, actor, id); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdateRole(ctx, id, input.Version, role.Name); err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+	return nil
+}
+
+// ChangeStatus enables, disables or bans a user. Disabled and banned users are refused on
+// their next request.
+func (s *UserService) ChangeStatus(ctx context.Context, actor *entities.User, id int, input StatusInput) error {
+	status := entities.UserStatus(input.Status)
+	switch status {
+	case entities.UserStatusActive, entities.UserStatusDisabled, entities.UserStatusBanned:
+	default:
+		return apperrors.Invalid("invalid_status", "Invalid status %q", input.Status).WithField("status", "must be Active, Disabled or Banned")
+	}
+
+	if _, err := s.getOtherUser(ctx, actor, id); err != nil {
+		return err
+	}
+
+	var reason *string
+	if status != entities.UserStatusActive && input.Reason != nil {
+		if trimmed := strings.TrimSpace(*input.Reason); trimmed != "" {
+			reason = &trimmed
+		}
+	}
+
+	if err := s.userRepo.UpdateStatus(ctx, id, input.Version, status, reason); err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+	return nil
+}
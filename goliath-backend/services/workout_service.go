@@ -4,52 +4,77 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 
+	"goliath/apperrors"
 	"goliath/entities"
 	"goliath/repositories"
 )
 
-// WorkoutService handles business logic for workout-related operations
+// Errors of the workout service
+var (
+	ErrExerciseArchived = apperrors.Conflict("exercise_archived", "exercise is archived")
+	ErrTemplateNotFound = apperrors.NotFound("template_not_found", "template not found")
+	ErrSetsDefined      = apperrors.Conflict("sets_defined", "sets, reps, time and weight are summarized from the individual sets of the workout exercise")
+)
+
+// WorkoutService handles business logic for workout-related operations. It does not check who
+// may access a workout: callers authorize the user with the authorization package first, which
+// also verifies that exercises and sets belong to the workout of the URL.
 type WorkoutService struct {
-	workoutRepo         *repositories.WorkoutRepository
-	workoutExerciseRepo *repositories.WorkoutExerciseRepository
+	workoutRepo            *repositories.WorkoutRepository
+	workoutExerciseRepo    *repositories.WorkoutExerciseRepository
+	workoutExerciseSetRepo *repositories.WorkoutExerciseSetRepository
+	exerciseRepo           *repositories.ExerciseRepository
+	workoutChangeRepo      *repositories.WorkoutChangeRepository
 }
 
 // NewWorkoutService creates a new WorkoutService
-func NewWorkoutService(workoutRepo *repositories.WorkoutRepository, workoutExerciseRepo *repositories.WorkoutExerciseRepository) *WorkoutService {
+func NewWorkoutService(workoutRepo *repositories.WorkoutRepository, workoutExerciseRepo *repositories.WorkoutExerciseRepository, workoutExerciseSetRepo *repositories.WorkoutExerciseSetRepository, exerciseRepo *repositories.ExerciseRepository, workoutChangeRepo *repositories.WorkoutChangeRepository) *WorkoutService {
 	return &WorkoutService{
-		workoutRepo:         workoutRepo,
-		workoutExerciseRepo: workoutExerciseRepo,
+		workoutRepo:            workoutRepo,
+		workoutExerciseRepo:    workoutExerciseRepo,
+		workoutExerciseSetRepo: workoutExerciseSetRepo,
+		exerciseRepo:           exerciseRepo,
+		workoutChangeRepo:      workoutChangeRepo,
 	}
 }
 
-// GetUserWorkouts retrieves all workouts for a user
-func (s *WorkoutService) GetUserWorkouts(ctx context.Context, userID int) ([]entities.Workout, error) {
-	workouts, err := s.workoutRepo.GetAllForUser(ctx, userID)
-	if err != nil {
-		return nil, err
+// recordChange adds a change to the audit trail of a workout
+func (s *WorkoutService) recordChange(ctx context.Context, workoutID int, entityType entities.WorkoutChangeEntity, entityID int64, action entities.WorkoutChangeAction) error {
+	if err := s.workoutChangeRepo.Record(ctx, workoutID, entityType, int(entityID), action); err != nil {
+		return fmt.Errorf("failed to record workout change: %w", err)
 	}
-	return workouts, nil
+	return nil
 }
 
-// GetWorkoutByID retrieves a single workout and verifies ownership
-func (s *WorkoutService) GetWorkoutByID(ctx context.Context, id int, userID int) (*entities.Workout, error) {
-	workout, err := s.workoutRepo.GetByID(ctx, id)
+// GetWorkoutChanges retrieves one page of the audit trail of a workout
+func (s *WorkoutService) GetWorkoutChanges(ctx context.Context, id int, params repositories.ListParams) ([]entities.WorkoutChange, string, error) {
+	return s.workoutChangeRepo.ListForWorkout(ctx, id, params)
+}
+
+// GetUserWorkouts retrieves one page of workouts for a user
+func (s *WorkoutService) GetUserWorkouts(ctx context.Context, userID int, params repositories.ListParams) ([]entities.Workout, string, error) {
+	workouts, nextCursor, err := s.workoutRepo.ListForUser(ctx, userID, params)
 	if err != nil {
-		return nil, fmt.Errorf("workout not found: %w", err)
+		return nil, "", err
 	}
+	return workouts, nextCursor, nil
+}
 
-	// Verify the workout belongs to the user
-	if workout.UserID != userID {
-		return nil, fmt.Errorf("unauthorized: workout does not belong to user")
+// GetWorkoutByID retrieves a single workout
+func (s *WorkoutService) GetWorkoutByID(ctx context.Context, id int) (*entities.Workout, error) {
+	workout, err := s.workoutRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrWorkoutNotFound)
 	}
-
 	return workout, nil
 }
 
 // CreateWorkoutInput represents input for creating a workout
 type CreateWorkoutInput struct {
-	Name string `json:"name" binding:"required,min=1"`
+	Name      string `json:"name" binding:"required,min=1"`
+	Shareable bool   `json:"shareable"` // Share the workout in the template library
 }
 
 // CreateWorkout creates a new workout for a user
@@ -57,76 +82,230 @@ func (s *WorkoutService) CreateWorkout(ctx context.Context, userID int, input Cr
 	log.Printf("Service: creating workout %s for user %d", input.Name, userID)
 	
 	// Create workout
-	workoutID, err := s.workoutRepo.Create(ctx, input.Name, userID)
+	workoutID, err := s.workoutRepo.Create(ctx, input.Name, userID, input.Shareable)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create workout: %w", err)
 	}
+	if err := s.recordChange(ctx, int(workoutID), entities.WorkoutChangeEntityWorkout, workoutID, entities.WorkoutChangeCreated); err != nil {
+		return 0, err
+	}
 
 	return workoutID, nil
 }
 
 // UpdateWorkoutInput represents input for updating a workout
 type UpdateWorkoutInput struct {
-	Name string `json:"name" binding:"required,min=1"`
+	Name      string `json:"name" binding:"required,min=1"`
+	Shareable *bool  `json:"shareable,omitempty"` // Unchanged when omitted
+	Version   int    `json:"version"`             // Expected version; 0 (If-Match: *) skips the check
 }
 
-// UpdateWorkout updates an existing workout with ownership verification
-func (s *WorkoutService) UpdateWorkout(ctx context.Context, id int, userID int, input UpdateWorkoutInput) error {
-	log.Printf("Service: updating workout %d for user %d", id, userID)
+// UpdateWorkout updates an existing workout
+func (s *WorkoutService) UpdateWorkout(ctx context.Context, id int, input UpdateWorkoutInput) error {
+	log.Printf("Service: updating workout %d", id)
 	
-	// Check if workout exists and belongs to user
-	workout, err := s.workoutRepo.GetByID(ctx, id)
+	workout, err := s.GetWorkoutByID(ctx, id)
 	if err != nil {
-		return fmt.Errorf("workout not found: %w", err)
+		return err
 	}
 
-	// Verify the workout belongs to the user
-	if workout.UserID != userID {
-		return fmt.Errorf("unauthorized: workout does not belong to user")
+	shareable := workout.Shareable
+	if input.Shareable != nil {
+		shareable = *input.Shareable
 	}
 
 	// Update workout
-	err = s.workoutRepo.Update(ctx, id, input.Name)
+	err = s.workoutRepo.Update(ctx, id, input.Version, input.Name, shareable)
 	if err != nil {
 		return fmt.Errorf("failed to update workout: %w", err)
 	}
 
+	return s.recordChange(ctx, id, entities.WorkoutChangeEntityWorkout, int64(id), entities.WorkoutChangeUpdated)
+}
+
+// DeleteWorkout deletes a workout
+func (s *WorkoutService) DeleteWorkout(ctx context.Context, id int) error {
+	// Delete workout
+	err := s.workoutRepo.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete workout: %w", err)
+	}
+
 	return nil
 }
 
-// DeleteWorkout deletes a workout with ownership verification
-func (s *WorkoutService) DeleteWorkout(ctx context.Context, id int, userID int) error {
-	// Check if workout exists and belongs to user
+// CloneWorkoutInput represents input for duplicating a workout or cloning a template
+type CloneWorkoutInput struct {
+	Name string `json:"name"` // Defaults to the name of the source workout
+}
+
+// DuplicateWorkout copies a workout with all its exercises and sets. The copy belongs to the owner
+// of the workout, also when a coach duplicates it.
+func (s *WorkoutService) DuplicateWorkout(ctx context.Context, id int, input CloneWorkoutInput) (int64, error) {
+	workout, err := s.GetWorkoutByID(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	name := input.Name
+	if name == "" {
+		name = workout.Name + " (copy)"
+	}
+
+	workoutID, err := s.workoutRepo.Clone(ctx, id, workout.UserID, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to duplicate workout: %w", err)
+	}
+	if err := s.recordChange(ctx, int(workoutID), entities.WorkoutChangeEntityWorkout, workoutID, entities.WorkoutChangeCreated); err != nil {
+		return 0, err
+	}
+
+	return workoutID, nil
+}
+
+// Template is a shared workout as other users see it in the template library: without its owner
+// and the user IDs of who created and modified it
+type Template struct {
+	ID      int    `json:"id"`
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+}
+
+// TemplateExercise represents a planned exercise of a template
+type TemplateExercise struct {
+	ID           int           `json:"id"`
+	ExerciseID   int           `json:"exercise_id"`
+	ExerciseName string        `json:"exercise_name"`
+	ExerciseType string        `json:"exercise_type"`
+	Position     int           `json:"position"`
+	Sets         *int          `json:"sets,omitempty"`
+	Reps         *int          `json:"reps,omitempty"`
+	TimeSeconds  *int          `json:"time_seconds,omitempty"`
+	Weight       *float64      `json:"weight,omitempty"`
+	Notes        *string       `json:"notes,omitempty"`
+	SetDetails   []TemplateSet `json:"set_details,omitempty"`
+}
+
+// TemplateSet represents a planned set of a template exercise
+type TemplateSet struct {
+	Position    int              `json:"position"`
+	SetType     entities.SetType `json:"set_type"`
+	Reps        *int             `json:"reps,omitempty"`
+	TimeSeconds *int             `json:"time_seconds,omitempty"`
+	Weight      *float64         `json:"weight,omitempty"`
+	RPE         *float64         `json:"rpe,omitempty"`
+}
+
+// newTemplate returns the template of a workout
+func newTemplate(workout entities.Workout) Template {
+	return Template{ID: workout.ID, Version: workout.Version, Name: workout.Name}
+}
+
+// GetTemplates retrieves one page of the template library
+func (s *WorkoutService) GetTemplates(ctx context.Context, params repositories.ListParams) ([]Template, string, error) {
+	workouts, nextCursor, err := s.workoutRepo.ListShareable(ctx, params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	templates := make([]Template, 0, len(workouts))
+	for _, workout := range workouts {
+		templates = append(templates, newTemplate(workout))
+	}
+	return templates, nextCursor, nil
+}
+
+// getTemplate loads a workout that may be read as a template: shared by its owner, or owned by the user
+func (s *WorkoutService) getTemplate(ctx context.Context, id int, userID int) (*entities.Workout, error) {
 	workout, err := s.workoutRepo.GetByID(ctx, id)
 	if err != nil {
-		return fmt.Errorf("workout not found: %w", err)
+		return nil, notFound(err, ErrTemplateNotFound)
 	}
 
-	// Verify the workout belongs to the user
-	if workout.UserID != userID {
-		return fmt.Errorf("unauthorized: workout does not belong to user")
+	// Private workouts of other users are not revealed
+	if !workout.Shareable && workout.UserID != userID {
+		return nil, ErrTemplateNotFound
 	}
 
-	// Delete workout
-	err = s.workoutRepo.Delete(ctx, id)
+	return workout, nil
+}
+
+// GetTemplate retrieves a single template
+func (s *WorkoutService) GetTemplate(ctx context.Context, id int, userID int) (*Template, error) {
+	workout, err := s.getTemplate(ctx, id, userID)
 	if err != nil {
-		return fmt.Errorf("failed to delete workout: %w", err)
+		return nil, err
 	}
 
-	return nil
+	template := newTemplate(*workout)
+	return &template, nil
 }
 
-// GetWorkoutExercises retrieves all exercises for a workout with ownership verification
-func (s *WorkoutService) GetWorkoutExercises(ctx context.Context, workoutID int, userID int) ([]entities.WorkoutExercise, error) {
-	// Verify workout belongs to user
-	workout, err := s.workoutRepo.GetByID(ctx, workoutID)
+// GetTemplateExercises retrieves the exercises of a template with their individual sets
+func (s *WorkoutService) GetTemplateExercises(ctx context.Context, id int, userID int) ([]TemplateExercise, error) {
+	if _, err := s.getTemplate(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	workoutExercises, err := s.workoutExerciseRepo.GetAllForWorkout(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("workout not found: %w", err)
+		return nil, err
 	}
-	if workout.UserID != userID {
-		return nil, fmt.Errorf("unauthorized: workout does not belong to user")
+
+	exercises := make([]TemplateExercise, 0, len(workoutExercises))
+	for _, we := range workoutExercises {
+		exercise := TemplateExercise{
+			ID:           we.ID,
+			ExerciseID:   we.ExerciseID,
+			ExerciseName: we.ExerciseName,
+			ExerciseType: we.ExerciseType,
+			Position:     we.Position,
+			Sets:         we.Sets,
+			Reps:         we.Reps,
+			TimeSeconds:  we.TimeSeconds,
+			Weight:       we.Weight,
+			Notes:        we.Notes,
+		}
+		for _, set := range we.SetDetails {
+			exercise.SetDetails = append(exercise.SetDetails, TemplateSet{
+				Position:    set.Position,
+				SetType:     set.SetType,
+				Reps:        set.Reps,
+				TimeSeconds: set.TimeSeconds,
+				Weight:      set.Weight,
+				RPE:         set.RPE,
+			})
+		}
+		exercises = append(exercises, exercise)
+	}
+	return exercises, nil
+}
+
+// CloneTemplate copies a template into the user's own workouts
+func (s *WorkoutService) CloneTemplate(ctx context.Context, id int, userID int, input CloneWorkoutInput) (int64, error) {
+	template, err := s.getTemplate(ctx, id, userID)
+	if err != nil {
+		return 0, err
 	}
 
+	name := input.Name
+	if name == "" {
+		name = template.Name
+	}
+
+	workoutID, err := s.workoutRepo.Clone(ctx, id, userID, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clone template: %w", err)
+	}
+	if err := s.recordChange(ctx, int(workoutID), entities.WorkoutChangeEntityWorkout, workoutID, entities.WorkoutChangeCreated); err != nil {
+		return 0, err
+	}
+
+	return workoutID, nil
+}
+
+// GetWorkoutExercises retrieves all exercises for a workout
+func (s *WorkoutService) GetWorkoutExercises(ctx context.Context, workoutID int) ([]entities.WorkoutExercise, error) {
 	// Get exercises for workout
 	exercises, err := s.workoutExerciseRepo.GetAllForWorkout(ctx, workoutID)
 	if err != nil {
@@ -136,6 +315,15 @@ func (s *WorkoutService) GetWorkoutExercises(ctx context.Context, workoutID int,
 	return exercises, nil
 }
 
+// GetWorkoutExercise retrieves a single exercise of a workout with its sets
+func (s *WorkoutService) GetWorkoutExercise(ctx context.Context, workoutExerciseID int) (*entities.WorkoutExercise, error) {
+	workoutExercise, err := s.workoutExerciseRepo.GetByID(ctx, workoutExerciseID)
+	if err != nil {
+		return nil, notFound(err, ErrWorkoutExerciseNotFound)
+	}
+	return workoutExercise, nil
+}
+
 // AddExerciseToWorkoutInput represents input for adding an exercise to a workout
 type AddExerciseToWorkoutInput struct {
 	ExerciseID  int      `json:"exercise_id" binding:"required"`
@@ -147,15 +335,15 @@ type AddExerciseToWorkoutInput struct {
 	Notes       *string  `json:"notes,omitempty"`
 }
 
-// AddExerciseToWorkout adds an exercise to a workout with ownership verification
-func (s *WorkoutService) AddExerciseToWorkout(ctx context.Context, workoutID int, userID int, input AddExerciseToWorkoutInput) (int64, error) {
-	// Verify workout belongs to user
-	workout, err := s.workoutRepo.GetByID(ctx, workoutID)
+// AddExerciseToWorkout adds an exercise to a workout
+func (s *WorkoutService) AddExerciseToWorkout(ctx context.Context, workoutID int, input AddExerciseToWorkoutInput) (int64, error) {
+	// Archived exercises stay in existing workouts but cannot be added to new ones
+	exercise, err := s.exerciseRepo.GetByID(ctx, input.ExerciseID)
 	if err != nil {
-		return 0, fmt.Errorf("workout not found: %w", err)
+		return 0, notFound(err, apperrors.Invalid("unknown_exercise", "exercise %d not found", input.ExerciseID).WithField("exercise_id", "no exercise with this ID"))
 	}
-	if workout.UserID != userID {
-		return 0, fmt.Errorf("unauthorized: workout does not belong to user")
+	if exercise.ArchivedWhen != nil {
+		return 0, ErrExerciseArchived
 	}
 
 	// Create workout exercise
@@ -163,6 +351,9 @@ func (s *WorkoutService) AddExerciseToWorkout(ctx context.Context, workoutID int
 	if err != nil {
 		return 0, fmt.Errorf("failed to add exercise to workout: %w", err)
 	}
+	if err := s.recordChange(ctx, workoutID, entities.WorkoutChangeEntityWorkoutExercise, id, entities.WorkoutChangeCreated); err != nil {
+		return 0, err
+	}
 
 	return id, nil
 }
@@ -175,56 +366,168 @@ type UpdateWorkoutExerciseInput struct {
 	TimeSeconds *int     `json:"time_seconds,omitempty"`
 	Weight      *float64 `json:"weight,omitempty"`
 	Notes       *string  `json:"notes,omitempty"`
+	Version     int      `json:"version"` // Expected version; 0 (If-Match: *) skips the check
 }
 
-// UpdateWorkoutExercise updates a workout exercise with ownership verification
-func (s *WorkoutService) UpdateWorkoutExercise(ctx context.Context, workoutExerciseID int, userID int, input UpdateWorkoutExerciseInput) error {
-	// Get workout exercise
-	workoutExercise, err := s.workoutExerciseRepo.GetByID(ctx, workoutExerciseID)
+// UpdateWorkoutExercise updates an exercise of a workout. Once the exercise has individual sets,
+// its sets, reps, time and weight are their summary and can only be sent back unchanged.
+func (s *WorkoutService) UpdateWorkoutExercise(ctx context.Context, workoutID int, workoutExerciseID int, input UpdateWorkoutExerciseInput) error {
+	workoutExercise, err := s.GetWorkoutExercise(ctx, workoutExerciseID)
 	if err != nil {
-		return fmt.Errorf("workout exercise not found: %w", err)
+		return err
 	}
-
-	// Verify workout belongs to user
-	workout, err := s.workoutRepo.GetByID(ctx, workoutExercise.WorkoutID)
-	if err != nil {
-		return fmt.Errorf("workout not found: %w", err)
-	}
-	if workout.UserID != userID {
-		return fmt.Errorf("unauthorized: workout does not belong to user")
+	if len(workoutExercise.SetDetails) > 0 {
+		if err := checkSetSummary(workoutExercise, input); err != nil {
+			return err
+		}
 	}
 
 	// Update workout exercise
-	err = s.workoutExerciseRepo.Update(ctx, workoutExerciseID, input.Position, input.Sets, input.Reps, input.TimeSeconds, input.Weight, input.Notes)
+	err = s.workoutExerciseRepo.Update(ctx, workoutExerciseID, input.Version, input.Position, input.Sets, input.Reps, input.TimeSeconds, input.Weight, input.Notes)
 	if err != nil {
 		return fmt.Errorf("failed to update workout exercise: %w", err)
 	}
 
+	return s.recordChange(ctx, workoutID, entities.WorkoutChangeEntityWorkoutExercise, int64(workoutExerciseID), entities.WorkoutChangeUpdated)
+}
+
+// checkSetSummary rejects scalar values that differ from the summary of the individual sets, which
+// would be overwritten by the summary on the next read
+func checkSetSummary(workoutExercise *entities.WorkoutExercise, input UpdateWorkoutExerciseInput) error {
+	err := ErrSetsDefined
+	if input.Sets != nil && !equalValues(input.Sets, workoutExercise.Sets) {
+		err = err.WithField("sets", "is the number of individual sets")
+	}
+	if input.Reps != nil && !equalValues(input.Reps, workoutExercise.Reps) {
+		err = err.WithField("reps", "is taken from the top set")
+	}
+	if input.TimeSeconds != nil && !equalValues(input.TimeSeconds, workoutExercise.TimeSeconds) {
+		err = err.WithField("time_seconds", "is taken from the top set")
+	}
+	if input.Weight != nil && !equalValues(input.Weight, workoutExercise.Weight) {
+		err = err.WithField("weight", "is taken from the top set")
+	}
+	if len(err.Fields) > 0 {
+		return err
+	}
 	return nil
 }
 
-// RemoveExerciseFromWorkout removes an exercise from a workout with ownership verification
-func (s *WorkoutService) RemoveExerciseFromWorkout(ctx context.Context, workoutExerciseID int, userID int) error {
-	// Get workout exercise
-	workoutExercise, err := s.workoutExerciseRepo.GetByID(ctx, workoutExerciseID)
+// equalValues reports whether two optional values are both unset or equal
+func equalValues[T comparable](a *T, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// RemoveExerciseFromWorkout removes an exercise from a workout
+func (s *WorkoutService) RemoveExerciseFromWorkout(ctx context.Context, workoutID int, workoutExerciseID int) error {
+	// Delete workout exercise
+	err := s.workoutExerciseRepo.Delete(ctx, workoutExerciseID)
 	if err != nil {
-		return fmt.Errorf("workout exercise not found: %w", err)
+		return fmt.Errorf("failed to remove exercise from workout: %w", err)
+	}
+
+	return s.recordChange(ctx, workoutID, entities.WorkoutChangeEntityWorkoutExercise, int64(workoutExerciseID), entities.WorkoutChangeDeleted)
+}
+
+// GetSetTypes returns all valid set types
+func (s *WorkoutService) GetSetTypes() []string {
+	return []string{
+		string(entities.SetTypeWarmUp),
+		string(entities.SetTypeWorking),
+		string(entities.SetTypeDrop),
+		string(entities.SetTypeFailure),
 	}
+}
+
+// GetWorkoutExerciseSets retrieves the individual sets of a workout exercise
+func (s *WorkoutService) GetWorkoutExerciseSets(ctx context.Context, workoutExerciseID int) ([]entities.WorkoutExerciseSet, error) {
+	return s.workoutExerciseSetRepo.GetAllForWorkoutExercise(ctx, workoutExerciseID)
+}
 
-	// Verify workout belongs to user
-	workout, err := s.workoutRepo.GetByID(ctx, workoutExercise.WorkoutID)
+// WorkoutExerciseSetInput represents input for creating or updating a set of a workout exercise
+type WorkoutExerciseSetInput struct {
+	Position    int      `json:"position"` // Defaults to the end of the list when creating
+	SetType     string   `json:"set_type"` // Defaults to Working
+	Reps        *int     `json:"reps,omitempty"`
+	TimeSeconds *int     `json:"time_seconds,omitempty"`
+	Weight      *float64 `json:"weight,omitempty"`
+	RPE         *float64 `json:"rpe,omitempty" binding:"omitempty,min=1,max=10"`
+	Version     int      `json:"version"` // Expected version when updating; 0 (If-Match: *) skips the check
+}
+
+// validateSetType resolves the set type of the input, defaulting to Working
+func (s *WorkoutService) validateSetType(setType string) (entities.SetType, error) {
+	if setType == "" {
+		return entities.SetTypeWorking, nil
+	}
+	for _, t := range s.GetSetTypes() {
+		if setType == t {
+			return entities.SetType(setType), nil
+		}
+	}
+	return "", apperrors.Invalid("invalid_set_type", "invalid set type: %s", setType).WithField("set_type", "must be one of %s", strings.Join(s.GetSetTypes(), ", "))
+}
+
+// AddSetToWorkoutExercise adds a set to an exercise of a workout
+func (s *WorkoutService) AddSetToWorkoutExercise(ctx context.Context, workoutID int, workoutExerciseID int, input WorkoutExerciseSetInput) (int64, error) {
+	workoutExercise, err := s.GetWorkoutExercise(ctx, workoutExerciseID)
 	if err != nil {
-		return fmt.Errorf("workout not found: %w", err)
+		return 0, err
 	}
-	if workout.UserID != userID {
-		return fmt.Errorf("unauthorized: workout does not belong to user")
+
+	setType, err := s.validateSetType(input.SetType)
+	if err != nil {
+		return 0, err
 	}
 
-	// Delete workout exercise
-	err = s.workoutExerciseRepo.Delete(ctx, workoutExerciseID)
+	position := input.Position
+	if position <= 0 {
+		position = len(workoutExercise.SetDetails) + 1
+	}
+
+	id, err := s.workoutExerciseSetRepo.Create(ctx, workoutExerciseID, position, setType, input.Reps, input.TimeSeconds, input.Weight, input.RPE)
 	if err != nil {
-		return fmt.Errorf("failed to remove exercise from workout: %w", err)
+		return 0, fmt.Errorf("failed to add set to workout exercise: %w", err)
+	}
+	if err := s.recordChange(ctx, workoutID, entities.WorkoutChangeEntityWorkoutExerciseSet, id, entities.WorkoutChangeCreated); err != nil {
+		return 0, err
 	}
 
-	return nil
+	return id, nil
+}
+
+// UpdateWorkoutExerciseSet updates a set of an exercise of a workout
+func (s *WorkoutService) UpdateWorkoutExerciseSet(ctx context.Context, workoutID int, setID int, input WorkoutExerciseSetInput) error {
+	set, err := s.workoutExerciseSetRepo.GetByID(ctx, setID)
+	if err != nil {
+		return notFound(err, ErrSetNotFound)
+	}
+
+	setType, err := s.validateSetType(input.SetType)
+	if err != nil {
+		return err
+	}
+
+	position := input.Position
+	if position <= 0 {
+		position = set.Position
+	}
+
+	if err := s.workoutExerciseSetRepo.Update(ctx, setID, input.Version, position, setType, input.Reps, input.TimeSeconds, input.Weight, input.RPE); err != nil {
+		return fmt.Errorf("failed to update set: %w", err)
+	}
+
+	return s.recordChange(ctx, workoutID, entities.WorkoutChangeEntityWorkoutExerciseSet, int64(setID), entities.WorkoutChangeUpdated)
+}
+
+// RemoveSetFromWorkoutExercise removes a set from an exercise of a workout
+func (s *WorkoutService) RemoveSetFromWorkoutExercise(ctx context.Context, workoutID int, setID int) error {
+	if err := s.workoutExerciseSetRepo.Delete(ctx, setID); err != nil {
+		return fmt.Errorf("failed to remove set: %w", err)
+	}
+
+	return s.recordChange(ctx, workoutID, entities.WorkoutChangeEntityWorkoutExerciseSet, int64(setID), entities.WorkoutChangeDeleted)
 }
@@ -6,6 +6,40 @@ const API_BASE = import.meta.env.PROD
   ? 'https://api.goliath.c7d5a6.com' // TODO: Replace with your actual backend URL
   : '/api'
 
+// Invalid field of a request, as listed in the errors of a problem response
+export interface FieldError {
+  field: string
+  message: string
+}
+
+// Error returned by the backend as an RFC 7807 problem response. code is stable and meant to be
+// switched on (e.g. exercise_name_taken); the message is the detail and the field errors.
+export class ApiError extends Error {
+  status: number
+  code: string
+  fields: FieldError[]
+
+  constructor(status: number, code: string, detail: string, fields: FieldError[]) {
+    const reasons = fields.map((f) => `${f.field} ${f.message}`).join(', ')
+    super(reasons ? `${detail} (${reasons})` : detail)
+    this.name = 'ApiError'
+    this.status = status
+    this.code = code
+    this.fields = fields
+  }
+}
+
+// Reads the problem response of a failed request
+async function apiError(response: Response): Promise<ApiError> {
+  const problem = await response.json().catch(() => ({}))
+  return new ApiError(
+    response.status,
+    problem.code || 'internal_error',
+    problem.detail || `API Error: ${response.statusText}`,
+    problem.errors || [],
+  )
+}
+
 // Helper function for authenticated GET requests
 export async function apiGet<T>(endpoint: string): Promise<T> {
   const response = await fetch(`${API_BASE}${endpoint}`, {
@@ -17,12 +51,38 @@ export async function apiGet<T>(endpoint: string): Promise<T> {
   })
 
   if (!response.ok) {
-    throw new Error(`API Error: ${response.statusText}`)
+    throw await apiError(response)
   }
 
   return response.json()
 }
 
+// Largest page the list endpoints return; they return 50 items unless a limit is given
+const MAX_LIST_LIMIT = 200
+
+// Page of a list endpoint: the items under their key (e.g. muscles) and the cursor of the next page
+interface ListPage {
+  next_cursor: string | null
+  [key: string]: unknown
+}
+
+// Helper function for authenticated GET requests of every page of a list endpoint
+export async function apiGetAll<T>(endpoint: string, key: string): Promise<T[]> {
+  const separator = endpoint.includes('?') ? '&' : '?'
+  const items: T[] = []
+  let cursor: string | null = null
+  do {
+    const query: string = cursor
+      ? `limit=${MAX_LIST_LIMIT}&cursor=${encodeURIComponent(cursor)}`
+      : `limit=${MAX_LIST_LIMIT}`
+    const page: ListPage = await apiGet<ListPage>(`${endpoint}${separator}${query}`)
+    items.push(...(page[key] as T[]))
+    cursor = page.next_cursor
+  } while (cursor)
+
+  return items
+}
+
 // Helper function for authenticated POST requests
 export async function apiPost<T>(endpoint: string, data: any): Promise<T> {
   const response = await fetch(`${API_BASE}${endpoint}`, {
@@ -35,8 +95,7 @@ export async function apiPost<T>(endpoint: string, data: any): Promise<T> {
   })
 
   if (!response.ok) {
-    const error = await response.json().catch(() => ({ error: response.statusText }))
-    throw new Error(error.error || `API Error: ${response.statusText}`)
+    throw await apiError(response)
   }
 
   return response.json()
@@ -54,8 +113,7 @@ export async function apiPut<T>(endpoint: string, data: any): Promise<T> {
   })
 
   if (!response.ok) {
-    const error = await response.json().catch(() => ({ error: response.statusText }))
-    throw new Error(error.error || `API Error: ${response.statusText}`)
+    throw await apiError(response)
   }
 
   return response.json()
@@ -72,8 +130,7 @@ export async function apiDelete<T>(endpoint: string): Promise<T> {
   })
 
   if (!response.ok) {
-    const error = await response.json().catch(() => ({ error: response.statusText }))
-    throw new Error(error.error || `API Error: ${response.statusText}`)
+    throw await apiError(response)
   }
 
   return response.json()
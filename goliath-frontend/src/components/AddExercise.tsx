@@ -1,6 +1,6 @@
 import { createSignal, createResource, For, Show } from 'solid-js'
 import { useNavigate } from '@solidjs/router'
-import { apiGet, apiPost } from '../api'
+import { ApiError, apiGet, apiGetAll, apiPost } from '../api'
 
 interface Muscle {
   id: number
@@ -13,8 +13,7 @@ interface SelectedMuscle extends Muscle {
 }
 
 async function fetchMuscles() {
-  const data = await apiGet<{ muscles: Muscle[] }>('/muscles')
-  return data.muscles
+  return apiGetAll<Muscle>('/muscles', 'muscles')
 }
 
 async function fetchExerciseTypes() {
@@ -98,7 +97,11 @@ export default function AddExercise() {
       // Success - navigate to exercises page
       navigate('/exercises')
     } catch (err: any) {
-      setError(err.message || 'Network error')
+      if (err instanceof ApiError && err.code === 'exercise_name_taken') {
+        setError(`An exercise named "${name().trim()}" already exists`)
+      } else {
+        setError(err.message || 'Network error')
+      }
       setIsSubmitting(false)
     }
   }
@@ -1,6 +1,6 @@
 import { createSignal, createResource, createEffect, For, Show } from 'solid-js'
 import { useNavigate, useParams } from '@solidjs/router'
-import { apiGet, apiPut } from '../api'
+import { ApiError, apiGet, apiGetAll, apiPut } from '../api'
 
 interface Muscle {
   id: number
@@ -16,6 +16,7 @@ interface ExerciseMuscle {
 
 interface Exercise {
   id: number
+  version: number
   name: string
   type: string
   muscles: ExerciseMuscle[]
@@ -30,8 +31,7 @@ async function fetchExercise(id: number) {
 }
 
 async function fetchMuscles() {
-  const data = await apiGet<{ muscles: Muscle[] }>('/muscles')
-  return data.muscles
+  return apiGetAll<Muscle>('/muscles', 'muscles')
 }
 
 async function fetchExerciseTypes() {
@@ -129,6 +129,7 @@ export default function EditExercise() {
     
     try {
       await apiPut(`/exercises/${exerciseId}`, {
+        version: exercise()!.version,
         name: name().trim(),
         type: selectedType(),
         muscles: selectedMuscles().map(m => ({
@@ -140,7 +141,13 @@ export default function EditExercise() {
       // Success - navigate to exercises page
       navigate('/exercises')
     } catch (err: any) {
-      setError(err.message || 'Network error')
+      if (err instanceof ApiError && err.code === 'exercise_name_taken') {
+        setError(`An exercise named "${name().trim()}" already exists`)
+      } else if (err instanceof ApiError && err.code === 'version_conflict') {
+        setError('This exercise was changed elsewhere since you opened it; reload the page and try again')
+      } else {
+        setError(err.message || 'Network error')
+      }
       setIsSubmitting(false)
     }
   }
@@ -1,17 +1,19 @@
 import { createSignal, createResource, createEffect, Show, For } from 'solid-js'
 import { useNavigate, useParams } from '@solidjs/router'
-import { apiGet, apiPut, apiPost, apiDelete } from '../api'
+import { ApiError, apiGet, apiGetAll, apiPut, apiPost, apiDelete } from '../api'
 import { useAuth } from '../auth'
 import { A } from '@solidjs/router'
 
 interface Workout {
   id: number
+  version: number
   name: string
   user_id: number
 }
 
 interface WorkoutExercise {
   id: number
+  version: number
   exercise_id: number
   exercise_name: string
   exercise_type: string
@@ -39,8 +41,7 @@ async function fetchWorkoutExercises(id: number) {
 }
 
 async function fetchAllExercises() {
-  const data = await apiGet<{ exercises: Exercise[] }>('/exercises')
-  return data.exercises
+  return apiGetAll<Exercise>('/exercises', 'exercises')
 }
 
 export default function EditWorkout() {
@@ -94,12 +95,17 @@ export default function EditWorkout() {
     
     try {
       await apiPut(`/workouts/${workoutId}`, {
+        version: workout()!.version,
         name: name().trim(),
       })
 
       navigate('/workouts')
     } catch (err: any) {
-      setError(err.message || 'Network error')
+      if (err instanceof ApiError && err.code === 'version_conflict') {
+        setError('This workout was changed elsewhere since you opened it; reload the page and try again')
+      } else {
+        setError(err.message || 'Network error')
+      }
       setIsSubmitting(false)
     }
   }
@@ -121,7 +127,11 @@ export default function EditWorkout() {
       setShowExerciseSearch(false)
       refetchExercises()
     } catch (err: any) {
-      setError(err.message || 'Failed to add exercise')
+      if (err instanceof ApiError && err.code === 'exercise_archived') {
+        setError(`${exercise.name} is archived and can no longer be added to workouts`)
+      } else {
+        setError(err.message || 'Failed to add exercise')
+      }
     }
   }
 
@@ -131,6 +141,7 @@ export default function EditWorkout() {
 
     try {
       await apiPut(`/workouts/${workoutId}/exercises/${ex.id}`, {
+        version: ex.version,
         position: ex.position,
         sets: ex.sets,
         reps: ex.reps,
@@ -142,7 +153,15 @@ export default function EditWorkout() {
       setEditingExercise(null)
       refetchExercises()
     } catch (err: any) {
-      setError(err.message || 'Failed to update exercise')
+      if (err instanceof ApiError && err.code === 'sets_defined') {
+        setError('Sets, reps, time and weight of this exercise come from its individual sets; change those instead')
+      } else if (err instanceof ApiError && err.code === 'version_conflict') {
+        setError('This exercise was changed elsewhere; it has been reloaded, please edit it again')
+        setEditingExercise(null)
+        refetchExercises()
+      } else {
+        setError(err.message || 'Failed to update exercise')
+      }
     }
   }
 
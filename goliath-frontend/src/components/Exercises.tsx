@@ -1,6 +1,6 @@
 import { createSignal, createResource, For, Show, createMemo } from 'solid-js'
 import { A } from '@solidjs/router'
-import { apiGet } from '../api'
+import { apiGetAll } from '../api'
 
 interface ExerciseArea {
   exercise_area_id: number
@@ -21,7 +21,8 @@ interface ExercisesResponse {
 }
 
 async function fetchExercises(): Promise<ExercisesResponse> {
-  return apiGet<ExercisesResponse>('/exercises')
+  const exercises = await apiGetAll<Exercise>('/exercises', 'exercises')
+  return { exercises, count: exercises.length }
 }
 
 const typeColors: Record<string, { bg: string; text: string }> = {
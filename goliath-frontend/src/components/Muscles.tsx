@@ -1,5 +1,5 @@
 import { createSignal, createResource, For, Show, createMemo } from 'solid-js'
-import { apiGet } from '../api'
+import { apiGetAll } from '../api'
 
 interface Muscle {
   id: number
@@ -15,7 +15,8 @@ interface MusclesResponse {
 }
 
 async function fetchMuscles(): Promise<MusclesResponse> {
-  return apiGet<MusclesResponse>('/muscles')
+  const muscles = await apiGetAll<Muscle>('/muscles', 'muscles')
+  return { muscles, count: muscles.length }
 }
 
 export default function Muscles() {
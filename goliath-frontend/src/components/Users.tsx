@@ -1,5 +1,5 @@
 import { createSignal, createResource, For, Show, createMemo } from 'solid-js'
-import { apiGet } from '../api'
+import { apiGetAll } from '../api'
 
 interface User {
   id: number
@@ -18,7 +18,8 @@ interface UsersResponse {
 }
 
 async function fetchUsers(): Promise<UsersResponse> {
-  return apiGet<UsersResponse>('/users')
+  const users = await apiGetAll<User>('/users', 'users')
+  return { users, count: users.length }
 }
 
 function formatDate(dateString: string): string {
@@ -1,6 +1,6 @@
 import { createSignal, createResource, For, Show, createMemo } from 'solid-js'
 import { A } from '@solidjs/router'
-import { apiGet, apiDelete } from '../api'
+import { apiGetAll, apiDelete } from '../api'
 import { useAuth } from '../auth'
 
 interface Workout {
@@ -16,7 +16,8 @@ interface WorkoutsResponse {
 }
 
 async function fetchWorkouts(): Promise<WorkoutsResponse> {
-  return apiGet<WorkoutsResponse>('/workouts')
+  const workouts = await apiGetAll<Workout>('/workouts', 'workouts')
+  return { workouts, count: workouts.length }
 }
 
 export default function Workouts() {